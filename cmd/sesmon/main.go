@@ -4,20 +4,100 @@ sesmon - SES monitoring and alerting daemon
 package main
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
+	"runtime"
+	"runtime/debug"
 	"syscall"
+	"text/tabwriter"
+	"time"
 
+	"github.com/desertwitch/sesmon/pkg/sesmon"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 // Version is the program version as filled in by the Makefile.
 var Version string
 
+var (
+	// errNotReloadable occurs when a configuration cannot be re-read for a reload.
+	errNotReloadable = errors.New("not reloadable")
+
+	// errInvalidFlag occurs when a command-line flag has an invalid value.
+	errInvalidFlag = errors.New("invalid flag")
+)
+
+// buildInfo is the build and runtime information of the program (as printed by "version").
+type buildInfo struct {
+	Version    string
+	GoVersion  string
+	Platform   string
+	Commit     string
+	CommitDate string
+	Modified   bool
+	SgSes      string
+	Filesystem string
+}
+
+// newBuildInfo returns the [buildInfo] of the program, with the commit (and its date)
+// as stamped into the binary by the Go toolchain (if built from a version control checkout),
+// and the sg_ses executable as found in PATH (from its path or name, "" if not found).
+func newBuildInfo(sgSesPath string) buildInfo {
+	info := buildInfo{
+		Version:    Version,
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		Filesystem: afero.NewOsFs().Name(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitDate = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if path, err := exec.LookPath(sgSesPath); err == nil {
+		info.SgSes = path
+	}
+
+	return info
+}
+
+// fne returns the first non-empty string of two strings.
+func fne(a, b string) string {
+	if a != "" {
+		return a
+	}
+
+	return b
+}
+
+// exitCodeError is an error that requests a specific exit code of the program.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+// Error returns the error message as a string.
+func (e *exitCodeError) Error() string {
+	return e.msg
+}
+
 // newRootCmd returns the primary [cobra.Command] pointer for the program.
 func newRootCmd(ctx context.Context) *cobra.Command {
 	rootCmd := &cobra.Command{
@@ -31,60 +111,215 @@ func newRootCmd(ctx context.Context) *cobra.Command {
 	monitorCmd := newMonitorCmd(ctx)
 	checkCmd := newCheckCmd()
 	testCmd := newTestCmd()
+	testNotifyCmd := newTestNotifyCmd(ctx)
+	checkStatusCmd := newCheckStatusCmd(ctx)
+	dumpCmd := newDumpCmd(ctx)
+	configDumpCmd := newConfigDumpCmd()
+	listDevicesCmd := newListDevicesCmd(ctx)
+	statusCmd := newStatusCmd()
+	ackCmd := newAckCmd()
+	versionCmd := newVersionCmd()
 
-	rootCmd.AddCommand(monitorCmd, checkCmd, testCmd)
+	rootCmd.AddCommand(monitorCmd, checkCmd, testCmd, testNotifyCmd, checkStatusCmd,
+		dumpCmd, configDumpCmd, listDevicesCmd, statusCmd, ackCmd, versionCmd)
 
 	return rootCmd
 }
 
 // newMonitorCmd returns the "monitor" [cobra.Command] pointer for the program.
 func newMonitorCmd(ctx context.Context) *cobra.Command {
+	var logJSON, once, dryRun, skipToolCheck bool
+	var duration time.Duration
+
 	monitorCmd := &cobra.Command{
-		Use:   "monitor <config.yaml>",
+		Use:   "monitor <config.yaml|dir|->",
 		Short: "Monitor target SES-capable devices using a configuration file",
 		Args:  cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			yamlConfig, err := os.ReadFile(args[0])
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if duration < 0 {
+				return fmt.Errorf("%w: --duration must be >= 0", errInvalidFlag)
+			}
+
+			yamlConfig, err := readConfig(cmd, args[0])
 			if err != nil {
 				return fmt.Errorf("failure reading configuration file: %w", err)
 			}
 
-			prog, err := NewProgram(yamlConfig, nil, nil, nil, os.Stderr)
+			opts := append(programOptions(logJSON), sesmon.WithSystemdNotify())
+			if dryRun {
+				opts = append(opts, sesmon.WithDryRun())
+			}
+
+			var out io.Writer = os.Stderr
+			logFile, err := openLogFile(yamlConfig)
+			if err != nil {
+				return fmt.Errorf("failure opening log file: %w", err)
+			}
+			if logFile != nil {
+				defer logFile.Close()
+				out = logFile
+			}
+
+			prog, err := sesmon.NewProgram(yamlConfig, nil, nil, nil, out, opts...)
 			if err != nil {
 				return fmt.Errorf("failure establishing program: %w", err)
 			}
 
-			prog.Start(ctx)
-			<-prog.Done()
+			if !skipToolCheck {
+				if err := prog.CheckTools(); err != nil {
+					return fmt.Errorf("failure checking tools: %w", err)
+				}
+			}
 
-			return nil
+			if once {
+				if err := prog.RunOnce(ctx); err != nil {
+					return fmt.Errorf("failure polling devices: %w", err)
+				}
+
+				return nil
+			}
+
+			hups := make(chan os.Signal, 1)
+			signal.Notify(hups, syscall.SIGHUP)
+			defer signal.Stop(hups)
+
+			usr1s := make(chan os.Signal, 1)
+			signal.Notify(usr1s, syscall.SIGUSR1)
+			defer signal.Stop(usr1s)
+
+			runCtx := ctx
+			if duration > 0 {
+				var cancel context.CancelFunc
+				runCtx, cancel = context.WithTimeout(ctx, duration)
+				defer cancel()
+			}
+
+			prog.Start(runCtx)
+
+			for {
+				select {
+				case <-prog.Done():
+					return nil
+
+				case <-runCtx.Done():
+					if ctx.Err() == nil {
+						prog.Logger().Info(fmt.Sprintf("Duration of %s has elapsed - shutting down", duration), sesmon.LogEvent("shutdown"))
+					}
+					if err := prog.Shutdown(); err != nil {
+						return fmt.Errorf("failure shutting down: %w", err)
+					}
+
+					return nil
+
+				case <-hups:
+					if logFile != nil {
+						reopenLogFile(logFile, prog.Logger())
+					}
+					if err := reloadProgram(prog, args[0]); err != nil {
+						prog.Logger().Warn(fmt.Sprintf("Configuration was not reloaded: %v", err), sesmon.LogEvent("reload_failure"))
+					}
+
+				case <-usr1s:
+					reopenLogFile(logFile, prog.Logger())
+				}
+			}
 		},
 	}
 
+	monitorCmd.Flags().BoolVar(&logJSON, "log-json", false, "Output structured (JSON) log lines (overrides configuration file)")
+	monitorCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only log the notifications instead of dispatching them (overrides configuration file)")
+	monitorCmd.Flags().BoolVar(&once, "once", false, "Poll enabled devices once (comparing against output_dir) and exit (e.g. for cron)")
+	monitorCmd.Flags().BoolVar(&skipToolCheck, "skip-tool-check", false, "Do not check that sg_ses (or the fetch commands) can be found at startup")
+	monitorCmd.Flags().DurationVar(&duration, "duration", 0, "Shut down gracefully after running for the given time (e.g. \"10m\", 0 = indefinitely)")
+
 	return monitorCmd
 }
 
+// programOptions returns the [sesmon.ProgramOption] slice for the given CLI flags.
+func programOptions(logJSON bool) []sesmon.ProgramOption {
+	var opts []sesmon.ProgramOption
+	if logJSON {
+		opts = append(opts, sesmon.WithLogJSON())
+	}
+
+	return opts
+}
+
+// openLogFile opens the log file of a configuration (or returns nil if none is configured).
+// A configuration that cannot be parsed is left to be reported when establishing the program.
+func openLogFile(yamlConfig []byte) (*sesmon.RotatingFile, error) {
+	config, err := sesmon.ParseConfigYAML(yamlConfig)
+	if err != nil || config.LogFile == "" {
+		return nil, nil //nolint:nilnil,nilerr
+	}
+
+	return sesmon.NewRotatingFile(afero.NewOsFs(), config)
+}
+
+// reopenLogFile reopens the log file (e.g. after it was moved or truncated by logrotate),
+// as done on SIGUSR1 (without reloading the configuration) and on SIGHUP. Without a log
+// file (logging to stderr), there is nothing to reopen, which is only logged as such.
+func reopenLogFile(logFile *sesmon.RotatingFile, logger *slog.Logger) {
+	if logFile == nil {
+		logger.Info("No log_file is configured - nothing to reopen", sesmon.LogEvent("log_reopen"))
+
+		return
+	}
+
+	if err := logFile.Reopen(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reopening log file: %v\n", err)
+
+		return
+	}
+
+	logger.Info("Log file was reopened", sesmon.LogEvent("log_reopen"))
+}
+
+// stdinConfigPath is the configuration path for reading the configuration from standard input.
+const stdinConfigPath = "-"
+
+// readConfig reads the configuration from a file or directory (see [sesmon.ReadConfig]),
+// or from the standard input of the command if the path is [stdinConfigPath] (e.g. in pipelines).
+func readConfig(cmd *cobra.Command, configPath string) ([]byte, error) {
+	if configPath != stdinConfigPath {
+		return sesmon.ReadConfig(afero.NewOsFs(), configPath) //nolint:wrapcheck
+	}
+
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return nil, fmt.Errorf("failure reading from standard input: %w", err)
+	}
+
+	return data, nil
+}
+
+// reloadProgram re-reads a configuration file and reloads the [sesmon.Program] with it.
+func reloadProgram(prog *sesmon.Program, configPath string) error {
+	if configPath == stdinConfigPath {
+		return fmt.Errorf("%w: configuration was read from standard input", errNotReloadable)
+	}
+
+	yamlConfig, err := sesmon.ReadConfig(afero.NewOsFs(), configPath)
+	if err != nil {
+		return fmt.Errorf("failure reading configuration file: %w", err)
+	}
+
+	return prog.Reload(yamlConfig)
+}
+
 // newMonitorCmd returns the "check" [cobra.Command] pointer for the program.
 func newCheckCmd() *cobra.Command {
 	checkCmd := &cobra.Command{
-		Use:   "check <config.yaml>",
-		Short: "Check if a configuration file is syntactically parseable (YAML)",
+		Use:   "check <config.yaml|dir|->",
+		Short: "Check if a configuration file is syntactically parseable (YAML or JSON)",
 		Args:  cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			yamlConfig, err := os.ReadFile(args[0])
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yamlConfig, err := readConfig(cmd, args[0])
 			if err != nil {
 				return fmt.Errorf("failure reading configuration file: %w", err)
 			}
 
-			decoder := yaml.NewDecoder(bytes.NewReader(yamlConfig))
-			decoder.KnownFields(true)
-
-			var config ConfigYAML
-			if err := decoder.Decode(&config); err != nil {
-				return fmt.Errorf("failure parsing YAML: %w", err)
-			}
-
-			return nil
+			return sesmon.CheckConfig(yamlConfig)
 		},
 	}
 
@@ -93,28 +328,331 @@ func newCheckCmd() *cobra.Command {
 
 // // newMonitorCmd returns the "test" [cobra.Command] pointer for the program.
 func newTestCmd() *cobra.Command {
+	var logJSON bool
+
 	testCmd := &cobra.Command{
-		Use:   "test <config.yaml>",
-		Short: "Test if enabled devices of a configuration file can be resolved",
+		Use:   "test <config.yaml|dir|->",
+		Short: "Test if enabled devices of a configuration file can be resolved (and all devices are valid)",
 		Args:  cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			yamlConfig, err := os.ReadFile(args[0])
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yamlConfig, err := readConfig(cmd, args[0])
 			if err != nil {
 				return fmt.Errorf("failure reading configuration file: %w", err)
 			}
 
-			_, err = NewProgram(yamlConfig, nil, nil, nil, os.Stderr)
+			config, err := sesmon.ParseConfigYAML(yamlConfig)
+			if err != nil {
+				return fmt.Errorf("failure establishing program: %w", err)
+			}
+
+			if err := sesmon.ValidateConfig(config, afero.NewOsFs()); err != nil {
+				return fmt.Errorf("failure validating configuration:\n%w", err)
+			}
+
+			prog, err := sesmon.NewProgram(yamlConfig, nil, nil, nil, os.Stderr, programOptions(logJSON)...)
 			if err != nil {
 				return fmt.Errorf("failure establishing program: %w", err)
 			}
 
+			if err := prog.CheckTools(); err != nil {
+				return fmt.Errorf("failure checking tools: %w", err)
+			}
+
 			return nil
 		},
 	}
 
+	testCmd.Flags().BoolVar(&logJSON, "log-json", false, "Output structured (JSON) log lines (overrides configuration file)")
+
 	return testCmd
 }
 
+// newTestNotifyCmd returns the "test-notify" [cobra.Command] pointer for the program.
+func newTestNotifyCmd(ctx context.Context) *cobra.Command {
+	testNotifyCmd := &cobra.Command{
+		Use:   "test-notify <config.yaml|dir|-> <device>",
+		Short: "Send a synthetic notification using the notification agent of a device (path or address)",
+		Args:  cobra.ExactArgs(2), //nolint:mnd
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yamlConfig, err := readConfig(cmd, args[0])
+			if err != nil {
+				return fmt.Errorf("failure reading configuration file: %w", err)
+			}
+
+			retryRunner, err := sesmon.NewRetryCommandRunner(sesmon.NewLogger(os.Stderr, false, false))
+			if err != nil {
+				return fmt.Errorf("failure establishing command runner: %w", err)
+			}
+			runner, err := sesmon.NewPrintingCommandRunner(cmd.OutOrStdout(), retryRunner)
+			if err != nil {
+				return fmt.Errorf("failure establishing command runner: %w", err)
+			}
+
+			prog, err := sesmon.NewProgram(yamlConfig, nil, nil, runner, os.Stderr)
+			if err != nil {
+				return fmt.Errorf("failure establishing program: %w", err)
+			}
+
+			if err := prog.TestNotify(ctx, args[1]); err != nil {
+				return fmt.Errorf("failure sending test notification: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	return testNotifyCmd
+}
+
+// newCheckStatusCmd returns the "check-status" [cobra.Command] pointer for the program.
+func newCheckStatusCmd(ctx context.Context) *cobra.Command {
+	var perfdata bool
+
+	checkStatusCmd := &cobra.Command{
+		Use:   "check-status <config.yaml|dir|->",
+		Short: "Poll enabled devices once and report their status (Nagios/Icinga plugin)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result := &sesmon.CheckResult{}
+
+			yamlConfig, err := readConfig(cmd, args[0])
+			if err != nil {
+				result.AddProblem(sesmon.CheckStateUnknown, fmt.Sprintf("failure reading configuration file: %v", err))
+			} else if prog, err := sesmon.NewProgram(yamlConfig, nil, nil, nil, cmd.ErrOrStderr()); err != nil {
+				result.AddProblem(sesmon.CheckStateUnknown, fmt.Sprintf("failure establishing program: %v", err))
+			} else {
+				result = prog.CheckStatus(ctx)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), result.String(perfdata))
+
+			if result.State != sesmon.CheckStateOK {
+				cmd.SilenceErrors = true
+
+				return &exitCodeError{code: result.State, msg: result.StateName()}
+			}
+
+			return nil
+		},
+	}
+
+	checkStatusCmd.Flags().BoolVar(&perfdata, "perfdata", false, "Append performance data (temperatures and voltages)")
+
+	return checkStatusCmd
+}
+
+// newDumpCmd returns the "dump" [cobra.Command] pointer for the program.
+func newDumpCmd(ctx context.Context) *cobra.Command {
+	dumpCmd := &cobra.Command{
+		Use:   "dump <config.yaml|dir|-> [device]",
+		Short: "Poll enabled devices (or one device, by path or address) once and print the parsed results (JSON)",
+		Args:  cobra.RangeArgs(1, 2), //nolint:mnd
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yamlConfig, err := readConfig(cmd, args[0])
+			if err != nil {
+				return fmt.Errorf("failure reading configuration file: %w", err)
+			}
+
+			prog, err := sesmon.NewProgram(yamlConfig, nil, nil, nil, cmd.ErrOrStderr())
+			if err != nil {
+				return fmt.Errorf("failure establishing program: %w", err)
+			}
+
+			var device string
+			if len(args) > 1 {
+				device = args[1]
+			}
+
+			if err := prog.Dump(ctx, device, cmd.OutOrStdout()); err != nil {
+				return fmt.Errorf("failure dumping devices: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	return dumpCmd
+}
+
+// newConfigDumpCmd returns the "config-dump" [cobra.Command] pointer for the program.
+func newConfigDumpCmd() *cobra.Command {
+	configDumpCmd := &cobra.Command{
+		Use:   "config-dump <config.yaml|dir|-> [device]",
+		Short: "Print the effective (merged) configuration of enabled devices (or one device, by path or address) (JSON)",
+		Args:  cobra.RangeArgs(1, 2), //nolint:mnd
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yamlConfig, err := readConfig(cmd, args[0])
+			if err != nil {
+				return fmt.Errorf("failure reading configuration file: %w", err)
+			}
+
+			prog, err := sesmon.NewProgram(yamlConfig, nil, nil, nil, cmd.ErrOrStderr())
+			if err != nil {
+				return fmt.Errorf("failure establishing program: %w", err)
+			}
+
+			var device string
+			if len(args) > 1 {
+				device = args[1]
+			}
+
+			if err := prog.ConfigDump(device, cmd.OutOrStdout()); err != nil {
+				return fmt.Errorf("failure dumping configurations: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	return configDumpCmd
+}
+
+// newStatusCmd returns the "status" [cobra.Command] pointer for the program.
+func newStatusCmd() *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:   "status <socket> [device]",
+		Short: "Query the status of all devices (or one device, by path or address) from a running program (JSON)",
+		Args:  cobra.RangeArgs(1, 2), //nolint:mnd
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var device string
+			if len(args) > 1 {
+				device = args[1]
+			}
+
+			resp, err := sesmon.QueryStatus(args[0], device)
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			if resp.Device != nil {
+				data, err = json.MarshalIndent(resp.Device, "", "  ")
+			} else {
+				data, err = json.MarshalIndent(resp.Overview, "", "  ")
+			}
+			if err != nil {
+				return fmt.Errorf("failure marshalling status to JSON: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+
+			return nil
+		},
+	}
+
+	return statusCmd
+}
+
+// newAckCmd returns the "ack" [cobra.Command] pointer for the program.
+func newAckCmd() *cobra.Command {
+	ackCmd := &cobra.Command{
+		Use:   "ack <socket> <device> <element>",
+		Short: "Acknowledge an element (e.g. 23#4) of a device in a running program, skipping its notifications until its status changes",
+		Args:  cobra.ExactArgs(3), //nolint:mnd
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resp, err := sesmon.QueryAck(args[0], args[1], args[2])
+			if err != nil {
+				return err
+			}
+
+			data, err := json.MarshalIndent(resp.Ack, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failure marshalling acknowledgement to JSON: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+
+			return nil
+		},
+	}
+
+	return ackCmd
+}
+
+// newListDevicesCmd returns the "list-devices" [cobra.Command] pointer for the program.
+func newListDevicesCmd(ctx context.Context) *cobra.Command {
+	var sgSesPath, sysfsRoot string
+
+	listDevicesCmd := &cobra.Command{
+		Use:   "list-devices",
+		Short: "List discovered devices (with SAS address and SES capability)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger := sesmon.NewLogger(cmd.ErrOrStderr(), false, false)
+
+			finder, err := sesmon.NewDeviceFinder(afero.NewOsFs(), sysfsRoot, logger)
+			if err != nil {
+				return fmt.Errorf("failure establishing device finder: %w", err)
+			}
+
+			runner, err := sesmon.NewRetryCommandRunner(logger)
+			if err != nil {
+				return fmt.Errorf("failure establishing command runner: %w", err)
+			}
+
+			if err := sesmon.ListDevices(ctx, finder, runner, sgSesPath, cmd.OutOrStdout()); err != nil {
+				return fmt.Errorf("failure listing devices: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	listDevicesCmd.Flags().StringVar(&sgSesPath, "sg-ses-path", *sesmon.DefaultDeviceMonitorConfig().SgSesPath,
+		"Path to (or name of) the sg_ses executable used for probing the devices")
+	listDevicesCmd.Flags().StringVar(&sysfsRoot, "sysfs-root", sesmon.DefaultSysfsRoot,
+		"Mount point of sysfs used for looking up the devices")
+
+	return listDevicesCmd
+}
+
+// newVersionCmd returns the "version" [cobra.Command] pointer for the program.
+func newVersionCmd() *cobra.Command {
+	var verbose bool
+	var sgSesPath string
+
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the program version (with build and runtime information, if verbose)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			info := newBuildInfo(sgSesPath)
+			if !verbose {
+				fmt.Fprintln(cmd.OutOrStdout(), "sesmon version "+fne(info.Version, "unknown"))
+
+				return nil
+			}
+
+			commit := fne(info.Commit, "unknown")
+			if info.Modified {
+				commit += " (modified)"
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0) //nolint:mnd
+			fmt.Fprintf(w, "Version:\t%s\n", fne(info.Version, "unknown"))
+			fmt.Fprintf(w, "Commit:\t%s\n", commit)
+			fmt.Fprintf(w, "Commit date:\t%s\n", fne(info.CommitDate, "unknown"))
+			fmt.Fprintf(w, "Go version:\t%s\n", info.GoVersion)
+			fmt.Fprintf(w, "Platform:\t%s\n", info.Platform)
+			fmt.Fprintf(w, "sg_ses:\t%s\n", fne(info.SgSes, fmt.Sprintf("not found (%q in PATH)", sgSesPath)))
+			fmt.Fprintf(w, "Filesystem:\t%s\n", info.Filesystem)
+
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("failure printing version: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	versionCmd.Flags().BoolVarP(&verbose, "verbose", "v", false,
+		"Print also the build and runtime information (e.g. for bug reports)")
+	versionCmd.Flags().StringVar(&sgSesPath, "sg-ses-path", *sesmon.DefaultDeviceMonitorConfig().SgSesPath,
+		"Path to (or name of) the sg_ses executable to look for")
+
+	return versionCmd
+}
+
 func main() {
 	var exitCode int
 	defer func() {
@@ -127,13 +665,18 @@ func main() {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		defer recoverGoPanic("signals", nil)
+		defer sesmon.RecoverGoPanic("signals", nil)
 		<-sigs
 		cancel()
 	}()
 
 	rootCmd := newRootCmd(ctx)
 	if err := rootCmd.Execute(); err != nil {
-		exitCode = 1
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.code
+		} else {
+			exitCode = 1
+		}
 	}
 }
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
+	"github.com/desertwitch/sesmon/pkg/sesmon"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
-// Expectation: newRootCmd should create root command with monitor, check, and test subcommands.
+// Expectation: newRootCmd should create root command with monitor, check, test, test-notify, check-status and dump subcommands.
 func Test_newRootCmd_SubcommandsAdded_Success(t *testing.T) {
 	t.Parallel()
 
@@ -24,7 +29,7 @@ func Test_newRootCmd_SubcommandsAdded_Success(t *testing.T) {
 	require.True(t, rootCmd.CompletionOptions.DisableDefaultCmd)
 
 	commands := rootCmd.Commands()
-	require.Len(t, commands, 3)
+	require.Len(t, commands, 11)
 
 	commandNames := make([]string, len(commands))
 	for i, cmd := range commands {
@@ -33,6 +38,14 @@ func Test_newRootCmd_SubcommandsAdded_Success(t *testing.T) {
 	require.Contains(t, commandNames, "monitor")
 	require.Contains(t, commandNames, "check")
 	require.Contains(t, commandNames, "test")
+	require.Contains(t, commandNames, "test-notify")
+	require.Contains(t, commandNames, "check-status")
+	require.Contains(t, commandNames, "dump")
+	require.Contains(t, commandNames, "list-devices")
+	require.Contains(t, commandNames, "status")
+	require.Contains(t, commandNames, "ack")
+	require.Contains(t, commandNames, "config-dump")
+	require.Contains(t, commandNames, "version")
 }
 
 // Expectation: newMonitorCmd should return error when config file does not exist.
@@ -91,6 +104,119 @@ func Test_newMonitorCmd_InvalidYAML_Error(t *testing.T) {
 	require.Contains(t, err.Error(), "failure establishing program")
 }
 
+// Expectation: newMonitorCmd should poll the devices once and exit with the once flag.
+func Test_newMonitorCmd_Once_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	dumpPath := filepath.Join(tmpDir, "dump.json")
+	require.NoError(t, os.WriteFile(dumpPath, []byte(`{"join_of_diagnostic_pages":{"element_list":[]}}`), 0o600))
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+devices:
+  - device: `+dumpPath+`
+    type: 1
+    enabled: true
+    config:
+      output_dir: `+filepath.Join(tmpDir, "output")+`
+`), 0o600))
+
+	monitorCmd := newMonitorCmd(t.Context())
+
+	monitorCmd.SetOut(io.Discard)
+	monitorCmd.SetErr(io.Discard)
+
+	monitorCmd.SetArgs([]string{"--once", configPath})
+	require.NoError(t, monitorCmd.Execute())
+
+	_, err := os.Stat(filepath.Join(tmpDir, "output", "current_parsed.json"))
+	require.NoError(t, err)
+}
+
+// Expectation: newMonitorCmd should shut down gracefully once the duration has elapsed.
+func Test_newMonitorCmd_Duration_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	dumpPath := filepath.Join(tmpDir, "dump.json")
+	require.NoError(t, os.WriteFile(dumpPath, []byte(`{"join_of_diagnostic_pages":{"element_list":[]}}`), 0o600))
+
+	logPath := filepath.Join(tmpDir, "sesmon.log")
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+log_file: `+logPath+`
+devices:
+  - device: `+dumpPath+`
+    type: 1
+    enabled: true
+    config:
+      output_dir: `+filepath.Join(tmpDir, "output")+`
+`), 0o600))
+
+	monitorCmd := newMonitorCmd(t.Context())
+
+	monitorCmd.SetOut(io.Discard)
+	monitorCmd.SetErr(io.Discard)
+
+	monitorCmd.SetArgs([]string{"--duration", "500ms", configPath})
+	require.NoError(t, monitorCmd.Execute())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "Duration of 500ms has elapsed - shutting down")
+
+	_, err = os.Stat(filepath.Join(tmpDir, "output", "current_parsed.json"))
+	require.NoError(t, err)
+}
+
+// Expectation: newMonitorCmd should return an error for a negative duration.
+func Test_newMonitorCmd_Duration_Error(t *testing.T) {
+	t.Parallel()
+
+	monitorCmd := newMonitorCmd(t.Context())
+
+	monitorCmd.SetOut(io.Discard)
+	monitorCmd.SetErr(io.Discard)
+
+	monitorCmd.SetArgs([]string{"--duration", "-1s", "config.yaml"})
+	err := monitorCmd.Execute()
+	require.ErrorIs(t, err, errInvalidFlag)
+}
+
+// Expectation: reopenLogFile should reopen a moved log file (continuing to log into a new one).
+func Test_reopenLogFile_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	logFile, err := sesmon.NewRotatingFile(fsys, sesmon.ConfigYAML{LogFile: "/var/log/sesmon.log"})
+	require.NoError(t, err)
+	defer logFile.Close()
+
+	logger := sesmon.NewLogger(logFile, false, true)
+	logger.Info("before")
+	require.NoError(t, fsys.Rename("/var/log/sesmon.log", "/var/log/sesmon.log.old"))
+
+	reopenLogFile(logFile, logger)
+
+	data, err := afero.ReadFile(fsys, "/var/log/sesmon.log")
+	require.NoError(t, err)
+	require.Equal(t, "Log file was reopened\n", string(data))
+
+	data, err = afero.ReadFile(fsys, "/var/log/sesmon.log.old")
+	require.NoError(t, err)
+	require.Equal(t, "before\n", string(data))
+}
+
+// Expectation: reopenLogFile should only log that there is nothing to reopen without a log file.
+func Test_reopenLogFile_NoLogFile_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	reopenLogFile(nil, sesmon.NewLogger(&buf, false, true))
+	require.Equal(t, "No log_file is configured - nothing to reopen\n", buf.String())
+}
+
 // Expectation: newCheckCmd should return error when config file does not exist.
 func Test_newCheckCmd_ConfigFileNotFound_Error(t *testing.T) {
 	t.Parallel()
@@ -170,6 +296,58 @@ devices:
 	require.NoError(t, err)
 }
 
+// Expectation: newCheckCmd should succeed on valid JSON configuration file.
+func Test_newCheckCmd_ValidJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "valid.json")
+	validJSON := `{"devices": [{"device": "/dev/sg0", "address": "5000c500a1b2c3d4", "enabled": false}]}`
+	err := os.WriteFile(configPath, []byte(validJSON), 0o600)
+	require.NoError(t, err)
+
+	checkCmd := newCheckCmd()
+
+	checkCmd.SetOut(io.Discard)
+	checkCmd.SetErr(io.Discard)
+
+	checkCmd.SetArgs([]string{configPath})
+	err = checkCmd.Execute()
+
+	require.NoError(t, err)
+}
+
+// Expectation: newCheckCmd should read the configuration from standard input for "-".
+func Test_newCheckCmd_Stdin_Success(t *testing.T) {
+	t.Parallel()
+
+	checkCmd := newCheckCmd()
+
+	checkCmd.SetIn(strings.NewReader("devices:\n  - device: /dev/sg0\n    enabled: false\n"))
+	checkCmd.SetOut(io.Discard)
+	checkCmd.SetErr(io.Discard)
+
+	checkCmd.SetArgs([]string{"-"})
+	require.NoError(t, checkCmd.Execute())
+
+	checkCmd = newCheckCmd()
+
+	checkCmd.SetIn(strings.NewReader("unknown_field: value\n"))
+	checkCmd.SetOut(io.Discard)
+	checkCmd.SetErr(io.Discard)
+
+	checkCmd.SetArgs([]string{"-"})
+	require.ErrorContains(t, checkCmd.Execute(), "failure parsing YAML")
+}
+
+// Expectation: reloadProgram should refuse to reload a configuration read from standard input.
+func Test_reloadProgram_Stdin_Error(t *testing.T) {
+	t.Parallel()
+
+	err := reloadProgram(nil, "-")
+	require.ErrorIs(t, err, errNotReloadable)
+}
+
 // Expectation: newCheckCmd should return error when YAML has unknown fields.
 func Test_newCheckCmd_UnknownFields_Error(t *testing.T) {
 	t.Parallel()
@@ -281,3 +459,310 @@ devices:
 
 	require.NoError(t, err)
 }
+
+// Expectation: newTestCmd should return error when sg_ses of an enabled device cannot be found.
+func Test_newTestCmd_ToolNotFound_Error(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "valid.yaml")
+
+	validYAML := `---
+devices:
+  - device: /dev/null
+    enabled: true
+    config:
+      sg_ses_path: ` + filepath.Join(tmpDir, "missing") + `
+`
+	err := os.WriteFile(configPath, []byte(validYAML), 0o600)
+	require.NoError(t, err)
+
+	testCmd := newTestCmd()
+
+	testCmd.SetOut(io.Discard)
+	testCmd.SetErr(io.Discard)
+
+	testCmd.SetArgs([]string{configPath})
+	err = testCmd.Execute()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure checking tools")
+	require.Contains(t, err.Error(), "sg_ses_path")
+}
+
+// Expectation: newMonitorCmd and newTestCmd should provide the structured logging flag.
+func Test_LogJSONFlag_Success(t *testing.T) {
+	t.Parallel()
+
+	require.NotNil(t, newMonitorCmd(t.Context()).Flags().Lookup("log-json"))
+	require.NotNil(t, newTestCmd().Flags().Lookup("log-json"))
+}
+
+// Expectation: newMonitorCmd should provide the dry run flag.
+func Test_DryRunFlag_Success(t *testing.T) {
+	t.Parallel()
+
+	require.NotNil(t, newMonitorCmd(t.Context()).Flags().Lookup("dry-run"))
+}
+
+// Expectation: newTestNotifyCmd should return error when config file does not exist.
+func Test_newTestNotifyCmd_ConfigFileNotFound_Error(t *testing.T) {
+	t.Parallel()
+
+	testNotifyCmd := newTestNotifyCmd(t.Context())
+
+	testNotifyCmd.SetOut(io.Discard)
+	testNotifyCmd.SetErr(io.Discard)
+
+	testNotifyCmd.SetArgs([]string{"nonexistent.yaml", "/dev/sg0"})
+	err := testNotifyCmd.Execute()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure reading configuration file")
+}
+
+// Expectation: newTestNotifyCmd should return error when not exactly two arguments are provided.
+func Test_newTestNotifyCmd_WrongArgs_Error(t *testing.T) {
+	t.Parallel()
+
+	testNotifyCmd := newTestNotifyCmd(t.Context())
+
+	testNotifyCmd.SetOut(io.Discard)
+	testNotifyCmd.SetErr(io.Discard)
+
+	testNotifyCmd.SetArgs([]string{"config.yaml"})
+	err := testNotifyCmd.Execute()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "accepts 2 arg(s), received 1")
+}
+
+// Expectation: newCheckStatusCmd should report UNKNOWN (exit code 3) when the config file does not exist.
+func Test_newCheckStatusCmd_ConfigFileNotFound_Error(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	checkStatusCmd := newCheckStatusCmd(t.Context())
+
+	checkStatusCmd.SetOut(&out)
+	checkStatusCmd.SetErr(io.Discard)
+
+	checkStatusCmd.SetArgs([]string{"nonexistent.yaml"})
+	err := checkStatusCmd.Execute()
+
+	var exitErr *exitCodeError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, sesmon.CheckStateUnknown, exitErr.code)
+	require.Contains(t, out.String(), "SESMON UNKNOWN")
+	require.Contains(t, out.String(), "failure reading configuration file")
+}
+
+// Expectation: newCheckStatusCmd should report the status of the devices (exit code per state).
+func Test_newCheckStatusCmd_ValidConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "valid.yaml")
+
+	devicePath := filepath.Join(tmpDir, "device.json")
+	err := os.WriteFile(devicePath, []byte(`{"join_of_diagnostic_pages": {"element_list": [
+		{"element_type": {"i": 4}, "element_number": 0, "status_descriptor": {"status": {"i": 1}, "temperature": {"i": 30}}}
+	]}}`), 0o600)
+	require.NoError(t, err)
+
+	validYAML := `---
+devices:
+  - device: ` + devicePath + `
+    type: 1
+    enabled: true
+`
+	err = os.WriteFile(configPath, []byte(validYAML), 0o600)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	checkStatusCmd := newCheckStatusCmd(t.Context())
+
+	checkStatusCmd.SetOut(&out)
+	checkStatusCmd.SetErr(io.Discard)
+
+	checkStatusCmd.SetArgs([]string{configPath, "--perfdata"})
+	err = checkStatusCmd.Execute()
+
+	require.NoError(t, err)
+	require.Equal(t, "SESMON OK - 1 devices checked, no problems | 'device.json_4#0_temp'=30;;\n", out.String())
+}
+
+// Expectation: newDumpCmd should return error when config file does not exist.
+func Test_newDumpCmd_ConfigFileNotFound_Error(t *testing.T) {
+	t.Parallel()
+
+	dumpCmd := newDumpCmd(t.Context())
+
+	dumpCmd.SetOut(io.Discard)
+	dumpCmd.SetErr(io.Discard)
+
+	dumpCmd.SetArgs([]string{"nonexistent.yaml"})
+	err := dumpCmd.Execute()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure reading configuration file")
+}
+
+// Expectation: newDumpCmd should return error when too many arguments are provided.
+func Test_newDumpCmd_WrongArgs_Error(t *testing.T) {
+	t.Parallel()
+
+	dumpCmd := newDumpCmd(t.Context())
+
+	dumpCmd.SetOut(io.Discard)
+	dumpCmd.SetErr(io.Discard)
+
+	dumpCmd.SetArgs([]string{"config.yaml", "/dev/sg0", "extra"})
+	err := dumpCmd.Execute()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "accepts between 1 and 2 arg(s), received 3")
+}
+
+// Expectation: newDumpCmd should print the parsed results of a JSON file device.
+func Test_newDumpCmd_ValidConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "valid.yaml")
+
+	devicePath := filepath.Join(tmpDir, "device.json")
+	err := os.WriteFile(devicePath, []byte(`{"join_of_diagnostic_pages": {"element_list": [
+		{"element_type": {"i": 4}, "element_number": 0, "status_descriptor": {"status": {"i": 1}}}
+	]}}`), 0o600)
+	require.NoError(t, err)
+
+	validYAML := `---
+devices:
+  - device: ` + devicePath + `
+    type: 1
+    enabled: true
+`
+	err = os.WriteFile(configPath, []byte(validYAML), 0o600)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	dumpCmd := newDumpCmd(t.Context())
+
+	dumpCmd.SetOut(&out)
+	dumpCmd.SetErr(io.Discard)
+
+	dumpCmd.SetArgs([]string{configPath, devicePath})
+	err = dumpCmd.Execute()
+
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"4#0": {`)
+	require.Contains(t, out.String(), `"status": 1`)
+}
+
+// Expectation: newConfigDumpCmd should print the merged configuration of a device.
+func Test_newConfigDumpCmd_ValidConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "valid.yaml")
+
+	devicePath := filepath.Join(tmpDir, "device.json")
+	err := os.WriteFile(devicePath, []byte(`{}`), 0o600)
+	require.NoError(t, err)
+
+	validYAML := `---
+defaults:
+  config:
+    poll_interval: 2m
+devices:
+  - device: ` + devicePath + `
+    type: 1
+    enabled: true
+    config:
+      poll_attempts: 5
+`
+	err = os.WriteFile(configPath, []byte(validYAML), 0o600)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	configDumpCmd := newConfigDumpCmd()
+
+	configDumpCmd.SetOut(&out)
+	configDumpCmd.SetErr(io.Discard)
+
+	configDumpCmd.SetArgs([]string{configPath})
+	err = configDumpCmd.Execute()
+
+	require.NoError(t, err)
+	require.Contains(t, out.String(), `"poll_interval": "2m0s"`)
+	require.Contains(t, out.String(), `"poll_attempts": 5`)
+	require.Contains(t, out.String(), `"poll_attempt_timeout": "15s"`)
+}
+
+// Expectation: newConfigDumpCmd should return error when config file does not exist.
+func Test_newConfigDumpCmd_ConfigFileNotFound_Error(t *testing.T) {
+	t.Parallel()
+
+	configDumpCmd := newConfigDumpCmd()
+
+	configDumpCmd.SetOut(io.Discard)
+	configDumpCmd.SetErr(io.Discard)
+
+	configDumpCmd.SetArgs([]string{"nonexistent.yaml"})
+	err := configDumpCmd.Execute()
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure reading configuration file")
+}
+
+// Expectation: The status command should fail if no program is listening on the socket.
+func Test_newStatusCmd_NoSocket_Error(t *testing.T) {
+	t.Parallel()
+
+	cmd := newStatusCmd()
+	cmd.SetArgs([]string{filepath.Join(t.TempDir(), "missing.sock")})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "failure connecting to status socket")
+}
+
+// Expectation: The ack command should fail if no program is listening on the socket.
+func Test_newAckCmd_NoSocket_Error(t *testing.T) {
+	t.Parallel()
+
+	cmd := newAckCmd()
+	cmd.SetArgs([]string{filepath.Join(t.TempDir(), "missing.sock"), "/dev/sg0", "23#0"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "failure connecting to status socket")
+}
+
+// Expectation: The version command should print the version, and the build and runtime information if verbose.
+func Test_newVersionCmd_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	cmd := newVersionCmd()
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "sesmon version "+fne(Version, "unknown")+"\n", buf.String())
+
+	buf.Reset()
+	cmd = newVersionCmd()
+	cmd.SetArgs([]string{"--verbose", "--sg-ses-path", "/nonexistent/sg_ses"})
+	cmd.SetOut(&buf)
+	cmd.SetErr(io.Discard)
+
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, buf.String(), runtime.Version())
+	require.Contains(t, buf.String(), runtime.GOOS+"/"+runtime.GOARCH)
+	require.Contains(t, buf.String(), `not found ("/nonexistent/sg_ses" in PATH)`)
+	require.Contains(t, buf.String(), "OsFs")
+}
@@ -0,0 +1,153 @@
+package sesmon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// acksFilename is the file in [DeviceMonitorConfig.OutputDir] the acknowledgements
+// are persisted to (if [DeviceMonitorConfig.PersistAcks] is enabled).
+const acksFilename = "acks.json"
+
+// Acknowledge acknowledges an element (by its ID, e.g. "23#4") in its current status,
+// so that the notifications for its changes are skipped until its status changes to
+// another value (which clears the acknowledgement). It is safe for concurrent use.
+func (d *DeviceMonitor) Acknowledge(element string) (ElementAck, error) {
+	result, ok := d.Status().Results[element]
+	if !ok {
+		return ElementAck{}, fmt.Errorf("%w: [%s] (not among the last poll results)", errElementNotFound, element)
+	}
+
+	ack := ElementAck{
+		Element:    element,
+		Descriptor: result.Descriptor,
+		Status:     result.Status,
+		StatusDesc: result.StatusDesc,
+		AckedAt:    time.Now().Format(time.RFC3339),
+	}
+
+	d.state.acksMu.Lock()
+	if d.state.acks == nil {
+		d.state.acks = make(map[string]ElementAck)
+	}
+	d.state.acks[element] = ack
+	d.state.acksMu.Unlock()
+
+	d.logger.Info(fmt.Sprintf("Acknowledged element [%s] with status [%s] - skipping its notifications until its status changes",
+		element, fmtPtrStr(result.StatusDesc, fmtPtrInt(result.Status, "-"))), LogEvent("ack"))
+	d.persistAcks()
+
+	return ack, nil
+}
+
+// Acks returns the current acknowledgements of elements (safe for concurrent use).
+func (d *DeviceMonitor) Acks() map[string]ElementAck {
+	d.state.acksMu.Lock()
+	defer d.state.acksMu.Unlock()
+
+	return maps.Clone(d.state.acks)
+}
+
+// acknowledgedChanges removes the changes of acknowledged elements that remain in their
+// acknowledged status. Changes of acknowledged elements to another status clear their
+// acknowledgement instead (so that these changes and all further ones are alerted again).
+func (d *DeviceMonitor) acknowledgedChanges(changes []Change) []Change {
+	d.state.acksMu.Lock()
+	if len(d.state.acks) == 0 {
+		d.state.acksMu.Unlock()
+
+		return changes
+	}
+
+	var cleared bool
+	out := make([]Change, 0, len(changes))
+	for _, ch := range changes {
+		ack, ok := d.state.acks[ch.ID]
+		if !ok {
+			out = append(out, ch)
+
+			continue
+		}
+		if ch.After != nil && ptrIntEqual(ch.After.Status, ack.Status) {
+			continue
+		}
+
+		delete(d.state.acks, ch.ID)
+		cleared = true
+		out = append(out, ch)
+		d.logger.Info(fmt.Sprintf("Acknowledgement of element [%s] was cleared (as its status has changed)", ch.ID), LogEvent("ack_cleared"))
+	}
+	d.state.acksMu.Unlock()
+
+	if cleared {
+		d.persistAcks()
+	}
+	if excluded := len(changes) - len(out); excluded > 0 && *d.cfg.Verbose {
+		d.logger.Info(fmt.Sprintf("%d changes of acknowledged elements were excluded", excluded), LogEvent("changes_excluded"))
+	}
+
+	return out
+}
+
+// persistAcks writes the current acknowledgements to [DeviceMonitorConfig.OutputDir]
+// (if [DeviceMonitorConfig.PersistAcks] is enabled), only logging any failure.
+func (d *DeviceMonitor) persistAcks() {
+	if d.cfg.OutputDir == nil || !*d.cfg.PersistAcks {
+		return
+	}
+
+	data, err := json.MarshalIndent(d.Acks(), "", "  ")
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error marshalling acknowledgements to JSON: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	deviceDir, err := d.ensureDeviceFolder()
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error persisting acknowledgements: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	if err := writeFileAtomic(d.fsys, filepath.Join(deviceDir, d.outputName(acksFilename)), data, d.outputFileMode()); err != nil {
+		d.logger.Error(fmt.Sprintf("Error persisting acknowledgements: %v", err), LogEvent("error"))
+	}
+}
+
+// loadAcks reads the persisted acknowledgements from [DeviceMonitorConfig.OutputDir]
+// (if [DeviceMonitorConfig.PersistAcks] is enabled), where a missing file is no error.
+func (d *DeviceMonitor) loadAcks() error {
+	if d.cfg.OutputDir == nil || !*d.cfg.PersistAcks {
+		return nil
+	}
+
+	data, err := afero.ReadFile(d.fsys, filepath.Join(*d.cfg.OutputDir, d.outputName(acksFilename)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failure reading from file: %w", err)
+	}
+
+	var acks map[string]ElementAck
+	if err := json.Unmarshal(data, &acks); err != nil {
+		return fmt.Errorf("failure parsing acknowledgements: %w", err)
+	}
+
+	d.state.acksMu.Lock()
+	d.state.acks = acks
+	d.state.acksMu.Unlock()
+
+	if len(acks) > 0 {
+		d.logger.Info(fmt.Sprintf("Loaded %d acknowledged elements from the output folder", len(acks)), LogEvent("state_loaded"))
+	}
+
+	return nil
+}
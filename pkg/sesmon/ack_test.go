@@ -0,0 +1,113 @@
+package sesmon
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonAckElement returns the JSON of a device with a single element (of a status and prdfail bit).
+func jsonAckElement(status int, prdfail int) string {
+	return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,`+
+		`"status_descriptor":{"status":{"i":%d,"meaning":"S%d"},"prdfail":%d}}]}}`, status, status, prdfail)
+}
+
+// Expectation: Acknowledge should skip the notifications of an element until its status changes.
+func Test_DeviceMonitor_Acknowledge_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{PollAttempts: ptr(1)},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		n,
+	)
+
+	runner.setResponse(jsonAckElement(1, 0), "", nil)
+	m.tick(t.Context())
+
+	runner.setResponse(jsonAckElement(2, 0), "", nil)
+	m.tick(t.Context())
+	m.state.notifications.Wait()
+	require.Equal(t, 1, n.callCount())
+
+	ack, err := m.Acknowledge("23#0")
+	require.NoError(t, err)
+	require.Equal(t, "23#0", ack.Element)
+	require.Equal(t, ptr(2), ack.Status)
+	require.Contains(t, m.Status().Acks, "23#0")
+	require.Contains(t, buf.String(), "Acknowledged element [23#0] with status [S2]")
+
+	// A flapping field (in the acknowledged status) is not notified.
+	runner.setResponse(jsonAckElement(2, 1), "", nil)
+	m.tick(t.Context())
+	m.state.notifications.Wait()
+	require.Equal(t, 1, n.callCount())
+
+	// A change to another status clears the acknowledgement.
+	runner.setResponse(jsonAckElement(3, 1), "", nil)
+	m.tick(t.Context())
+	m.state.notifications.Wait()
+	require.Equal(t, 2, n.callCount())
+	require.Empty(t, m.Acks())
+	require.Contains(t, buf.String(), "Acknowledgement of element [23#0] was cleared")
+}
+
+// Expectation: Acknowledge should return an error for an element not among the last poll results.
+func Test_DeviceMonitor_Acknowledge_Error(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	_, err := m.Acknowledge("23#0")
+	require.ErrorIs(t, err, errElementNotFound)
+	require.Empty(t, m.Acks())
+}
+
+// Expectation: The acknowledgements should be persisted to and loaded from the output folder.
+func Test_DeviceMonitor_PersistAcks_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	cfg := &DeviceMonitorConfig{
+		PollAttempts: ptr(1),
+		OutputDir:    ptr("/output"),
+		PersistAcks:  ptr(true),
+	}
+
+	m := newTestDeviceMonitor(t, Device{Type: 0, Path: "/dev/sg25"}, cfg,
+		fsys, runner, slog.New(slog.DiscardHandler), newMockNotifier())
+
+	runner.setResponse(jsonAckElement(2, 0), "", nil)
+	m.tick(t.Context())
+
+	_, err := m.Acknowledge("23#0")
+	require.NoError(t, err)
+
+	exists, err := afero.Exists(fsys, "/output/"+acksFilename)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	m2 := newTestDeviceMonitor(t, Device{Type: 0, Path: "/dev/sg25"}, cfg,
+		fsys, runner, slog.New(slog.DiscardHandler), newMockNotifier())
+	require.NoError(t, m2.loadAcks())
+	require.Contains(t, m2.Acks(), "23#0")
+	require.Equal(t, ptr(2), m2.Acks()["23#0"].Status)
+}
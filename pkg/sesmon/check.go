@@ -0,0 +1,198 @@
+package sesmon
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Check states (and exit codes) following the Nagios/Icinga plugin conventions.
+const (
+	CheckStateOK = iota
+	CheckStateWarning
+	CheckStateCritical
+	CheckStateUnknown
+)
+
+// checkStateNames are the textual representations of the check states.
+var checkStateNames = map[int]string{
+	CheckStateOK:       "OK",
+	CheckStateWarning:  "WARNING",
+	CheckStateCritical: "CRITICAL",
+	CheckStateUnknown:  "UNKNOWN",
+}
+
+// checkStateRanks are the severities of the check states (for aggregation),
+// where a CRITICAL outweighs an UNKNOWN, which in turn outweighs a WARNING.
+var checkStateRanks = map[int]int{
+	CheckStateOK:       0,
+	CheckStateWarning:  1,
+	CheckStateUnknown:  2,
+	CheckStateCritical: 3,
+}
+
+// worseCheckState returns the more severe of two check states.
+func worseCheckState(a, b int) int {
+	if checkStateRanks[b] > checkStateRanks[a] {
+		return b
+	}
+
+	return a
+}
+
+// CheckResult is the result of a one-shot status check of all monitored devices.
+type CheckResult struct {
+	State    int
+	Devices  int
+	Problems []string
+	Perfdata []string
+}
+
+// AddProblem adds a problem to the [CheckResult], escalating the state if needed.
+func (r *CheckResult) AddProblem(state int, problem string) {
+	r.State = worseCheckState(r.State, state)
+	r.Problems = append(r.Problems, problem)
+}
+
+// StateName returns the textual representation of the state of the [CheckResult].
+func (r *CheckResult) StateName() string {
+	return checkStateNames[r.State]
+}
+
+// String returns the one-line summary of the [CheckResult] (in plugin format),
+// optionally including the performance data (after the pipe character).
+func (r *CheckResult) String(perfdata bool) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "SESMON %s - %d devices checked", r.StateName(), r.Devices)
+	if len(r.Problems) > 0 {
+		fmt.Fprintf(&sb, ", %d problems: %s", len(r.Problems), strings.Join(r.Problems, "; "))
+	} else {
+		sb.WriteString(", no problems")
+	}
+
+	if perfdata && len(r.Perfdata) > 0 {
+		sb.WriteString(" | ")
+		sb.WriteString(strings.Join(r.Perfdata, " "))
+	}
+
+	return sb.String()
+}
+
+// CheckStatus polls all devices once (synchronously, without any monitoring loop)
+// and evaluates the element statuses (and temperature thresholds) into a [CheckResult].
+// It both observes and respects the given context for earlier termination.
+func (p *Program) CheckStatus(ctx context.Context) *CheckResult {
+	monitors := slices.SortedFunc(maps.Values(p.getMonitors()), func(a, b *DeviceMonitor) int {
+		return strings.Compare(a.device.Path, b.device.Path)
+	})
+
+	result := &CheckResult{Devices: len(monitors)}
+	for _, monitor := range monitors {
+		monitor.check(ctx, result)
+	}
+
+	return result
+}
+
+// check polls the device once and adds any problems and performance data to the [CheckResult].
+func (d *DeviceMonitor) check(ctx context.Context, result *CheckResult) {
+	ret, _, _, err := d.fetchFromDevice(ctx)
+	if err != nil {
+		result.AddProblem(CheckStateUnknown, fmt.Sprintf("[%s] failure fetching from device: %v", d.device.Path, err))
+
+		return
+	}
+
+	results, _, err := parseSES(ret)
+	if err != nil {
+		result.AddProblem(CheckStateUnknown, fmt.Sprintf("[%s] failure parsing fetched data: %v", d.device.Path, err))
+
+		return
+	}
+	results = filterResults(results, d.cfg.MonitorTypes)
+	applyStatusMeanings(results, *d.cfg.CanonicalStatusMeanings, d.cfg.StatusMeanings)
+
+	keys := slices.SortedFunc(maps.Keys(results), func(a, b string) int {
+		ra, rb := results[a], results[b]
+		if ra.Type != rb.Type {
+			return ra.Type - rb.Type
+		}
+		if ra.TypeNum != rb.TypeNum {
+			return ra.TypeNum - rb.TypeNum
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	base := filepath.Base(d.device.Path)
+	for _, k := range keys {
+		r := results[k]
+
+		element := k
+		if r.Descriptor != nil {
+			element = fmt.Sprintf("%s (%q)", k, *r.Descriptor)
+		}
+
+		if state := checkStateForStatus(r.Status); state != CheckStateOK {
+			result.AddProblem(state, fmt.Sprintf("[%s] element %s is %s",
+				d.device.Path, element, fmtPtrQStr(r.StatusDesc, fmtPtrInt(r.Status, "-"))))
+		}
+
+		if r.TemperatureC != nil {
+			switch tempLevel(tempLevelNormal, *r.TemperatureC, d.cfg.TempWarn, d.cfg.TempCrit, 0) {
+			case tempLevelCritical:
+				result.AddProblem(CheckStateCritical, fmt.Sprintf("[%s] element %s temperature %d C reached critical threshold",
+					d.device.Path, element, *r.TemperatureC))
+			case tempLevelWarning:
+				result.AddProblem(CheckStateWarning, fmt.Sprintf("[%s] element %s temperature %d C reached warning threshold",
+					d.device.Path, element, *r.TemperatureC))
+			}
+
+			result.Perfdata = append(result.Perfdata, fmt.Sprintf("'%s_%s_temp'=%d;%s;%s",
+				base, k, *r.TemperatureC, fmtPtrInt(d.cfg.TempWarn, ""), fmtPtrInt(d.cfg.TempCrit, "")))
+		}
+
+		if v, ok := leadingNumber(r.Voltage); ok {
+			result.Perfdata = append(result.Perfdata, fmt.Sprintf("'%s_%s_volt'=%s", base, k, v))
+		}
+	}
+}
+
+// checkStateForStatus returns the check state for a SES element status code.
+// Elements without status (or an informational one) are considered as OK.
+func checkStateForStatus(status *int) int {
+	if status == nil {
+		return CheckStateOK
+	}
+
+	switch *status {
+	case sesStatusCritical, sesStatusUnrecoverable:
+		return CheckStateCritical
+	case sesStatusNoncritical, sesStatusUnknown:
+		return CheckStateWarning
+	default:
+		return CheckStateOK
+	}
+}
+
+// leadingNumber returns the leading number of a textual value (e.g., "12.05 V").
+func leadingNumber(s *string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+
+	fields := strings.Fields(*s)
+	if len(fields) == 0 {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+		return "", false
+	}
+
+	return fields[0], true
+}
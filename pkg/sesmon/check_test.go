@@ -0,0 +1,112 @@
+package sesmon
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCheckProgram returns a [Program] with JSON file devices of the given contents.
+func newTestCheckProgram(t *testing.T, files map[string]string, monitorConfig string) *Program {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	yaml := "devices:\n"
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0o644))
+		yaml += "  - device: " + path + "\n    type: 1\n    enabled: true\n" + monitorConfig
+	}
+
+	var buf safeBuffer
+	prog, err := NewProgram([]byte(yaml), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	return prog
+}
+
+// Expectation: CheckStatus should report OK when all elements are OK.
+func Test_Program_CheckStatus_OK_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestCheckProgram(t, map[string]string{
+		"/dev/sg25": `{"join_of_diagnostic_pages": {"element_list": [
+			{"element_type": {"i": 4}, "element_number": 0, "status_descriptor": {"status": {"i": 1, "meaning": "OK"}, "temperature": {"i": 25, "meaning": "25 C"}}},
+			{"element_type": {"i": 18}, "element_number": 0, "status_descriptor": {"status": {"i": 1, "meaning": "OK"}, "voltage": {"value_in_volts": "12.05 V"}}}
+		]}}`,
+	}, "")
+
+	result := prog.CheckStatus(t.Context())
+	require.Equal(t, CheckStateOK, result.State)
+	require.Equal(t, 1, result.Devices)
+	require.Empty(t, result.Problems)
+	require.Equal(t, []string{"'sg25_4#0_temp'=25;;", "'sg25_18#0_volt'=12.05"}, result.Perfdata)
+
+	require.Equal(t, "SESMON OK - 1 devices checked, no problems", result.String(false))
+	require.Equal(t, "SESMON OK - 1 devices checked, no problems | 'sg25_4#0_temp'=25;; 'sg25_18#0_volt'=12.05",
+		result.String(true))
+}
+
+// Expectation: CheckStatus should report the most severe state of all elements.
+func Test_Program_CheckStatus_Critical_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestCheckProgram(t, map[string]string{
+		"/dev/sg25": `{"join_of_diagnostic_pages": {"element_list": [
+			{"element_type": {"i": 23}, "element_number": 3, "descriptor": "Slot 03", "status_descriptor": {"status": {"i": 2, "meaning": "Critical"}}},
+			{"element_type": {"i": 23}, "element_number": 4, "status_descriptor": {"status": {"i": 3, "meaning": "Noncritical"}}}
+		]}}`,
+	}, "")
+
+	result := prog.CheckStatus(t.Context())
+	require.Equal(t, CheckStateCritical, result.State)
+	require.Len(t, result.Problems, 2)
+	require.Contains(t, result.Problems[0], `element 23#3 ("Slot 03") is "Critical"`)
+	require.Contains(t, result.Problems[1], `element 23#4 is "Noncritical"`)
+	require.Contains(t, result.String(false), "SESMON CRITICAL - 1 devices checked, 2 problems")
+}
+
+// Expectation: CheckStatus should report a warning for temperatures above the warning threshold.
+func Test_Program_CheckStatus_TemperatureWarning_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestCheckProgram(t, map[string]string{
+		"/dev/sg25": `{"join_of_diagnostic_pages": {"element_list": [
+			{"element_type": {"i": 4}, "element_number": 0, "status_descriptor": {"status": {"i": 1, "meaning": "OK"}, "temperature": {"i": 45, "meaning": "45 C"}}}
+		]}}`,
+	}, "    config:\n      temp_warn: 40\n      temp_crit: 50\n")
+
+	result := prog.CheckStatus(t.Context())
+	require.Equal(t, CheckStateWarning, result.State)
+	require.Len(t, result.Problems, 1)
+	require.Contains(t, result.Problems[0], "temperature 45 C reached warning threshold")
+	require.Equal(t, []string{"'sg25_4#0_temp'=45;40;50"}, result.Perfdata)
+}
+
+// Expectation: CheckStatus should report UNKNOWN when a device cannot be polled.
+func Test_Program_CheckStatus_Unknown_Error(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestCheckProgram(t, map[string]string{
+		"/dev/sg25": `not json`,
+		"/dev/sg26": `{"join_of_diagnostic_pages": {"element_list": [
+			{"element_type": {"i": 23}, "element_number": 0, "status_descriptor": {"status": {"i": 3, "meaning": "Noncritical"}}}
+		]}}`,
+	}, "    config:\n      poll_attempts: 1\n")
+
+	result := prog.CheckStatus(t.Context())
+	require.Equal(t, CheckStateUnknown, result.State)
+	require.Equal(t, 2, result.Devices)
+	require.Len(t, result.Problems, 2)
+	require.Contains(t, result.Problems[0], "[/dev/sg25] failure")
+}
+
+// Expectation: worseCheckState should rank CRITICAL over UNKNOWN over WARNING over OK.
+func Test_worseCheckState_Success(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, CheckStateWarning, worseCheckState(CheckStateOK, CheckStateWarning))
+	require.Equal(t, CheckStateUnknown, worseCheckState(CheckStateWarning, CheckStateUnknown))
+	require.Equal(t, CheckStateCritical, worseCheckState(CheckStateUnknown, CheckStateCritical))
+	require.Equal(t, CheckStateCritical, worseCheckState(CheckStateCritical, CheckStateWarning))
+}
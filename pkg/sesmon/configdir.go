@@ -0,0 +1,139 @@
+package sesmon
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// configDirExt is the extension of the configuration files read from a configuration directory.
+const configDirExt = ".yaml"
+
+// ReadConfig reads a configuration file, or all configuration files (*.yaml) of a configuration
+// directory in sorted order, which are then merged into one configuration (see [mergeConfigs]).
+func ReadConfig(fsys afero.Fs, path string) ([]byte, error) {
+	fi, err := fsys.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failure accessing path: %w", err)
+	}
+
+	if !fi.IsDir() {
+		data, err := afero.ReadFile(fsys, path)
+		if err != nil {
+			return nil, fmt.Errorf("failure reading file: %w", err)
+		}
+
+		return data, nil
+	}
+
+	entries, err := afero.ReadDir(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failure reading directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), configDirExt) {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: [%s]", errNoConfigFiles, path)
+	}
+	slices.Sort(names)
+
+	configs := make([][]byte, 0, len(names))
+	for _, name := range names {
+		data, err := afero.ReadFile(fsys, filepath.Join(path, name))
+		if err != nil {
+			return nil, fmt.Errorf("[%s] failure reading file: %w", name, err)
+		}
+		configs = append(configs, data)
+	}
+
+	return mergeConfigs(names, configs)
+}
+
+// mergeConfigs merges configurations into one YAML document, with the first configuration
+// as the base (providing all top-level options) and the "devices" of the others appended
+// to it. Each configuration is also parsed on its own, so that any errors are reported for
+// the configuration they are in. Any other top-level options in the others are rejected.
+func mergeConfigs(names []string, configs [][]byte) ([]byte, error) {
+	var base *yaml.Node
+	var devices *yaml.Node
+
+	for i, data := range configs {
+		if _, err := ParseConfigYAML(data); err != nil {
+			return nil, fmt.Errorf("[%s] %w", names[i], err)
+		}
+
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("[%s] failure parsing YAML: %w", names[i], err)
+		}
+		root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+			root = doc.Content[0]
+		}
+
+		if base == nil {
+			root.Style = 0 // block style (for a first configuration in JSON)
+			base = root
+			devices = mappingValue(root, "devices")
+
+			continue
+		}
+
+		for j := 0; j+1 < len(root.Content); j += 2 {
+			key, value := root.Content[j], root.Content[j+1]
+			if key.Value != "devices" {
+				return nil, fmt.Errorf("[%s] %w: top-level option [%s] is only supported in the first configuration file (%s)",
+					names[i], errInvalidArgument, key.Value, names[0])
+			}
+			if value.Kind != yaml.SequenceNode {
+				continue // null (no devices)
+			}
+			if devices == nil || devices.Kind != yaml.SequenceNode {
+				devices = setMappingValue(base, "devices", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"})
+			}
+			devices.Content = append(devices.Content, value.Content...)
+		}
+	}
+
+	data, err := yaml.Marshal(base)
+	if err != nil {
+		return nil, fmt.Errorf("failure encoding merged YAML: %w", err)
+	}
+
+	return data, nil
+}
+
+// mappingValue returns the value of a key within a YAML mapping node, or nil if not present.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// setMappingValue sets the value of a key within a YAML mapping node (adding the key
+// if not present) and returns the value.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+
+			return value
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+
+	return value
+}
@@ -0,0 +1,156 @@
+package sesmon
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: ReadConfig should return a configuration file as it is.
+func Test_ReadConfig_File_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	data := []byte(`{"devices": [{"device": "/dev/sg0"}]}`)
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.yaml", data, 0o644))
+
+	config, err := ReadConfig(fs, "/etc/sesmon.yaml")
+	require.NoError(t, err)
+	require.Equal(t, data, config)
+}
+
+// Expectation: ReadConfig should merge the devices of all configuration files of a directory
+// in sorted order, with the top-level options of the first configuration file.
+func Test_ReadConfig_Directory_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/20-shelf2.yaml", []byte(`
+devices:
+  - device: /dev/sg2
+    description: "Shelf 2"
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/00-base.yaml", []byte(`
+disable_timestamps: true
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/10-shelf1.yaml", []byte(`
+devices:
+  - device: /dev/sg1
+    description: "Shelf 1"
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/README", []byte(`not a configuration`), 0o644))
+
+	data, err := ReadConfig(fs, "/etc/sesmon.d")
+	require.NoError(t, err)
+
+	config, err := ParseConfigYAML(data)
+	require.NoError(t, err)
+	require.True(t, config.DisableTimestamps)
+	require.Len(t, config.Devices, 2)
+	require.Equal(t, "/dev/sg1", config.Devices[0].Device)
+	require.Equal(t, "Shelf 1", config.Devices[0].Description)
+	require.Equal(t, "/dev/sg2", config.Devices[1].Device)
+}
+
+// Expectation: ReadConfig should merge the devices into a first configuration file written as JSON.
+func Test_ReadConfig_DirectoryJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/00-base.yaml", []byte(
+		`{"disable_timestamps": true, "devices": [{"device": "/dev/sg0"}]}`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/10-shelf1.yaml", []byte(`
+devices:
+  - device: /dev/sg1
+`), 0o644))
+
+	data, err := ReadConfig(fs, "/etc/sesmon.d")
+	require.NoError(t, err)
+
+	config, err := ParseConfigYAML(data)
+	require.NoError(t, err)
+	require.True(t, config.DisableTimestamps)
+	require.Len(t, config.Devices, 2)
+	require.Equal(t, "/dev/sg0", config.Devices[0].Device)
+	require.Equal(t, "/dev/sg1", config.Devices[1].Device)
+}
+
+// Expectation: ReadConfig should return an error for a directory without configuration files.
+func Test_ReadConfig_EmptyDirectory_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/etc/sesmon.d", 0o755))
+
+	_, err := ReadConfig(fs, "/etc/sesmon.d")
+	require.ErrorIs(t, err, errNoConfigFiles)
+
+	_, err = ReadConfig(fs, "/etc/nonexistent.yaml")
+	require.Error(t, err)
+}
+
+// Expectation: ReadConfig should return an error naming the configuration file that is invalid.
+func Test_ReadConfig_InvalidFile_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/00-base.yaml", []byte(`
+devices: []
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/10-shelf1.yaml", []byte(`
+devices:
+  - device: /dev/sg1
+    unknown_field: true
+`), 0o644))
+
+	_, err := ReadConfig(fs, "/etc/sesmon.d")
+	require.ErrorContains(t, err, "[10-shelf1.yaml]")
+	require.ErrorContains(t, err, "unknown_field")
+}
+
+// Expectation: ReadConfig should return an error for top-level options beyond the first configuration file.
+func Test_ReadConfig_TopLevelOption_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/00-base.yaml", []byte(`
+disable_timestamps: true
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/10-shelf1.yaml", []byte(`
+log_json: true
+devices:
+  - device: /dev/sg1
+`), 0o644))
+
+	_, err := ReadConfig(fs, "/etc/sesmon.d")
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "[10-shelf1.yaml]")
+	require.ErrorContains(t, err, "log_json")
+}
+
+// Expectation: NewProgram should detect the same device being configured in multiple configuration files.
+func Test_ReadConfig_DuplicateDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/10-shelf1.yaml", []byte(`
+devices:
+  - device: /dev/sg1
+    enabled: true
+`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/etc/sesmon.d/20-shelf1-again.yaml", []byte(`
+devices:
+  - device: /dev/sg1
+    enabled: true
+`), 0o644))
+
+	data, err := ReadConfig(fs, "/etc/sesmon.d")
+	require.NoError(t, err)
+
+	var buf safeBuffer
+	program, err := NewProgram(data, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorContains(t, err, "multiple times")
+	require.Nil(t, program)
+}
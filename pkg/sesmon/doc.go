@@ -0,0 +1,26 @@
+/*
+Package sesmon implements the monitoring engine of sesmon, a monitoring and
+alerting daemon for SES-capable SCSI enclosures (as used by the sesmon command).
+
+A [Program] is established from a configuration (see [ConfigYAML] and
+[ReadConfig]) with [NewProgram], which creates a [DeviceMonitor] for every
+enabled device. The monitors poll their devices through a [CommandRunner]
+(running sg_ses), resolve them through a [DeviceLookuper] and dispatch their
+alerts through a [Notifier], all of which can be replaced by the caller:
+
+	yamlConfig, err := sesmon.ReadConfig(afero.NewOsFs(), "/etc/sesmon.yaml")
+	if err != nil {
+		return err
+	}
+
+	prog, err := sesmon.NewProgram(yamlConfig, nil, nil, nil, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	prog.Start(ctx)
+	<-ctx.Done()
+
+	return prog.Shutdown()
+*/
+package sesmon
@@ -0,0 +1,172 @@
+package sesmon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// waitDelay is the default maximum time to wait for subprocesses to exit on termination.
+const waitDelay = 5 * time.Second
+
+// CommandRunner is the contract for a command execution helper as part of a [Program].
+type CommandRunner interface {
+	Run(ctx context.Context, cfg RunCommandConfig) (stdout, stderr string, err error)
+}
+
+// RunCommandConfig is the configuration for a [RetryCommandRunner] implementation.
+type RunCommandConfig struct {
+	Description string
+	Command     string
+	Args        []string
+
+	// Written to standard input of every attempt (if not nil).
+	Stdin []byte
+
+	// Appended to the environment of the program (if not empty).
+	Env []string
+
+	Attempts        int
+	AttemptTimeout  time.Duration
+	AttemptInterval time.Duration
+
+	// Growth of the waits between the attempts (zero value = fixed AttemptInterval).
+	AttemptBackoff RetryBackoff
+
+	// How long to wait for the command to exit after it was killed (0 = [waitDelay]).
+	WaitDelay time.Duration
+
+	ExpectJSON  bool
+	PrintErrors bool
+
+	// Called after every failed attempt (if not nil), e.g. for counting the retries.
+	OnAttemptError func(attempt int, err error)
+
+	// Ignore any text preceding the JSON object when validating it (with ExpectJSON).
+	// The standard output is returned unmodified, including any such preamble text.
+	TrimPreamble bool
+}
+
+var _ CommandRunner = (*RetryCommandRunner)(nil)
+
+// RetryCommandRunner is the principal [CommandRunner] implementation.
+type RetryCommandRunner struct {
+	logger *slog.Logger
+}
+
+// NewRetryCommandRunner returns a pointer to a new [RetryCommandRunner].
+func NewRetryCommandRunner(logger *slog.Logger) (*RetryCommandRunner, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	return &RetryCommandRunner{logger: logger}, nil
+}
+
+// Run executes a command according to a provided [RunCommandConfig].
+// It both observes and respects context cancellation for earlier termination.
+func (r *RetryCommandRunner) Run(ctx context.Context, cfg RunCommandConfig) (string, string, error) {
+	var stdout, stderr string
+
+	attempt, err := withBackoffRetries(
+		ctx,
+		func() error {
+			var stdoutBuf, stderrBuf bytes.Buffer
+
+			runCtx, runCancel := context.WithTimeout(ctx, cfg.AttemptTimeout)
+			defer runCancel()
+
+			cmd := exec.CommandContext(runCtx, cfg.Command, cfg.Args...)
+			cmd.Stdout = &stdoutBuf
+			cmd.Stderr = &stderrBuf
+			if cfg.Stdin != nil {
+				cmd.Stdin = bytes.NewReader(cfg.Stdin)
+			}
+			if len(cfg.Env) > 0 {
+				cmd.Env = append(os.Environ(), cfg.Env...)
+			}
+			cmd.WaitDelay = cfg.WaitDelay
+			if cmd.WaitDelay <= 0 {
+				cmd.WaitDelay = waitDelay
+			}
+
+			err := cmd.Run()
+			stdout = stdoutBuf.String()
+			stderr = stderrBuf.String()
+
+			if err == nil && cfg.ExpectJSON {
+				data := stdoutBuf.Bytes()
+				if cfg.TrimPreamble {
+					_, data = splitJSONPreamble(data)
+				}
+				if !json.Valid(data) {
+					err = errInvalidJSON
+				}
+			}
+
+			return err
+		},
+		func(attempt int, err error) {
+			if cfg.PrintErrors {
+				r.logger.Warn(fmt.Sprintf("%s: [%d/%d] execution failure: %v: stdout=[%s] stderr=[%s]",
+					cfg.Description, attempt, cfg.Attempts, err, stdout, stderr), LogEvent("exec_failure"))
+			}
+			if cfg.OnAttemptError != nil {
+				cfg.OnAttemptError(attempt, err)
+			}
+		},
+		cfg.Attempts,
+		cfg.AttemptInterval,
+		cfg.AttemptBackoff,
+	)
+	if err != nil {
+		return stdout, stderr, fmt.Errorf("[%d/%d] execution failure: %w: stdout=[%s] stderr=[%s]",
+			attempt, cfg.Attempts, err, stdout, stderr)
+	}
+
+	return stdout, stderr, nil
+}
+
+var _ CommandRunner = (*PrintingCommandRunner)(nil)
+
+// PrintingCommandRunner is a [CommandRunner] printing the exact argv of every
+// command to an [io.Writer], before handing it over to another [CommandRunner].
+type PrintingCommandRunner struct {
+	out    io.Writer
+	runner CommandRunner
+}
+
+// NewPrintingCommandRunner returns a pointer to a new [PrintingCommandRunner].
+func NewPrintingCommandRunner(out io.Writer, runner CommandRunner) (*PrintingCommandRunner, error) {
+	if out == nil || runner == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	return &PrintingCommandRunner{out: out, runner: runner}, nil
+}
+
+// Run prints the argv of the command and then executes it with the wrapped [CommandRunner].
+// It both observes and respects context cancellation for earlier termination.
+func (r *PrintingCommandRunner) Run(ctx context.Context, cfg RunCommandConfig) (string, string, error) {
+	fmt.Fprintf(r.out, "Executing: [%s]\n", quoteArgv(cfg.Command, cfg.Args))
+
+	return r.runner.Run(ctx, cfg) //nolint:wrapcheck
+}
+
+// quoteArgv returns the argv of a command as a string, with all arguments quoted.
+func quoteArgv(command string, args []string) string {
+	argv := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{command}, args...) {
+		argv = append(argv, strconv.Quote(arg))
+	}
+
+	return strings.Join(argv, " ")
+}
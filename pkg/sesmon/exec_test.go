@@ -1,9 +1,11 @@
-package main
+package sesmon
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
 	"sync"
 	"testing"
 	"time"
@@ -65,7 +67,7 @@ func Test_RetryCommandRunner_Run_CapturesStdout_Success(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -89,7 +91,7 @@ func Test_RetryCommandRunner_Run_CapturesStderr_Success(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -113,7 +115,7 @@ func Test_RetryCommandRunner_Run_RetriesOnFailure_Error(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -127,6 +129,12 @@ func Test_RetryCommandRunner_Run_RetriesOnFailure_Error(t *testing.T) {
 		PrintErrors:     false,
 	}
 
+	var failed []int
+	cfg.OnAttemptError = func(attempt int, err error) {
+		require.Error(t, err)
+		failed = append(failed, attempt)
+	}
+
 	start := time.Now()
 	_, _, err := runner.Run(ctx, cfg)
 	elapsed := time.Since(start)
@@ -135,6 +143,7 @@ func Test_RetryCommandRunner_Run_RetriesOnFailure_Error(t *testing.T) {
 	require.Contains(t, err.Error(), "[3/3]")
 	require.Contains(t, err.Error(), "execution failure")
 	require.GreaterOrEqual(t, elapsed, 100*time.Millisecond)
+	require.Equal(t, []int{1, 2, 3}, failed)
 }
 
 // Expectation: Context cancellation should stop execution immediately.
@@ -142,7 +151,7 @@ func Test_RetryCommandRunner_Run_ContextCanceled_Error(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx, cancel := context.WithCancel(t.Context())
@@ -167,7 +176,7 @@ func Test_RetryCommandRunner_Run_CommandTimeout_Error(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -185,12 +194,36 @@ func Test_RetryCommandRunner_Run_CommandTimeout_Error(t *testing.T) {
 	require.Contains(t, err.Error(), "execution failure")
 }
 
+// Expectation: The wait delay should bound the wait for a killed command (still holding its output open).
+func Test_RetryCommandRunner_Run_WaitDelay_Error(t *testing.T) {
+	t.Parallel()
+
+	runner := &RetryCommandRunner{
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	ctx := t.Context()
+	cfg := RunCommandConfig{
+		Description:    "test command",
+		Command:        "sh",
+		Args:           []string{"-c", "sleep 10; true"},
+		AttemptTimeout: 100 * time.Millisecond,
+		WaitDelay:      200 * time.Millisecond,
+		Attempts:       1,
+	}
+
+	start := time.Now()
+	_, _, err := runner.Run(ctx, cfg)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), waitDelay)
+}
+
 // Expectation: ExpectJSON should validate JSON output.
 func Test_RetryCommandRunner_Run_ExpectJSON_ValidJSON_Success(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -210,12 +243,39 @@ func Test_RetryCommandRunner_Run_ExpectJSON_ValidJSON_Success(t *testing.T) {
 	require.Empty(t, stderr)
 }
 
+// Expectation: TrimPreamble should ignore text preceding the JSON output when validating it.
+func Test_RetryCommandRunner_Run_ExpectJSON_TrimPreamble_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &RetryCommandRunner{
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	cfg := RunCommandConfig{
+		Description:     "test command",
+		Command:         "printf",
+		Args:            []string{`warning: firmware quirk\n{"key":"value"}`},
+		AttemptTimeout:  5 * time.Second,
+		Attempts:        1,
+		AttemptInterval: 50 * time.Millisecond,
+		ExpectJSON:      true,
+	}
+
+	_, _, err := runner.Run(t.Context(), cfg)
+	require.ErrorIs(t, err, errInvalidJSON)
+
+	cfg.TrimPreamble = true
+	stdout, _, err := runner.Run(t.Context(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, "warning: firmware quirk\n{\"key\":\"value\"}", stdout)
+}
+
 // Expectation: ExpectJSON should fail and retry when output is not valid JSON.
 func Test_RetryCommandRunner_Run_ExpectJSON_InvalidJSON_Error(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -243,7 +303,7 @@ func Test_RetryCommandRunner_Run_ContextCancelledDuringRetry_Error(t *testing.T)
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx, cancel := context.WithCancel(t.Context())
@@ -274,7 +334,7 @@ func Test_RetryCommandRunner_Run_PrintErrors_Error(t *testing.T) {
 
 	var logBuf safeBuffer
 	runner := &RetryCommandRunner{
-		logger: log.New(&logBuf, "", 0),
+		logger: NewLogger(&logBuf, false, true),
 	}
 
 	ctx := t.Context()
@@ -299,7 +359,7 @@ func Test_RetryCommandRunner_Run_ZeroRetries_Error(t *testing.T) {
 	t.Parallel()
 
 	runner := &RetryCommandRunner{
-		logger: log.New(io.Discard, "", 0),
+		logger: slog.New(slog.DiscardHandler),
 	}
 
 	ctx := t.Context()
@@ -318,3 +378,104 @@ func Test_RetryCommandRunner_Run_ZeroRetries_Error(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "[1/1]")
 }
+
+// Expectation: PrintingCommandRunner should print the exact argv and delegate to the wrapped runner.
+func Test_PrintingCommandRunner_Run_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	mock := &mockCommandRunner{stdout: "out", stderr: "err"}
+	runner := &PrintingCommandRunner{out: &buf, runner: mock}
+
+	stdout, stderr, err := runner.Run(t.Context(), RunCommandConfig{
+		Command: "/usr/local/bin/notify.sh",
+		Args:    []string{"/dev/sg0", "", "My Device", "message"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "out", stdout)
+	require.Equal(t, "err", stderr)
+	require.Equal(t, 1, mock.callCount())
+
+	require.Equal(t, "Executing: [\"/usr/local/bin/notify.sh\" \"/dev/sg0\" \"\" \"My Device\" \"message\"]\n", buf.String())
+}
+
+// Expectation: PrintingCommandRunner should return the error of the wrapped runner.
+func Test_PrintingCommandRunner_Run_Error(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	mock := &mockCommandRunner{err: errors.New("boom")}
+	runner := &PrintingCommandRunner{out: &buf, runner: mock}
+
+	_, _, err := runner.Run(t.Context(), RunCommandConfig{Command: "cmd"})
+	require.Error(t, err)
+	require.Contains(t, buf.String(), "\"cmd\"")
+}
+
+// Expectation: The command runner constructors should return an error when a dependency is nil.
+func Test_NewCommandRunners_NilDependency_Error(t *testing.T) {
+	t.Parallel()
+
+	retry, err := NewRetryCommandRunner(slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.NotNil(t, retry)
+
+	printing, err := NewPrintingCommandRunner(io.Discard, retry)
+	require.NoError(t, err)
+	require.NotNil(t, printing)
+
+	_, err = NewRetryCommandRunner(nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewPrintingCommandRunner(io.Discard, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: Stdin should be written to the standard input of every attempt.
+func Test_RetryCommandRunner_Run_Stdin_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &RetryCommandRunner{
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	ctx := t.Context()
+	cfg := RunCommandConfig{
+		Description:     "test command",
+		Command:         "sh",
+		Args:            []string{"-c", `cat; [ -f "$0" ] || { touch "$0"; exit 1; }`, t.TempDir() + "/marker"},
+		Stdin:           []byte(`{"payload":true}`),
+		AttemptTimeout:  5 * time.Second,
+		Attempts:        2,
+		AttemptInterval: 50 * time.Millisecond,
+		ExpectJSON:      true,
+	}
+
+	stdout, _, err := runner.Run(ctx, cfg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"payload":true}`, stdout)
+}
+
+// Expectation: Env should be appended to the environment of the program.
+func Test_RetryCommandRunner_Run_Env_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &RetryCommandRunner{
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	ctx := t.Context()
+	cfg := RunCommandConfig{
+		Description:     "test command",
+		Command:         "sh",
+		Args:            []string{"-c", `printf '%s:%s' "$SESMON_TEST" "${PATH:+set}"`},
+		Env:             []string{"SESMON_TEST=value with spaces"},
+		AttemptTimeout:  5 * time.Second,
+		Attempts:        1,
+		AttemptInterval: 50 * time.Millisecond,
+	}
+
+	stdout, _, err := runner.Run(ctx, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "value with spaces:set", stdout)
+}
@@ -0,0 +1,171 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// fileNotifierLocks serializes the writes to the same alert log file (keyed by its path),
+// as the devices commonly share one [FileNotifier] configuration (e.g. from the defaults).
+var fileNotifierLocks sync.Map
+
+// FileNotifierConfig is the configuration for a [FileNotifier] implementation.
+type FileNotifierConfig struct {
+	// Size (in MiB) of the alert log file before it is rotated (0 = never rotate).
+	MaxSize *int `json:"max_size" yaml:"max_size"`
+
+	// Amount of rotated alert log files to keep ("<path>.1" being the newest).
+	Backups *int `json:"backups" yaml:"backups"`
+
+	// Template (Go text/template) for the notification message, evaluated with
+	// a [MessageTemplateData] (empty = default message). Validated on load.
+	MessageTemplate *string `json:"message_template" yaml:"message_template"`
+}
+
+// DefaultFileNotifierConfig returns a pointer to a default [FileNotifierConfig].
+func DefaultFileNotifierConfig() *FileNotifierConfig {
+	return &FileNotifierConfig{
+		MaxSize: ptr(defaultLogFileMaxSize),
+		Backups: ptr(defaultLogFileBackups),
+	}
+}
+
+var _ Notifier = (*FileNotifier)(nil)
+
+// FileNotifier is a [Notifier] appending a timestamped line per notification
+// (with the device, the severity of a [ChangeReport] and the message) to an
+// alert log file, as a human-readable log independent of any output folders.
+// The alert log file is opened for appending with every notification, so that
+// it can also be moved away (e.g. by logrotate) without any further signaling.
+// It is rotated once it would exceed [FileNotifierConfig.MaxSize].
+type FileNotifier struct {
+	// Path to the alert log file.
+	path string
+
+	fsys   afero.Fs
+	logger *slog.Logger
+
+	cfg  *FileNotifierConfig
+	tmpl *template.Template
+}
+
+// NewFileNotifier returns a pointer to a new [FileNotifier].
+func NewFileNotifier(path string, cfg *FileNotifierConfig, fsys afero.Fs, logger *slog.Logger) (*FileNotifier, error) {
+	if fsys == nil || logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("%w: no path provided", errInvalidArgument)
+	}
+
+	fcfg, err := mergeFileNotifierConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	tmpl, err := parseMessageTemplate(fmtPtrStr(fcfg.MessageTemplate, ""))
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	return &FileNotifier{
+		path:   path,
+		fsys:   fsys,
+		logger: logger,
+		cfg:    fcfg,
+		tmpl:   tmpl,
+	}, nil
+}
+
+// Notify appends the line of the notification to the alert log file,
+// rotating the alert log file first if it would exceed its maximum size.
+func (n *FileNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%q: context error: %w", n.path, err)
+	}
+
+	line := n.line(time.Now(), device, message, extra)
+
+	mu, _ := fileNotifierLocks.LoadOrStore(n.path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	if maxSize := int64(*n.cfg.MaxSize) << 20; maxSize > 0 { //nolint:mnd
+		if fi, err := n.fsys.Stat(n.path); err == nil && fi.Size() > 0 && fi.Size()+int64(len(line)) > maxSize {
+			shiftRotatedFiles(n.fsys, n.path, *n.cfg.Backups)
+		}
+	}
+
+	f, err := n.fsys.OpenFile(n.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, logFilePerms)
+	if err != nil {
+		return fmt.Errorf("%q: failure opening alert log file: %w", n.path, err)
+	}
+
+	if _, err := f.WriteString(line); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("%q: failure writing to alert log file: %w", n.path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("%q: failure closing alert log file: %w", n.path, err)
+	}
+
+	return nil
+}
+
+// Describe returns the line that would be appended to the alert log file.
+func (n *FileNotifier) Describe(device Device, message string, extra any) string {
+	return fmt.Sprintf("%q to %q", strings.TrimSuffix(n.line(time.Now(), device, message, extra), "\n"), n.path)
+}
+
+// line returns the line of a notification for the alert log file, in the format of
+// "<timestamp> <device> (<address>, <description>) [<severity>] <message>". The severity
+// is only known for a [ChangeReport] (otherwise "-"). Any whitespace of the message (including
+// line breaks) is collapsed, so that every notification remains on one line of the alert log file.
+func (n *FileNotifier) line(now time.Time, device Device, message string, extra any) string {
+	message, err := renderMessage(n.tmpl, device, message, extra)
+	if err != nil {
+		n.logger.Warn(fmt.Sprintf("%q: %v (using default message)", n.path, err), LogEvent("template_failure"))
+	}
+
+	var severity string
+	switch r := extra.(type) {
+	case ChangeReport:
+		severity = r.Severity
+	case *ChangeReport:
+		if r != nil {
+			severity = r.Severity
+		}
+	}
+
+	message = strings.Join(strings.Fields(message), " ")
+
+	return fmt.Sprintf("%s %s (%s, %s) [%s] %s\n", now.Format(time.RFC3339), device.Path,
+		fne(device.Address, "-"), fne(device.Description, "-"), fne(severity, "-"), message)
+}
+
+// Name returns the name of the notification agent as a string.
+func (n *FileNotifier) Name() string {
+	return "file_notifier"
+}
+
+// Config returns the configuration of the notification agent as a string.
+func (n *FileNotifier) Config() string {
+	cfgJSON, err := json.Marshal(n.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+
+	return fmt.Sprintf("%q:%s", n.path, cfgJSON)
+}
@@ -0,0 +1,154 @@
+package sesmon
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: NewFileNotifier should successfully create a notifier with the default config.
+func Test_NewFileNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewFileNotifier("/var/log/sesmon-alerts.log", nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.Equal(t, DefaultFileNotifierConfig(), n.cfg)
+	require.Equal(t, "file_notifier", n.Name())
+	require.Contains(t, n.Config(), `"/var/log/sesmon-alerts.log":{"max_size":10,"backups":2`)
+}
+
+// Expectation: NewFileNotifier should return an error for invalid arguments.
+func Test_NewFileNotifier_InvalidArguments_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	logger := slog.New(slog.DiscardHandler)
+
+	_, err := NewFileNotifier("", nil, fs, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewFileNotifier("/alerts.log", &FileNotifierConfig{MaxSize: ptr(-1)}, fs, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewFileNotifier("/alerts.log", &FileNotifierConfig{Backups: ptr(-1)}, fs, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewFileNotifier("/alerts.log", &FileNotifierConfig{MessageTemplate: ptr("{{.Invalid")}, fs, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewFileNotifier("/alerts.log", nil, nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: FileNotifier should append one timestamped line per notification with the severity.
+func Test_FileNotifier_Notify_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/alerts.log", []byte("existing\n"), 0o640))
+
+	n, err := NewFileNotifier("/alerts.log", nil, fs, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Address: "0x500a098012345678", Description: "JBOD"}
+	report := ChangeReport{Severity: SeverityCritical}
+
+	require.NoError(t, n.Notify(t.Context(), device, "Degraded: element 23#0", report))
+	require.NoError(t, n.Notify(t.Context(), Device{Path: "/dev/sg26"}, "Heartbeat:\nall fine", nil))
+
+	data, err := afero.ReadFile(fs, "/alerts.log")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "existing", lines[0])
+
+	ts, rest, ok := strings.Cut(lines[1], " ")
+	require.True(t, ok)
+	_, err = time.Parse(time.RFC3339, ts)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg25 (0x500a098012345678, JBOD) [critical] Degraded: element 23#0", rest)
+
+	require.True(t, strings.HasSuffix(lines[2], " /dev/sg26 (-, -) [-] Heartbeat: all fine"))
+}
+
+// Expectation: FileNotifier should use the message template for the message of the line.
+func Test_FileNotifier_Notify_MessageTemplate_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	n, err := NewFileNotifier("/alerts.log",
+		&FileNotifierConfig{MessageTemplate: ptr("{{.Kind}}: {{len .Changes}} changes")}, fs, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	report := ChangeReport{Kind: ChangeKindDegraded, Severity: SeverityWarning, Changes: []Change{{ID: "23#0"}}}
+	require.NoError(t, n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "default", report))
+
+	data, err := afero.ReadFile(fs, "/alerts.log")
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(data), " [warning] degraded: 1 changes\n"))
+}
+
+// Expectation: FileNotifier should rotate the alert log file once it would exceed its maximum size.
+func Test_FileNotifier_Notify_Rotate_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/alerts.log", []byte(strings.Repeat("x", 1<<20)), 0o640))
+	require.NoError(t, afero.WriteFile(fs, "/alerts.log.1", []byte("older"), 0o640))
+
+	n, err := NewFileNotifier("/alerts.log", &FileNotifierConfig{MaxSize: ptr(1)}, fs, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil))
+
+	data, err := afero.ReadFile(fs, "/alerts.log")
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(data), " [-] test message\n"))
+	require.Equal(t, 1, strings.Count(string(data), "\n"))
+
+	data, err = afero.ReadFile(fs, "/alerts.log.1")
+	require.NoError(t, err)
+	require.Len(t, data, 1<<20)
+
+	data, err = afero.ReadFile(fs, "/alerts.log.2")
+	require.NoError(t, err)
+	require.Equal(t, "older", string(data))
+}
+
+// Expectation: FileNotifier should return an error if the alert log file cannot be opened.
+func Test_FileNotifier_Notify_Error(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewFileNotifier("/alerts.log", nil, afero.NewReadOnlyFs(afero.NewMemMapFs()), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	err = n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.ErrorContains(t, err, "failure opening alert log file")
+}
+
+// Expectation: FileNotifier should describe the line it would append (for a dry run).
+func Test_FileNotifier_Describe_Success(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewFileNotifier("/alerts.log", nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	desc := describeNotification(n, Device{Path: "/dev/sg25"}, "test message", &ChangeReport{Severity: SeverityInfo})
+	require.Contains(t, desc, `/dev/sg25 (-, -) [info] test message" to "/alerts.log"`)
+}
+
+// Expectation: FileNotifierConfig should be serialized with its YAML names.
+func Test_FileNotifierConfig_MarshalJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(DefaultFileNotifierConfig())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"max_size":10,"backups":2,"message_template":null}`, string(data))
+}
@@ -0,0 +1,112 @@
+package sesmon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// healthReadHeaderTimeout is the maximum time to read the headers of a health request.
+const healthReadHeaderTimeout = 5 * time.Second
+
+// startHealthServer starts the HTTP server for the health endpoints (if configured).
+// The caller is expected to hold the lock of the [Program] when calling.
+func (p *Program) startHealthServer() {
+	if p.config.HealthAddr == "" {
+		return
+	}
+
+	ln, err := net.Listen("tcp", p.config.HealthAddr)
+	if err != nil {
+		p.logger.Error(fmt.Sprintf("Error starting health endpoints on [%s]: %v", p.config.HealthAddr, err), LogEvent("error"))
+
+		return
+	}
+
+	srv := &http.Server{
+		Handler:           p.healthHandler(),
+		ReadHeaderTimeout: healthReadHeaderTimeout,
+	}
+	p.health = srv
+	p.healthAddr = ln.Addr()
+
+	logger := p.logger
+	go func() {
+		defer RecoverGoPanic("health", logger)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(fmt.Sprintf("Error serving health endpoints: %v", err), LogEvent("error"))
+		}
+	}()
+
+	p.logger.Info(fmt.Sprintf("Serving health endpoints (/healthz, /readyz) on [%s]", p.healthAddr), LogEvent("health"))
+}
+
+// stopHealthServer shuts down the HTTP server for the health endpoints (if running).
+// The caller is expected to hold the lock of the [Program] when calling.
+func (p *Program) stopHealthServer() {
+	if p.health == nil {
+		return
+	}
+
+	_ = p.health.Close()
+	p.health = nil
+	p.healthAddr = nil
+}
+
+// stopped returns if all monitors of the program have stopped.
+func (p *Program) stopped() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// healthHandler returns the [http.Handler] serving the health endpoints:
+// "/healthz" responds with 200 while the program is running (503 otherwise),
+// "/readyz" responds with 200 once every monitor has polled successfully.
+func (p *Program) healthHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if p.stopped() {
+			http.Error(w, "program has stopped", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if p.stopped() {
+			http.Error(w, "program has stopped", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		monitors := p.getMonitors()
+
+		var notReady []string
+		for _, monitor := range monitors {
+			if !monitor.Ready() {
+				notReady = append(notReady, monitor.device.Path)
+			}
+		}
+		slices.Sort(notReady)
+		if len(notReady) > 0 {
+			http.Error(w, "not ready: "+strings.Join(notReady, ", "), http.StatusServiceUnavailable)
+
+			return
+		}
+
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
@@ -0,0 +1,137 @@
+package sesmon
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHealthProgram returns a [Program] with a JSON file device and the given top-level config.
+func newTestHealthProgram(t *testing.T, content string, globalConfig string) *Program {
+	t.Helper()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte(content), 0o644))
+
+	yaml := []byte(globalConfig + `
+devices:
+  - device: /dev/sg0
+    type: 1
+    enabled: true
+    config:
+      poll_interval: 1h
+      poll_attempts: 1
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	return prog
+}
+
+// serveHealth returns the status code of a request against the health handler.
+func serveHealth(t *testing.T, prog *Program, path string) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	prog.healthHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Code
+}
+
+// Expectation: The health endpoints should respond according to the program and monitor states.
+func Test_Program_healthHandler_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestHealthProgram(t, `{}`, "")
+
+	require.Equal(t, http.StatusOK, serveHealth(t, prog, "/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, serveHealth(t, prog, "/readyz"))
+
+	prog.Start(t.Context())
+
+	require.Eventually(t, func() bool {
+		return serveHealth(t, prog, "/readyz") == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, http.StatusOK, serveHealth(t, prog, "/healthz"))
+
+	prog.Stop()
+	<-prog.Done()
+
+	require.Equal(t, http.StatusServiceUnavailable, serveHealth(t, prog, "/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, serveHealth(t, prog, "/readyz"))
+}
+
+// Expectation: The readiness endpoint should not become ready while the device polls fail.
+func Test_Program_healthHandler_NotReady_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestHealthProgram(t, `not json`, "")
+
+	prog.Start(t.Context())
+	defer func() {
+		prog.Stop()
+		<-prog.Done()
+	}()
+
+	require.Eventually(t, func() bool {
+		return prog.getMonitors()["/dev/sg0"].Status().LastPollError != ""
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, http.StatusOK, serveHealth(t, prog, "/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, serveHealth(t, prog, "/readyz"))
+}
+
+// Expectation: The health server should be started with the program and shut down with Stop.
+func Test_Program_HealthServer_StartStop_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestHealthProgram(t, `{}`, `health_addr: "127.0.0.1:0"`)
+
+	prog.Start(t.Context())
+
+	prog.mu.Lock()
+	addr := prog.healthAddr
+	prog.mu.Unlock()
+	require.NotNil(t, addr)
+
+	resp, err := http.Get("http://" + addr.String() + "/healthz") //nolint:noctx
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "ok\n", string(body))
+
+	prog.Stop()
+	<-prog.Done()
+
+	prog.mu.Lock()
+	require.Nil(t, prog.health)
+	prog.mu.Unlock()
+
+	_, err = http.Get("http://" + addr.String() + "/healthz") //nolint:noctx
+	require.Error(t, err)
+}
+
+// Expectation: The health server should not be started when no address is configured.
+func Test_Program_HealthServer_NotConfigured_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestHealthProgram(t, `{}`, "")
+
+	prog.Start(t.Context())
+	defer func() {
+		prog.Stop()
+		<-prog.Done()
+	}()
+
+	prog.mu.Lock()
+	defer prog.mu.Unlock()
+	require.Nil(t, prog.health)
+}
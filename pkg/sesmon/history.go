@@ -0,0 +1,125 @@
+package sesmon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// elementHistoryFilename is the file in [DeviceMonitorConfig.OutputDir] the history
+// of the elements is written to (if [DeviceMonitorConfig.ElementHistory] is set).
+const elementHistoryFilename = "element-history.json"
+
+// recordHistory records the statuses of the elements that have changed (or appeared or
+// disappeared) since their last recorded status, dropping their oldest statuses beyond
+// [DeviceMonitorConfig.ElementHistory]. It returns if any status was recorded.
+func (d *DeviceMonitor) recordHistory(now time.Time, results map[string]Result) bool {
+	limit := *d.cfg.ElementHistory
+	if limit <= 0 {
+		return false
+	}
+
+	if d.state.history == nil {
+		d.state.history = make(map[string][]ElementHistoryEntry)
+	}
+
+	var recorded bool
+	record := func(id string, entry ElementHistoryEntry) {
+		h := d.state.history[id]
+		if len(h) > 0 {
+			last := h[len(h)-1]
+			if last.Missing == entry.Missing && ptrIntEqual(last.Status, entry.Status) {
+				return
+			}
+		}
+		if len(h) >= limit {
+			h = append(h[:0], h[len(h)-limit+1:]...)
+		}
+		d.state.history[id] = append(h, entry)
+		recorded = true
+	}
+
+	at := now.Format(time.RFC3339)
+	for _, id := range slices.Sorted(maps.Keys(results)) {
+		r := results[id]
+		record(id, ElementHistoryEntry{At: at, Status: r.Status, StatusDesc: r.StatusDesc})
+	}
+	for id := range d.state.history {
+		if _, ok := results[id]; !ok {
+			record(id, ElementHistoryEntry{At: at, Missing: true})
+		}
+	}
+
+	return recorded
+}
+
+// loadHistory reads the [ElementHistory] from [DeviceMonitorConfig.OutputDir] (if [ElementHistory]
+// is set), so that it is continued across the runs and restarts (instead of starting anew), where
+// a missing file is no error. The statuses beyond [DeviceMonitorConfig.ElementHistory] are dropped.
+func (d *DeviceMonitor) loadHistory() error {
+	limit := *d.cfg.ElementHistory
+	if d.cfg.OutputDir == nil || limit <= 0 {
+		return nil
+	}
+
+	data, err := d.readOutputFile(elementHistoryFilename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var history ElementHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return fmt.Errorf("failure parsing element history: %w", err)
+	}
+
+	d.state.history = make(map[string][]ElementHistoryEntry, len(history.Elements))
+	for id, entries := range history.Elements {
+		if len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+		d.state.history[id] = entries
+	}
+
+	if len(history.Elements) > 0 {
+		d.logger.Info(fmt.Sprintf("Loaded the history of %d elements from the output folder", len(history.Elements)),
+			LogEvent("state_loaded"))
+	}
+
+	return nil
+}
+
+// writeHistory writes the [ElementHistory] to [DeviceMonitorConfig.OutputDir]
+// (gzip-compressed if [DeviceMonitorConfig.OutputCompress] is enabled).
+func (d *DeviceMonitor) writeHistory(now time.Time) error {
+	deviceDir, err := d.ensureDeviceFolder()
+	if err != nil {
+		return fmt.Errorf("failure ensuring folder: %w", err)
+	}
+
+	data, err := json.MarshalIndent(ElementHistory{
+		Device:    d.device,
+		UpdatedAt: now.Format(time.RFC3339),
+		Elements:  d.state.history,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failure marshalling to JSON: %w", err)
+	}
+
+	historyPath, data, err := d.compressOutput(filepath.Join(deviceDir, d.outputName(elementHistoryFilename)), data)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(d.fsys, historyPath, data, d.outputFileMode()); err != nil {
+		return fmt.Errorf("failure writing to file: %w", err)
+	}
+
+	return nil
+}
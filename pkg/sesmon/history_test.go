@@ -0,0 +1,142 @@
+package sesmon
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: poll should record the status changes per element (bounded) and write them to the output folder.
+func Test_DeviceMonitor_poll_ElementHistory_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGone := `{"join_of_diagnostic_pages":{"element_list":[]}}`
+
+	fs := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{OutputDir: ptr("/out"), ElementHistory: ptr(3)},
+		fs,
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	for _, out := range []string{jsonAckElement(1, 0), jsonAckElement(1, 1), jsonAckElement(2, 0)} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(t.Context()))
+	}
+	m.state.notifications.Wait()
+
+	data, err := afero.ReadFile(fs, "/out/element-history.json")
+	require.NoError(t, err)
+
+	var history ElementHistory
+	require.NoError(t, json.Unmarshal(data, &history))
+	require.Equal(t, "/dev/sg25", history.Device.Path)
+	require.Len(t, history.Elements["23#0"], 2)
+	require.Equal(t, ptr(1), history.Elements["23#0"][0].Status)
+	require.Equal(t, ptr(2), history.Elements["23#0"][1].Status)
+	require.Equal(t, ptr("S2"), history.Elements["23#0"][1].StatusDesc)
+
+	for _, out := range []string{jsonAckElement(5, 0), jsonGone, jsonGone} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(t.Context()))
+	}
+	m.state.notifications.Wait()
+
+	data, err = afero.ReadFile(fs, "/out/element-history.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &history))
+
+	entries := history.Elements["23#0"]
+	require.Len(t, entries, 3)
+	require.Equal(t, ptr(2), entries[0].Status)
+	require.Equal(t, ptr(5), entries[1].Status)
+	require.True(t, entries[2].Missing)
+	require.Nil(t, entries[2].Status)
+}
+
+// Expectation: poll should neither record nor write any element history if disabled.
+func Test_DeviceMonitor_poll_ElementHistory_Disabled_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{OutputDir: ptr("/out")},
+		fs,
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	runner.setResponse(jsonAckElement(1, 0), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	require.Nil(t, m.state.history)
+	exists, err := afero.Exists(fs, "/out/element-history.json")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+// Expectation: RunOnce should continue the element history of the previous runs (instead of starting anew).
+func Test_DeviceMonitor_RunOnce_ElementHistory_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	runOnce := func(out string, compress bool) {
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:   ptr(1),
+				OutputDir:      ptr("/out"),
+				OutputCompress: ptr(compress),
+				ElementHistory: ptr(2),
+			},
+			fs,
+			runner,
+			slog.New(slog.DiscardHandler),
+			newMockNotifier(),
+		)
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.RunOnce(t.Context()))
+	}
+
+	runOnce(jsonAckElement(1, 0), false)
+	runOnce(jsonAckElement(2, 0), false)
+	runOnce(jsonAckElement(2, 0), false)
+
+	data, err := afero.ReadFile(fs, "/out/element-history.json")
+	require.NoError(t, err)
+
+	var history ElementHistory
+	require.NoError(t, json.Unmarshal(data, &history))
+	require.Len(t, history.Elements["23#0"], 2)
+	require.Equal(t, ptr(1), history.Elements["23#0"][0].Status)
+	require.Equal(t, ptr(2), history.Elements["23#0"][1].Status)
+
+	runOnce(jsonAckElement(5, 0), true)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{OutputDir: ptr("/out"), OutputCompress: ptr(true), ElementHistory: ptr(2)},
+		fs,
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+	require.NoError(t, m.loadHistory())
+	require.Len(t, m.state.history["23#0"], 2)
+	require.Equal(t, ptr(2), m.state.history["23#0"][0].Status)
+	require.Equal(t, ptr(5), m.state.history["23#0"][1].Status)
+}
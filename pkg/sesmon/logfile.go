@@ -0,0 +1,165 @@
+package sesmon
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// defaultLogFileMaxSize is the default size (in MiB) of a log file before it is rotated.
+	defaultLogFileMaxSize = 10
+
+	// defaultLogFileBackups is the default amount of rotated log files to keep.
+	defaultLogFileBackups = 2
+
+	// logFilePerms are the permissions of created log files.
+	logFilePerms = 0o640
+)
+
+// RotatingFile is an [io.Writer] appending to a log file, which is rotated once it
+// would exceed its maximum size (keeping a configurable amount of rotated files as
+// "<path>.1" being the newest up to "<path>.<backups>"). It is safe for concurrent use.
+type RotatingFile struct {
+	mu sync.Mutex
+
+	fsys    afero.Fs
+	path    string
+	maxSize int64 // 0 = never rotate
+	backups int
+
+	file afero.File
+	size int64
+}
+
+// NewRotatingFile returns a pointer to a new [RotatingFile] for the log file
+// settings of a [ConfigYAML], which opens (or creates) the log file for appending.
+func NewRotatingFile(fsys afero.Fs, config ConfigYAML) (*RotatingFile, error) {
+	maxSize, backups := defaultLogFileMaxSize, defaultLogFileBackups
+	if config.LogFileMaxSize != nil {
+		maxSize = *config.LogFileMaxSize
+	}
+	if config.LogFileBackups != nil {
+		backups = *config.LogFileBackups
+	}
+	if maxSize < 0 || backups < 0 {
+		return nil, fmt.Errorf("%w: log_file_max_size and log_file_backups must be >= 0", errInvalidArgument)
+	}
+
+	r := &RotatingFile{
+		fsys:    fsys,
+		path:    config.LogFile,
+		maxSize: int64(maxSize) << 20, //nolint:mnd
+		backups: backups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Write appends to the log file, rotating it first if it would exceed its maximum size.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, fmt.Errorf("%q: %w", r.path, fs.ErrClosed)
+	}
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("%q: failure writing to log file: %w", r.path, err)
+	}
+
+	return n, nil
+}
+
+// Reopen closes and reopens the log file, e.g. after it was moved by logrotate.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		_ = r.file.Close()
+		r.file = nil
+	}
+
+	return r.open()
+}
+
+// Close closes the log file, after which any writes to it fail.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	if err != nil {
+		return fmt.Errorf("%q: failure closing log file: %w", r.path, err)
+	}
+
+	return nil
+}
+
+// open opens (or creates) the log file for appending. The caller is expected to hold the lock.
+func (r *RotatingFile) open() error {
+	f, err := r.fsys.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, logFilePerms)
+	if err != nil {
+		return fmt.Errorf("%q: failure opening log file: %w", r.path, err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("%q: failure reading log file info: %w", r.path, err)
+	}
+
+	r.file = f
+	r.size = fi.Size()
+
+	return nil
+}
+
+// rotate shifts the rotated log files (dropping the oldest one) and starts a new log file.
+// Without any backups, the log file is only truncated. The caller is expected to hold the lock.
+func (r *RotatingFile) rotate() error {
+	_ = r.file.Close()
+	r.file = nil
+
+	shiftRotatedFiles(r.fsys, r.path, r.backups)
+
+	return r.open()
+}
+
+// shiftRotatedFiles shifts the rotated files of a path ("<path>.1" being the newest up to
+// "<path>.<backups>", dropping the oldest one) and moves the file itself to "<path>.1".
+// Without any backups, the file is only removed. Failures are not fatal, as the file is
+// then appended to instead (and so are not returned).
+func shiftRotatedFiles(fsys afero.Fs, path string, backups int) {
+	if backups > 0 {
+		for i := backups - 1; i >= 1; i-- {
+			_ = fsys.Rename(path+"."+strconv.Itoa(i), path+"."+strconv.Itoa(i+1))
+		}
+		_ = fsys.Rename(path, path+".1")
+	} else {
+		_ = fsys.Remove(path)
+	}
+}
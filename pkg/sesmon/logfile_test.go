@@ -0,0 +1,113 @@
+package sesmon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: The log file should be appended to and rotated once exceeding its maximum size.
+func Test_RotatingFile_Rotate_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/var/log/sesmon.log", []byte("old\n"), 0o640))
+
+	r, err := NewRotatingFile(fsys, ConfigYAML{LogFile: "/var/log/sesmon.log", LogFileMaxSize: ptr(1), LogFileBackups: ptr(2)})
+	require.NoError(t, err)
+	defer r.Close()
+
+	line := strings.Repeat("x", 700<<10) + "\n"
+	for _, c := range []string{"a", "b", "c"} {
+		_, err := r.Write([]byte(c + line))
+		require.NoError(t, err)
+	}
+
+	current, err := afero.ReadFile(fsys, "/var/log/sesmon.log")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(current), "c"))
+
+	backup, err := afero.ReadFile(fsys, "/var/log/sesmon.log.1")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(backup), "b"))
+
+	backup, err = afero.ReadFile(fsys, "/var/log/sesmon.log.2")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(backup), "old\na"))
+
+	exists, err := afero.Exists(fsys, "/var/log/sesmon.log.3")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+// Expectation: The log file should only be truncated when rotating without any backups.
+func Test_RotatingFile_NoBackups_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+
+	r, err := NewRotatingFile(fsys, ConfigYAML{LogFile: "/sesmon.log", LogFileMaxSize: ptr(1), LogFileBackups: ptr(0)})
+	require.NoError(t, err)
+	defer r.Close()
+
+	line := strings.Repeat("x", 700<<10) + "\n"
+	for _, c := range []string{"a", "b"} {
+		_, err := r.Write([]byte(c + line))
+		require.NoError(t, err)
+	}
+
+	current, err := afero.ReadFile(fsys, "/sesmon.log")
+	require.NoError(t, err)
+	require.Len(t, current, len(line)+1)
+	require.True(t, strings.HasPrefix(string(current), "b"))
+
+	exists, err := afero.Exists(fsys, "/sesmon.log.1")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+// Expectation: Reopen should continue with a new log file after it was moved away (e.g. by logrotate).
+func Test_RotatingFile_Reopen_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+
+	r, err := NewRotatingFile(fsys, ConfigYAML{LogFile: "/sesmon.log"})
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = r.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, fsys.Rename("/sesmon.log", "/sesmon.log-20250101"))
+	require.NoError(t, r.Reopen())
+
+	_, err = r.Write([]byte("after\n"))
+	require.NoError(t, err)
+
+	current, err := afero.ReadFile(fsys, "/sesmon.log")
+	require.NoError(t, err)
+	require.Equal(t, "after\n", string(current))
+}
+
+// Expectation: Writes should fail once the log file was closed, and invalid settings should be rejected.
+func Test_RotatingFile_Error(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+
+	_, err := NewRotatingFile(fsys, ConfigYAML{LogFile: "/sesmon.log", LogFileMaxSize: ptr(-1)})
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewRotatingFile(afero.NewReadOnlyFs(fsys), ConfigYAML{LogFile: "/sesmon.log"})
+	require.ErrorContains(t, err, "failure opening log file")
+
+	r, err := NewRotatingFile(fsys, ConfigYAML{LogFile: "/sesmon.log"})
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	_, err = r.Write([]byte("closed\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logEventKey is the key of the attribute holding the event of a log record
+	// (see [LogEvent]), as used within the structured (JSON) log output.
+	logEventKey = "event"
+
+	// logEventDefault is the event of log records without an event attribute.
+	logEventDefault = "log"
+
+	// logTimeFormat is the timestamp format of the human readable log output
+	// (same as the [log.LstdFlags] of the standard library logger).
+	logTimeFormat = "2006/01/02 15:04:05 "
+)
+
+// LogEvent returns the attribute for the event of a log record (e.g. "poll_failure"),
+// which classifies the record for log processing within the structured (JSON) log output.
+func LogEvent(event string) slog.Attr {
+	return slog.String(logEventKey, event)
+}
+
+// jsonLogEntry is a single line of structured (JSON) log output.
+type jsonLogEntry struct {
+	Time        string `json:"time,omitempty"`
+	Level       string `json:"level"`
+	Device      string `json:"device,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Description string `json:"description,omitempty"`
+	Event       string `json:"event"`
+	Msg         string `json:"msg"`
+}
+
+// logHandler is a [slog.Handler] writing every log record as a single line, either
+// human readable (prefixed with the device) or as a JSON-encoded [jsonLogEntry].
+// The level and the event (see [LogEvent]) are only part of the JSON log output,
+// any other attributes of the log records are not part of either output.
+type logHandler struct {
+	out io.Writer
+	mu  *sync.Mutex
+
+	// Write JSON-encoded [jsonLogEntry] lines (instead of human readable lines).
+	logJSON bool
+
+	device      string
+	address     string
+	description string
+
+	// Prefix of the human readable lines (derived from the device).
+	prefix string
+
+	// Event of all log records (as set with [slog.Logger.With]).
+	event string
+
+	// Include timestamps in the log lines.
+	timestamps bool
+}
+
+// Enabled returns if log records of a level are handled (all of at least [slog.LevelInfo]).
+func (h *logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
+
+// Handle writes a log record as a single (human readable or JSON-encoded) line to the output.
+func (h *logHandler) Handle(_ context.Context, r slog.Record) error {
+	var line []byte
+
+	if h.logJSON {
+		event := fne(h.event, logEventDefault)
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == logEventKey {
+				event = a.Value.String()
+
+				return false
+			}
+
+			return true
+		})
+
+		entry := jsonLogEntry{
+			Level:       strings.ToLower(r.Level.String()),
+			Device:      h.device,
+			Address:     h.address,
+			Description: h.description,
+			Event:       event,
+			Msg:         r.Message,
+		}
+		if h.timestamps {
+			entry.Time = r.Time.Format(time.RFC3339)
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		line = data
+	} else {
+		if h.timestamps {
+			line = r.Time.AppendFormat(line, logTimeFormat)
+		}
+		line = append(line, h.prefix...)
+		line = append(line, r.Message...)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := h.out.Write(append(line, '\n')); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// WithAttrs returns a copy of the handler with the event of the attributes (if any).
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	for _, a := range attrs {
+		if a.Key == logEventKey {
+			c.event = a.Value.String()
+		}
+	}
+
+	return &c
+}
+
+// WithGroup returns the handler as it is, as attributes are not part of the log lines.
+func (h *logHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+// NewLogger returns a pointer to a new program-wide [slog.Logger], either writing
+// human readable or structured (JSON) log lines to the given output (see [ConfigYAML]).
+func NewLogger(out io.Writer, logJSON bool, disableTimestamps bool) *slog.Logger {
+	return newLogger(out, logJSON, disableTimestamps, "", "", "")
+}
+
+// newLogger returns a pointer to a new [slog.Logger] for the given device
+// (which can be empty for the program-wide logger), either writing human
+// readable (prefixed) or structured (JSON) log lines to the given output.
+// The description of the device is included (if not empty) to tell apart
+// the devices more easily (e.g. "/dev/sg7:0x5000 (Top JBOD - Row 2): ").
+func newLogger(
+	out io.Writer, logJSON bool, disableTimestamps bool, device string, address string, description string,
+) *slog.Logger {
+	var prefix string
+	if device != "" || address != "" {
+		prefix = device + ":" + address
+		if description != "" {
+			prefix += " (" + description + ")"
+		}
+		prefix += ": "
+	}
+
+	return slog.New(&logHandler{
+		out:         out,
+		mu:          &sync.Mutex{},
+		logJSON:     logJSON,
+		device:      device,
+		address:     address,
+		description: description,
+		prefix:      prefix,
+		timestamps:  !disableTimestamps,
+	})
+}
@@ -0,0 +1,159 @@
+package sesmon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: logHandler should write a JSON line with all the fields, including the level and the event.
+func Test_logHandler_Handle_JSON_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, true, false, "/dev/sg0", "0x00", "")
+
+	logger.Warn("Alert: test", LogEvent("alert"))
+	require.True(t, strings.HasSuffix(buf.String(), "\n"))
+
+	var entry jsonLogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	require.NotEmpty(t, entry.Time)
+	require.Equal(t, "warn", entry.Level)
+	require.Equal(t, "/dev/sg0", entry.Device)
+	require.Equal(t, "0x00", entry.Address)
+	require.Equal(t, "alert", entry.Event)
+	require.Equal(t, "Alert: test", entry.Msg)
+}
+
+// Expectation: logHandler should use the default event and omit the empty fields (without timestamps).
+func Test_logHandler_Handle_JSONDefaults_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, true, true, "", "", "")
+
+	logger.Error("test")
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+
+	require.NotContains(t, result, "time")
+	require.NotContains(t, result, "device")
+	require.NotContains(t, result, "address")
+	require.Equal(t, "error", result["level"])
+	require.Equal(t, logEventDefault, result["event"])
+	require.Equal(t, "test", result["msg"])
+}
+
+// Expectation: logHandler should use the event of a derived logger, unless the log record has its own event.
+func Test_logHandler_WithAttrs_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, true, true, "", "", "").With(LogEvent("poll"))
+
+	var entry jsonLogEntry
+	logger.Info("test")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "poll", entry.Event)
+
+	buf.Reset()
+	logger.Info("test", LogEvent("changes"))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "changes", entry.Event)
+}
+
+// Expectation: logHandler should not handle log records below the info level.
+func Test_logHandler_Enabled_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, false, true, "", "", "")
+
+	logger.Debug("test")
+	require.Empty(t, buf.String())
+}
+
+// Expectation: logHandler should return an error when the output cannot be written to.
+func Test_logHandler_Handle_OutputFailure_Error(t *testing.T) {
+	t.Parallel()
+
+	h := newLogger(&failingWriter{}, true, true, "", "", "").Handler()
+
+	require.Error(t, h.Handle(t.Context(), slog.Record{Message: "test", Level: slog.LevelInfo}))
+}
+
+// Expectation: newLogger should produce prefixed human readable log lines.
+func Test_newLogger_Text_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, false, true, "/dev/sg0", "0x00", "")
+
+	logger.Warn("test", LogEvent("warning"))
+	require.Equal(t, "/dev/sg0:0x00: test\n", buf.String())
+}
+
+// Expectation: newLogger should prefix the human readable log lines with a timestamp (if enabled).
+func Test_newLogger_TextTimestamps_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, false, false, "/dev/sg0", "0x00", "")
+
+	logger.Info("test")
+	require.Regexp(t, `^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} /dev/sg0:0x00: test\n$`, buf.String())
+}
+
+// Expectation: newLogger should include the description of a device in the prefix (if any).
+func Test_newLogger_TextDescription_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, false, true, "/dev/sg0", "0x00", "Top JBOD - Row 2")
+
+	logger.Info("test")
+	require.Equal(t, "/dev/sg0:0x00 (Top JBOD - Row 2): test\n", buf.String())
+}
+
+// Expectation: NewLogger should produce unprefixed lines for the program-wide logger.
+func Test_NewLogger_TextNoDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, false, true)
+
+	logger.Info("test")
+	require.Equal(t, "test\n", buf.String())
+}
+
+// Expectation: newLogger should include the description of a device as field of JSON log lines (if any).
+func Test_newLogger_JSONDescription_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := newLogger(&buf, true, true, "/dev/sg0", "0x00", "Top JBOD - Row 2")
+
+	logger.Info("test")
+
+	var entry jsonLogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "Top JBOD - Row 2", entry.Description)
+
+	buf.Reset()
+	newLogger(&buf, true, true, "/dev/sg0", "0x00", "").Info("test")
+	require.NotContains(t, buf.String(), "description")
+}
+
+type failingWriter struct{}
+
+func (f *failingWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("write failure")
+}
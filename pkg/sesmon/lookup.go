@@ -0,0 +1,297 @@
+package sesmon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/afero"
+)
+
+// scsiTypeEnclosure is the SCSI peripheral device type of SES enclosures.
+const scsiTypeEnclosure = "13"
+
+// DefaultSysfsRoot is the default mount point of sysfs (used for device lookups).
+const DefaultSysfsRoot = "/sys"
+
+// DeviceLookuper is the contract for a SAS device resolver as part of a [Program].
+type DeviceLookuper interface {
+	FindAddress(devicePath string) (string, bool)
+	FindDevice(deviceAddress string) (string, bool)
+	FindEnclosures() []string
+}
+
+var _ DeviceLookuper = (*DeviceFinder)(nil)
+
+// DeviceFinder is the principal [DeviceLookuper] implementation.
+type DeviceFinder struct {
+	devices    map[string]string // SAS address -> device path
+	addresses  map[string]string // device path -> SAS address
+	paths      []string          // device paths of all found devices
+	enclosures []string          // device paths of SES enclosures
+}
+
+// foundDevice is a single device as found by a [deviceSource].
+type foundDevice struct {
+	path      string // device path (e.g. "/dev/sg25")
+	address   string // normalized address (empty if none)
+	enclosure bool   // whether the device is an SES enclosure
+}
+
+// deviceSource is the contract for a source (transport) of devices for a [DeviceFinder],
+// looking up the devices (and their addresses) relative to the given sysfs mount point.
+type deviceSource interface {
+	name() string
+	find(fsys afero.Fs, sysfsRoot string) ([]foundDevice, error)
+}
+
+// defaultDeviceSources are the [deviceSource] used by [NewDeviceFinder].
+var defaultDeviceSources = []deviceSource{scsiGenericSource{}, nvmeSource{}}
+
+// NewDeviceFinder returns a pointer to a new [DeviceFinder].
+// The devices are looked up relative to the given sysfs mount point,
+// covering both SCSI generic devices (SAS) and NVMe controllers.
+func NewDeviceFinder(fsys afero.Fs, sysfsRoot string, logger *slog.Logger) (*DeviceFinder, error) {
+	return newDeviceFinderFromSources(fsys, sysfsRoot, logger, defaultDeviceSources...)
+}
+
+// newDeviceFinderFromSources returns a pointer to a new [DeviceFinder],
+// which combines the devices of all of the given [deviceSource].
+func newDeviceFinderFromSources(fsys afero.Fs, sysfsRoot string, logger *slog.Logger, sources ...deviceSource) (*DeviceFinder, error) {
+	devices := map[string]string{}
+	ignored := map[string]struct{}{}
+	paths := []string{}
+	enclosures := []string{}
+
+	for _, source := range sources {
+		found, err := source.find(fsys, sysfsRoot)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source.name(), err)
+		}
+
+		for _, d := range found {
+			paths = append(paths, d.path)
+			if d.enclosure {
+				enclosures = append(enclosures, d.path)
+			}
+
+			if d.address == "" {
+				continue
+			}
+			if _, ok := devices[d.address]; ok {
+				ignored[d.address] = struct{}{}
+			}
+			devices[d.address] = d.path
+		}
+	}
+
+	for k := range ignored {
+		logger.Warn(fmt.Sprintf("SAS address [%s] came up for multiple devices "+
+			"(ignoring it for address lookups)", k), LogEvent("warning"))
+		delete(devices, k)
+	}
+
+	addresses := make(map[string]string, len(devices))
+	for sas, sg := range devices {
+		addresses[sg] = sas
+	}
+
+	return &DeviceFinder{
+		devices:    devices,
+		addresses:  addresses,
+		paths:      paths,
+		enclosures: enclosures,
+	}, nil
+}
+
+// scsiGenericSource is the [deviceSource] for SCSI generic devices (SAS),
+// using "class/scsi_generic/sg*/device" (with "sas_address" and "type").
+type scsiGenericSource struct{}
+
+func (scsiGenericSource) name() string {
+	return "scsi_generic"
+}
+
+func (scsiGenericSource) find(fsys afero.Fs, sysfsRoot string) ([]foundDevice, error) {
+	matches, err := afero.Glob(fsys, filepath.Join(sysfsRoot, "class", "scsi_generic", "sg*", "device"))
+	if err != nil {
+		return nil, fmt.Errorf("glob failure: %w", err)
+	}
+
+	found := make([]foundDevice, 0, len(matches))
+	for _, d := range matches {
+		dev := foundDevice{path: "/dev/" + filepath.Base(filepath.Dir(d))} // sgN
+
+		if typb, err := afero.ReadFile(fsys, filepath.Join(d, "type")); err == nil &&
+			strings.TrimSpace(string(typb)) == scsiTypeEnclosure {
+			dev.enclosure = true
+		}
+
+		if sasb, err := afero.ReadFile(fsys, filepath.Join(d, "sas_address")); err == nil {
+			dev.address = normalizeSASAddress(string(sasb))
+		}
+
+		found = append(found, dev)
+	}
+
+	return found, nil
+}
+
+// nvmeSource is the [deviceSource] for NVMe controllers (e.g. of NVMe-attached
+// enclosures), using "class/nvme/nvme*" with the EUI-64 or NAA identifier in the
+// "wwid" of its first namespace as address. Controllers without such an identifier
+// are still found, but cannot be looked up by address (and are not auto-discovered).
+type nvmeSource struct{}
+
+func (nvmeSource) name() string {
+	return "nvme"
+}
+
+func (nvmeSource) find(fsys afero.Fs, sysfsRoot string) ([]foundDevice, error) {
+	matches, err := afero.Glob(fsys, filepath.Join(sysfsRoot, "class", "nvme", "nvme*"))
+	if err != nil {
+		return nil, fmt.Errorf("glob failure: %w", err)
+	}
+
+	found := make([]foundDevice, 0, len(matches))
+	for _, d := range matches {
+		dev := foundDevice{path: "/dev/" + filepath.Base(d)} // nvmeN
+
+		namespaces, err := afero.Glob(fsys, filepath.Join(d, "nvme*n*", "wwid"))
+		if err != nil {
+			return nil, fmt.Errorf("glob failure: %w", err)
+		}
+		slices.Sort(namespaces)
+
+		for _, ns := range namespaces {
+			if wwidb, err := afero.ReadFile(fsys, ns); err == nil {
+				if addr, ok := nvmeWWIDAddress(string(wwidb)); ok {
+					dev.address = addr
+
+					break
+				}
+			}
+		}
+
+		found = append(found, dev)
+	}
+
+	return found, nil
+}
+
+// nvmeWWIDAddress returns the normalized address of an NVMe "wwid" (e.g. "eui.0025388b91b02345").
+// Only the EUI-64 and NAA identifiers are supported, as these are also hexadecimal (like SAS addresses).
+func nvmeWWIDAddress(wwid string) (string, bool) {
+	wwid = strings.ToLower(strings.TrimSpace(wwid))
+
+	for _, prefix := range []string{"eui.", "naa."} {
+		if id, ok := strings.CutPrefix(wwid, prefix); ok && id != "" {
+			return normalizeSASAddress(id), true
+		}
+	}
+
+	return "", false
+}
+
+// FindAddress tries to resolve a device path to a SAS address.
+func (f *DeviceFinder) FindAddress(devicePath string) (string, bool) {
+	if v, ok := f.addresses[devicePath]; ok {
+		return v, true
+	}
+
+	return "", false
+}
+
+// FindDevice tries to resolve a SAS address to a device path.
+// The SAS address is matched regardless of its case and "0x" prefix.
+func (f *DeviceFinder) FindDevice(deviceAddress string) (string, bool) {
+	if v, ok := f.devices[normalizeSASAddress(deviceAddress)]; ok {
+		return v, true
+	}
+
+	return "", false
+}
+
+// FindEnclosures returns the device paths of all SES enclosures (sorted).
+func (f *DeviceFinder) FindEnclosures() []string {
+	enclosures := slices.Clone(f.enclosures)
+	slices.Sort(enclosures)
+
+	return enclosures
+}
+
+// normalizeSASAddress returns the canonical form of a SAS address (lowercase
+// and "0x"-prefixed), so that addresses with or without the prefix compare equal.
+// An empty (or whitespace-only) SAS address is returned as an empty string.
+func normalizeSASAddress(addr string) string {
+	addr = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(addr)), "0x")
+	if addr == "" {
+		return ""
+	}
+
+	return "0x" + addr
+}
+
+// FindDevices returns the device paths of all found devices (sorted).
+func (f *DeviceFinder) FindDevices() []string {
+	paths := slices.Clone(f.paths)
+	slices.Sort(paths)
+
+	return paths
+}
+
+// ListDevices writes a tab-aligned table of all devices found by the
+// [DeviceFinder], with their SAS address, whether they are of the SES enclosure
+// type and whether sg_ses succeeds on them (so the SES-capable ones stand out).
+// It both observes and respects context cancellation for earlier termination.
+func ListDevices(ctx context.Context, finder *DeviceFinder, runner CommandRunner, sgSesPath string, out io.Writer) error {
+	cfg := DefaultDeviceMonitorConfig()
+	enclosures := finder.FindEnclosures()
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(tw, "DEVICE\tADDRESS\tENCLOSURE\tSES")
+
+	for _, path := range finder.FindDevices() {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		address, ok := finder.FindAddress(path)
+		if !ok {
+			address = "-"
+		}
+
+		_, _, err := runner.Run(ctx, RunCommandConfig{
+			Description:     fmt.Sprintf("%q", sgSesPath),
+			Command:         sgSesPath,
+			Args:            append(slices.Clone(cfg.SgSesArgs), path),
+			Attempts:        1,
+			AttemptTimeout:  *cfg.PollAttemptTimeout,
+			AttemptInterval: *cfg.PollAttemptInterval,
+			ExpectJSON:      true,
+		})
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", path, address,
+			yesNo(slices.Contains(enclosures, path)), yesNo(err == nil))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failure writing output: %w", err)
+	}
+
+	return nil
+}
+
+// yesNo returns "yes" or "no" for a boolean.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -1,7 +1,10 @@
-package main
+package sesmon
 
 import (
-	"log"
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -17,9 +20,9 @@ func Test_NewDeviceFinder_SingleDevice_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -27,6 +30,22 @@ func Test_NewDeviceFinder_SingleDevice_Success(t *testing.T) {
 	require.Equal(t, "/dev/sg0", finder.devices["0x5000c50098765432"])
 }
 
+// Expectation: NewDeviceFinder should look up the devices relative to the given sysfs root.
+func Test_NewDeviceFinder_SysfsRoot_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, fs.MkdirAll("/host/sys/class/scsi_generic/sg1/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/host/sys/class/scsi_generic/sg1/device/sas_address", []byte("0x5000c50098765432"), 0o644))
+
+	finder, err := NewDeviceFinder(fs, "/host/sys", slog.New(slog.DiscardHandler))
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"/dev/sg1"}, finder.FindDevices())
+	require.Equal(t, "/dev/sg1", finder.devices["0x5000c50098765432"])
+}
+
 // Expectation: NewDeviceFinder should successfully create finder with multiple devices.
 func Test_NewDeviceFinder_MultipleDevices_Success(t *testing.T) {
 	t.Parallel()
@@ -40,9 +59,9 @@ func Test_NewDeviceFinder_MultipleDevices_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg2/device/sas_address", []byte("0x5000c50098765434"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -61,9 +80,9 @@ func Test_NewDeviceFinder_NormalizeAddressToLowercase_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0X5000C50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -80,9 +99,9 @@ func Test_NewDeviceFinder_TrimWhitespace_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("  0x5000c50098765432  \n"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -101,9 +120,9 @@ func Test_NewDeviceFinder_SkipDevicesWithoutSasAddress_Success(t *testing.T) {
 	// sg1 has no sas_address file
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -122,9 +141,9 @@ func Test_NewDeviceFinder_SkipEmptySasAddress_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/sas_address", []byte(""), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -143,9 +162,9 @@ func Test_NewDeviceFinder_SkipWhitespaceOnlySasAddress_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/sas_address", []byte("   \n  \t  "), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -166,9 +185,9 @@ func Test_NewDeviceFinder_IgnoreDuplicateSasAddress_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg2/device/sas_address", []byte("0x5000c50098765433"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -176,10 +195,16 @@ func Test_NewDeviceFinder_IgnoreDuplicateSasAddress_Success(t *testing.T) {
 	require.Equal(t, "/dev/sg2", finder.devices["0x5000c50098765433"])
 	require.NotContains(t, finder.devices, "0x5000c50098765432")
 
+	require.Len(t, finder.addresses, 1)
+	require.Equal(t, "0x5000c50098765433", finder.addresses["/dev/sg2"])
+
+	_, ok := finder.FindAddress("/dev/sg0")
+	require.False(t, ok)
+	_, ok = finder.FindAddress("/dev/sg1")
+	require.False(t, ok)
+
 	output := buf.String()
-	require.Contains(t, output, "Warning:")
-	require.Contains(t, output, "0x5000c50098765432")
-	require.Contains(t, output, "multiple devices")
+	require.Contains(t, output, "SAS address [0x5000c50098765432] came up for multiple devices")
 }
 
 // Expectation: NewDeviceFinder should handle multiple duplicate addresses correctly.
@@ -197,9 +222,9 @@ func Test_NewDeviceFinder_MultipleDuplicates_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg3/device/sas_address", []byte("0x5000c50098765433"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -207,6 +232,7 @@ func Test_NewDeviceFinder_MultipleDuplicates_Success(t *testing.T) {
 
 	output := buf.String()
 	require.Empty(t, finder.devices)
+	require.Empty(t, finder.addresses)
 	require.Contains(t, output, "0x5000c50098765432")
 	require.Contains(t, output, "0x5000c50098765433")
 }
@@ -219,9 +245,9 @@ func Test_NewDeviceFinder_GlobError_Error(t *testing.T) {
 	// Create an invalid filesystem state or use a read-only filesystem
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	// When no matching paths exist, glob returns nil error with empty slice
 	// So this test verifies the finder is created but empty
@@ -238,9 +264,9 @@ func Test_NewDeviceFinder_EmptyScsiGeneric_Success(t *testing.T) {
 	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic", 0o755))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -256,9 +282,9 @@ func Test_NewDeviceFinder_HighDeviceNumbers_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg127/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 
 	require.NoError(t, err)
 	require.NotNil(t, finder)
@@ -275,9 +301,9 @@ func Test_FindDevice_ValidAddress_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	device, found := finder.FindDevice("0x5000c50098765432")
@@ -286,6 +312,54 @@ func Test_FindDevice_ValidAddress_Success(t *testing.T) {
 	require.Equal(t, "/dev/sg0", device)
 }
 
+// Expectation: FindDevice should match SAS addresses regardless of their case and "0x" prefix.
+func Test_FindDevice_MixedPrefix_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg1/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c500a1b2c3d4"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/sas_address", []byte("5000C500A1B2C3D5"), 0o644))
+
+	finder, err := NewDeviceFinder(fs, "/sys", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	for addr, want := range map[string]string{
+		"0x5000c500a1b2c3d4": "/dev/sg0",
+		"5000c500a1b2c3d4":   "/dev/sg0",
+		"0X5000C500A1B2C3D4": "/dev/sg0",
+		"0x5000c500a1b2c3d5": "/dev/sg1",
+		"5000c500a1b2c3d5":   "/dev/sg1",
+	} {
+		device, found := finder.FindDevice(addr)
+		require.True(t, found, addr)
+		require.Equal(t, want, device, addr)
+	}
+
+	addr, found := finder.FindAddress("/dev/sg1")
+	require.True(t, found)
+	require.Equal(t, "0x5000c500a1b2c3d5", addr)
+}
+
+// Expectation: normalizeSASAddress should return the lowercase and "0x"-prefixed form of a SAS address.
+func Test_normalizeSASAddress_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"0x5000c500a1b2c3d4":     "0x5000c500a1b2c3d4",
+		"5000c500a1b2c3d4":       "0x5000c500a1b2c3d4",
+		" 0X5000C500A1B2C3D4 \n": "0x5000c500a1b2c3d4",
+		"":                       "",
+		"  ":                     "",
+		"0x":                     "",
+	}
+
+	for in, want := range tests {
+		require.Equal(t, want, normalizeSASAddress(in), in)
+	}
+}
+
 // Expectation: FindDevice should return false for unknown SAS address.
 func Test_FindDevice_UnknownAddress_NotFound(t *testing.T) {
 	t.Parallel()
@@ -295,9 +369,9 @@ func Test_FindDevice_UnknownAddress_NotFound(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	device, found := finder.FindDevice("0x9999999999999999")
@@ -315,9 +389,9 @@ func Test_FindDevice_EmptyAddress_NotFound(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	device, found := finder.FindDevice("")
@@ -335,9 +409,9 @@ func Test_FindAddress_ValidDevice_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	address, found := finder.FindAddress("/dev/sg0")
@@ -355,9 +429,9 @@ func Test_FindAddress_UnknownDevice_NotFound(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	address, found := finder.FindAddress("/dev/sg99")
@@ -375,9 +449,9 @@ func Test_FindAddress_EmptyDevice_NotFound(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c50098765432"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	address, found := finder.FindAddress("")
@@ -397,9 +471,9 @@ func Test_FindAddress_FindDevice_Bidirectional_Success(t *testing.T) {
 	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/sas_address", []byte("0x5000c50098765433"), 0o644))
 
 	var buf safeBuffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
-	finder, err := NewDeviceFinder(fs, logger)
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
 	require.NoError(t, err)
 
 	// Forward lookup: address -> device
@@ -421,3 +495,194 @@ func Test_FindAddress_FindDevice_Bidirectional_Success(t *testing.T) {
 	require.True(t, foundAddr1)
 	require.Equal(t, "0x5000c50098765433", address1)
 }
+
+// Expectation: FindEnclosures should return only devices of the SES enclosure type (sorted).
+func Test_FindEnclosures_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg1/device", 0o755))
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg2/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/type", []byte("0\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/type", []byte("13\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg2/device/type", []byte("13\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg2/device/sas_address", []byte("0x5000c50098765432"), 0o644))
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/dev/sg1", "/dev/sg2"}, finder.FindEnclosures())
+
+	addr, ok := finder.FindAddress("/dev/sg2")
+	require.True(t, ok)
+	require.Equal(t, "0x5000c50098765432", addr)
+}
+
+// Expectation: FindEnclosures should return no devices when there are no SES enclosures.
+func Test_FindEnclosures_NoEnclosures_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/type", []byte("0\n"), 0o644))
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	finder, err := NewDeviceFinder(fs, "/sys", logger)
+	require.NoError(t, err)
+
+	require.Empty(t, finder.FindEnclosures())
+}
+
+// Expectation: FindDevices should return all found devices (sorted), regardless of address or type.
+func Test_FindDevices_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg1/device", 0o755))
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/sas_address", []byte("0x5000c50098765432"), 0o644))
+
+	finder, err := NewDeviceFinder(fs, "/sys", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/dev/sg0", "/dev/sg1"}, finder.FindDevices())
+}
+
+// Expectation: ListDevices should print a table of all devices with their address, type and SES capability.
+func Test_ListDevices_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg1/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/sas_address", []byte("0x5000c50098765432"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg1/device/type", []byte("13\n"), 0o644))
+
+	finder, err := NewDeviceFinder(fs, "/sys", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("{}", "", nil)
+
+	var out bytes.Buffer
+	require.NoError(t, ListDevices(t.Context(), finder, runner, "/usr/bin/sg_ses", &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"DEVICE", "ADDRESS", "ENCLOSURE", "SES"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"/dev/sg0", "-", "no", "yes"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"/dev/sg1", "0x5000c50098765432", "yes", "yes"}, strings.Fields(lines[2]))
+
+	require.Equal(t, 2, runner.callCount())
+	require.Equal(t, "/usr/bin/sg_ses", runner.lastConfig().Command)
+	require.Equal(t, "/dev/sg1", runner.lastConfig().Args[len(runner.lastConfig().Args)-1])
+	require.Equal(t, 1, runner.lastConfig().Attempts)
+}
+
+// Expectation: ListDevices should mark devices as not SES-capable when sg_ses fails on them.
+func Test_ListDevices_ProbeFailure_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+
+	finder, err := NewDeviceFinder(fs, "/sys", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errors.New("exit status 1"))
+
+	var out bytes.Buffer
+	require.NoError(t, ListDevices(t.Context(), finder, runner, "sg_ses", &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"/dev/sg0", "-", "no", "no"}, strings.Fields(lines[1]))
+}
+
+// Expectation: NewDeviceFinder should find NVMe controllers with EUI-64 or NAA identifiers as address.
+func Test_NewDeviceFinder_NVMe_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c500a1b2c3d4"), 0o644))
+	require.NoError(t, fs.MkdirAll("/sys/class/nvme/nvme0/nvme0n1", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/nvme/nvme0/nvme0n1/wwid", []byte("eui.0025388B91B02345\n"), 0o644))
+	require.NoError(t, fs.MkdirAll("/sys/class/nvme/nvme1/nvme1n1", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/nvme/nvme1/nvme1n1/wwid", []byte("nvme.144d-53333-000001\n"), 0o644))
+	require.NoError(t, fs.MkdirAll("/sys/class/nvme/nvme2", 0o755))
+
+	finder, err := NewDeviceFinder(fs, "/sys", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/dev/nvme0", "/dev/nvme1", "/dev/nvme2", "/dev/sg0"}, finder.FindDevices())
+	require.Empty(t, finder.FindEnclosures())
+
+	device, found := finder.FindDevice("0025388b91b02345")
+	require.True(t, found)
+	require.Equal(t, "/dev/nvme0", device)
+
+	_, found = finder.FindAddress("/dev/nvme1")
+	require.False(t, found)
+
+	device, found = finder.FindDevice("0x5000c500a1b2c3d4")
+	require.True(t, found)
+	require.Equal(t, "/dev/sg0", device)
+}
+
+// stubDeviceSource is a [deviceSource] returning a fixed set of devices.
+type stubDeviceSource struct {
+	devices []foundDevice
+	err     error
+}
+
+func (s stubDeviceSource) name() string {
+	return "stub"
+}
+
+func (s stubDeviceSource) find(_ afero.Fs, _ string) ([]foundDevice, error) {
+	return s.devices, s.err
+}
+
+// Expectation: newDeviceFinderFromSources should combine all sources and ignore duplicate addresses across them.
+func Test_newDeviceFinderFromSources_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+
+	finder, err := newDeviceFinderFromSources(afero.NewMemMapFs(), "/sys", NewLogger(&buf, false, true),
+		stubDeviceSource{devices: []foundDevice{
+			{path: "/dev/sg0", address: "0x5000", enclosure: true},
+			{path: "/dev/sg1", address: "0x6000"},
+		}},
+		stubDeviceSource{devices: []foundDevice{
+			{path: "/dev/nvme0", address: "0x6000"},
+		}},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"/dev/nvme0", "/dev/sg0", "/dev/sg1"}, finder.FindDevices())
+	require.Equal(t, []string{"/dev/sg0"}, finder.FindEnclosures())
+
+	_, found := finder.FindDevice("0x6000")
+	require.False(t, found)
+	require.Contains(t, buf.String(), "came up for multiple devices")
+}
+
+// Expectation: newDeviceFinderFromSources should return an error if any source fails.
+func Test_newDeviceFinderFromSources_SourceError_Error(t *testing.T) {
+	t.Parallel()
+
+	finder, err := newDeviceFinderFromSources(afero.NewMemMapFs(), "/sys", slog.New(slog.DiscardHandler),
+		stubDeviceSource{err: errors.New("broken")},
+	)
+	require.ErrorContains(t, err, "stub: broken")
+	require.Nil(t, finder)
+}
@@ -0,0 +1,2070 @@
+package sesmon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DeviceTypeDevice = 0
+	DeviceTypeFile   = 1
+)
+
+// elementDropID is the ID of the [Change] that the removals of elements are coalesced into
+// with [DeviceMonitorConfig.ElementDropThreshold] (which is not the ID of any element).
+const elementDropID = "element-drop"
+
+type DeviceMonitorConfig struct {
+	// How often to poll the target device for data.
+	PollInterval *time.Duration `yaml:"poll_interval"`
+
+	// Random delay of [0, PollJitter) before the first device poll, and also
+	// a random lengthening of [0, PollJitter) to the device poll interval.
+	// Avoids many devices being polled at once (never exceeds [PollInterval]).
+	PollJitter *time.Duration `yaml:"poll_jitter"`
+
+	// How often to attempt a device poll (must be > 0).
+	PollAttempts *int `yaml:"poll_attempts"`
+
+	// How long a device poll attempt can take (multiplies with attempts).
+	PollAttemptTimeout *time.Duration `yaml:"poll_attempt_timeout"`
+
+	// How long to wait between device poll attempts (in case of failure).
+	PollAttemptInterval *time.Duration `yaml:"poll_attempt_interval"`
+
+	// Multiplier of the wait between device poll attempts after every failed attempt,
+	// so that the waits grow (1 = fixed "poll_attempt_interval", must be >= 1).
+	PollAttemptBackoff *float64 `yaml:"poll_attempt_backoff"`
+
+	// Upper bound of the grown wait between device poll attempts (0 = unbounded).
+	PollAttemptBackoffMax *time.Duration `yaml:"poll_attempt_backoff_max"`
+
+	// Fraction of the wait between device poll attempts that is randomly added to or
+	// subtracted from it, so that retries of devices spread out (0 = none, up to 1).
+	PollAttemptJitter *float64 `yaml:"poll_attempt_jitter"`
+
+	// How long to wait for a timed out device poll to exit after it was killed,
+	// before giving up on it, e.g. for sg_ses hanging on a SAS link (must be > 0).
+	PollWaitDelay *time.Duration `yaml:"poll_wait_delay"`
+
+	// Refuse (instead of warning about) configurations where the worst-case
+	// poll attempt budget (attempts x (timeout + interval)) exceeds [PollInterval].
+	PollBudgetStrict *bool `yaml:"poll_budget_strict"`
+
+	// How many consecutive poll failures trigger back-off period.
+	// Note: First failure = after 3 attempts (set value of poll_attempts),
+	// so backoff after 3 failures = after total 9 failed poll attempts.
+	PollBackoffAfter *int `yaml:"poll_backoff_after"`
+
+	// How long to pause polling the device when in back-off period.
+	PollBackoffTime *time.Duration `yaml:"poll_backoff_time"`
+
+	// Dispatch notification through agent when entering back-off period.
+	// Applies only if a notification agent is configured for the device.
+	PollBackoffNotify *bool `yaml:"poll_backoff_notify"`
+
+	// Permanently stop monitoring the device when entering back-off period.
+	// If false, monitoring resumes normally after [PollBackoffTime] elapses.
+	PollBackoffStopMonitor *bool `yaml:"poll_backoff_stopmonitor"`
+
+	// When monitoring resumes after [PollBackoffTime] elapsed, treat the next poll as a fresh
+	// baseline: the net differences to the results from before the back-off period are raised
+	// as a single summary alert (bypassing [ChangeDebounce]), instead of as per-element alerts.
+	BackoffResetBaseline *bool `yaml:"backoff_reset_baseline"`
+
+	// Fraction of failed polls within the last [PollFailureRateWindow] polls at or above which
+	// an alert is raised (once), regardless of whether the failures were consecutive (unlike
+	// [PollBackoffAfter]), e.g. for intermittently failing devices (0 = disabled, up to 1,
+	// where 1 alerts once all of the polls within the window have failed).
+	PollFailureRateAlert *float64 `yaml:"poll_failure_rate_alert"`
+
+	// Amount of the most recent polls the failure rate is calculated over (must be > 0).
+	// The window is reset (allowing for another alert) once all polls within it have succeeded.
+	PollFailureRateWindow *int `yaml:"poll_failure_rate_window"`
+
+	// Optional: Command to run when monitoring of the device stops (for any reason,
+	// including [PollBackoffStopMonitor]), with the device path and address as arguments.
+	// Its failure is only logged, so it never holds up the stopping beyond [OnStopTimeout].
+	OnStopCommand *string `yaml:"on_stop_command"`
+
+	// How long the [OnStopCommand] can take before it is terminated (must be > 0).
+	OnStopTimeout *time.Duration `yaml:"on_stop_timeout"`
+
+	// Optional: Command to run before every device poll (e.g. to reset a latched condition
+	// of the enclosure), with the device path and address as arguments. Its failure is only
+	// logged, unless [PrePollAbort] is set (then failing the poll, counting towards back-off).
+	PrePollCommand *string `yaml:"pre_poll_command"`
+
+	// How long the [PrePollCommand] can take before it is terminated (must be > 0). As it runs
+	// within the slot of the device poll, this counts towards the worst-case poll budget.
+	PrePollTimeout *time.Duration `yaml:"pre_poll_timeout"`
+
+	// Abort the device poll if the [PrePollCommand] fails (instead of only logging it).
+	PrePollAbort *bool `yaml:"pre_poll_abort"`
+
+	// Path to (or name of) the sg_ses executable used for polling the device.
+	SgSesPath *string `yaml:"sg_ses_path"`
+
+	// Arguments for the sg_ses executable (device path is appended as last argument).
+	// The executable needs to output JSON, so the arguments should include "--json".
+	SgSesArgs []string `yaml:"sg_ses_args"`
+
+	// Optional: Command used for polling the device instead of the sg_ses executable.
+	// It needs to output JSON in the same format as sg_ses (with [FetchArgs] as arguments).
+	FetchCommand *string `yaml:"fetch_command"`
+
+	// Arguments for the [FetchCommand], each evaluated as a template with the [Device]
+	// (e.g. "{{.Path}}" or "{{.Address}}"). The device path is not appended to these.
+	FetchArgs []string `yaml:"fetch_args"`
+
+	// Tolerate (and trim) any non-JSON text preceding the JSON output of sg_ses
+	// (e.g. warnings printed by some firmwares), logged when [Verbose] is set.
+	ToleratePreamble *bool `yaml:"tolerate_preamble"`
+
+	// Dispatch notification through agent when elements recover (back to OK).
+	// Applies only if a notification agent is configured for the device.
+	NotifyOnRecovery *bool `yaml:"notify_on_recovery"`
+
+	// Minimum (highest) severity of the changes of an alert for dispatching a notification
+	// through the agent (ok, info, warning, critical or unrecoverable), where alerts below
+	// it are still logged and reported. Recoveries are controlled by [NotifyOnRecovery].
+	NotifyMinSeverity *string `yaml:"notify_min_severity"`
+
+	// Raise an alert on the initial poll for all elements with problems (that is,
+	// a status of a warning or critical state), rather than only seeding the results.
+	// Does not apply if previous results were loaded from [OutputDir] (compared instead).
+	AlertOnStartUnhealthy *bool `yaml:"alert_on_start_unhealthy"`
+
+	// Minimum time between alert notifications through agent (0 = unlimited).
+	// Alerts within this window are held back (but still logged and written),
+	// with a summary of them dispatched once the window elapses. Recoveries
+	// are never held back. Applies only if a notification agent is configured.
+	NotifyMinInterval *time.Duration `yaml:"notify_min_interval"`
+
+	// How long an alert suppresses an identical consecutive alert (0 = indefinitely).
+	// Once expired, an identical alert (e.g. of a flapping element) is raised again.
+	AlertDedupTTL *time.Duration `yaml:"alert_dedup_ttl"`
+
+	// Shorten the notification messages to the status transition of every change
+	// (e.g. "Slot 03: OK -> Critical"), with all fields only logged if [Verbose].
+	// The change reports (and notification payloads) still contain all fields.
+	CompactMessages *bool `yaml:"compact_messages"`
+
+	// How often to dispatch a heartbeat notification through agent (0 = disabled).
+	// Heartbeats summarize the current element counts and health of the device,
+	// providing evidence that the monitoring is alive even when nothing changes.
+	HeartbeatInterval *time.Duration `yaml:"heartbeat_interval"`
+
+	// Temperature (in Celsius) at which an element raises a warning alert.
+	// Alerts are only raised on crossing, with [TempHysteresis] for clearing.
+	TempWarn *int `yaml:"temp_warn"`
+
+	// Temperature (in Celsius) at which an element raises a critical alert.
+	// Alerts are only raised on crossing, with [TempHysteresis] for clearing.
+	TempCrit *int `yaml:"temp_crit"`
+
+	// How many degrees (in Celsius) the temperature must fall below a crossed
+	// threshold before the alert clears again (avoids alerts flapping).
+	TempHysteresis *int `yaml:"temp_hysteresis"`
+
+	// Raise alerts when the speed class of a cooling element (fan) changes.
+	// Fans stopping (or starting again) always raise alerts regardless of this.
+	AlertFanSpeed *bool `yaml:"alert_fan_speed"`
+
+	// Consider predicted failures (the PRDFAIL bit of an element getting set) as
+	// critical changes, rather than as warnings (if the status is not any worse).
+	PrdFailCritical *bool `yaml:"prdfail_critical"`
+
+	// Element types (e.g. 2 = power supply, 23 = disk slot) to exclusively monitor.
+	// If set, all elements of other types are ignored entirely (applies before
+	// [SuppressTypes], which can further suppress changes of the monitored types).
+	MonitorTypes []int `yaml:"monitor_types"`
+
+	// Element types (e.g. 16 = UPS) for which changes are suppressed from the alerts.
+	// Suppressed changes are excluded from notifications and the change reports,
+	// but the elements are still contained in the current device state output.
+	SuppressTypes []int `yaml:"suppress_types"`
+
+	// How many consecutive polls a status change of an element must persist for
+	// before raising an alert (must be > 0, 1 = alert immediately). Changes that
+	// revert to the previous value within these polls never raise an alert.
+	ChangeDebounce *int `yaml:"change_debounce"`
+
+	// Element status codes (e.g. 5 = Not installed) that are considered as transient, e.g. as
+	// briefly reported during SAS topology changes. Changes into these are only alerted once
+	// persisting for [TransientDebounce] polls, changes out of these clear silently (unless alerted).
+	TransientStatuses []int `yaml:"transient_statuses"`
+
+	// How many consecutive polls a change into any of [TransientStatuses] must persist for
+	// before raising an alert (must be > 0, the higher one of this and [ChangeDebounce] applies).
+	TransientDebounce *int `yaml:"transient_debounce"`
+
+	// Fraction of the elements (of the previous poll) that needs to disappear at once,
+	// for their removals to be coalesced into a single critical change (0 = disabled, up to 1).
+	// The removals are held back by [ChangeDebounce] as any others before being coalesced.
+	ElementDropThreshold *float64 `yaml:"element_drop_threshold"`
+
+	// Derive the status descriptions of elements from their numeric status codes
+	// (using the canonical SES meanings), instead of the text of the fetched data.
+	CanonicalStatusMeanings *bool `yaml:"canonical_status_meanings"`
+
+	// Status descriptions by numeric status code (0-15), which take precedence over
+	// both the canonical SES meanings and the text of the fetched data.
+	StatusMeanings map[int]string `yaml:"status_meanings"`
+
+	// Folder to write JSON files of device state and alerts to.
+	// Must be unique per device and creates the following files:
+	//  - current.json (raw snapshot of current device state, if [WriteSnapshots] is enabled)
+	//  - current_parsed.json (parsed snapshot of current device state, if [WriteSnapshots] is enabled)
+	//  - change-YYYYMMDD-HHMMSS.json (single timestamped change report, if [WriteChangeReports] is enabled)
+	//  - ...
+	//  - changelog.jsonl (all change reports, if [OutputChangelog] is enabled)
+	//  - snapshot-YYYYMMDD-HHMMSS.json (parsed snapshots, if [SnapshotHistory] is set)
+	//  - element-history.json (status changes per element, if [ElementHistory] is set)
+	//  - pending.json (changes held back, if [ChangeDebounce] or [TransientStatuses] is set, only for [DeviceMonitor.RunOnce])
+	// All .json files are written as .json.gz if [OutputCompress] is enabled.
+	// All files are named with [OutputPrefix] (if set), e.g. "sesmon-current.json".
+	// See [OutputMaxReports] and [OutputMaxAge] for retention of change reports.
+	OutputDir *string `yaml:"output_dir"`
+
+	// Prefix of the names of all files written to [OutputDir] (e.g. "sesmon-" for
+	// "sesmon-current.json"), e.g. for an [OutputDir] shared with other tools.
+	// Must not contain any path separators (empty = no prefix).
+	OutputPrefix *string `yaml:"output_prefix"`
+
+	// Write the current.json and current_parsed.json snapshots to [OutputDir] on every poll.
+	// Applies only if [OutputDir] is set, disable to reduce the writes (e.g. on flash storage).
+	WriteSnapshots *bool `yaml:"write_snapshots"`
+
+	// Write the timestamped change reports (change-*.json) to [OutputDir] on changes.
+	// Applies only if [OutputDir] is set, the changelog is controlled by [OutputChangelog].
+	WriteChangeReports *bool `yaml:"write_change_reports"`
+
+	// Include the full parsed results of the device (at the time of the changes) in the
+	// change reports, so that each of them is self-contained (at the cost of their size).
+	ReportResults *bool `yaml:"report_results"`
+
+	// How many change reports to keep in [OutputDir] (0 = unlimited).
+	// The oldest change reports beyond this limit are removed after writing.
+	OutputMaxReports *int `yaml:"output_max_reports"`
+
+	// How long to keep change reports in [OutputDir] (0 = unlimited).
+	// Change reports older than this are removed after writing.
+	OutputMaxAge *time.Duration `yaml:"output_max_age"`
+
+	// Append every change report as a single JSON line to changelog.jsonl
+	// in [OutputDir] (complementing the single timestamped change reports).
+	OutputChangelog *bool `yaml:"output_changelog"`
+
+	// Gzip-compress the change reports and snapshots written to [OutputDir]
+	// (as .json.gz files, except for the appended changelog.jsonl).
+	OutputCompress *bool `yaml:"output_compress"`
+
+	// Permissions (octal) of the files written to [OutputDir] (before the umask).
+	OutputFileMode *string `yaml:"output_file_mode"`
+
+	// Permissions (octal) of the folders created for [OutputDir] (before the umask).
+	OutputDirMode *string `yaml:"output_dir_mode"`
+
+	// Persist the acknowledgements of elements (see [DeviceMonitor.Acknowledge]) to acks.json
+	// in [OutputDir], so that they survive restarts of the program (otherwise kept in memory).
+	PersistAcks *bool `yaml:"persist_acks"`
+
+	// How many timestamped parsed snapshots to keep in [OutputDir] (0 = disabled).
+	// Written on polls with changes (or every poll, see [SnapshotEveryPoll]),
+	// with the oldest snapshots beyond this limit removed after writing.
+	SnapshotHistory *int `yaml:"snapshot_history"`
+
+	// Write a timestamped parsed snapshot on every poll (not only on those with changes).
+	// Applies only if [SnapshotHistory] is set, which also limits the amount kept.
+	SnapshotEveryPoll *bool `yaml:"snapshot_every_poll"`
+
+	// How many status changes to keep per element (with their timestamps) for their history
+	// (0 = disabled). The history is written to element-history.json in [OutputDir] on every
+	// status change of any element, and is continued from it across restarts (and runs).
+	ElementHistory *int `yaml:"element_history"`
+
+	// Output a structured (JSON) change event for every detection as part of log output.
+	LogChangeEvents *bool `yaml:"log_change_events"`
+
+	// Output also verbose operational information as part of log output.
+	Verbose *bool `yaml:"verbose"`
+}
+
+// MarshalJSON is a custom JSON marshaller for user readable [time.Duration] strings.
+func (c DeviceMonitorConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct { //nolint:wrapcheck
+		PollInterval            *string        `json:"poll_interval"`
+		PollJitter              *string        `json:"poll_jitter"`
+		PollAttempts            *int           `json:"poll_attempts"`
+		PollAttemptTimeout      *string        `json:"poll_attempt_timeout"`
+		PollAttemptInterval     *string        `json:"poll_attempt_interval"`
+		PollAttemptBackoff      *float64       `json:"poll_attempt_backoff"`
+		PollAttemptBackoffMax   *string        `json:"poll_attempt_backoff_max"`
+		PollAttemptJitter       *float64       `json:"poll_attempt_jitter"`
+		PollWaitDelay           *string        `json:"poll_wait_delay"`
+		PollBudgetStrict        *bool          `json:"poll_budget_strict"`
+		PollBackoffAfter        *int           `json:"poll_backoff_after"`
+		PollBackoffTime         *string        `json:"poll_backoff_time"`
+		PollBackoffNotify       *bool          `json:"poll_backoff_notify"`
+		PollBackoffStopMonitor  *bool          `json:"poll_backoff_stopmonitor"`
+		BackoffResetBaseline    *bool          `json:"backoff_reset_baseline"`
+		PollFailureRateAlert    *float64       `json:"poll_failure_rate_alert"`
+		PollFailureRateWindow   *int           `json:"poll_failure_rate_window"`
+		OnStopCommand           *string        `json:"on_stop_command"`
+		OnStopTimeout           *string        `json:"on_stop_timeout"`
+		PrePollCommand          *string        `json:"pre_poll_command"`
+		PrePollTimeout          *string        `json:"pre_poll_timeout"`
+		PrePollAbort            *bool          `json:"pre_poll_abort"`
+		SgSesPath               *string        `json:"sg_ses_path"`
+		SgSesArgs               []string       `json:"sg_ses_args"`
+		FetchCommand            *string        `json:"fetch_command"`
+		FetchArgs               []string       `json:"fetch_args"`
+		ToleratePreamble        *bool          `json:"tolerate_preamble"`
+		NotifyOnRecovery        *bool          `json:"notify_on_recovery"`
+		NotifyMinSeverity       *string        `json:"notify_min_severity"`
+		AlertOnStartUnhealthy   *bool          `json:"alert_on_start_unhealthy"`
+		NotifyMinInterval       *string        `json:"notify_min_interval"`
+		AlertDedupTTL           *string        `json:"alert_dedup_ttl"`
+		CompactMessages         *bool          `json:"compact_messages"`
+		HeartbeatInterval       *string        `json:"heartbeat_interval"`
+		TempWarn                *int           `json:"temp_warn"`
+		TempCrit                *int           `json:"temp_crit"`
+		TempHysteresis          *int           `json:"temp_hysteresis"`
+		AlertFanSpeed           *bool          `json:"alert_fan_speed"`
+		PrdFailCritical         *bool          `json:"prdfail_critical"`
+		MonitorTypes            []int          `json:"monitor_types"`
+		SuppressTypes           []int          `json:"suppress_types"`
+		ChangeDebounce          *int           `json:"change_debounce"`
+		TransientStatuses       []int          `json:"transient_statuses"`
+		TransientDebounce       *int           `json:"transient_debounce"`
+		ElementDropThreshold    *float64       `json:"element_drop_threshold"`
+		CanonicalStatusMeanings *bool          `json:"canonical_status_meanings"`
+		StatusMeanings          map[int]string `json:"status_meanings"`
+		OutputDir               *string        `json:"output_dir"`
+		OutputPrefix            *string        `json:"output_prefix"`
+		WriteSnapshots          *bool          `json:"write_snapshots"`
+		WriteChangeReports      *bool          `json:"write_change_reports"`
+		ReportResults           *bool          `json:"report_results"`
+		OutputMaxReports        *int           `json:"output_max_reports"`
+		OutputMaxAge            *string        `json:"output_max_age"`
+		OutputChangelog         *bool          `json:"output_changelog"`
+		OutputCompress          *bool          `json:"output_compress"`
+		OutputFileMode          *string        `json:"output_file_mode"`
+		OutputDirMode           *string        `json:"output_dir_mode"`
+		PersistAcks             *bool          `json:"persist_acks"`
+		SnapshotHistory         *int           `json:"snapshot_history"`
+		SnapshotEveryPoll       *bool          `json:"snapshot_every_poll"`
+		ElementHistory          *int           `json:"element_history"`
+		LogChangeEvents         *bool          `json:"log_change_events"`
+		Verbose                 *bool          `json:"verbose"`
+	}{
+		PollInterval:            durPtrToStrPtr(c.PollInterval),
+		PollJitter:              durPtrToStrPtr(c.PollJitter),
+		PollAttempts:            c.PollAttempts,
+		PollAttemptTimeout:      durPtrToStrPtr(c.PollAttemptTimeout),
+		PollAttemptInterval:     durPtrToStrPtr(c.PollAttemptInterval),
+		PollAttemptBackoff:      c.PollAttemptBackoff,
+		PollAttemptBackoffMax:   durPtrToStrPtr(c.PollAttemptBackoffMax),
+		PollAttemptJitter:       c.PollAttemptJitter,
+		PollWaitDelay:           durPtrToStrPtr(c.PollWaitDelay),
+		PollBudgetStrict:        c.PollBudgetStrict,
+		PollBackoffAfter:        c.PollBackoffAfter,
+		PollBackoffTime:         durPtrToStrPtr(c.PollBackoffTime),
+		PollBackoffNotify:       c.PollBackoffNotify,
+		PollBackoffStopMonitor:  c.PollBackoffStopMonitor,
+		BackoffResetBaseline:    c.BackoffResetBaseline,
+		PollFailureRateAlert:    c.PollFailureRateAlert,
+		PollFailureRateWindow:   c.PollFailureRateWindow,
+		OnStopCommand:           c.OnStopCommand,
+		OnStopTimeout:           durPtrToStrPtr(c.OnStopTimeout),
+		PrePollCommand:          c.PrePollCommand,
+		PrePollTimeout:          durPtrToStrPtr(c.PrePollTimeout),
+		PrePollAbort:            c.PrePollAbort,
+		SgSesPath:               c.SgSesPath,
+		SgSesArgs:               c.SgSesArgs,
+		FetchCommand:            c.FetchCommand,
+		FetchArgs:               c.FetchArgs,
+		ToleratePreamble:        c.ToleratePreamble,
+		NotifyOnRecovery:        c.NotifyOnRecovery,
+		NotifyMinSeverity:       c.NotifyMinSeverity,
+		AlertOnStartUnhealthy:   c.AlertOnStartUnhealthy,
+		NotifyMinInterval:       durPtrToStrPtr(c.NotifyMinInterval),
+		AlertDedupTTL:           durPtrToStrPtr(c.AlertDedupTTL),
+		CompactMessages:         c.CompactMessages,
+		HeartbeatInterval:       durPtrToStrPtr(c.HeartbeatInterval),
+		TempWarn:                c.TempWarn,
+		TempCrit:                c.TempCrit,
+		TempHysteresis:          c.TempHysteresis,
+		AlertFanSpeed:           c.AlertFanSpeed,
+		PrdFailCritical:         c.PrdFailCritical,
+		MonitorTypes:            c.MonitorTypes,
+		SuppressTypes:           c.SuppressTypes,
+		ChangeDebounce:          c.ChangeDebounce,
+		TransientStatuses:       c.TransientStatuses,
+		TransientDebounce:       c.TransientDebounce,
+		ElementDropThreshold:    c.ElementDropThreshold,
+		CanonicalStatusMeanings: c.CanonicalStatusMeanings,
+		StatusMeanings:          c.StatusMeanings,
+		OutputDir:               c.OutputDir,
+		OutputPrefix:            c.OutputPrefix,
+		WriteSnapshots:          c.WriteSnapshots,
+		WriteChangeReports:      c.WriteChangeReports,
+		ReportResults:           c.ReportResults,
+		OutputMaxReports:        c.OutputMaxReports,
+		OutputMaxAge:            durPtrToStrPtr(c.OutputMaxAge),
+		OutputChangelog:         c.OutputChangelog,
+		OutputCompress:          c.OutputCompress,
+		OutputFileMode:          c.OutputFileMode,
+		OutputDirMode:           c.OutputDirMode,
+		PersistAcks:             c.PersistAcks,
+		SnapshotHistory:         c.SnapshotHistory,
+		SnapshotEveryPoll:       c.SnapshotEveryPoll,
+		ElementHistory:          c.ElementHistory,
+		LogChangeEvents:         c.LogChangeEvents,
+		Verbose:                 c.Verbose,
+	})
+}
+
+// UnmarshalYAML is a custom YAML unmarshaller accepting bare integers (as seconds) for durations.
+func (c *DeviceMonitorConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain DeviceMonitorConfig
+
+	return decodeYAMLDurations(value, (*plain)(c), reflect.TypeFor[DeviceMonitorConfig]())
+}
+
+// DefaultDeviceMonitorConfig returns a pointer to a default [DeviceMonitorConfig].
+//
+//nolint:mnd
+func DefaultDeviceMonitorConfig() *DeviceMonitorConfig {
+	return &DeviceMonitorConfig{
+		PollInterval:            ptr(90 * time.Second),
+		PollJitter:              ptr(time.Duration(0)),
+		PollAttempts:            ptr(3),
+		PollAttemptTimeout:      ptr(15 * time.Second),
+		PollAttemptInterval:     ptr(15 * time.Second),
+		PollAttemptBackoff:      ptr(1.0),
+		PollAttemptBackoffMax:   ptr(2 * time.Minute),
+		PollAttemptJitter:       ptr(0.0),
+		PollWaitDelay:           ptr(waitDelay),
+		PollBudgetStrict:        ptr(false),
+		PollBackoffAfter:        ptr(3),
+		PollBackoffTime:         ptr(3 * time.Minute),
+		PollBackoffNotify:       ptr(true),
+		PollBackoffStopMonitor:  ptr(false),
+		BackoffResetBaseline:    ptr(false),
+		PollFailureRateAlert:    ptr(0.0),
+		PollFailureRateWindow:   ptr(10),
+		OnStopCommand:           nil,
+		OnStopTimeout:           ptr(10 * time.Second),
+		PrePollCommand:          nil,
+		PrePollTimeout:          ptr(10 * time.Second),
+		PrePollAbort:            ptr(false),
+		SgSesPath:               ptr("sg_ses"),
+		SgSesArgs:               []string{"--all", "--no-time", "--json"},
+		FetchCommand:            nil,
+		FetchArgs:               []string{},
+		ToleratePreamble:        ptr(false),
+		NotifyOnRecovery:        ptr(true),
+		NotifyMinSeverity:       ptr(SeverityOK),
+		AlertOnStartUnhealthy:   ptr(false),
+		NotifyMinInterval:       ptr(time.Duration(0)),
+		AlertDedupTTL:           ptr(time.Duration(0)),
+		CompactMessages:         ptr(false),
+		HeartbeatInterval:       ptr(time.Duration(0)),
+		TempWarn:                nil,
+		TempCrit:                nil,
+		TempHysteresis:          ptr(2),
+		AlertFanSpeed:           ptr(false),
+		PrdFailCritical:         ptr(false),
+		MonitorTypes:            []int{},
+		SuppressTypes:           []int{},
+		ChangeDebounce:          ptr(1),
+		TransientStatuses:       []int{},
+		TransientDebounce:       ptr(3),
+		ElementDropThreshold:    ptr(0.0),
+		CanonicalStatusMeanings: ptr(false),
+		StatusMeanings:          nil,
+		OutputDir:               nil,
+		OutputPrefix:            ptr(""),
+		WriteSnapshots:          ptr(true),
+		WriteChangeReports:      ptr(true),
+		ReportResults:           ptr(false),
+		OutputMaxReports:        ptr(0),
+		OutputMaxAge:            ptr(time.Duration(0)),
+		OutputChangelog:         ptr(false),
+		OutputCompress:          ptr(false),
+		OutputFileMode:          ptr("0666"),
+		OutputDirMode:           ptr("0777"),
+		PersistAcks:             ptr(false),
+		SnapshotHistory:         ptr(0),
+		SnapshotEveryPoll:       ptr(false),
+		ElementHistory:          ptr(0),
+		LogChangeEvents:         ptr(false),
+		Verbose:                 ptr(false),
+	}
+}
+
+// deviceMonitorState is the state of a [DeviceMonitor].
+type deviceMonitorState struct {
+	// Amount of poll failures for the device (resets with back-off period).
+	pollFailures int
+
+	// Whether the device path has disappeared (as detected by [DeviceMonitor.fetchFromDevice]).
+	deviceGone bool
+
+	// Outcomes (true = failed) of the most recent polls (bounded by [PollFailureRateWindow])
+	// and whether their failure rate was alerted (see [PollFailureRateAlert]).
+	pollWindow         []bool
+	failureRateAlerted bool
+
+	// Hash and time of the last alert that has been raised (to avoid duplicate alerts).
+	lastAlertHash string
+	lastAlertAt   time.Time
+
+	// Map of the previous poll [Result] for comparison against current.
+	previousResults map[string]Result
+
+	// Whether monitoring has resumed after a back-off period (see [BackoffResetBaseline]).
+	resumedFromBackoff bool
+
+	// Amount of elements skipped by the previous poll (without element type or number).
+	skippedElements int
+
+	// File last read for a file-type device with a glob pattern (the newest match).
+	globFile string
+
+	// Map of the current temperature levels (normal, warning, critical).
+	tempLevels map[string]int
+
+	// Map of the element changes held back until confirmed (see [ChangeDebounce]).
+	pendingChanges map[string]*pendingChange
+
+	// Set of the elements whose change into a transient status was alerted, so that
+	// (only) their changes out of it are alerted as well (see [TransientStatuses]).
+	transientAlerts map[string]struct{}
+
+	// Ring buffers of the status changes per element (bounded by [ElementHistory]).
+	history map[string][]ElementHistoryEntry
+
+	// Time of the last alert notification and the changes held back since
+	// (within [NotifyMinInterval]), with the channel dispatching their summary
+	// right away once closed (see [DeviceMonitor.flushThrottle]), instead of
+	// once the window elapses. It is nil while no summary is due.
+	lastNotifyAt     time.Time
+	throttledChanges []Change
+	throttleFlush    chan struct{}
+	throttleMu       sync.Mutex
+
+	// Notifications that are still being dispatched (awaited by [DeviceMonitor.RunOnce] and when stopping).
+	notifications sync.WaitGroup
+
+	// Serializes appends to the changelog (so that lines never interleave).
+	changelogMu sync.Mutex
+
+	// Current status of the monitor (as returned by [DeviceMonitor.Status]).
+	status   DeviceStatus
+	statusMu sync.Mutex
+
+	// Whether at least one device poll has succeeded (as returned by [DeviceMonitor.Ready]).
+	pollSucceeded atomic.Bool
+
+	// Time (as Unix nanoseconds) of the monitor's start and its last successful device poll
+	// (as evaluated by [DeviceMonitor.Progressing]).
+	startedAt       atomic.Int64
+	lastPollSuccess atomic.Int64
+
+	// Whether notifications are suppressed (as set by [DeviceMonitor.SetMaintenance]).
+	maintenance atomic.Bool
+
+	// Map of the acknowledged elements (as set by [DeviceMonitor.Acknowledge]).
+	acks   map[string]ElementAck
+	acksMu sync.Mutex
+
+	// Stop is only allowed to run once, this [sync.Once] ensures that.
+	once sync.Once
+
+	// Closing of stop signals the monitor for the device to stop.
+	stop chan struct{}
+
+	// Closing of done signals the consumers that the monitor is done.
+	done chan struct{}
+}
+
+// pendingChange is an element change that is held back until it is confirmed.
+type pendingChange struct {
+	// The element before the change (nil if it did not exist before).
+	before *Result
+
+	// How many consecutive polls the change has persisted for.
+	polls int
+}
+
+// fetchStats are the statistics of a successful device fetch (see [DeviceMonitor.fetchFromDevice]).
+type fetchStats struct {
+	// Amount of attempts needed for the fetch (including the successful one).
+	attempts int
+
+	// Duration of the fetch (including all attempts, excluding any wait for a poll slot and the pre-poll command).
+	duration time.Duration
+}
+
+type DeviceMonitor struct {
+	device Device
+
+	fsys     afero.Fs
+	runner   CommandRunner
+	notifier Notifier
+	logger   *slog.Logger
+
+	cfg   *DeviceMonitorConfig
+	state *deviceMonitorState
+
+	// Returns a random duration of [0, n) for jitter (defaults to [rand.N]).
+	randDuration func(n time.Duration) time.Duration
+
+	// Called whenever the status of the monitor has changed (if not nil).
+	// Needs to be set before [DeviceMonitor.Start] and be safe for concurrent use.
+	onStatus func()
+
+	// Semaphore shared between the monitors of a pool to limit their concurrent device polls.
+	// Needs to be set before [DeviceMonitor.Start], a nil semaphore is unlimited.
+	pollSem chan struct{}
+
+	// Name of the pool sharing the poll semaphore ("" = default pool, see [DeviceYAML.Pool]).
+	pool string
+
+	// Whether notifications are only logged instead of dispatched (see [DeviceMonitor.notify]).
+	// Needs to be set before [DeviceMonitor.Start], change reports and snapshots are still written.
+	dryRun bool
+}
+
+// newDeviceMonitorState returns a pointer to a new [deviceMonitorState].
+func newDeviceMonitorState() *deviceMonitorState {
+	return &deviceMonitorState{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// pollBudget returns the worst-case duration of a device poll (including all attempts
+// and the [DeviceMonitorConfig.PrePollCommand], if any).
+func pollBudget(cfg *DeviceMonitorConfig) time.Duration {
+	budget := time.Duration(*cfg.PollAttempts) * *cfg.PollAttemptTimeout
+	for attempt := 1; attempt <= *cfg.PollAttempts; attempt++ {
+		budget += pollBackoff(cfg).maxWait(*cfg.PollAttemptInterval, attempt)
+	}
+	if cfg.PrePollCommand != nil {
+		budget += *cfg.PrePollTimeout
+	}
+
+	return budget
+}
+
+// pollBackoff returns the [RetryBackoff] of the waits between device poll attempts.
+func pollBackoff(cfg *DeviceMonitorConfig) RetryBackoff {
+	return RetryBackoff{
+		Multiplier: *cfg.PollAttemptBackoff,
+		Max:        *cfg.PollAttemptBackoffMax,
+		Jitter:     *cfg.PollAttemptJitter,
+	}
+}
+
+// NewDeviceMonitor returns a pointer to a new [DeviceMonitor].
+func NewDeviceMonitor(
+	device Device,
+	cfg *DeviceMonitorConfig,
+	fsys afero.Fs, runner CommandRunner, logger *slog.Logger, notifier Notifier,
+) (*DeviceMonitor, error) {
+	if fsys == nil || runner == nil || logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+	if device.Path == "" {
+		return nil, fmt.Errorf("%w: no device provided", errInvalidArgument)
+	}
+	if device.Type == DeviceTypeFile && isGlobPattern(device.Path) {
+		// The matching files may only be written later (e.g. by an external collector).
+		if _, err := filepath.Match(device.Path, ""); err != nil {
+			return nil, fmt.Errorf("%w: device glob pattern failure: %w", errInvalidArgument, err)
+		}
+	} else if _, err := fsys.Stat(device.Path); err != nil {
+		return nil, fmt.Errorf("%w: stat device failure: %w", errInvalidArgument, err)
+	}
+
+	mcfg, err := mergeDeviceMonitorConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	if budget := pollBudget(mcfg); budget > *mcfg.PollInterval {
+		if *mcfg.PollBudgetStrict {
+			return nil, fmt.Errorf("configuration failure: %w: worst-case poll budget (%s) "+
+				"exceeds poll_interval (%s)", errInvalidArgument, budget, *mcfg.PollInterval)
+		}
+		var prePoll string
+		if mcfg.PrePollCommand != nil {
+			prePoll = fmt.Sprintf(" + %s pre-poll", *mcfg.PrePollTimeout)
+		}
+		if *mcfg.PollAttemptBackoff > 1 || *mcfg.PollAttemptJitter > 0 {
+			logger.Warn(fmt.Sprintf("Worst-case poll budget (%s = %d x %s + backed-off waits from %s%s) exceeds "+
+				"the poll interval (%s), polls may overlap", budget, *mcfg.PollAttempts, *mcfg.PollAttemptTimeout,
+				*mcfg.PollAttemptInterval, prePoll, *mcfg.PollInterval), LogEvent("warning"))
+		} else {
+			logger.Warn(fmt.Sprintf("Worst-case poll budget (%s = %d x (%s + %s)%s) exceeds the poll interval (%s), "+
+				"polls may overlap", budget, *mcfg.PollAttempts, *mcfg.PollAttemptTimeout,
+				*mcfg.PollAttemptInterval, prePoll, *mcfg.PollInterval), LogEvent("warning"))
+		}
+	}
+
+	m := &DeviceMonitor{
+		device:   device,
+		fsys:     fsys,
+		runner:   runner,
+		logger:   logger,
+		notifier: notifier,
+		cfg:      mcfg,
+		state:    newDeviceMonitorState(),
+	}
+
+	return m, nil
+}
+
+// Stop stops the monitoring for the device.
+func (d *DeviceMonitor) Stop() {
+	d.state.once.Do(func() {
+		d.logger.Info("Monitoring for this device is shutting down...", LogEvent("monitor_stop"))
+		close(d.state.stop)
+	})
+}
+
+// runOnStop runs the [OnStopCommand] (if any) once monitoring has stopped, bounded by the
+// [OnStopTimeout]. It is not cancelled with the context, as that is usually why it is run.
+func (d *DeviceMonitor) runOnStop(ctx context.Context) {
+	if d.cfg.OnStopCommand == nil {
+		return
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), *d.cfg.OnStopTimeout)
+	defer cancel()
+
+	_, _, err := d.runner.Run(stopCtx, RunCommandConfig{
+		Description:    fmt.Sprintf("%q", *d.cfg.OnStopCommand),
+		Command:        *d.cfg.OnStopCommand,
+		Args:           []string{d.device.Path, d.device.Address},
+		Attempts:       1,
+		AttemptTimeout: *d.cfg.OnStopTimeout,
+		PrintErrors:    true,
+	})
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error running on-stop command: %v", err), LogEvent("error"))
+	}
+}
+
+// runPrePoll runs the [PrePollCommand] (if any) before a device poll, bounded by the
+// [PrePollTimeout]. Its failure is only logged, unless [PrePollAbort] is set, where it
+// is returned instead (so that the device poll is aborted as failed).
+func (d *DeviceMonitor) runPrePoll(ctx context.Context) error {
+	if d.cfg.PrePollCommand == nil {
+		return nil
+	}
+
+	_, _, err := d.runner.Run(ctx, RunCommandConfig{
+		Description:    fmt.Sprintf("%q", *d.cfg.PrePollCommand),
+		Command:        *d.cfg.PrePollCommand,
+		Args:           []string{d.device.Path, d.device.Address},
+		Attempts:       1,
+		AttemptTimeout: *d.cfg.PrePollTimeout,
+		PrintErrors:    true,
+	})
+	if err != nil {
+		if *d.cfg.PrePollAbort {
+			return fmt.Errorf("failure running pre-poll command: %w", err)
+		}
+		d.logger.Error(fmt.Sprintf("Error running pre-poll command (continuing with the poll): %v", err), LogEvent("error"))
+	}
+
+	return nil
+}
+
+// notify dispatches a notification through the agent, unless configured for a dry run,
+// where the notification that would have been dispatched is only logged (as described
+// by the agent, e.g. with the full argv of a notification script) instead.
+func (d *DeviceMonitor) notify(ctx context.Context, msg string, extra any) error {
+	if d.dryRun {
+		d.logger.Info(fmt.Sprintf("Dry run - skipping notification through agent [%s]: %s",
+			d.notifier.Name(), describeNotification(d.notifier, d.device, msg, extra)), LogEvent("dry_run"))
+
+		return nil
+	}
+
+	return d.notifier.Notify(ctx, d.device, msg, extra) //nolint:wrapcheck
+}
+
+// closeNotifier releases the resources held by the notification agent (if any).
+func (d *DeviceMonitor) closeNotifier() {
+	if n, ok := d.notifier.(closableNotifier); ok {
+		n.Close()
+	}
+}
+
+// Status returns the current [DeviceStatus] of the monitor (safe for concurrent use).
+func (d *DeviceMonitor) Status() DeviceStatus {
+	d.state.statusMu.Lock()
+	defer d.state.statusMu.Unlock()
+
+	status := d.state.status
+	status.Device = d.device
+	status.Maintenance = d.state.maintenance.Load()
+	status.Acks = d.Acks()
+
+	return status
+}
+
+// SetMaintenance enters or exits the maintenance mode of the monitor, where all notifications
+// are suppressed, while the device continues to be polled (and any output to be written).
+func (d *DeviceMonitor) SetMaintenance(on bool) {
+	if d.state.maintenance.Swap(on) == on {
+		return
+	}
+
+	if on {
+		d.logger.Info("Maintenance mode entered - notifications are suppressed until exited", LogEvent("maintenance"))
+	} else {
+		d.logger.Info("Maintenance mode exited - notifications are no longer suppressed", LogEvent("maintenance"))
+	}
+
+	if d.onStatus != nil {
+		d.onStatus()
+	}
+}
+
+// PollStats returns the current [PollStats] of the monitor (safe for concurrent use).
+func (d *DeviceMonitor) PollStats() PollStats {
+	return d.Status().PollStats
+}
+
+// Ready returns if the monitor has completed at least one successful device poll.
+func (d *DeviceMonitor) Ready() bool {
+	return d.state.pollSucceeded.Load()
+}
+
+// Progressing returns if the monitor has made poll progress recently, that is a successful
+// device poll (or, before the first one, its start) within twice its poll interval plus the
+// worst-case duration of a device poll (so that a single failing poll is still tolerated).
+func (d *DeviceMonitor) Progressing(now time.Time) bool {
+	last := d.state.lastPollSuccess.Load()
+	if last == 0 {
+		last = d.state.startedAt.Load()
+	}
+	if last == 0 {
+		return false
+	}
+
+	return now.Sub(time.Unix(0, last)) <= 2*(*d.cfg.PollInterval)+pollBudget(d.cfg)
+}
+
+// setStatus modifies the current [DeviceStatus] of the monitor and then
+// calls the onStatus hook (if set) to signal the consumers about the change.
+func (d *DeviceMonitor) setStatus(fn func(s *DeviceStatus)) {
+	d.state.statusMu.Lock()
+	fn(&d.state.status)
+	d.state.statusMu.Unlock()
+
+	if d.onStatus != nil {
+		d.onStatus()
+	}
+}
+
+// jitter returns a random duration of [0, [DeviceMonitorConfig.PollJitter]),
+// where the jitter is capped to never exceed [DeviceMonitorConfig.PollInterval].
+func (d *DeviceMonitor) jitter() time.Duration {
+	j := min(*d.cfg.PollJitter, *d.cfg.PollInterval)
+	if j <= 0 {
+		return 0
+	}
+
+	if d.randDuration != nil {
+		return d.randDuration(j)
+	}
+
+	return rand.N(j)
+}
+
+// tick is a single device poll, which updates the status and handles any failure.
+func (d *DeviceMonitor) tick(ctx context.Context) {
+	start := time.Now()
+	err := d.poll(ctx)
+	now := time.Now()
+
+	if err == nil {
+		d.state.pollSucceeded.Store(true)
+		d.state.lastPollSuccess.Store(now.UnixNano())
+	}
+
+	var stats PollStats
+	d.setStatus(func(s *DeviceStatus) {
+		s.LastPollAt = now.Format(time.RFC3339)
+		s.LastPollError = ""
+		if err != nil {
+			s.LastPollError = err.Error()
+		}
+		s.Results = d.state.previousResults
+
+		s.PollStats.TotalPolls++
+		s.PollStats.LastPollDuration = now.Sub(start).Round(time.Millisecond).String()
+		if err == nil {
+			s.PollStats.SuccessfulPolls++
+			s.PollStats.LastSuccessAt = s.LastPollAt
+		}
+		stats = s.PollStats
+	})
+
+	if *d.cfg.Verbose {
+		d.logger.Info(fmt.Sprintf("Poll statistics: %d polls (%d successful) with %d retries, last poll took %s",
+			stats.TotalPolls, stats.SuccessfulPolls, stats.TotalRetries, stats.LastPollDuration), LogEvent("poll_stats"))
+	}
+
+	d.trackPresence(ctx, err)
+	d.trackFailureRate(ctx, err)
+
+	if err != nil {
+		d.pollFailure(ctx, err)
+	}
+}
+
+// trackFailureRate alerts (once) when the fraction of failed polls within the last
+// [PollFailureRateWindow] polls exceeds [PollFailureRateAlert], regardless of whether
+// the failures were consecutive. The window is reset once all of its polls have succeeded.
+func (d *DeviceMonitor) trackFailureRate(ctx context.Context, err error) {
+	if *d.cfg.PollFailureRateAlert <= 0 {
+		return
+	}
+
+	window := *d.cfg.PollFailureRateWindow
+	d.state.pollWindow = append(d.state.pollWindow, err != nil)
+	if len(d.state.pollWindow) > window {
+		d.state.pollWindow = d.state.pollWindow[len(d.state.pollWindow)-window:]
+	}
+
+	failures := 0
+	for _, failed := range d.state.pollWindow {
+		if failed {
+			failures++
+		}
+	}
+	rate := float64(failures) / float64(len(d.state.pollWindow))
+	d.setStatus(func(s *DeviceStatus) {
+		s.PollStats.RecentFailureRate = &rate
+	})
+
+	if len(d.state.pollWindow) < window {
+		return
+	}
+
+	if failures == 0 {
+		if d.state.failureRateAlerted {
+			d.logger.Info(fmt.Sprintf("Poll failure rate has recovered (no failures within the last %d polls)", window), LogEvent("poll_failure_rate"))
+		}
+		d.state.pollWindow = nil
+		d.state.failureRateAlerted = false
+
+		return
+	}
+
+	if d.state.failureRateAlerted || rate < *d.cfg.PollFailureRateAlert {
+		return
+	}
+	d.state.failureRateAlerted = true
+
+	msg := fmt.Sprintf("Poll failure rate: %d of the last %d polls have failed (%.0f%%, at or above %.0f%%)",
+		failures, window, rate*100, *d.cfg.PollFailureRateAlert*100) //nolint:mnd
+	d.logger.Warn(msg, LogEvent("poll_failure_rate"))
+
+	if d.notifier != nil && d.state.maintenance.Load() {
+		d.logger.Info("Poll failure rate occurred in maintenance mode - skipping notification", LogEvent("notify_skipped"))
+	} else if d.notifier != nil {
+		d.state.notifications.Go(func() {
+			defer RecoverGoPanic("failure-rate-notifier", d.logger)
+			if err := d.notify(ctx, msg, nil); err != nil {
+				d.logger.Error(fmt.Sprintf("Alert notification agent error: %v", err), LogEvent("notify_failure"))
+			}
+		})
+	}
+}
+
+// trackPresence alerts about the device path disappearing (see [errDeviceGone]) or
+// reappearing, once for every such transition (as opposed to for every failed poll).
+func (d *DeviceMonitor) trackPresence(ctx context.Context, err error) {
+	gone := errors.Is(err, errDeviceGone)
+	if gone == d.state.deviceGone {
+		return
+	}
+	d.state.deviceGone = gone
+
+	var msg string
+	if gone {
+		msg = fmt.Sprintf("Device gone: [%s] no longer exists (unplugged or removed)", d.device.Path)
+		d.logger.Error(msg, LogEvent("device_gone"))
+	} else {
+		msg = fmt.Sprintf("Device back: [%s] exists again (after having disappeared)", d.device.Path)
+		d.logger.Info(msg, LogEvent("device_back"))
+	}
+
+	if d.notifier != nil && d.state.maintenance.Load() {
+		d.logger.Info("Presence change occurred in maintenance mode - skipping notification", LogEvent("notify_skipped"))
+	} else if d.notifier != nil {
+		d.state.notifications.Go(func() {
+			defer RecoverGoPanic("presence-notifier", d.logger)
+			if err := d.notify(ctx, msg, nil); err != nil {
+				d.logger.Error(fmt.Sprintf("Alert notification agent error: %v", err), LogEvent("notify_failure"))
+			}
+		})
+	}
+}
+
+// Done returns a channel that is closed when monitoring has stopped.
+func (d *DeviceMonitor) Done() <-chan struct{} {
+	return d.state.done
+}
+
+// Start starts monitoring of the device.
+// The context is both observed and respected for earlier termination.
+func (d *DeviceMonitor) Start(ctx context.Context) {
+	cfgJSON, err := json.Marshal(d.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+	if d.notifier == nil {
+		d.logger.Info(fmt.Sprintf("Monitoring [%s:%s] with configuration [%s]; "+
+			"and no notification agent", d.device.Path, d.device.Address, cfgJSON), LogEvent("monitor_start"))
+	} else {
+		d.logger.Info(fmt.Sprintf("Monitoring [%s:%s] with configuration [%s]; "+
+			"and notification agent [%s] with configuration [%s]",
+			d.device.Path, d.device.Address, cfgJSON, d.notifier.Name(), d.notifier.Config()), LogEvent("monitor_start"))
+	}
+
+	if err := d.loadAcks(); err != nil {
+		d.logger.Warn(fmt.Sprintf("No acknowledgements were loaded: %v", err), LogEvent("warning"))
+	}
+	if err := d.loadHistory(); err != nil {
+		d.logger.Warn(fmt.Sprintf("No element history was loaded: %v", err), LogEvent("warning"))
+	}
+
+	d.state.startedAt.Store(time.Now().UnixNano())
+
+	if *d.cfg.HeartbeatInterval > 0 && d.notifier != nil {
+		go d.heartbeats(ctx)
+	}
+
+	go func() {
+		defer RecoverGoPanic("monitor", d.logger)
+		defer close(d.state.done)
+		defer d.closeNotifier()
+		defer d.runOnStop(ctx)
+		defer d.state.notifications.Wait()
+		defer d.Stop()
+
+		if delay := d.jitter(); delay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.state.stop:
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		d.tick(ctx)
+
+		ticker := time.NewTicker(*d.cfg.PollInterval + d.jitter())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.state.stop:
+				return
+			case <-ticker.C:
+				d.tick(ctx)
+			}
+		}
+	}()
+}
+
+// RunOnce polls the device a single time (instead of periodically), comparing the results
+// against the previous ones persisted in the output folder (see [DeviceMonitor.loadPreviousResults]),
+// which needs both [OutputDir] and [WriteSnapshots] (as otherwise there is nothing to compare against).
+// It returns once all resulting notifications were dispatched, or an error if the poll has failed.
+// The changes held back by [ChangeDebounce] and [TransientStatuses] persist across runs (see
+// [pendingFilename]), whereas the window of [NotifyMinInterval] does not persist across runs
+// (with the summary of any changes held back within it dispatched before returning).
+func (d *DeviceMonitor) RunOnce(ctx context.Context) error {
+	defer d.closeNotifier()
+
+	if d.notifier == nil {
+		d.logger.Info(fmt.Sprintf("Polling [%s:%s] once; and no notification agent", d.device.Path, d.device.Address), LogEvent("monitor_start"))
+	} else {
+		d.logger.Info(fmt.Sprintf("Polling [%s:%s] once; and notification agent [%s]",
+			d.device.Path, d.device.Address, d.notifier.Name()), LogEvent("monitor_start"))
+	}
+
+	if err := d.loadAcks(); err != nil {
+		d.logger.Warn(fmt.Sprintf("No acknowledgements were loaded: %v", err), LogEvent("warning"))
+	}
+	if err := d.loadHistory(); err != nil {
+		d.logger.Warn(fmt.Sprintf("No element history was loaded: %v", err), LogEvent("warning"))
+	}
+
+	persist := d.cfg.OutputDir != nil && *d.cfg.WriteSnapshots
+	if persist {
+		if err := d.loadPreviousResults(); err != nil {
+			d.logger.Warn(fmt.Sprintf("No previous results were loaded (comparing from next run): %v", err), LogEvent("warning"))
+		} else if err := d.loadPending(); err != nil {
+			d.logger.Warn(fmt.Sprintf("No held back changes were loaded: %v", err), LogEvent("warning"))
+		}
+	} else if d.cfg.OutputDir != nil {
+		d.logger.Warn("No previous results were loaded (as write_snapshots is disabled)", LogEvent("warning"))
+	}
+
+	d.state.startedAt.Store(time.Now().UnixNano())
+	d.tick(ctx)
+	d.flushThrottle()
+	d.state.notifications.Wait()
+
+	if persist {
+		d.persistPending()
+	}
+
+	if status := d.Status(); status.LastPollError != "" {
+		return fmt.Errorf("%q: %w: %s", d.device.Path, errPollFailed, status.LastPollError)
+	}
+
+	return nil
+}
+
+// loadPreviousResults seeds the previous results (and temperature levels) of the monitor
+// from the parsed device snapshot ("current_parsed.json") as persisted in the output folder.
+func (d *DeviceMonitor) loadPreviousResults() error {
+	snapshot, err := d.readDeviceSnapshot("current_parsed.json")
+	if err != nil {
+		return err
+	}
+
+	var results map[string]Result
+	if err := json.Unmarshal(snapshot.Raw, &results); err != nil {
+		return fmt.Errorf("failure parsing previous results: %w", err)
+	}
+
+	d.state.previousResults = results
+	_, d.state.tempLevels = temperatureDiff(nil, nil, results, d.cfg.TempWarn, d.cfg.TempCrit, 0)
+
+	d.logger.Info(fmt.Sprintf("Loaded %d previous elements (as of %s) from the output folder",
+		len(results), snapshot.CapturedAt), LogEvent("state_loaded"))
+
+	return nil
+}
+
+// heartbeats dispatches a heartbeat notification through the agent every [HeartbeatInterval].
+// It both observes and respects the given context and the stopping of the monitor.
+func (d *DeviceMonitor) heartbeats(ctx context.Context) {
+	defer RecoverGoPanic("heartbeat", d.logger)
+
+	ticker := time.NewTicker(*d.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.state.stop:
+			return
+		case <-ticker.C:
+			d.heartbeat(ctx)
+		}
+	}
+}
+
+// heartbeat dispatches a single heartbeat notification through the agent.
+// Heartbeats never affect the alerting (such as the suppression of duplicates).
+func (d *DeviceMonitor) heartbeat(ctx context.Context) {
+	msg := d.heartbeatMessage()
+	d.logger.Info(msg, LogEvent("heartbeat"))
+
+	if d.state.maintenance.Load() {
+		d.logger.Info("Heartbeat occurred in maintenance mode - skipping notification", LogEvent("notify_skipped"))
+
+		return
+	}
+
+	if err := d.notify(ctx, msg, nil); err != nil {
+		d.logger.Error(fmt.Sprintf("Heartbeat notification agent error: %v", err), LogEvent("notify_failure"))
+	}
+}
+
+// heartbeatMessage returns the heartbeat message summarizing the current [DeviceStatus].
+func (d *DeviceMonitor) heartbeatMessage() string {
+	status := d.Status()
+
+	if !d.Ready() {
+		return "Heartbeat: Monitoring is alive, but no device poll has succeeded yet"
+	}
+
+	problems := countProblems(status.Results)
+
+	msg := fmt.Sprintf("Heartbeat: Monitoring is alive with %d elements (%d OK, %d with problems) as of poll at %s",
+		len(status.Results), len(status.Results)-problems, problems, status.LastPollAt)
+
+	if status.InBackoff {
+		msg += fmt.Sprintf(" - device polling is in back-off until %s", status.BackoffUntil)
+	} else if status.LastPollError != "" {
+		msg += " - last device poll failed: " + status.LastPollError
+	}
+
+	return msg
+}
+
+// logSkippedElements warns about elements that were skipped when parsing (as they
+// have no element type or number), whenever their amount changes from the previous
+// poll. When verbose, their raw indices (within the element list) are logged on every poll.
+func (d *DeviceMonitor) logSkippedElements(skipped []int) {
+	if len(skipped) > 0 && (*d.cfg.Verbose || len(skipped) != d.state.skippedElements) {
+		msg := fmt.Sprintf("Skipped %d elements without element type or number (not monitored)", len(skipped))
+		if *d.cfg.Verbose {
+			msg += fmt.Sprintf(" at raw indices %v", skipped)
+		}
+		d.logger.Warn(msg, LogEvent("parse_skipped"))
+	} else if len(skipped) == 0 && d.state.skippedElements > 0 {
+		d.logger.Info("No longer skipping elements without element type or number", LogEvent("parse_skipped"))
+	}
+	d.state.skippedElements = len(skipped)
+}
+
+// poll is a device polling attempt (including any retries on failure).
+func (d *DeviceMonitor) poll(ctx context.Context) error {
+	ret, warnings, stats, err := d.fetchFromDevice(ctx)
+	if err != nil {
+		return fmt.Errorf("failure fetching from device: %w", err)
+	}
+	if warnings != "" && *d.cfg.Verbose {
+		d.logger.Warn(fmt.Sprintf("Device poll succeeded with warnings on standard error: %q", warnings), LogEvent("poll_warnings"))
+	}
+
+	currentResults, skipped, err := parseSES(ret)
+	if err != nil {
+		return fmt.Errorf("failure parsing fetched data: %w", err)
+	}
+	d.logSkippedElements(skipped)
+	currentResults = filterResults(currentResults, d.cfg.MonitorTypes)
+	applyStatusMeanings(currentResults, *d.cfg.CanonicalStatusMeanings, d.cfg.StatusMeanings)
+
+	defer func() {
+		d.state.previousResults = currentResults
+	}()
+
+	if d.cfg.OutputDir != nil && *d.cfg.WriteSnapshots {
+		d.writeCurrentData(ret, warnings, currentResults)
+	}
+
+	if now := time.Now(); d.recordHistory(now, currentResults) && d.cfg.OutputDir != nil {
+		if err := d.writeHistory(now); err != nil {
+			d.logger.Error(fmt.Sprintf("Error writing element history to file: %v", err), LogEvent("error"))
+		}
+	}
+
+	var changed bool
+	if d.cfg.OutputDir != nil && *d.cfg.SnapshotHistory > 0 {
+		defer func() {
+			if changed || *d.cfg.SnapshotEveryPoll {
+				d.writeSnapshotHistory(currentResults)
+			}
+		}()
+	}
+
+	resumed := d.state.resumedFromBackoff && d.state.previousResults != nil
+	d.state.resumedFromBackoff = false
+
+	tempChanges, tempLevels := temperatureDiff(d.state.tempLevels, d.state.previousResults,
+		currentResults, d.cfg.TempWarn, d.cfg.TempCrit, *d.cfg.TempHysteresis)
+	d.state.tempLevels = tempLevels
+
+	var changes []Change
+	if d.state.previousResults == nil {
+		problems := countProblems(currentResults)
+		d.logger.Info(fmt.Sprintf("Retrieved %d initial elements from SES-capable device (%d OK, %d with problems)",
+			len(currentResults), len(currentResults)-problems, problems), LogEvent("poll"))
+		if *d.cfg.Verbose && len(currentResults) > 0 {
+			d.logger.Info(fmt.Sprintf("Retrieved initial elements by type: %s", typeSummary(currentResults)), LogEvent("poll"))
+		}
+
+		// Temperatures already beyond a threshold are alerted regardless, as their levels are
+		// recorded now (so that the following polls would no longer see these as changes).
+		changes = tempChanges
+		if *d.cfg.AlertOnStartUnhealthy && problems > 0 {
+			changes = append(unhealthyChanges(currentResults), changes...)
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+	} else if resumed {
+		changes = rowsDiff(d.state.previousResults, currentResults)
+		d.retainPending(changes, currentResults)
+		changes = d.coalesceElementDrop(changes, currentResults)
+		if *d.cfg.PrdFailCritical {
+			escalatePredictedFailures(changes)
+		}
+		changes = append(changes, tempChanges...)
+		changes = append(changes, fanDiff(d.state.previousResults, currentResults, *d.cfg.AlertFanSpeed)...)
+
+		d.logger.Info(fmt.Sprintf("Retrieved %d elements after back-off as fresh baseline (%d net changes since before the back-off)",
+			len(currentResults), len(changes)), LogEvent("poll"))
+	} else {
+		if *d.cfg.Verbose {
+			d.logger.Info(fmt.Sprintf("Retrieved batch of %d elements from SES-capable device (fetched in %s after %d of %d attempts)",
+				len(currentResults), stats.duration.Round(time.Millisecond), stats.attempts, *d.cfg.PollAttempts), LogEvent("poll"))
+		}
+
+		changes = d.debounceChanges(rowsDiff(d.state.previousResults, currentResults), currentResults)
+		changes = d.coalesceElementDrop(changes, currentResults)
+		if *d.cfg.PrdFailCritical {
+			escalatePredictedFailures(changes)
+		}
+		changes = append(changes, tempChanges...)
+		changes = append(changes, fanDiff(d.state.previousResults, currentResults, *d.cfg.AlertFanSpeed)...)
+	}
+
+	total := len(changes)
+	changes = suppressChanges(changes, d.cfg.SuppressTypes)
+	if suppressed := total - len(changes); suppressed > 0 && *d.cfg.Verbose {
+		d.logger.Info(fmt.Sprintf("%d changes of suppressed element types were excluded", suppressed), LogEvent("changes_excluded"))
+	}
+	changes = d.acknowledgedChanges(changes)
+
+	if len(changes) == 0 {
+		if *d.cfg.Verbose {
+			d.logger.Info("No changes detected comparing previous vs. current results", LogEvent("changes"))
+		}
+
+		return nil
+	} else if *d.cfg.Verbose {
+		d.logger.Info(fmt.Sprintf("%d changes detected comparing previous vs. current results",
+			len(changes)), LogEvent("changes"))
+	}
+	changed = true
+
+	report := ChangeReport{
+		Device:     d.device,
+		DetectedAt: time.Now().Format(time.RFC3339),
+		Kind:       reportKind(changes),
+		Severity:   reportSeverity(changes),
+		Changes:    changes,
+		Warnings:   warnings,
+	}
+	if *d.cfg.ReportResults {
+		report.Results = currentResults
+	}
+
+	msg := d.changesMessage(changes)
+	if resumed {
+		msg = fmt.Sprintf("%d net changes since before the back-off: %s", len(changes), msg)
+	}
+	if *d.cfg.LogChangeEvents {
+		d.logChangeEvent(report)
+	}
+
+	// The full changes are hashed, so that compact messages do not hide any differences.
+	h := sha256.Sum256([]byte(buildMessage(changesAsText(changes))))
+	hash := hex.EncodeToString(h[:])
+
+	if report.Kind != ChangeKindRecovered && d.isDuplicateAlert(hash) {
+		d.logger.Info("Alert changes match the previous alert - skipping notification", LogEvent("notify_skipped"))
+	} else {
+		d.handleAlert(ctx, hash, msg, report)
+	}
+
+	return nil
+}
+
+// coalesceElementDrop replaces the removals of elements with a single critical [Change]
+// (of [elementDropID]), if at least [ElementDropThreshold] of the elements have disappeared
+// at once, as happens when a part of the enclosure (e.g. an expander) has dropped offline.
+// The removals are those confirmed by [DeviceMonitor.debounceChanges], so that the count of
+// elements before these is the current count without the additions and with the removals.
+func (d *DeviceMonitor) coalesceElementDrop(changes []Change, curr map[string]Result) []Change {
+	threshold := *d.cfg.ElementDropThreshold
+	if threshold <= 0 {
+		return changes
+	}
+
+	removed := make(map[string]Result)
+	var added int
+	for _, ch := range changes {
+		switch {
+		case ch.Before != nil && ch.After == nil:
+			removed[ch.ID] = *ch.Before
+		case ch.Before == nil && ch.After != nil:
+			added++
+		}
+	}
+
+	count := len(curr) - added + len(removed)
+	if len(removed) == 0 || float64(len(removed))/float64(count) < threshold {
+		return changes
+	}
+	fraction := float64(len(removed)) / float64(count)
+
+	reason := fmt.Sprintf("element count dropped from %d to %d (%.0f%%), with %d elements disappeared (%s)",
+		count, count-len(removed), fraction*100, len(removed), typeSummary(removed))
+	d.logger.Warn(fmt.Sprintf("Enclosure partially offline: %s", reason), LogEvent("element_drop"))
+
+	out := slices.DeleteFunc(changes, func(ch Change) bool {
+		_, ok := removed[ch.ID]
+
+		return ok && ch.After == nil
+	})
+
+	return append(out, Change{
+		ID:         elementDropID,
+		Kind:       ChangeKindDegraded,
+		Severity:   SeverityCritical,
+		Descriptor: ptr("Enclosure partially offline"),
+		Reason:     ptr(reason),
+	})
+}
+
+// changesMessage returns the textual representation of changes as used for notifications,
+// which is compact if [CompactMessages] is set (then logging all fields if [Verbose]).
+func (d *DeviceMonitor) changesMessage(changes []Change) string {
+	if !*d.cfg.CompactMessages {
+		return buildMessage(changesAsText(changes))
+	}
+
+	if *d.cfg.Verbose {
+		d.logger.Info(fmt.Sprintf("Changes in full: %s", buildMessage(changesAsText(changes))), LogEvent("changes"))
+	}
+
+	return buildMessage(changesAsCompactText(changes))
+}
+
+// logChangeEvent logs the [ChangeEvent] of a [ChangeReport] as a single JSON line.
+func (d *DeviceMonitor) logChangeEvent(report ChangeReport) {
+	eventJSON, err := json.Marshal(changeEvent(report))
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error marshalling change event to JSON: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	d.logger.Info(fmt.Sprintf("Change event: %s", eventJSON), LogEvent("change_event"))
+}
+
+// isDuplicateAlert returns if an alert (by its hash) matches the previous alert,
+// unless that has been raised longer than [AlertDedupTTL] ago (if not zero).
+func (d *DeviceMonitor) isDuplicateAlert(hash string) bool {
+	if d.state.lastAlertHash == "" || d.state.lastAlertHash != hash {
+		return false
+	}
+
+	if ttl := *d.cfg.AlertDedupTTL; ttl > 0 && time.Since(d.state.lastAlertAt) >= ttl {
+		return false
+	}
+
+	return true
+}
+
+// debounceChanges holds back the element changes until they have persisted for
+// [ChangeDebounce] consecutive polls (or [TransientDebounce] polls for changes into any
+// of [TransientStatuses]), returning only the changes now confirmed. Held back changes
+// are dropped once the element reverts to its previous value.
+func (d *DeviceMonitor) debounceChanges(changes []Change, curr map[string]Result) []Change {
+	changes = d.transientRecoveries(changes)
+	if *d.cfg.ChangeDebounce <= 1 && len(d.cfg.TransientStatuses) == 0 {
+		return changes
+	}
+
+	if d.state.pendingChanges == nil {
+		d.state.pendingChanges = make(map[string]*pendingChange)
+	}
+
+	var out []Change
+	for _, ch := range changes {
+		if _, ok := d.state.pendingChanges[ch.ID]; ok {
+			continue
+		}
+		if *d.cfg.ChangeDebounce <= 1 && !d.isTransient(ch.After) {
+			out = append(out, ch)
+
+			continue
+		}
+		d.state.pendingChanges[ch.ID] = &pendingChange{before: ch.Before}
+	}
+
+	for id, pc := range d.state.pendingChanges {
+		c, cok := curr[id]
+		if (pc.before == nil && !cok) || (pc.before != nil && cok && rowsEqual(*pc.before, c)) {
+			delete(d.state.pendingChanges, id)
+
+			continue
+		}
+
+		required := *d.cfg.ChangeDebounce
+		transient := cok && d.isTransient(&c)
+		if transient {
+			required = max(required, *d.cfg.TransientDebounce)
+		}
+
+		pc.polls++
+		if pc.polls < required {
+			continue
+		}
+		delete(d.state.pendingChanges, id)
+
+		before, after := make(map[string]Result), make(map[string]Result)
+		if pc.before != nil {
+			before[id] = *pc.before
+		}
+		if cok {
+			after[id] = c
+		}
+		if transient {
+			if d.state.transientAlerts == nil {
+				d.state.transientAlerts = make(map[string]struct{})
+			}
+			d.state.transientAlerts[id] = struct{}{}
+		}
+		out = append(out, rowsDiff(before, after)...)
+	}
+
+	if n := len(d.state.pendingChanges); n > 0 && *d.cfg.Verbose {
+		if len(d.cfg.TransientStatuses) > 0 {
+			d.logger.Info(fmt.Sprintf("%d changes are held back until persisting for %d polls (%d polls into transient statuses)",
+				n, *d.cfg.ChangeDebounce, max(*d.cfg.ChangeDebounce, *d.cfg.TransientDebounce)), LogEvent("changes_held"))
+		} else {
+			d.logger.Info(fmt.Sprintf("%d changes are held back until persisting for %d polls",
+				n, *d.cfg.ChangeDebounce), LogEvent("changes_held"))
+		}
+	}
+
+	return out
+}
+
+// transientRecoveries removes the recoveries of elements out of any of [TransientStatuses],
+// unless their change into the transient status was alerted (which are then kept as-is).
+// So transient statuses clear silently, such as after elements were briefly not installed.
+func (d *DeviceMonitor) transientRecoveries(changes []Change) []Change {
+	if len(d.cfg.TransientStatuses) == 0 {
+		return changes
+	}
+
+	out := make([]Change, 0, len(changes))
+	for _, ch := range changes {
+		if !d.isTransient(ch.Before) || d.isTransient(ch.After) {
+			out = append(out, ch)
+
+			continue
+		}
+		_, alerted := d.state.transientAlerts[ch.ID]
+		delete(d.state.transientAlerts, ch.ID)
+		if alerted || ch.Kind != ChangeKindRecovered {
+			out = append(out, ch)
+
+			continue
+		}
+		if *d.cfg.Verbose {
+			d.logger.Info(fmt.Sprintf("Element [%s] recovered from transient status [%s] - clearing silently",
+				ch.ID, fmtPtrStr(ch.Before.StatusDesc, fmtPtrInt(ch.Before.Status, "-"))), LogEvent("transient_cleared"))
+		}
+	}
+
+	return out
+}
+
+// isTransient returns if an element is in any of [TransientStatuses] (false for nil).
+func (d *DeviceMonitor) isTransient(r *Result) bool {
+	return r != nil && r.Status != nil && slices.Contains(d.cfg.TransientStatuses, *r.Status)
+}
+
+// checkTool returns an error if the command fetching from the device (sg_ses or the
+// fetch command) cannot be found, which is not needed for devices of [DeviceTypeFile].
+func (d *DeviceMonitor) checkTool() error {
+	if d.device.Type == DeviceTypeFile {
+		return nil
+	}
+
+	command, name := *d.cfg.SgSesPath, "sg_ses_path"
+	if d.cfg.FetchCommand != nil {
+		command, name = *d.cfg.FetchCommand, "fetch_command"
+	}
+
+	if _, err := exec.LookPath(command); err != nil {
+		return fmt.Errorf("%w: %s [%s] is not executable or not in PATH: %w", errToolNotFound, name, command, err)
+	}
+
+	return nil
+}
+
+// deviceFile returns the file to read for a file-type device, which is the newest
+// file matching the device path if that is a glob pattern (or else the device path).
+func (d *DeviceMonitor) deviceFile() (string, error) {
+	if !isGlobPattern(d.device.Path) {
+		return d.device.Path, nil
+	}
+
+	path, err := newestGlobMatch(d.fsys, d.device.Path)
+	if err != nil {
+		return "", fmt.Errorf("failure matching files: %w", err)
+	}
+
+	if path != d.state.globFile {
+		if *d.cfg.Verbose || d.state.globFile == "" {
+			d.logger.Info(fmt.Sprintf("Reading from newest file [%s] matching [%s]", path, d.device.Path), LogEvent("poll"))
+		}
+		d.state.globFile = path
+	}
+
+	return path, nil
+}
+
+// acquirePollSlot waits for a free slot of the shared poll semaphore (if any),
+// returning a function that releases the slot again once the poll is done.
+// It both observes and respects the given context for earlier termination.
+func (d *DeviceMonitor) acquirePollSlot(ctx context.Context) (func(), error) {
+	if d.pollSem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err() //nolint:wrapcheck
+	case <-d.state.stop:
+		return nil, errMonitorStopped
+	case d.pollSem <- struct{}{}:
+		return func() { <-d.pollSem }, nil
+	}
+}
+
+// fetchFromDevice tries to fetch the SES information from the device.
+// If the device is of type [DeviceTypeDevice] it uses sg_ses (or the [FetchCommand]),
+// otherwise it tries to open the device path as a file and expects it to contain JSON.
+// The [PrePollCommand] (see [DeviceMonitor.runPrePoll]) is run before, while holding the
+// same slot of the shared poll semaphore, so that it is limited by [MaxConcurrentPolls]
+// (and the poll pools) along with the fetch. Besides the fetched data and the [fetchStats],
+// it returns any warnings printed on standard error by an otherwise successful command
+// (e.g. sg_ses on a degrading SAS link).
+func (d *DeviceMonitor) fetchFromDevice(ctx context.Context) ([]byte, string, fetchStats, error) {
+	var stats fetchStats
+
+	release, err := d.acquirePollSlot(ctx)
+	if err != nil {
+		return nil, "", stats, fmt.Errorf("failure waiting for poll slot: %w", err)
+	}
+	defer release()
+
+	if err := d.runPrePoll(ctx); err != nil {
+		return nil, "", stats, err
+	}
+
+	start := time.Now()
+
+	if d.device.Type == DeviceTypeFile {
+		var by []byte
+
+		attempt, err := withBackoffRetries(
+			ctx,
+			func() error {
+				path, err := d.deviceFile()
+				if err != nil {
+					return err
+				}
+
+				by, err = afero.ReadFile(d.fsys, path)
+				if err != nil {
+					return fmt.Errorf("failure reading from file: %w", err)
+				}
+				if *d.cfg.ToleratePreamble {
+					by = d.trimPreamble(by)
+				}
+				if !json.Valid(by) {
+					return fmt.Errorf("failure parsing from file: %w", errInvalidJSON)
+				}
+
+				return nil
+			},
+			func(attempt int, err error) {
+				d.logger.Warn(fmt.Sprintf("[%d/%d] %v", attempt, *d.cfg.PollAttempts, err), LogEvent("poll_retry"))
+				d.countPollRetry(attempt)
+			},
+			*d.cfg.PollAttempts,
+			*d.cfg.PollAttemptInterval,
+			pollBackoff(d.cfg),
+		)
+		if err != nil {
+			return nil, "", stats, fmt.Errorf("[%d/%d] %w", attempt, *d.cfg.PollAttempts, err)
+		}
+
+		stats.attempts, stats.duration = attempt, time.Since(start)
+
+		return by, "", stats, nil
+	}
+
+	// Without a fetch command, the device path needs to exist locally (for sg_ses).
+	if d.cfg.FetchCommand == nil {
+		if _, err := d.fsys.Stat(d.device.Path); errors.Is(err, fs.ErrNotExist) {
+			return nil, "", stats, fmt.Errorf("%q: %w", d.device.Path, errDeviceGone)
+		}
+	}
+
+	command := *d.cfg.SgSesPath
+	args := make([]string, 0, len(d.cfg.SgSesArgs)+1)
+	args = append(args, d.cfg.SgSesArgs...)
+	args = append(args, d.device.Path)
+
+	if d.cfg.FetchCommand != nil {
+		command = *d.cfg.FetchCommand
+		args, err = renderFetchArgs(d.cfg.FetchArgs, d.device)
+		if err != nil {
+			return nil, "", stats, fmt.Errorf("%q: %w", command, err)
+		}
+	}
+
+	var failed int
+
+	stdout, stderr, err := d.runner.Run(ctx, RunCommandConfig{
+		Description:     fmt.Sprintf("%q", command),
+		Command:         command,
+		Args:            args,
+		Attempts:        *d.cfg.PollAttempts,
+		AttemptTimeout:  *d.cfg.PollAttemptTimeout,
+		AttemptInterval: *d.cfg.PollAttemptInterval,
+		AttemptBackoff:  pollBackoff(d.cfg),
+		WaitDelay:       *d.cfg.PollWaitDelay,
+		ExpectJSON:      true,
+		PrintErrors:     true,
+		TrimPreamble:    *d.cfg.ToleratePreamble,
+		OnAttemptError: func(attempt int, _ error) {
+			failed = attempt
+			d.countPollRetry(attempt)
+		},
+	})
+	if err != nil {
+		return nil, "", stats, fmt.Errorf("%q: %w", command, err)
+	}
+	stats.attempts, stats.duration = failed+1, time.Since(start)
+
+	warnings := strings.TrimSpace(stderr)
+
+	if *d.cfg.ToleratePreamble {
+		return d.trimPreamble([]byte(stdout)), warnings, stats, nil
+	}
+
+	return []byte(stdout), warnings, stats, nil
+}
+
+// countPollRetry counts a failed poll attempt as retry (if another attempt follows it).
+// The retries are counted without calling the onStatus hook (as part of an ongoing poll).
+func (d *DeviceMonitor) countPollRetry(attempt int) {
+	if attempt >= *d.cfg.PollAttempts {
+		return
+	}
+
+	d.state.statusMu.Lock()
+	d.state.status.PollStats.TotalRetries++
+	d.state.statusMu.Unlock()
+}
+
+// trimPreamble trims any (non-JSON) text preceding the JSON output of a device.
+// The trimmed preamble text is logged if configured to be verbose.
+func (d *DeviceMonitor) trimPreamble(b []byte) []byte {
+	preamble, data := splitJSONPreamble(b)
+	if len(preamble) > 0 && *d.cfg.Verbose {
+		d.logger.Info(fmt.Sprintf("Trimmed preamble text preceding the JSON output: %q", preamble), LogEvent("preamble_trimmed"))
+	}
+
+	return data
+}
+
+// writeCurrentData writes the current map[string]Result to JSON snapshot files
+// (including any warnings of the device poll, see [DeviceMonitor.fetchFromDevice]).
+func (d *DeviceMonitor) writeCurrentData(raw []byte, warnings string, parsed map[string]Result) {
+	snapshot := DeviceSnapshot{
+		Device:     d.device,
+		CapturedAt: time.Now().Format(time.RFC3339),
+		Warnings:   warnings,
+		Raw:        json.RawMessage(raw),
+	}
+	if err := d.writeDeviceSnapshot(snapshot, "current.json"); err != nil {
+		d.logger.Error(fmt.Sprintf("Error writing device snapshot to file: %v", err), LogEvent("error"))
+	}
+
+	results, err := json.MarshalIndent(parsed, "", "  ")
+	if err == nil {
+		snapshot.Raw = json.RawMessage(results)
+		if err := d.writeDeviceSnapshot(snapshot, "current_parsed.json"); err != nil {
+			d.logger.Error(fmt.Sprintf("Error writing parsed device snapshot to file: %v", err), LogEvent("error"))
+		}
+	} else {
+		d.logger.Error(fmt.Sprintf("Error marshalling parsed device snapshot to JSON: %v", err), LogEvent("error"))
+	}
+}
+
+// writeSnapshotHistory writes the parsed results as a timestamped [DeviceSnapshot]
+// and removes the oldest ones beyond [DeviceMonitorConfig.SnapshotHistory].
+func (d *DeviceMonitor) writeSnapshotHistory(parsed map[string]Result) {
+	results, err := json.MarshalIndent(parsed, "", "  ")
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error marshalling parsed device snapshot to JSON: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	now := time.Now()
+	snapshot := DeviceSnapshot{
+		Device:     d.device,
+		CapturedAt: now.Format(time.RFC3339),
+		Raw:        json.RawMessage(results),
+	}
+
+	filename := snapshotPrefix + now.Format(outputTimestampFormat) + snapshotSuffix
+	if err := d.writeDeviceSnapshot(snapshot, filename); err != nil {
+		d.logger.Error(fmt.Sprintf("Error writing timestamped device snapshot to file: %v", err), LogEvent("error"))
+	} else if err := d.pruneSnapshots(); err != nil {
+		d.logger.Error(fmt.Sprintf("Error pruning old device snapshots: %v", err), LogEvent("error"))
+	}
+}
+
+// throttleAlert returns if the notification of a (non-recovery) [ChangeReport] is to be held back,
+// as another one was already dispatched within [NotifyMinInterval]. The held back changes are then
+// collected, with a summary of them dispatched once the window elapses. Otherwise, the time of the
+// notification is recorded (as start of a new window) and the notification is to be dispatched.
+func (d *DeviceMonitor) throttleAlert(ctx context.Context, report ChangeReport) bool {
+	if *d.cfg.NotifyMinInterval <= 0 {
+		return false
+	}
+
+	d.state.throttleMu.Lock()
+	defer d.state.throttleMu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(d.state.lastNotifyAt)
+
+	if d.state.lastNotifyAt.IsZero() || elapsed >= *d.cfg.NotifyMinInterval {
+		d.state.lastNotifyAt = now
+
+		return false
+	}
+
+	d.state.throttledChanges = append(d.state.throttledChanges, report.Changes...)
+	if d.state.throttleFlush == nil {
+		flush := make(chan struct{})
+		d.state.throttleFlush = flush
+
+		d.state.notifications.Go(func() {
+			defer RecoverGoPanic("summary-notifier", d.logger)
+
+			timer := time.NewTimer(*d.cfg.NotifyMinInterval - elapsed)
+			defer timer.Stop()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.state.stop:
+				return
+			case <-flush:
+			case <-timer.C:
+			}
+
+			d.throttleSummary(ctx)
+		})
+	}
+
+	return true
+}
+
+// flushThrottle dispatches the summary of the changes held back within [NotifyMinInterval]
+// right away (if any), instead of once the window elapses (e.g. as a run is about to end).
+func (d *DeviceMonitor) flushThrottle() {
+	d.state.throttleMu.Lock()
+	defer d.state.throttleMu.Unlock()
+
+	if flush := d.state.throttleFlush; flush != nil {
+		select {
+		case <-flush: // already flushed
+		default:
+			close(flush)
+		}
+	}
+}
+
+// throttleSummary dispatches a summary of the changes held back within [NotifyMinInterval],
+// which also starts a new window (so that any further changes are again being held back).
+func (d *DeviceMonitor) throttleSummary(ctx context.Context) {
+	d.state.throttleMu.Lock()
+	changes := d.state.throttledChanges
+	d.state.throttledChanges = nil
+	d.state.throttleFlush = nil
+	if len(changes) > 0 {
+		d.state.lastNotifyAt = time.Now()
+	}
+	d.state.throttleMu.Unlock()
+
+	if len(changes) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	report := ChangeReport{
+		Device:     d.device,
+		DetectedAt: time.Now().Format(time.RFC3339),
+		Kind:       reportKind(changes),
+		Severity:   reportSeverity(changes),
+		Changes:    changes,
+	}
+	msg := fmt.Sprintf("Summary: %d changes were held back within notify_min_interval: %s",
+		len(changes), d.changesMessage(changes))
+	d.logger.Warn(msg, LogEvent("alert_summary"))
+
+	if d.state.maintenance.Load() {
+		d.logger.Info("Summary occurred in maintenance mode - skipping notification", LogEvent("notify_skipped"))
+
+		return
+	}
+
+	if err := d.notify(ctx, msg, report); err != nil {
+		d.logger.Error(fmt.Sprintf("Summary notification agent error: %v", err), LogEvent("notify_failure"))
+	}
+}
+
+// handleAlert handles alerting for a slice of [Change] with a given message.
+// If no notification agent was configured, it only emits the alert to log output.
+func (d *DeviceMonitor) handleAlert(ctx context.Context, hash string, msg string, report ChangeReport) {
+	if report.Kind == ChangeKindRecovered {
+		d.logger.Info("Recovery: "+msg, LogEvent("recovery"))
+	} else {
+		d.logger.Warn("Alert: "+msg, LogEvent("alert"))
+	}
+
+	if d.notifier != nil && d.state.maintenance.Load() {
+		d.logger.Info("Alert changes occurred in maintenance mode - skipping notification", LogEvent("notify_skipped"))
+	} else if d.notifier != nil && report.Kind == ChangeKindRecovered && !*d.cfg.NotifyOnRecovery {
+		d.logger.Info("Alert changes are recoveries only - skipping notification", LogEvent("notify_skipped"))
+	} else if d.notifier != nil && report.Kind != ChangeKindRecovered && !meetsSeverity(report.Severity, *d.cfg.NotifyMinSeverity) {
+		d.logger.Info(fmt.Sprintf("Alert changes are of severity [%s] below notify_min_severity [%s] - skipping notification",
+			report.Severity, *d.cfg.NotifyMinSeverity), LogEvent("notify_skipped"))
+	} else if d.notifier != nil && report.Kind != ChangeKindRecovered && d.throttleAlert(ctx, report) {
+		d.logger.Info("Alert changes occurred within notify_min_interval - holding back notification", LogEvent("notify_throttled"))
+	} else if d.notifier != nil {
+		d.state.notifications.Go(func() {
+			defer RecoverGoPanic("alert-notifier", d.logger)
+			if err := d.notify(ctx, msg, report); err != nil {
+				d.logger.Error(fmt.Sprintf("Alert notification agent error: %v", err), LogEvent("notify_failure"))
+			}
+		})
+	}
+
+	if d.cfg.OutputDir != nil {
+		if *d.cfg.WriteChangeReports {
+			if err := d.writeChangeReport(report); err != nil {
+				d.logger.Error(fmt.Sprintf("Error writing change report to file: %v", err), LogEvent("error"))
+			} else if err := d.pruneChangeReports(); err != nil {
+				d.logger.Error(fmt.Sprintf("Error pruning old change reports: %v", err), LogEvent("error"))
+			}
+		}
+		if *d.cfg.OutputChangelog {
+			if err := d.appendChangelog(report); err != nil {
+				d.logger.Error(fmt.Sprintf("Error appending change report to changelog: %v", err), LogEvent("error"))
+			}
+		}
+	}
+
+	d.state.lastAlertHash = hash
+	d.state.lastAlertAt = time.Now()
+}
+
+// pollFailure is called after a single device poll (including retries) has failed.
+// It handles the back-off period alerting, stopping and time (if configured to do so).
+// It both observes and respects the given context for earlier termination.
+func (d *DeviceMonitor) pollFailure(ctx context.Context, err error) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-d.state.stop:
+		return
+	default:
+	}
+
+	d.state.pollFailures++
+	d.setStatus(func(s *DeviceStatus) {
+		s.PollFailures = d.state.pollFailures
+	})
+
+	if d.state.pollFailures < *d.cfg.PollBackoffAfter {
+		d.logger.Error(fmt.Sprintf("Error polling device [%d/%d]: %v",
+			d.state.pollFailures, *d.cfg.PollBackoffAfter, err), LogEvent("poll_failure"))
+	} else {
+		var msg string
+		if *d.cfg.PollBackoffStopMonitor {
+			msg = fmt.Sprintf("Error polling device [%d/%d] (stopping device monitor): %v",
+				d.state.pollFailures, *d.cfg.PollBackoffAfter, err)
+		} else {
+			msg = fmt.Sprintf("Error polling device [%d/%d] (entering %s back-off): %v",
+				d.state.pollFailures, *d.cfg.PollBackoffAfter, *d.cfg.PollBackoffTime, err)
+		}
+
+		d.logger.Error(msg, LogEvent("poll_failure"))
+
+		if d.notifier != nil && *d.cfg.PollBackoffNotify && d.state.maintenance.Load() {
+			d.logger.Info("Back-off occurred in maintenance mode - skipping notification", LogEvent("notify_skipped"))
+		} else if d.notifier != nil && *d.cfg.PollBackoffNotify {
+			d.state.notifications.Go(func() {
+				defer RecoverGoPanic("failure-notifier", d.logger)
+				if err := d.notify(ctx, msg, nil); err != nil {
+					d.logger.Error(fmt.Sprintf("Alert notification agent error: %v", err), LogEvent("notify_failure"))
+				}
+			})
+		}
+
+		if *d.cfg.PollBackoffStopMonitor {
+			d.Stop()
+
+			return
+		}
+
+		d.setStatus(func(s *DeviceStatus) {
+			s.InBackoff = true
+			s.BackoffUntil = time.Now().Add(*d.cfg.PollBackoffTime).Format(time.RFC3339)
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.state.stop:
+			return
+		case <-time.After(*d.cfg.PollBackoffTime):
+		}
+
+		d.state.pollFailures = 0
+		d.state.resumedFromBackoff = *d.cfg.BackoffResetBaseline
+		d.setStatus(func(s *DeviceStatus) {
+			s.PollFailures = 0
+			s.InBackoff = false
+			s.BackoffUntil = ""
+		})
+	}
+}
@@ -0,0 +1,4044 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Test helper to create monitors without validation for internal method testing.
+func newTestDeviceMonitor(t *testing.T, device Device, cfg *DeviceMonitorConfig, fsys afero.Fs, runner CommandRunner, logger *slog.Logger, notifier Notifier) *DeviceMonitor {
+	t.Helper()
+
+	var err error
+	cfg, err = mergeDeviceMonitorConfig(cfg)
+	require.NoError(t, err)
+
+	// The device path needs to exist (as also verified by [NewDeviceMonitor]).
+	if fsys != nil && device.Type == DeviceTypeDevice && device.Path != "" {
+		if exists, _ := afero.Exists(fsys, device.Path); !exists {
+			require.NoError(t, afero.WriteFile(fsys, device.Path, []byte{}, 0o644))
+		}
+	}
+
+	return &DeviceMonitor{
+		device:   device,
+		cfg:      cfg,
+		fsys:     fsys,
+		runner:   runner,
+		logger:   logger,
+		notifier: notifier,
+		state:    newDeviceMonitorState(),
+	}
+}
+
+// Expectation: NewDeviceMonitor should create a monitor with correct values.
+func Test_NewDeviceMonitor_Success(t *testing.T) {
+	t.Parallel()
+
+	cfg := &DeviceMonitorConfig{
+		PollInterval:            ptr(30 * time.Second),
+		PollJitter:              ptr(10 * time.Second),
+		PollAttemptTimeout:      ptr(10 * time.Second),
+		PollAttemptInterval:     ptr(time.Second),
+		PollAttemptBackoff:      ptr(2.0),
+		PollAttemptBackoffMax:   ptr(time.Minute),
+		PollAttemptJitter:       ptr(0.2),
+		PollWaitDelay:           ptr(10 * time.Second),
+		PollBudgetStrict:        ptr(true),
+		PollAttempts:            ptr(2),
+		PollBackoffAfter:        ptr(5),
+		PollBackoffTime:         ptr(5 * time.Minute),
+		PollBackoffNotify:       ptr(true),
+		PollBackoffStopMonitor:  ptr(false),
+		BackoffResetBaseline:    ptr(true),
+		PollFailureRateAlert:    ptr(0.5),
+		PollFailureRateWindow:   ptr(6),
+		OnStopCommand:           ptr("/usr/local/bin/on-stop"),
+		OnStopTimeout:           ptr(5 * time.Second),
+		PrePollCommand:          ptr("/usr/local/bin/pre-poll"),
+		PrePollTimeout:          ptr(5 * time.Second),
+		PrePollAbort:            ptr(true),
+		SgSesPath:               ptr("/usr/local/sbin/sg_ses"),
+		SgSesArgs:               []string{"--all", "--json", "--maxlen=1024"},
+		FetchCommand:            ptr("/usr/local/bin/vendor-ses"),
+		FetchArgs:               []string{"--json", "{{.Path}}"},
+		ToleratePreamble:        ptr(true),
+		NotifyOnRecovery:        ptr(false),
+		NotifyMinSeverity:       ptr(SeverityCritical),
+		AlertOnStartUnhealthy:   ptr(true),
+		NotifyMinInterval:       ptr(10 * time.Minute),
+		AlertDedupTTL:           ptr(time.Hour),
+		CompactMessages:         ptr(true),
+		HeartbeatInterval:       ptr(24 * time.Hour),
+		TempWarn:                ptr(45),
+		TempCrit:                ptr(55),
+		TempHysteresis:          ptr(3),
+		AlertFanSpeed:           ptr(true),
+		PrdFailCritical:         ptr(true),
+		MonitorTypes:            []int{2, 23},
+		SuppressTypes:           []int{16, 23},
+		ChangeDebounce:          ptr(2),
+		TransientStatuses:       []int{5},
+		TransientDebounce:       ptr(5),
+		ElementDropThreshold:    ptr(0.5),
+		CanonicalStatusMeanings: ptr(true),
+		StatusMeanings:          map[int]string{3: "Non-critical"},
+		OutputDir:               ptr("/output"),
+		OutputPrefix:            ptr("sesmon-"),
+		WriteSnapshots:          ptr(false),
+		WriteChangeReports:      ptr(false),
+		ReportResults:           ptr(true),
+		OutputMaxReports:        ptr(100),
+		OutputMaxAge:            ptr(720 * time.Hour),
+		OutputChangelog:         ptr(true),
+		OutputCompress:          ptr(true),
+		OutputFileMode:          ptr("0640"),
+		OutputDirMode:           ptr("0750"),
+		PersistAcks:             ptr(true),
+		SnapshotHistory:         ptr(24),
+		SnapshotEveryPoll:       ptr(true),
+		ElementHistory:          ptr(20),
+		LogChangeEvents:         ptr(true),
+		Verbose:                 ptr(false),
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	notifier := newMockNotifier()
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, cfg, fsys, runner, logger, notifier)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, cfg, m.cfg)
+}
+
+// Expectation: NewDeviceMonitor should error on no dependencies provided.
+func Test_NewDeviceMonitor_NoDependencies_Error(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	notifier := newMockNotifier()
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, nil, nil, runner, logger, notifier)
+
+	require.ErrorContains(t, err, "dependency")
+	require.Nil(t, m)
+}
+
+// Expectation: NewDeviceMonitor should error on no device provided.
+func Test_NewDeviceMonitor_NoDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	notifier := newMockNotifier()
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: ""}, nil, fsys, runner, logger, notifier)
+
+	require.ErrorContains(t, err, "no device provided")
+	require.Nil(t, m)
+}
+
+// Expectation: NewDeviceMonitor should error on not existing device provided.
+func Test_NewDeviceMonitor_DeviceNotExist_Error(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	notifier := newMockNotifier()
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/not/exist"}, nil, fsys, runner, logger, notifier)
+
+	require.ErrorContains(t, err, "stat device failure")
+	require.Nil(t, m)
+}
+
+// Expectation: NewDeviceMonitor should provide a default configuration on nil.
+func Test_NewDeviceMonitor_NoConfigGiven_Success(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	notifier := &mockNotifier{}
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, nil, fsys, runner, logger, notifier)
+
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, DefaultDeviceMonitorConfig(), m.cfg)
+}
+
+// Expectation: NewDeviceMonitor should warn when the worst-case poll budget exceeds the poll interval.
+func Test_NewDeviceMonitor_PollBudgetExceeded_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+	logger := NewLogger(&logBuf, false, true)
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	cfg := &DeviceMonitorConfig{
+		PollInterval:        ptr(60 * time.Second),
+		PollAttempts:        ptr(5),
+		PollAttemptTimeout:  ptr(30 * time.Second),
+		PollAttemptInterval: ptr(15 * time.Second),
+	}
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, cfg, fsys, runner, logger, nil)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Contains(t, logBuf.String(), "Worst-case poll budget (3m45s = 5 x (30s + 15s)) "+
+		"exceeds the poll interval (1m0s)")
+}
+
+// Expectation: NewDeviceMonitor should include the backed-off waits in the poll budget.
+func Test_NewDeviceMonitor_PollBudgetBackoff_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+	logger := NewLogger(&logBuf, false, true)
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	cfg := &DeviceMonitorConfig{
+		PollInterval:          ptr(5 * time.Minute),
+		PollAttempts:          ptr(5),
+		PollAttemptTimeout:    ptr(30 * time.Second),
+		PollAttemptInterval:   ptr(15 * time.Second),
+		PollAttemptBackoff:    ptr(2.0),
+		PollAttemptBackoffMax: ptr(2 * time.Minute),
+	}
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, cfg, fsys, runner, logger, nil)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Contains(t, logBuf.String(), "Worst-case poll budget (8m15s = 5 x 30s + backed-off waits from 15s) "+
+		"exceeds the poll interval (5m0s)")
+}
+
+// Expectation: NewDeviceMonitor should include the pre-poll timeout in the poll budget (if a pre-poll command is set).
+func Test_NewDeviceMonitor_PollBudgetPrePoll_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+	logger := NewLogger(&logBuf, false, true)
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	cfg := &DeviceMonitorConfig{
+		PollInterval:        ptr(60 * time.Second),
+		PollAttempts:        ptr(1),
+		PollAttemptTimeout:  ptr(30 * time.Second),
+		PollAttemptInterval: ptr(15 * time.Second),
+		PrePollTimeout:      ptr(30 * time.Second),
+	}
+
+	_, err = NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, cfg, fsys, runner, logger, nil)
+	require.NoError(t, err)
+	require.Empty(t, logBuf.String())
+
+	cfg.PrePollCommand = ptr("/usr/local/bin/pre-poll")
+
+	_, err = NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, cfg, fsys, runner, logger, nil)
+	require.NoError(t, err)
+	require.Contains(t, logBuf.String(), "Worst-case poll budget (1m15s = 1 x (30s + 15s) + 30s pre-poll) "+
+		"exceeds the poll interval (1m0s)")
+}
+
+// Expectation: NewDeviceMonitor should not warn when the default poll budget fits the poll interval.
+func Test_NewDeviceMonitor_PollBudgetDefault_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+	logger := NewLogger(&logBuf, false, true)
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, nil, fsys, runner, logger, nil)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Empty(t, logBuf.String())
+}
+
+// Expectation: NewDeviceMonitor should error when the poll budget exceeds the poll interval in strict mode.
+func Test_NewDeviceMonitor_PollBudgetStrict_Error(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	err := afero.WriteFile(fsys, "/dev/null", []byte{}, 0o644)
+	require.NoError(t, err)
+
+	cfg := &DeviceMonitorConfig{
+		PollInterval:     ptr(60 * time.Second),
+		PollBudgetStrict: ptr(true),
+	}
+
+	m, err := NewDeviceMonitor(Device{Type: 0, Path: "/dev/null"}, cfg, fsys, runner, logger, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "exceeds poll_interval")
+	require.Nil(t, m)
+}
+
+// Expectation: Start should begin monitoring and close done on stop.
+func Test_DeviceMonitor_Start_Stop_Done_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:        ptr(100 * time.Millisecond),
+			PollAttemptTimeout:  ptr(5 * time.Second),
+			PollAttemptInterval: ptr(50 * time.Millisecond),
+			PollAttempts:        ptr(1),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&logBuf, false, true),
+		newMockNotifier(),
+	)
+
+	m.Start(t.Context())
+	time.Sleep(500 * time.Millisecond)
+	m.Stop()
+
+	select {
+	case <-m.Done():
+		require.Contains(t, logBuf.String(), "Monitoring")
+		require.Contains(t, logBuf.String(), "shutting down")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Monitor did not stop in time")
+	}
+}
+
+// Expectation: Start should periodically dispatch heartbeats summarizing the device health.
+func Test_DeviceMonitor_Start_Heartbeat_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[`+
+		`{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}},`+
+		`{"element_type":{"i":23},"element_number":1,"status_descriptor":{"status":{"i":2}}}]}}`, "", nil)
+
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:      ptr(time.Hour),
+			HeartbeatInterval: ptr(200 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	m.Start(t.Context())
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	m.Stop()
+	<-m.Done()
+
+	calls := notifier.getCalls()
+	require.NotEmpty(t, calls)
+	require.Contains(t, calls[0], "Heartbeat:")
+	require.Contains(t, calls[0], "2 elements (1 OK, 1 with problems)")
+	require.Empty(t, m.state.lastAlertHash)
+}
+
+// Expectation: heartbeatMessage should state when no device poll has succeeded yet.
+func Test_DeviceMonitor_heartbeatMessage_NotReady_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	require.Contains(t, m.heartbeatMessage(), "no device poll has succeeded yet")
+}
+
+// Expectation: Start should stop when context is cancelled.
+func Test_DeviceMonitor_Start_ContextCancelled_Error(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:        ptr(100 * time.Millisecond),
+			PollAttemptTimeout:  ptr(5 * time.Second),
+			PollAttemptInterval: ptr(50 * time.Millisecond),
+			PollAttempts:        ptr(1),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	m.Start(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-m.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Monitor did not stop in time")
+	}
+}
+
+// Expectation: Stop should stop the monitoring and close the stop.
+func Test_DeviceMonitor_Stop_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:       ptr(30 * time.Second),
+			PollAttempts:       ptr(2),
+			PollAttemptTimeout: ptr(10 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	m.Stop()
+
+	select {
+	case <-m.state.stop:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("stop was not closed")
+	}
+}
+
+// Expectation: Stop should not panic on twice called Stop().
+func Test_DeviceMonitor_StopTwice_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:       ptr(30 * time.Second),
+			PollAttempts:       ptr(2),
+			PollAttemptTimeout: ptr(10 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	m.Stop()
+	m.Stop()
+
+	select {
+	case <-m.state.stop:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("stop was not closed")
+	}
+}
+
+// Expectation: The monitor should handle poll failures and increase the counters.
+func Test_DeviceMonitor_PollFailure_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errInvalidJSON)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:        ptr(100 * time.Millisecond),
+			PollAttemptTimeout:  ptr(5 * time.Second),
+			PollAttemptInterval: ptr(50 * time.Millisecond),
+			PollAttempts:        ptr(1),
+			PollBackoffAfter:    ptr(10000),
+			PollBackoffTime:     ptr(time.Minute),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&logBuf, false, true),
+		newMockNotifier(),
+	)
+
+	m.Start(t.Context())
+	time.Sleep(500 * time.Millisecond)
+	m.Stop()
+
+	select {
+	case <-m.Done():
+		require.NotZero(t, m.state.pollFailures)
+		require.Contains(t, logBuf.String(), "Monitoring")
+		require.Contains(t, logBuf.String(), "shutting down")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Monitor did not stop in time")
+	}
+}
+
+// Expectation: poll should handle first successful fetch from device.
+func Test_DeviceMonitor_poll_FirstPoll_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx := t.Context()
+	err := m.poll(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, m.state.previousResults)
+	require.Len(t, m.state.previousResults, 1)
+}
+
+// Expectation: poll should detect and notify on changes.
+func Test_DeviceMonitor_poll_DetectsChanges_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput1 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	jsonOutput2 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 2, "meaning": "Critical"}}
+				}
+			]
+		}
+	}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	runner.setResponse(jsonOutput1, "", nil)
+	err := m.poll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, notifier.callCount())
+
+	runner.setResponse(jsonOutput2, "", nil)
+	err = m.poll(ctx)
+	require.NoError(t, err)
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.Equal(t, 1, notifier.callCount())
+
+	calls := notifier.getCalls()
+	require.Len(t, calls, 1)
+	require.Contains(t, calls[0], "15#0")
+}
+
+// Expectation: poll should print verbose information on changes.
+func Test_DeviceMonitor_poll_DetectsChangesVerbose_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput1 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	jsonOutput2 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 2, "meaning": "Critical"}}
+				}
+			]
+		}
+	}`
+
+	var buf safeBuffer
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			Verbose:             ptr(true),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	runner.setResponse(jsonOutput1, "", nil)
+	err := m.poll(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, notifier.callCount())
+
+	runner.setResponse(jsonOutput2, "", nil)
+	err = m.poll(ctx)
+	require.NoError(t, err)
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.Equal(t, 1, notifier.callCount())
+
+	calls := notifier.getCalls()
+	require.Len(t, calls, 1)
+	require.Contains(t, calls[0], "15#0")
+
+	require.Contains(t, buf.String(), "Retrieved 1 initial elements from SES-capable device (1 OK, 0 with problems)")
+	require.Contains(t, buf.String(), "Retrieved initial elements by type: Enclosure: 1")
+	require.Contains(t, buf.String(), "Retrieved batch of")
+	require.Contains(t, buf.String(), "changes detected")
+}
+
+// Expectation: poll should not notify on identical consecutive states.
+func Test_DeviceMonitor_poll_NoChangeNoNotify_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	err := m.poll(ctx)
+	require.NoError(t, err)
+
+	err = m.poll(ctx)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 0, notifier.callCount())
+}
+
+// Expectation: poll should print verbose output on no changes detected.
+func Test_DeviceMonitor_poll_NoChangeVerbose_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	var buf safeBuffer
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			Verbose:             ptr(true),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	err := m.poll(ctx)
+	require.NoError(t, err)
+
+	err = m.poll(ctx)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 0, notifier.callCount())
+
+	require.Contains(t, buf.String(), "initial elements")
+	require.Contains(t, buf.String(), "Retrieved batch of")
+	require.Contains(t, buf.String(), "No changes detected")
+}
+
+// Expectation: poll should return an error when parsing fails.
+func Test_DeviceMonitor_poll_ParseError_Error(t *testing.T) {
+	t.Parallel()
+
+	invalidJSON := `{"invalid json structure`
+	runner := &mockCommandRunner{}
+	runner.setResponse(invalidJSON, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx := t.Context()
+	err := m.poll(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure parsing")
+}
+
+// Expectation: poll should handle notification errors gracefully.
+func Test_DeviceMonitor_poll_NotificationError_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput1 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	jsonOutput2 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 2, "meaning": "Critical"}}
+				}
+			]
+		}
+	}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+	notifier.setError(errors.New("notification failed"))
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	runner.setResponse(jsonOutput1, "", nil)
+	err := m.poll(ctx)
+	require.NoError(t, err)
+
+	runner.setResponse(jsonOutput2, "", nil)
+	err = m.poll(ctx)
+	require.NoError(t, err)
+
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, 1, notifier.callCount())
+}
+
+// Expectation: poll should notify about recoveries (element back to status OK) by default.
+func Test_DeviceMonitor_poll_NotifiesRecovery_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	for _, out := range []string{jsonGood, jsonBad, jsonGood} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.True(t, notifier.waitForNotification(2*time.Second))
+
+	calls := notifier.getCalls()
+	require.Len(t, calls, 2)
+	require.Contains(t, strings.Join(calls, " "), "kind=degraded")
+	require.Contains(t, strings.Join(calls, " "), "kind=recovered")
+}
+
+// Expectation: poll should hold back alerts within notify_min_interval (except recoveries) and summarize them.
+func Test_DeviceMonitor_poll_NotifyMinInterval_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[`+
+			`{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+	fs := afero.NewMemMapFs()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			NotifyMinInterval:   ptr(500 * time.Millisecond),
+			OutputDir:           ptr("/output"),
+		},
+		fs,
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	for _, status := range []int{sesStatusOK, sesStatusCritical, sesStatusNoncritical, sesStatusUnrecoverable} {
+		runner.setResponse(jsonStatus(status), "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.False(t, notifier.waitForNotification(100*time.Millisecond))
+	require.Equal(t, 1, notifier.callCount())
+
+	runner.setResponse(jsonStatus(sesStatusOK), "", nil)
+	require.NoError(t, m.poll(ctx))
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.Contains(t, notifier.getCalls()[1], "kind=recovered")
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	calls := notifier.getCalls()
+	require.Len(t, calls, 3)
+	require.True(t, strings.HasPrefix(calls[2], "Summary: 2 changes were held back"))
+
+	files, err := afero.ReadDir(fs, "/output")
+	require.NoError(t, err)
+	reports := 0
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), changeReportPrefix) {
+			reports++
+		}
+	}
+	require.Positive(t, reports) // change reports are still written
+}
+
+// Expectation: A summary due within notify_min_interval should be awaited as a notification,
+// being dispatched right away if flushed, but never once the monitor was stopped.
+func Test_DeviceMonitor_throttleSummary_StopFlush_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[`+
+			`{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	newMonitor := func() (*DeviceMonitor, *mockNotifier) {
+		runner := &mockCommandRunner{}
+		notifier := newMockNotifier()
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttemptTimeout:  ptr(10 * time.Second),
+				PollAttempts:        ptr(2),
+				PollAttemptInterval: ptr(100 * time.Millisecond),
+				NotifyMinInterval:   ptr(time.Hour),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+
+		for _, status := range []int{sesStatusOK, sesStatusCritical, sesStatusNoncritical} {
+			runner.setResponse(jsonStatus(status), "", nil)
+			require.NoError(t, m.poll(t.Context()))
+		}
+		require.True(t, notifier.waitForNotification(2*time.Second))
+
+		return m, notifier
+	}
+
+	waitNotifications := func(m *DeviceMonitor) {
+		done := make(chan struct{})
+		go func() {
+			m.state.notifications.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			require.FailNow(t, "summary is not awaited as notification")
+		}
+	}
+
+	m, notifier := newMonitor()
+	m.Stop()
+	waitNotifications(m)
+	require.Equal(t, 1, notifier.callCount())
+
+	m, notifier = newMonitor()
+	m.flushThrottle()
+	m.flushThrottle() // no panic on repeated flush
+	waitNotifications(m)
+	calls := notifier.getCalls()
+	require.Len(t, calls, 2)
+	require.True(t, strings.HasPrefix(calls[1], "Summary: 1 changes were held back"))
+}
+
+// Expectation: poll should not notify about recoveries when notify_on_recovery is disabled.
+func Test_DeviceMonitor_poll_NotifyOnRecoveryDisabled_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+	var buf safeBuffer
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			NotifyOnRecovery:    ptr(false),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	for _, out := range []string{jsonGood, jsonBad, jsonGood} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.False(t, notifier.waitForNotification(200*time.Millisecond))
+	require.Equal(t, 1, notifier.callCount())
+	require.Contains(t, buf.String(), "Recovery:")
+	require.Contains(t, buf.String(), "recoveries only - skipping notification")
+}
+
+// Expectation: poll should only notify alerts meeting notify_min_severity, while still logging and reporting all of them.
+func Test_DeviceMonitor_poll_NotifyMinSeverity_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+	var buf safeBuffer
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:      ptr(1),
+			NotifyMinSeverity: ptr(SeverityCritical),
+			OutputDir:         ptr("/output"),
+		},
+		fsys,
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	// OK to Noncritical (warning) is only logged and reported.
+	for _, status := range []int{1, 3} {
+		runner.setResponse(jsonStatus(status), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+	}
+	m.state.notifications.Wait()
+
+	require.Zero(t, notifier.callCount())
+	require.Contains(t, buf.String(), "Alert: ")
+	require.Contains(t, buf.String(), "of severity [warning] below notify_min_severity [critical] - skipping notification")
+
+	// Noncritical to Critical is notified.
+	runner.setResponse(jsonStatus(2), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	require.Equal(t, 1, notifier.callCount())
+
+	reports, err := afero.Glob(fsys, "/output/"+changeReportPrefix+"*")
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+}
+
+// failedAttemptsRunner is a [mockCommandRunner] reporting failed attempts before succeeding.
+type failedAttemptsRunner struct {
+	mockCommandRunner
+
+	failed int
+}
+
+func (r *failedAttemptsRunner) Run(ctx context.Context, cfg RunCommandConfig) (string, string, error) {
+	for attempt := 1; attempt <= r.failed; attempt++ {
+		if cfg.OnAttemptError != nil {
+			cfg.OnAttemptError(attempt, errors.New("test error"))
+		}
+	}
+
+	return r.mockCommandRunner.Run(ctx, cfg)
+}
+
+// Expectation: poll should log the fetch duration and the attempts needed for the fetch when verbose.
+func Test_DeviceMonitor_poll_VerboseFetchStats_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &failedAttemptsRunner{failed: 1}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts: ptr(3),
+			Verbose:      ptr(true),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		newMockNotifier(),
+	)
+
+	require.NoError(t, m.poll(t.Context()))
+	require.NoError(t, m.poll(t.Context()))
+
+	require.Regexp(t, `Retrieved batch of 0 elements from SES-capable device \(fetched in \S+ after 2 of 3 attempts\)`, buf.String())
+}
+
+// failingCommandRunner is a [mockCommandRunner] failing (only) the runs of a specific command.
+type failingCommandRunner struct {
+	mockCommandRunner
+
+	command string
+}
+
+func (r *failingCommandRunner) Run(ctx context.Context, cfg RunCommandConfig) (string, string, error) {
+	if cfg.Command == r.command {
+		r.mu.Lock()
+		r.calls++
+		r.configs = append(r.configs, cfg)
+		r.mu.Unlock()
+
+		return "", "", errors.New("pre-poll failure")
+	}
+
+	return r.mockCommandRunner.Run(ctx, cfg)
+}
+
+// Expectation: poll should run the pre-poll command before fetching, continuing with the poll on its failure.
+func Test_DeviceMonitor_poll_PrePollCommand_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &failingCommandRunner{command: "/usr/local/bin/pre-poll"}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25", Address: "0x5000"},
+		&DeviceMonitorConfig{
+			PollAttempts:   ptr(1),
+			PrePollCommand: ptr("/usr/local/bin/pre-poll"),
+			PrePollTimeout: ptr(5 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		newMockNotifier(),
+	)
+
+	require.NoError(t, m.poll(t.Context()))
+	require.Equal(t, 2, runner.callCount())
+
+	pre := runner.configs[0]
+	require.Equal(t, "/usr/local/bin/pre-poll", pre.Command)
+	require.Equal(t, []string{"/dev/sg25", "0x5000"}, pre.Args)
+	require.Equal(t, 1, pre.Attempts)
+	require.Equal(t, 5*time.Second, pre.AttemptTimeout)
+	require.Equal(t, "sg_ses", runner.lastConfig().Command)
+	require.Contains(t, buf.String(), "Error running pre-poll command (continuing with the poll): pre-poll failure")
+}
+
+// Expectation: poll should fail without fetching if the pre-poll command fails (with pre_poll_abort).
+func Test_DeviceMonitor_poll_PrePollCommand_Abort_Error(t *testing.T) {
+	t.Parallel()
+
+	runner := &failingCommandRunner{command: "/usr/local/bin/pre-poll"}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:   ptr(1),
+			PrePollCommand: ptr("/usr/local/bin/pre-poll"),
+			PrePollAbort:   ptr(true),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	err := m.poll(t.Context())
+	require.ErrorContains(t, err, "failure running pre-poll command: pre-poll failure")
+	require.Equal(t, 1, runner.callCount())
+	require.Nil(t, m.state.previousResults)
+}
+
+// Expectation: poll should only run the pre-poll command once holding a slot of the shared poll semaphore.
+func Test_DeviceMonitor_poll_PrePollCommand_PollSemaphore_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:   ptr(1),
+			PrePollCommand: ptr("/usr/local/bin/pre-poll"),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	m.pollSem = make(chan struct{}, 1)
+	m.pollSem <- struct{}{}
+
+	polled := make(chan error, 1)
+	go func() {
+		polled <- m.poll(t.Context())
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 0, runner.callCount())
+
+	<-m.pollSem
+	require.NoError(t, <-polled)
+
+	require.Equal(t, 2, runner.callCount())
+	require.Equal(t, "/usr/local/bin/pre-poll", runner.configs[0].Command)
+	require.Empty(t, m.pollSem)
+}
+
+// Expectation: fetchFromDevice should return the attempts needed for reading a file-type device.
+func Test_DeviceMonitor_fetchFromDevice_Stats_FromFile_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/device.json", []byte(`{}`), 0o644))
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/device.json"},
+		&DeviceMonitorConfig{},
+		fsys,
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	_, _, stats, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.attempts)
+	require.Positive(t, stats.duration)
+}
+
+// Expectation: poll should alert when a temperature threshold is crossed (and not on the first poll).
+func Test_DeviceMonitor_poll_TemperatureThreshold_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonTemp := func(temp int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":4},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":1},"temperature":{"i":%d,"meaning":"%d C"}}}]}}`, temp, temp)
+	}
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			TempWarn:            ptr(40),
+			TempCrit:            ptr(50),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+
+	for _, temp := range []int{30, 35, 41, 39} {
+		runner.setResponse(jsonTemp(temp), "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.False(t, notifier.waitForNotification(200*time.Millisecond))
+
+	calls := notifier.getCalls()
+	require.Len(t, calls, 1)
+	require.Contains(t, calls[0], "reached warning threshold")
+}
+
+// Expectation: poll should alert about a temperature already beyond a threshold on the first poll (only once).
+func Test_DeviceMonitor_poll_TemperatureThresholdAtStart_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":4},"element_number":0,`+
+		`"status_descriptor":{"status":{"i":1},"temperature":{"i":55,"meaning":"55 C"}}}]}}`, "", nil)
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts: ptr(1),
+			TempWarn:     ptr(40),
+			TempCrit:     ptr(50),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	calls := notifier.getCalls()
+	require.Len(t, calls, 1)
+	require.Contains(t, calls[0], "temperature 55 C reached critical threshold")
+	require.Equal(t, tempLevelCritical, m.state.tempLevels["4#0"])
+
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+	require.Len(t, notifier.getCalls(), 1)
+}
+
+// Expectation: poll should write snapshot files when OutputDir is configured.
+func Test_DeviceMonitor_poll_WritesSnapshots_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+
+	fsys := afero.NewMemMapFs()
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25", Description: "test-device"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			OutputDir:           ptr("/output"),
+		},
+		fsys,
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx := t.Context()
+	err := m.poll(ctx)
+	require.NoError(t, err)
+
+	exists, err := afero.Exists(fsys, "/output/current.json")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = afero.Exists(fsys, "/output/current_parsed.json")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	var snapshot DeviceSnapshot
+	data, err := afero.ReadFile(fsys, "/output/current.json")
+	require.NoError(t, err)
+	err = json.Unmarshal(data, &snapshot)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg25", snapshot.Device.Path)
+	require.Equal(t, "test-device", snapshot.Device.Description)
+
+	var results DeviceSnapshot
+	data, err = afero.ReadFile(fsys, "/output/current_parsed.json")
+	require.NoError(t, err)
+	err = json.Unmarshal(data, &results)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg25", results.Device.Path)
+	require.Equal(t, "test-device", results.Device.Description)
+}
+
+// Expectation: poll should write change report when changes are detected and OutputDir is set.
+func Test_DeviceMonitor_poll_WritesChangeReport_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	runner := &mockCommandRunner{}
+	fsys := afero.NewMemMapFs()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+			OutputDir:           ptr("/output"),
+		},
+		fsys,
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx := t.Context()
+
+	runner.setResponse(jsonGood, "", nil)
+	err := m.poll(ctx)
+	require.NoError(t, err)
+
+	runner.setResponse(jsonBad, "", nil)
+	err = m.poll(ctx)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(files), 1)
+
+	var foundChangeReport bool
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "change-") {
+			foundChangeReport = true
+
+			break
+		}
+	}
+	require.True(t, foundChangeReport)
+}
+
+// Expectation: fetchFromDevice should read from file when type is [DeviceTypeFile].
+func Test_DeviceMonitor_fetchFromDevice_FromFile_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	jsonOutput := `{"join_of_diagnostic_pages":{"element_list":[]}}`
+	err := afero.WriteFile(fsys, "/tmp/device.json", []byte(jsonOutput), 0o644)
+	require.NoError(t, err)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/device.json"},
+		DefaultDeviceMonitorConfig(),
+		fsys,
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	ctx := t.Context()
+	result, _, _, err := m.fetchFromDevice(ctx)
+	require.NoError(t, err)
+	require.JSONEq(t, jsonOutput, string(result))
+}
+
+// Expectation: fetchFromDevice should read from file when type is [DeviceTypeFile].
+func Test_DeviceMonitor_fetchFromDevice_FromFile_InvalidJSON_Error(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	jsonOutput := `{json`
+	err := afero.WriteFile(fsys, "/tmp/device.json", []byte(jsonOutput), 0o644)
+	require.NoError(t, err)
+
+	cfg := DefaultDeviceMonitorConfig()
+	cfg.PollAttempts = ptr(1)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/device.json"},
+		cfg,
+		fsys,
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	ctx := t.Context()
+	_, _, _, err = m.fetchFromDevice(ctx)
+	require.Error(t, err)
+	require.ErrorIs(t, err, errInvalidJSON)
+}
+
+// Expectation: fetchFromDevice should execute sg_ses command for [DeviceTypeDevice].
+func Test_DeviceMonitor_fetchFromDevice_FromDevPath_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{"join_of_diagnostic_pages":{"element_list":[]}}`
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttempts:        ptr(2),
+			PollAttemptInterval: ptr(100 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	ctx := t.Context()
+	result, _, _, err := m.fetchFromDevice(ctx)
+	require.NoError(t, err)
+	require.JSONEq(t, jsonOutput, string(result))
+	require.Equal(t, 1, runner.callCount())
+}
+
+// Expectation: fetchFromDevice should use the default sg_ses command and arguments.
+func Test_DeviceMonitor_fetchFromDevice_DefaultCommand_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	_, _, _, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+
+	cfg := runner.lastConfig()
+	require.Equal(t, "sg_ses", cfg.Command)
+	require.Equal(t, []string{"--all", "--no-time", "--json", "/dev/sg25"}, cfg.Args)
+	require.True(t, cfg.ExpectJSON)
+	require.Equal(t, waitDelay, cfg.WaitDelay)
+}
+
+// Expectation: fetchFromDevice should use the configured sg_ses command and arguments.
+func Test_DeviceMonitor_fetchFromDevice_CustomCommand_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			SgSesPath:     ptr("/usr/local/sbin/sg_ses"),
+			SgSesArgs:     []string{"--page=0x2", "--json"},
+			PollWaitDelay: ptr(30 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	_, _, _, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+
+	cfg := runner.lastConfig()
+	require.Equal(t, "/usr/local/sbin/sg_ses", cfg.Command)
+	require.Equal(t, []string{"--page=0x2", "--json", "/dev/sg25"}, cfg.Args)
+	require.True(t, cfg.ExpectJSON)
+	require.Equal(t, 30*time.Second, cfg.WaitDelay)
+}
+
+// Expectation: fetchFromDevice should use the fetch command (with evaluated arguments) if configured.
+func Test_DeviceMonitor_fetchFromDevice_FetchCommand_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25", Address: "0x500a098012345678"},
+		&DeviceMonitorConfig{
+			FetchCommand: ptr("/usr/local/bin/vendor-ses"),
+			FetchArgs:    []string{"status", "--json", "--dev={{.Path}}", "{{.Address}}"},
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	by, _, _, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"join_of_diagnostic_pages":{"element_list":[]}}`, string(by))
+
+	cfg := runner.lastConfig()
+	require.Equal(t, "/usr/local/bin/vendor-ses", cfg.Command)
+	require.Equal(t, []string{"status", "--json", "--dev=/dev/sg25", "0x500a098012345678"}, cfg.Args)
+	require.True(t, cfg.ExpectJSON)
+}
+
+// Expectation: fetchFromDevice should return an error when file doesn't exist.
+func Test_DeviceMonitor_fetchFromDevice_FileNotExist_Error(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	cfg := DefaultDeviceMonitorConfig()
+	cfg.PollAttemptInterval = ptr(100 * time.Millisecond)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/notxist.json"},
+		cfg,
+		fsys,
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	ctx := t.Context()
+	result, _, _, err := m.fetchFromDevice(ctx)
+	require.Error(t, err)
+	require.Nil(t, result)
+	require.Contains(t, err.Error(), "not exist")
+}
+
+// Expectation: fetchFromDevice should read from the newest file matching the glob pattern of a file-type device.
+func Test_DeviceMonitor_fetchFromDevice_FromFileGlob_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	older := `{"join_of_diagnostic_pages":{"element_list":[],"older":true}}`
+	newer := `{"join_of_diagnostic_pages":{"element_list":[],"newer":true}}`
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/ses/enc-b.json", []byte(older), 0o644))
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/ses/enc-a.json", []byte(newer), 0o644))
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/ses/enc-c.txt", []byte(`{}`), 0o644))
+	require.NoError(t, fsys.Chtimes("/tmp/ses/enc-b.json", time.Now(), time.Now().Add(-time.Hour)))
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/ses/enc-*.json"},
+		DefaultDeviceMonitorConfig(),
+		fsys,
+		&mockCommandRunner{},
+		NewLogger(&buf, false, true),
+		&mockNotifier{},
+	)
+
+	result, _, _, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.JSONEq(t, newer, string(result))
+	require.Contains(t, buf.String(), "Reading from newest file [/tmp/ses/enc-a.json] matching [/tmp/ses/enc-*.json]")
+
+	require.NoError(t, fsys.Chtimes("/tmp/ses/enc-b.json", time.Now(), time.Now().Add(time.Hour)))
+
+	result, _, _, err = m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.JSONEq(t, older, string(result))
+}
+
+// Expectation: fetchFromDevice should return an error when no file matches the glob pattern of a file-type device.
+func Test_DeviceMonitor_fetchFromDevice_FromFileGlobNoMatch_Error(t *testing.T) {
+	t.Parallel()
+
+	cfg := DefaultDeviceMonitorConfig()
+	cfg.PollAttempts = ptr(1)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/ses/*.json"},
+		cfg,
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		&mockNotifier{},
+	)
+
+	result, _, _, err := m.fetchFromDevice(t.Context())
+	require.ErrorIs(t, err, errNoGlobMatch)
+	require.Nil(t, result)
+}
+
+// Expectation: NewDeviceMonitor should accept a glob pattern for a file-type device without matching files,
+// but reject a malformed glob pattern.
+func Test_NewDeviceMonitor_FileGlob_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	logger := slog.New(slog.DiscardHandler)
+
+	m, err := NewDeviceMonitor(Device{Type: 1, Path: "/tmp/ses/*.json"}, nil, fsys, &mockCommandRunner{}, logger, nil)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+
+	m, err = NewDeviceMonitor(Device{Type: 1, Path: "/tmp/ses/[.json"}, nil, fsys, &mockCommandRunner{}, logger, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, m)
+}
+
+// Expectation: pollFailure should increment pollFailures counter.
+func Test_DeviceMonitor_pollFailure_IncrementCounter_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollBackoffAfter: ptr(5),
+			PollBackoffTime:  ptr(30 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx := t.Context()
+	err := errors.New("test error")
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 1, m.state.pollFailures)
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 2, m.state.pollFailures)
+}
+
+// Expectation: pollFailure should trigger backoff after threshold and reset counter.
+func Test_DeviceMonitor_pollFailure_Backoff_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollBackoffAfter:       ptr(3),
+			PollBackoffTime:        ptr(200 * time.Millisecond),
+			PollBackoffNotify:      ptr(false),
+			PollBackoffStopMonitor: ptr(false),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx := t.Context()
+	err := errors.New("test error")
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 1, m.state.pollFailures)
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 2, m.state.pollFailures)
+
+	start := time.Now()
+	m.pollFailure(ctx, err)
+	elapsed := time.Since(start)
+	require.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	require.Equal(t, 0, m.state.pollFailures)
+}
+
+// jsonElementCount returns the JSON of a device with a count of elements (the first of a status).
+func jsonElementCount(count int, status0 int) string {
+	elements := make([]string, 0, count)
+	for i := range count {
+		status := 1
+		if i == 0 {
+			status = status0
+		}
+		elements = append(elements, fmt.Sprintf(`{"element_type":{"i":23},"element_number":%d,"status_descriptor":{"status":{"i":%d}}}`, i, status))
+	}
+
+	return `{"join_of_diagnostic_pages":{"element_list":[` + strings.Join(elements, ",") + `]}}`
+}
+
+// Expectation: poll should coalesce the removals of a drastic element count drop into a single alert,
+// while still alerting the other changes individually.
+func Test_DeviceMonitor_poll_ElementDrop_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:         ptr(1),
+			ElementDropThreshold: ptr(0.5),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		n,
+	)
+
+	runner.setResponse(jsonElementCount(4, 1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	runner.setResponse(jsonElementCount(1, 2), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	calls := n.getCalls()
+	require.Len(t, calls, 1)
+	require.Contains(t, buf.String(), "Enclosure partially offline: element count dropped from 4 to 1 (75%), "+
+		"with 3 elements disappeared (type 23: 3)")
+	require.Contains(t, calls[0], "element count dropped from 4 to 1 (75%)")
+	require.Contains(t, calls[0], "severity=critical")
+	require.Contains(t, calls[0], "23#0")
+	require.NotContains(t, calls[0], "23#1")
+}
+
+// Expectation: poll should hold back the removals of an element count drop for the ChangeDebounce polls,
+// before raising their single critical change as any other change (recorded in the change report).
+func Test_DeviceMonitor_poll_ElementDropDebounce_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:         ptr(1),
+			OutputDir:            ptr("/output"),
+			ChangeDebounce:       ptr(2),
+			ElementDropThreshold: ptr(0.5),
+		},
+		fsys,
+		runner,
+		slog.New(slog.DiscardHandler),
+		n,
+	)
+
+	runner.setResponse(jsonElementCount(4, 1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	runner.setResponse(jsonElementCount(1, 1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+	require.Zero(t, n.callCount())
+
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+	require.Equal(t, 1, n.callCount())
+	require.Contains(t, n.getCalls()[0], "element count dropped from 4 to 1 (75%)")
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+
+	var report []byte
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "change-") {
+			report, err = afero.ReadFile(fsys, "/output/"+f.Name())
+			require.NoError(t, err)
+		}
+	}
+	require.Contains(t, string(report), `"id": "element-drop"`)
+	require.Contains(t, string(report), `"severity": "critical"`)
+	require.NotContains(t, string(report), `"23#1"`)
+}
+
+// Expectation: poll should use the canonical status descriptions, so that differing texts
+// of the fetching tool (for the same status) are consistent across polls.
+func Test_DeviceMonitor_poll_CanonicalStatusMeanings_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonMeaning := func(meaning string) string {
+		return `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,` +
+			`"status_descriptor":{"status":{"i":3,"meaning":"` + meaning + `"}}}]}}`
+	}
+
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:            ptr(1),
+			CanonicalStatusMeanings: ptr(true),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		n,
+	)
+
+	runner.setResponse(jsonMeaning("Non-critical"), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	require.Equal(t, "Noncritical", *m.state.previousResults["23#0"].StatusDesc)
+
+	runner.setResponse(jsonMeaning("Warning"), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	require.Zero(t, n.callCount())
+	require.Equal(t, "Noncritical", *m.state.previousResults["23#0"].StatusDesc)
+}
+
+// Expectation: poll should alert the removals individually, when the element count drop is below the threshold.
+func Test_DeviceMonitor_poll_ElementDropBelowThreshold_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:         ptr(1),
+			ElementDropThreshold: ptr(0.5),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		n,
+	)
+
+	runner.setResponse(jsonElementCount(4, 1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	runner.setResponse(jsonElementCount(3, 1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	require.Equal(t, 1, n.callCount())
+	require.Contains(t, n.getCalls()[0], "23#3")
+	require.NotContains(t, buf.String(), "Enclosure partially offline")
+}
+
+// Expectation: After a back-off period, poll should raise the net differences as a single summary
+// alert (bypassing the debounce) when backoff_reset_baseline is enabled, then resume normally.
+func Test_DeviceMonitor_pollFailure_BackoffResetBaseline_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status0, status1 int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[`+
+			`{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":%d}}},`+
+			`{"element_type":{"i":23},"element_number":1,"status_descriptor":{"status":{"i":%d}}}]}}`, status0, status1)
+	}
+
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:         ptr(1),
+			PollBackoffAfter:     ptr(1),
+			PollBackoffTime:      ptr(10 * time.Millisecond),
+			PollBackoffNotify:    ptr(false),
+			ChangeDebounce:       ptr(2),
+			BackoffResetBaseline: ptr(true),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		n,
+	)
+
+	runner.setResponse(jsonStatus(1, 1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	m.pollFailure(t.Context(), errors.New("test error"))
+	require.True(t, m.state.resumedFromBackoff)
+
+	runner.setResponse(jsonStatus(2, 5), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	require.False(t, m.state.resumedFromBackoff)
+	require.Equal(t, 1, n.callCount())
+	require.Contains(t, n.getCalls()[0], "2 net changes since before the back-off")
+	require.Contains(t, buf.String(), "as fresh baseline (2 net changes since before the back-off)")
+
+	// Resuming normally, changes are again debounced.
+	runner.setResponse(jsonStatus(1, 5), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+	require.Equal(t, 1, n.callCount())
+}
+
+// Expectation: After a back-off period, poll should debounce the changes as usual
+// when backoff_reset_baseline is disabled.
+func Test_DeviceMonitor_pollFailure_BackoffNoResetBaseline_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[`+
+			`{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	n := newMockNotifier()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:      ptr(1),
+			PollBackoffAfter:  ptr(1),
+			PollBackoffTime:   ptr(10 * time.Millisecond),
+			PollBackoffNotify: ptr(false),
+			ChangeDebounce:    ptr(2),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		n,
+	)
+
+	runner.setResponse(jsonStatus(1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	m.pollFailure(t.Context(), errors.New("test error"))
+	require.False(t, m.state.resumedFromBackoff)
+
+	runner.setResponse(jsonStatus(2), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+	require.Equal(t, 0, n.callCount())
+}
+
+// Expectation: pollFailure should trigger backoff notification when configured.
+func Test_DeviceMonitor_pollFailure_Backoff_Notify_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollBackoffAfter:       ptr(3),
+			PollBackoffTime:        ptr(200 * time.Millisecond),
+			PollBackoffNotify:      ptr(true),
+			PollBackoffStopMonitor: ptr(false),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		n,
+	)
+
+	ctx := t.Context()
+	err := errors.New("test error")
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 1, m.state.pollFailures)
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 2, m.state.pollFailures)
+
+	start := time.Now()
+	m.pollFailure(ctx, err)
+	elapsed := time.Since(start)
+	require.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	require.Equal(t, 0, m.state.pollFailures)
+	require.Equal(t, 1, n.callCount())
+}
+
+// Expectation: pollFailure should trigger backoff monitor stop when configured.
+func Test_DeviceMonitor_pollFailure_Backoff_Stop_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollBackoffAfter:       ptr(3),
+			PollBackoffTime:        ptr(200 * time.Millisecond),
+			PollBackoffNotify:      ptr(true),
+			PollBackoffStopMonitor: ptr(true),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		n,
+	)
+
+	ctx := t.Context()
+	err := errors.New("test error")
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 1, m.state.pollFailures)
+
+	m.pollFailure(ctx, err)
+	require.Equal(t, 2, m.state.pollFailures)
+
+	m.pollFailure(ctx, err)
+
+	select {
+	case <-m.state.stop:
+		time.Sleep(500 * time.Millisecond)
+		require.Equal(t, 1, n.callCount())
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Monitoring did not stop once entering back-off period")
+	}
+}
+
+// Expectation: pollFailure should stop backoff when context is cancelled.
+func Test_DeviceMonitor_pollFailure_Backoff_ContextCancelled_Error(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollBackoffAfter:       ptr(1),
+			PollBackoffTime:        ptr(10 * time.Second),
+			PollBackoffNotify:      ptr(false),
+			PollBackoffStopMonitor: ptr(false),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	err := errors.New("test error")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	m.pollFailure(ctx, err)
+	elapsed := time.Since(start)
+
+	require.Less(t, elapsed, 5*time.Second)
+}
+
+// Expectation: pollFailure should return immediately when context is already cancelled.
+func Test_DeviceMonitor_pollFailure_ContextCancelledBefore_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	pollBackoffAfter := 3
+	dm := &DeviceMonitor{
+		logger: logger,
+		cfg: &DeviceMonitorConfig{
+			PollBackoffAfter: &pollBackoffAfter,
+		},
+		state: newDeviceMonitorState(),
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	testErr := errors.New("test error")
+	dm.pollFailure(ctx, testErr)
+
+	require.Equal(t, 0, dm.state.pollFailures)
+	require.Empty(t, buf.String())
+}
+
+// Expectation: pollFailure should return immediately when stop channel is closed before call.
+func Test_DeviceMonitor_pollFailure_StopChannelClosedBefore_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	pollBackoffAfter := 3
+	dm := &DeviceMonitor{
+		logger: logger,
+		cfg: &DeviceMonitorConfig{
+			PollBackoffAfter: &pollBackoffAfter,
+		},
+		state: newDeviceMonitorState(),
+	}
+
+	close(dm.state.stop)
+
+	testErr := errors.New("test error")
+	dm.pollFailure(t.Context(), testErr)
+
+	require.Equal(t, 0, dm.state.pollFailures)
+	require.Empty(t, buf.String())
+}
+
+// Expectation: pollFailure should return when stop channel is closed during backoff wait.
+func Test_DeviceMonitor_pollFailure_StopChannelClosedDuringBackoff_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	pollBackoffAfter := 1
+	pollBackoffTime := 5 * time.Second
+	pollBackoffStopMonitor := false
+	dm := &DeviceMonitor{
+		logger: logger,
+		cfg: &DeviceMonitorConfig{
+			PollBackoffAfter:       &pollBackoffAfter,
+			PollBackoffTime:        &pollBackoffTime,
+			PollBackoffStopMonitor: &pollBackoffStopMonitor,
+		},
+		state: newDeviceMonitorState(),
+	}
+
+	testErr := errors.New("test error")
+
+	done := make(chan struct{})
+	go func() {
+		dm.pollFailure(t.Context(), testErr)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	close(dm.state.stop)
+
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("pollFailure did not return after stop channel closure")
+	case <-done:
+	}
+
+	require.Equal(t, 1, dm.state.pollFailures)
+	output := buf.String()
+	require.Contains(t, output, "entering 5s back-off")
+}
+
+// Expectation: pollFailure should call Stop when PollBackoffStopMonitor is true.
+func Test_DeviceMonitor_pollFailure_BackoffStopMonitor_CallsStop_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	pollBackoffAfter := 1
+	pollBackoffStopMonitor := true
+	dm := &DeviceMonitor{
+		logger: logger,
+		cfg: &DeviceMonitorConfig{
+			PollBackoffAfter:       &pollBackoffAfter,
+			PollBackoffStopMonitor: &pollBackoffStopMonitor,
+		},
+		state: newDeviceMonitorState(),
+	}
+
+	testErr := errors.New("test error")
+	dm.pollFailure(t.Context(), testErr)
+
+	select {
+	case <-dm.state.stop:
+	default:
+		t.Fatal("Stop() was not called")
+	}
+
+	output := buf.String()
+	require.Contains(t, output, "stopping device monitor")
+}
+
+// Expectation: tick should update the status of the monitor and call the status hook.
+func Test_DeviceMonitor_tick_Status_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:        ptr(1),
+			PollAttemptInterval: ptr(10 * time.Millisecond),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	var hooks atomic.Int32
+	m.onStatus = func() { hooks.Add(1) }
+
+	m.tick(t.Context())
+
+	status := m.Status()
+	require.Equal(t, "/dev/sg25", status.Device.Path)
+	require.NotEmpty(t, status.LastPollAt)
+	require.Empty(t, status.LastPollError)
+	require.Len(t, status.Results, 1)
+	require.Zero(t, status.PollFailures)
+	require.Equal(t, int32(1), hooks.Load())
+}
+
+// Expectation: tick should reflect poll failures and back-off periods in the status.
+func Test_DeviceMonitor_tick_StatusFailure_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errors.New("sg_ses failure"))
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:           ptr(1),
+			PollAttemptInterval:    ptr(10 * time.Millisecond),
+			PollBackoffAfter:       ptr(2),
+			PollBackoffTime:        ptr(100 * time.Millisecond),
+			PollBackoffNotify:      ptr(false),
+			PollBackoffStopMonitor: ptr(false),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	var sawBackoff atomic.Bool
+	m.onStatus = func() {
+		if s := m.Status(); s.InBackoff && s.BackoffUntil != "" && s.PollFailures == 2 {
+			sawBackoff.Store(true)
+		}
+	}
+
+	m.tick(t.Context())
+
+	status := m.Status()
+	require.Contains(t, status.LastPollError, "sg_ses failure")
+	require.Equal(t, 1, status.PollFailures)
+	require.False(t, status.InBackoff)
+
+	m.tick(t.Context())
+
+	status = m.Status()
+	require.True(t, sawBackoff.Load())
+	require.Zero(t, status.PollFailures)
+	require.False(t, status.InBackoff)
+	require.Empty(t, status.BackoffUntil)
+}
+
+// Expectation: jitter should be capped to the poll interval and use the injected source.
+func Test_DeviceMonitor_jitter_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval: ptr(time.Minute),
+			PollJitter:   ptr(time.Hour),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	var received time.Duration
+	m.randDuration = func(n time.Duration) time.Duration {
+		received = n
+
+		return n / 2
+	}
+
+	require.Equal(t, 30*time.Second, m.jitter())
+	require.Equal(t, time.Minute, received)
+
+	m.randDuration = nil
+	for range 100 {
+		j := m.jitter()
+		require.GreaterOrEqual(t, j, time.Duration(0))
+		require.Less(t, j, time.Minute)
+	}
+}
+
+// Expectation: jitter should be zero when no jitter is configured.
+func Test_DeviceMonitor_jitter_Disabled_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	m.randDuration = func(_ time.Duration) time.Duration {
+		t.Error("source should not be called without jitter")
+
+		return 0
+	}
+
+	require.Zero(t, m.jitter())
+}
+
+// Expectation: Start should delay the first poll by the jitter.
+func Test_DeviceMonitor_Start_Jitter_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("{}", "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval: ptr(time.Hour),
+			PollJitter:   ptr(time.Minute),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	m.randDuration = func(_ time.Duration) time.Duration {
+		return 300 * time.Millisecond
+	}
+
+	start := time.Now()
+	m.Start(t.Context())
+	defer func() {
+		m.Stop()
+		<-m.Done()
+	}()
+
+	require.Eventually(t, func() bool {
+		return runner.callCount() > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	require.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+}
+
+// Expectation: fetchFromDevice should wait for a free slot of the shared poll semaphore.
+func Test_DeviceMonitor_fetchFromDevice_PollSemaphore_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("{}", "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	m.pollSem = make(chan struct{}, 1)
+	m.pollSem <- struct{}{}
+
+	fetched := make(chan error, 1)
+	go func() {
+		_, _, _, err := m.fetchFromDevice(t.Context())
+		fetched <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, 0, runner.callCount())
+
+	<-m.pollSem
+	require.NoError(t, <-fetched)
+
+	require.Equal(t, 1, runner.callCount())
+	require.Empty(t, m.pollSem)
+}
+
+// Expectation: fetchFromDevice should respect context cancellation while waiting for a poll slot.
+func Test_DeviceMonitor_fetchFromDevice_PollSemaphoreCtxCancel_Error(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("{}", "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	m.pollSem = make(chan struct{}, 1)
+	m.pollSem <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	_, _, _, err := m.fetchFromDevice(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 0, runner.callCount())
+}
+
+// Expectation: fetchFromDevice should stop waiting for a poll slot when the monitor is stopped.
+func Test_DeviceMonitor_fetchFromDevice_PollSemaphoreStopped_Error(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("{}", "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	m.pollSem = make(chan struct{}, 1)
+	m.pollSem <- struct{}{}
+	m.Stop()
+
+	_, _, _, err := m.fetchFromDevice(t.Context())
+	require.ErrorIs(t, err, errMonitorStopped)
+	require.Equal(t, 0, runner.callCount())
+}
+
+// Expectation: fetchFromDevice should trim (and log) a preamble preceding the JSON output when tolerated.
+func Test_DeviceMonitor_fetchFromDevice_ToleratePreamble_Success(t *testing.T) {
+	t.Parallel()
+
+	var logBuf safeBuffer
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("warning: firmware quirk\n{}", "", nil)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{ToleratePreamble: ptr(true), Verbose: ptr(true)},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&logBuf, false, true),
+		nil,
+	)
+
+	by, _, _, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "{}", string(by))
+	require.True(t, runner.lastConfig().TrimPreamble)
+	require.Contains(t, logBuf.String(), `Trimmed preamble text preceding the JSON output: "warning: firmware quirk\n"`)
+}
+
+// Expectation: fetchFromDevice should trim a preamble of JSON file devices when tolerated.
+func Test_DeviceMonitor_fetchFromDevice_ToleratePreambleFile_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/test.json", []byte("warning: firmware quirk\n{}"), 0o644))
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/test.json"},
+		&DeviceMonitorConfig{PollAttempts: ptr(1)},
+		fs,
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	_, _, _, err := m.fetchFromDevice(t.Context())
+	require.ErrorIs(t, err, errInvalidJSON)
+
+	m.cfg.ToleratePreamble = ptr(true)
+	by, _, _, err := m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "{}", string(by))
+}
+
+// Expectation: poll should not notify about alerts in maintenance mode (but still log them).
+func Test_DeviceMonitor_poll_Maintenance_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+	var buf safeBuffer
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	m.SetMaintenance(true)
+	require.True(t, m.Status().Maintenance)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonBad} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.False(t, notifier.waitForNotification(200*time.Millisecond))
+	require.Contains(t, buf.String(), "Maintenance mode entered")
+	require.Contains(t, buf.String(), "Alert:")
+	require.Contains(t, buf.String(), "in maintenance mode - skipping notification")
+
+	m.SetMaintenance(false)
+	require.False(t, m.Status().Maintenance)
+	require.Contains(t, buf.String(), "Maintenance mode exited")
+
+	runner.setResponse(jsonGood, "", nil)
+	require.NoError(t, m.poll(ctx))
+	require.True(t, notifier.waitForNotification(2*time.Second))
+}
+
+// Expectation: poll should not notify about (or report) changes of suppressed element types.
+func Test_DeviceMonitor_poll_SuppressTypes_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":16},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":16},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	fs := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			SuppressTypes: []int{16},
+			OutputDir:     ptr("/output"),
+		},
+		fs,
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonBad} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.False(t, notifier.waitForNotification(200*time.Millisecond))
+
+	files, err := afero.ReadDir(fs, "/output")
+	require.NoError(t, err)
+	for _, f := range files {
+		require.False(t, strings.HasPrefix(f.Name(), "change-"))
+	}
+
+	parsed, err := afero.ReadFile(fs, "/output/current_parsed.json")
+	require.NoError(t, err)
+	require.Contains(t, string(parsed), `"16#0"`)
+}
+
+// Expectation: poll should ignore elements of types not in MonitorTypes (before applying SuppressTypes).
+func Test_DeviceMonitor_poll_MonitorTypes_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[` +
+		`{"element_type":{"i":2},"element_number":0,"status_descriptor":{"status":{"i":1}}},` +
+		`{"element_type":{"i":16},"element_number":0,"status_descriptor":{"status":{"i":1}}},` +
+		`{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[` +
+		`{"element_type":{"i":2},"element_number":0,"status_descriptor":{"status":{"i":2}}},` +
+		`{"element_type":{"i":16},"element_number":0,"status_descriptor":{"status":{"i":2}}},` +
+		`{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			MonitorTypes:  []int{2, 23},
+			SuppressTypes: []int{2},
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonBad} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.True(t, notifier.waitForNotification(2*time.Second))
+
+	calls := notifier.getCalls()
+	require.Len(t, calls, 1)
+	require.Contains(t, calls[0], "23#0")
+	require.NotContains(t, calls[0], "2#0")
+	require.NotContains(t, calls[0], "16#0")
+
+	require.Len(t, m.state.previousResults, 2)
+}
+
+// Expectation: poll should not alert for changes reverting within the ChangeDebounce polls.
+func Test_DeviceMonitor_poll_ChangeDebounce_Reverted_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":5}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{ChangeDebounce: ptr(3)},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonBad, jsonBad, jsonGood, jsonGood, jsonGood} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+
+	require.False(t, notifier.waitForNotification(200*time.Millisecond))
+	require.Empty(t, m.state.pendingChanges)
+}
+
+// Expectation: poll should alert once a change has persisted for the ChangeDebounce polls.
+func Test_DeviceMonitor_poll_ChangeDebounce_Persisted_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":5}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{ChangeDebounce: ptr(3)},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonBad, jsonBad} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+	require.False(t, notifier.waitForNotification(200*time.Millisecond))
+
+	runner.setResponse(jsonBad, "", nil)
+	require.NoError(t, m.poll(ctx))
+	require.True(t, notifier.waitForNotification(2*time.Second))
+	require.Equal(t, 1, notifier.callCount())
+	require.Empty(t, m.state.pendingChanges)
+}
+
+// Expectation: poll should not alert for changes into transient statuses reverting within the TransientDebounce polls,
+// while changes into other statuses are alerted right away.
+func Test_DeviceMonitor_poll_TransientStatuses_Reverted_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonGone := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":5}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{TransientStatuses: []int{5}, TransientDebounce: ptr(3)},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonGone, jsonGone, jsonGood} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+	m.state.notifications.Wait()
+	require.Equal(t, 0, notifier.callCount())
+	require.Empty(t, m.state.pendingChanges)
+
+	runner.setResponse(jsonBad, "", nil)
+	require.NoError(t, m.poll(ctx))
+	m.state.notifications.Wait()
+	require.Equal(t, 1, notifier.callCount())
+}
+
+// Expectation: poll should alert for changes into transient statuses once persisting for the TransientDebounce polls,
+// and should alert their recovery then, while recoveries out of transient statuses not alerted clear silently.
+func Test_DeviceMonitor_poll_TransientStatuses_Persisted_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonGone := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":5}}}]}}`
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{TransientStatuses: []int{5}, TransientDebounce: ptr(2)},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ctx := t.Context()
+	for _, out := range []string{jsonGood, jsonGone, jsonGone} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+	m.state.notifications.Wait()
+	require.Equal(t, 1, notifier.callCount())
+	require.Contains(t, m.state.transientAlerts, "23#0")
+
+	runner.setResponse(jsonGood, "", nil)
+	require.NoError(t, m.poll(ctx))
+	m.state.notifications.Wait()
+	require.Equal(t, 2, notifier.callCount())
+	require.Contains(t, notifier.getCalls()[1], "23#0")
+	require.Empty(t, m.state.transientAlerts)
+
+	// An element in a transient status since the initial poll clears silently.
+	m.state.previousResults = nil
+	for _, out := range []string{jsonGone, jsonGood} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(ctx))
+	}
+	m.state.notifications.Wait()
+	require.Equal(t, 2, notifier.callCount())
+}
+
+// Expectation: poll should write timestamped snapshots only on polls with changes (unless every poll).
+func Test_DeviceMonitor_poll_SnapshotHistory_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonGood := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`
+	jsonBad := `{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":2}}}]}}`
+
+	for _, everyPoll := range []bool{false, true} {
+		fs := afero.NewMemMapFs()
+		runner := &mockCommandRunner{}
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				OutputDir:         ptr("/output"),
+				SnapshotHistory:   ptr(5),
+				SnapshotEveryPoll: ptr(everyPoll),
+			},
+			fs,
+			runner,
+			slog.New(slog.DiscardHandler),
+			nil,
+		)
+
+		snapshots := func() int {
+			files, err := afero.ReadDir(fs, "/output")
+			require.NoError(t, err)
+
+			var n int
+			for _, f := range files {
+				if strings.HasPrefix(f.Name(), "snapshot-") {
+					n++
+				}
+			}
+
+			return n
+		}
+
+		ctx := t.Context()
+		runner.setResponse(jsonGood, "", nil)
+		require.NoError(t, m.poll(ctx))
+		require.NoError(t, m.poll(ctx))
+		if everyPoll {
+			require.NotZero(t, snapshots())
+		} else {
+			require.Zero(t, snapshots())
+		}
+
+		runner.setResponse(jsonBad, "", nil)
+		require.NoError(t, m.poll(ctx))
+		require.NotZero(t, snapshots())
+	}
+}
+
+// Expectation: SetMaintenance should only log (and signal) actual changes of the maintenance mode.
+func Test_DeviceMonitor_SetMaintenance_Idempotent_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	var statusCalls atomic.Int32
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		nil,
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		NewLogger(&buf, false, true),
+		nil,
+	)
+	m.onStatus = func() { statusCalls.Add(1) }
+
+	m.SetMaintenance(false)
+	m.SetMaintenance(true)
+	m.SetMaintenance(true)
+
+	require.Equal(t, int32(1), statusCalls.Load())
+	require.Equal(t, 1, strings.Count(buf.String(), "Maintenance mode"))
+}
+
+// Expectation: pollFailure should not notify about entering back-off in maintenance mode.
+func Test_DeviceMonitor_pollFailure_Backoff_Maintenance_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newMockNotifier()
+	var buf safeBuffer
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollBackoffAfter:  ptr(1),
+			PollBackoffTime:   ptr(10 * time.Millisecond),
+			PollBackoffNotify: ptr(true),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		NewLogger(&buf, false, true),
+		n,
+	)
+	m.SetMaintenance(true)
+
+	m.pollFailure(t.Context(), errors.New("test error"))
+
+	require.False(t, n.waitForNotification(200*time.Millisecond))
+	require.Contains(t, buf.String(), "Back-off occurred in maintenance mode - skipping notification")
+}
+
+// Expectation: RunOnce should compare against the previous results persisted in the output folder.
+func Test_DeviceMonitor_RunOnce_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput1 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`
+
+	jsonOutput2 := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 2, "meaning": "Critical"}}
+				}
+			]
+		}
+	}`
+
+	for _, compress := range []bool{false, true} {
+		fsys := afero.NewMemMapFs()
+		runner := &mockCommandRunner{}
+		notifier := newMockNotifier()
+
+		newMonitor := func() *DeviceMonitor {
+			return newTestDeviceMonitor(t,
+				Device{Type: 0, Path: "/dev/sg25"},
+				&DeviceMonitorConfig{
+					PollAttempts:   ptr(1),
+					OutputDir:      ptr("/output"),
+					OutputCompress: ptr(compress),
+				},
+				fsys,
+				runner,
+				slog.New(slog.DiscardHandler),
+				notifier,
+			)
+		}
+
+		runner.setResponse(jsonOutput1, "", nil)
+		require.NoError(t, newMonitor().RunOnce(t.Context()))
+		require.Equal(t, 0, notifier.callCount())
+
+		runner.setResponse(jsonOutput1, "", nil)
+		require.NoError(t, newMonitor().RunOnce(t.Context()))
+		require.Equal(t, 0, notifier.callCount())
+
+		runner.setResponse(jsonOutput2, "", nil)
+		require.NoError(t, newMonitor().RunOnce(t.Context()))
+		require.Equal(t, 1, notifier.callCount())
+		require.Contains(t, notifier.getCalls()[0], "15#0")
+	}
+}
+
+// Expectation: RunOnce should alert on temperatures exceeding thresholds on the first run,
+// but not again on the following run (where they already exceeded these on the previous run).
+func Test_DeviceMonitor_RunOnce_Temperature_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonOutput := `{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 4, "meaning": "Temperature sensor"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}, "temperature": {"i": 50, "meaning": "50 C"}}
+				}
+			]
+		}
+	}`
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	runner.setResponse(jsonOutput, "", nil)
+	notifier := newMockNotifier()
+
+	for range 2 {
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts: ptr(1),
+				TempWarn:     ptr(40),
+				OutputDir:    ptr("/output"),
+			},
+			fsys,
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+		require.NoError(t, m.RunOnce(t.Context()))
+		require.Equal(t, 1, notifier.callCount())
+	}
+
+	require.Contains(t, notifier.getCalls()[0], "temperature 50 C reached warning threshold")
+}
+
+// Expectation: RunOnce should return an error when the device poll has failed.
+func Test_DeviceMonitor_RunOnce_Error(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errInvalidJSON)
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts: ptr(1),
+			OutputDir:    ptr("/output"),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	err := m.RunOnce(t.Context())
+	require.ErrorIs(t, err, errPollFailed)
+	require.Contains(t, err.Error(), "/dev/sg25")
+}
+
+// Expectation: PollStats should count the polls, successful polls and retries of the monitor.
+func Test_DeviceMonitor_PollStats_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/dump.json", []byte("invalid"), 0o644))
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 1, Path: "/tmp/dump.json"},
+		&DeviceMonitorConfig{
+			PollAttempts:        ptr(3),
+			PollAttemptInterval: ptr(time.Duration(0)),
+			Verbose:             ptr(true),
+		},
+		fsys,
+		&mockCommandRunner{},
+		NewLogger(&buf, false, true),
+		nil,
+	)
+
+	require.Equal(t, PollStats{}, m.PollStats())
+
+	m.tick(t.Context())
+
+	stats := m.PollStats()
+	require.Equal(t, 1, stats.TotalPolls)
+	require.Equal(t, 0, stats.SuccessfulPolls)
+	require.Equal(t, 2, stats.TotalRetries)
+	require.NotEmpty(t, stats.LastPollDuration)
+	require.Empty(t, stats.LastSuccessAt)
+
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/dump.json",
+		[]byte(`{"join_of_diagnostic_pages":{"element_list":[]}}`), 0o644))
+
+	m.tick(t.Context())
+
+	stats = m.PollStats()
+	require.Equal(t, 2, stats.TotalPolls)
+	require.Equal(t, 1, stats.SuccessfulPolls)
+	require.Equal(t, 2, stats.TotalRetries)
+	require.NotEmpty(t, stats.LastSuccessAt)
+	require.Equal(t, stats, m.Status().PollStats)
+
+	require.Contains(t, buf.String(), "Poll statistics: 2 polls (1 successful) with 2 retries")
+}
+
+// Expectation: isDuplicateAlert should suppress identical consecutive alerts until AlertDedupTTL expires.
+func Test_DeviceMonitor_isDuplicateAlert_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		hash     string
+		lastHash string
+		lastAt   time.Time
+		expected bool
+	}{
+		{"no previous alert", 0, "a", "", time.Time{}, false},
+		{"different alert", 0, "a", "b", time.Now(), false},
+		{"identical alert without ttl", 0, "a", "a", time.Now().Add(-24 * time.Hour), true},
+		{"identical alert within ttl", time.Hour, "a", "a", time.Now().Add(-time.Minute), true},
+		{"identical alert after ttl", time.Hour, "a", "a", time.Now().Add(-2 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := newTestDeviceMonitor(t,
+				Device{Type: 0, Path: "/dev/sg25"},
+				&DeviceMonitorConfig{AlertDedupTTL: ptr(tt.ttl)},
+				afero.NewMemMapFs(),
+				&mockCommandRunner{},
+				slog.New(slog.DiscardHandler),
+				nil,
+			)
+			m.state.lastAlertHash = tt.lastHash
+			m.state.lastAlertAt = tt.lastAt
+
+			require.Equal(t, tt.expected, m.isDuplicateAlert(tt.hash))
+		})
+	}
+}
+
+// Expectation: poll should raise an identical alert again once AlertDedupTTL has expired.
+func Test_DeviceMonitor_poll_AlertDedupTTL_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	for _, ttl := range []time.Duration{0, 10 * time.Millisecond} {
+		runner := &mockCommandRunner{}
+		notifier := newMockNotifier()
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:  ptr(1),
+				AlertDedupTTL: ptr(ttl),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+
+		runner.setResponse(jsonStatus(1), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		okResults := m.state.previousResults
+
+		runner.setResponse(jsonStatus(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		require.True(t, notifier.waitForNotification(2*time.Second))
+
+		time.Sleep(20 * time.Millisecond)
+
+		m.state.previousResults = okResults
+		require.NoError(t, m.poll(t.Context()))
+
+		if ttl == 0 {
+			require.False(t, notifier.waitForNotification(200*time.Millisecond))
+			require.Equal(t, 1, notifier.callCount())
+		} else {
+			require.True(t, notifier.waitForNotification(2*time.Second))
+			require.Equal(t, 2, notifier.callCount())
+		}
+	}
+}
+
+// Expectation: poll should log the health summary of the initial elements (with the breakdown only if verbose).
+func Test_DeviceMonitor_poll_InitialSummary_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[`+
+		`{"element_type":{"i":23,"meaning":"Array device slot"},"element_number":0,"status_descriptor":{"status":{"i":1}}},`+
+		`{"element_type":{"i":23,"meaning":"Array device slot"},"element_number":1,"status_descriptor":{"status":{"i":2}}}]}}`, "", nil)
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{PollAttempts: ptr(1)},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		nil,
+	)
+
+	require.NoError(t, m.poll(t.Context()))
+	require.Contains(t, buf.String(), "Retrieved 2 initial elements from SES-capable device (1 OK, 1 with problems)")
+	require.NotContains(t, buf.String(), "by type")
+}
+
+// Expectation: poll should only log the notifications in a dry run (while still writing change reports).
+func Test_DeviceMonitor_poll_DryRun_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts: ptr(1),
+			OutputDir:    ptr("/output"),
+		},
+		fsys,
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+	m.dryRun = true
+
+	runner.setResponse(jsonStatus(1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+
+	runner.setResponse(jsonStatus(2), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	require.Zero(t, notifier.callCount())
+	require.Contains(t, buf.String(), "Dry run - skipping notification through agent [mock_notifier]")
+
+	reports, err := afero.Glob(fsys, "/output/"+changeReportPrefix+"*")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+}
+
+// Expectation: poll should include the full parsed results in the change reports (only if configured).
+func Test_DeviceMonitor_poll_ReportResults_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[`+
+			`{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":%d}}},`+
+			`{"element_type":{"i":23},"element_number":0,"status_descriptor":{"status":{"i":1}}}]}}`, status)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		fsys := afero.NewMemMapFs()
+		runner := &mockCommandRunner{}
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:  ptr(1),
+				OutputDir:     ptr("/output"),
+				ReportResults: ptr(enabled),
+			},
+			fsys,
+			runner,
+			slog.New(slog.DiscardHandler),
+			newMockNotifier(),
+		)
+
+		runner.setResponse(jsonStatus(1), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+
+		runner.setResponse(jsonStatus(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+
+		reports, err := afero.Glob(fsys, "/output/"+changeReportPrefix+"*")
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+
+		data, err := afero.ReadFile(fsys, reports[0])
+		require.NoError(t, err)
+
+		var report ChangeReport
+		require.NoError(t, json.Unmarshal(data, &report))
+		require.Len(t, report.Changes, 1)
+
+		if enabled {
+			require.Len(t, report.Results, 2)
+			require.Equal(t, ptr(2), report.Results["15#0"].Status)
+			require.Contains(t, report.Results, "23#0")
+		} else {
+			require.Nil(t, report.Results)
+		}
+	}
+}
+
+// Expectation: poll should alert on unhealthy elements on the initial poll (only if configured).
+func Test_DeviceMonitor_poll_AlertOnStartUnhealthy_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":3,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		runner := &mockCommandRunner{}
+		notifier := newMockNotifier()
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:          ptr(1),
+				AlertOnStartUnhealthy: ptr(enabled),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+
+		runner.setResponse(jsonStatus(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+
+		calls := notifier.getCalls()
+		if !enabled {
+			require.Empty(t, calls)
+
+			continue
+		}
+		require.Len(t, calls, 1)
+		require.Contains(t, calls[0], `reason="unhealthy at start of monitoring"`)
+		require.Contains(t, calls[0], "severity=critical")
+		require.Contains(t, calls[0], "Before: (-)")
+
+		// The following polls only alert on changes again.
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+		require.Len(t, notifier.getCalls(), 1)
+	}
+}
+
+// Expectation: poll should alert on predicted failures as critical (only if configured).
+func Test_DeviceMonitor_poll_PrdFailCritical_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonPrdFail := func(prdfail int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":3,`+
+			`"status_descriptor":{"status":{"i":1},"prdfail":%d}}]}}`, prdfail)
+	}
+
+	for _, critical := range []bool{false, true} {
+		runner := &mockCommandRunner{}
+		notifier := newMockNotifier()
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:    ptr(1),
+				PrdFailCritical: ptr(critical),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+
+		runner.setResponse(jsonPrdFail(0), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+
+		runner.setResponse(jsonPrdFail(1), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+
+		calls := notifier.getCalls()
+		require.Len(t, calls, 1)
+		require.Contains(t, calls[0], `reason="predicted failure"`)
+		if critical {
+			require.Contains(t, calls[0], "severity=critical")
+		} else {
+			require.Contains(t, calls[0], "severity=warning")
+		}
+	}
+}
+
+// Expectation: poll should notify with compact messages (and log all fields if verbose) if configured.
+func Test_DeviceMonitor_poll_CompactMessages_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":23},"element_number":3,`+
+			`"descriptor":"Slot 03","status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	for _, verbose := range []bool{false, true} {
+		runner := &mockCommandRunner{}
+		notifier := newMockNotifier()
+
+		var buf safeBuffer
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:    ptr(1),
+				CompactMessages: ptr(true),
+				Verbose:         ptr(verbose),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			NewLogger(&buf, false, true),
+			notifier,
+		)
+
+		runner.setResponse(jsonStatus(1), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+
+		runner.setResponse(jsonStatus(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+
+		calls := notifier.getCalls()
+		require.Len(t, calls, 1)
+		require.Contains(t, calls[0], "[Slot 03: 1 -> 2]")
+		require.NotContains(t, calls[0], "prdfail=")
+
+		if verbose {
+			require.Contains(t, buf.String(), "Changes in full: [element=")
+		} else {
+			require.NotContains(t, buf.String(), "Changes in full")
+		}
+	}
+}
+
+// Expectation: poll should warn about skipped elements whenever their amount changes
+// (and with their raw indices on every poll when verbose).
+func Test_DeviceMonitor_poll_SkippedElements_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonSkipped := func(skipped int) string {
+		elements := `{"element_type":{"i":15},"element_number":0,"status_descriptor":{"status":{"i":1}}}`
+		for range skipped {
+			elements += `,{"element_type":{"meaning":"Unknown"},"element_number":1}`
+		}
+
+		return `{"join_of_diagnostic_pages":{"element_list":[` + elements + `]}}`
+	}
+
+	for _, verbose := range []bool{false, true} {
+		runner := &mockCommandRunner{}
+
+		var buf safeBuffer
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts: ptr(1),
+				Verbose:      ptr(verbose),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			NewLogger(&buf, false, true),
+			newMockNotifier(),
+		)
+
+		runner.setResponse(jsonSkipped(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		require.NoError(t, m.poll(t.Context()))
+		require.Len(t, m.state.previousResults, 1)
+
+		if verbose {
+			require.Equal(t, 2, strings.Count(buf.String(),
+				"Skipped 2 elements without element type or number (not monitored) at raw indices [1 2]"))
+		} else {
+			require.Equal(t, 1, strings.Count(buf.String(),
+				"Skipped 2 elements without element type or number (not monitored)\n"))
+		}
+
+		runner.setResponse(jsonSkipped(0), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		require.Contains(t, buf.String(), "No longer skipping elements without element type or number")
+	}
+}
+
+// Expectation: poll should log and keep warnings (on standard error) of a successful device poll.
+func Test_DeviceMonitor_poll_Warnings_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts: ptr(1),
+			OutputDir:    ptr("/output"),
+			Verbose:      ptr(true),
+		},
+		fsys,
+		runner,
+		NewLogger(&buf, false, true),
+		newMockNotifier(),
+	)
+
+	runner.setResponse(jsonStatus(1), "", nil)
+	require.NoError(t, m.poll(t.Context()))
+	require.NotContains(t, buf.String(), "warnings")
+
+	runner.setResponse(jsonStatus(2), "sg_ses: warning: transport error\n", nil)
+	require.NoError(t, m.poll(t.Context()))
+	m.state.notifications.Wait()
+
+	require.Contains(t, buf.String(), `Device poll succeeded with warnings on standard error: "sg_ses: warning: transport error"`)
+
+	for _, file := range []string{"/output/current.json", "/output/current_parsed.json"} {
+		var snapshot DeviceSnapshot
+		data, err := afero.ReadFile(fsys, file)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &snapshot))
+		require.Equal(t, "sg_ses: warning: transport error", snapshot.Warnings, file)
+	}
+
+	reports, err := afero.Glob(fsys, "/output/"+changeReportPrefix+"*")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	var report ChangeReport
+	data, err := afero.ReadFile(fsys, reports[0])
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &report))
+	require.Equal(t, "sg_ses: warning: transport error", report.Warnings)
+}
+
+// Expectation: poll should log a structured change event for detected changes (only if configured).
+func Test_DeviceMonitor_poll_LogChangeEvents_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		runner := &mockCommandRunner{}
+
+		var buf safeBuffer
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25", Address: "0x5000"},
+			&DeviceMonitorConfig{
+				PollAttempts:    ptr(1),
+				LogChangeEvents: ptr(enabled),
+			},
+			afero.NewMemMapFs(),
+			runner,
+			NewLogger(&buf, false, true),
+			newMockNotifier(),
+		)
+
+		runner.setResponse(jsonStatus(1), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+
+		runner.setResponse(jsonStatus(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+
+		if !enabled {
+			require.NotContains(t, buf.String(), "Change event:")
+
+			continue
+		}
+
+		var line string
+		for l := range strings.Lines(buf.String()) {
+			if strings.HasPrefix(l, "Change event: ") {
+				line = strings.TrimSpace(strings.TrimPrefix(l, "Change event: "))
+			}
+		}
+		require.NotEmpty(t, line)
+
+		var event ChangeEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		require.Equal(t, "/dev/sg25", event.Device)
+		require.Equal(t, "0x5000", event.Address)
+		require.Equal(t, SeverityCritical, event.Severity)
+		require.Len(t, event.Transitions, 1)
+		require.Equal(t, "15#0", event.Transitions[0].ID)
+		require.Equal(t, ptr(1), event.Transitions[0].Before)
+		require.Equal(t, ptr(2), event.Transitions[0].After)
+	}
+}
+
+// Expectation: poll should write only the kinds of output files that are enabled.
+func Test_DeviceMonitor_poll_WriteOutputs_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonStatus := func(status int) string {
+		return fmt.Sprintf(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+			`"status_descriptor":{"status":{"i":%d}}}]}}`, status)
+	}
+
+	for _, tt := range []struct {
+		snapshots bool
+		reports   bool
+	}{
+		{snapshots: true, reports: true},
+		{snapshots: false, reports: true},
+		{snapshots: true, reports: false},
+		{snapshots: false, reports: false},
+	} {
+		fsys := afero.NewMemMapFs()
+		runner := &mockCommandRunner{}
+
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:       ptr(1),
+				OutputDir:          ptr("/output"),
+				OutputChangelog:    ptr(true),
+				WriteSnapshots:     ptr(tt.snapshots),
+				WriteChangeReports: ptr(tt.reports),
+			},
+			fsys,
+			runner,
+			slog.New(slog.DiscardHandler),
+			newMockNotifier(),
+		)
+
+		runner.setResponse(jsonStatus(1), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+
+		runner.setResponse(jsonStatus(2), "", nil)
+		require.NoError(t, m.poll(t.Context()))
+		m.state.notifications.Wait()
+
+		for _, file := range []string{"/output/current.json", "/output/current_parsed.json"} {
+			exists, err := afero.Exists(fsys, file)
+			require.NoError(t, err)
+			require.Equal(t, tt.snapshots, exists, file)
+		}
+
+		reports, err := afero.Glob(fsys, "/output/"+changeReportPrefix+"*")
+		require.NoError(t, err)
+		require.Equal(t, tt.reports, len(reports) == 1)
+
+		exists, err := afero.Exists(fsys, "/output/changelog.jsonl")
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+}
+
+// Expectation: RunOnce should not compare against stale snapshots if these are not written.
+func Test_DeviceMonitor_RunOnce_NoSnapshots_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/output/current_parsed.json",
+		[]byte(`{"device":{},"captured_at":"","raw":{"15#0":{"element_type":15,"status":1}}}`), 0o644))
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+		`"status_descriptor":{"status":{"i":2}}}]}}`, "", nil)
+
+	notifier := newMockNotifier()
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:   ptr(1),
+			OutputDir:      ptr("/output"),
+			WriteSnapshots: ptr(false),
+		},
+		fsys,
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	require.NoError(t, m.RunOnce(t.Context()))
+	require.Zero(t, notifier.callCount())
+	require.Contains(t, buf.String(), "No previous results were loaded (as write_snapshots is disabled)")
+}
+
+// Expectation: fetchFromDevice should recognize a device path that no longer exists.
+func Test_DeviceMonitor_fetchFromDevice_DeviceGone_Error(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{PollAttempts: ptr(1)},
+		fsys,
+		runner,
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+	require.NoError(t, fsys.Remove("/dev/sg25"))
+
+	_, _, _, err := m.fetchFromDevice(t.Context())
+	require.ErrorIs(t, err, errDeviceGone)
+	require.Zero(t, runner.callCount())
+
+	// A fetch command does not need the device path to exist (locally).
+	m.cfg.FetchCommand = ptr("/usr/local/bin/fetch")
+
+	_, _, _, err = m.fetchFromDevice(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 1, runner.callCount())
+}
+
+// Expectation: tick should alert once about a disappeared device, and once about its return.
+func Test_DeviceMonitor_tick_DeviceGone_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[{"element_type":{"i":15},"element_number":0,`+
+		`"status_descriptor":{"status":{"i":1}}}]}}`, "", nil)
+
+	notifier := newMockNotifier()
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:     ptr(1),
+			PollBackoffAfter: ptr(10),
+		},
+		fsys,
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	m.tick(t.Context())
+	require.NoError(t, fsys.Remove("/dev/sg25"))
+
+	m.tick(t.Context())
+	m.tick(t.Context())
+	m.state.notifications.Wait()
+
+	require.Contains(t, m.Status().LastPollError, errDeviceGone.Error())
+	require.Equal(t, []string{"Device gone: [/dev/sg25] no longer exists (unplugged or removed)"}, notifier.getCalls())
+	require.Equal(t, 2, strings.Count(buf.String(), "Error polling device"))
+
+	require.NoError(t, afero.WriteFile(fsys, "/dev/sg25", []byte{}, 0o644))
+
+	m.tick(t.Context())
+	m.tick(t.Context())
+	m.state.notifications.Wait()
+
+	require.Empty(t, m.Status().LastPollError)
+	require.Len(t, notifier.getCalls(), 2)
+	require.Equal(t, "Device back: [/dev/sg25] exists again (after having disappeared)", notifier.getCalls()[1])
+}
+
+// Expectation: tick should alert once about a poll failure rate at or above the threshold (also if not consecutive),
+// and be able to alert again only after the window was reset (by all of its polls having succeeded).
+func Test_DeviceMonitor_tick_PollFailureRate_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:          ptr(1),
+			PollBackoffAfter:      ptr(10),
+			PollFailureRateAlert:  ptr(0.5),
+			PollFailureRateWindow: ptr(3),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		notifier,
+	)
+
+	ok := func() { runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil) }
+	fail := func() { runner.setResponse("", "", errors.New("poll failure")) }
+
+	for _, outcome := range []func(){ok, fail, ok, fail} {
+		outcome()
+		m.tick(t.Context())
+	}
+	m.state.notifications.Wait()
+
+	require.Equal(t, []string{"Poll failure rate: 2 of the last 3 polls have failed (67%, at or above 50%)"}, notifier.getCalls())
+	require.InDelta(t, 2.0/3, *m.PollStats().RecentFailureRate, 0.001)
+
+	for _, outcome := range []func(){fail, ok, ok, ok} {
+		outcome()
+		m.tick(t.Context())
+	}
+	m.state.notifications.Wait()
+
+	require.Len(t, notifier.getCalls(), 1)
+	require.Contains(t, buf.String(), "Poll failure rate has recovered (no failures within the last 3 polls)")
+	require.Nil(t, m.state.pollWindow)
+
+	for _, outcome := range []func(){fail, fail, ok} {
+		outcome()
+		m.tick(t.Context())
+	}
+	m.state.notifications.Wait()
+
+	require.Len(t, notifier.getCalls(), 2)
+}
+
+// Expectation: tick should alert about a poll failure rate of the threshold 1 once all polls of the window have failed.
+func Test_DeviceMonitor_tick_PollFailureRate_ThresholdOne_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:          ptr(1),
+			PollBackoffAfter:      ptr(10),
+			PollFailureRateAlert:  ptr(1.0),
+			PollFailureRateWindow: ptr(3),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	ok := func() { runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil) }
+	fail := func() { runner.setResponse("", "", errors.New("poll failure")) }
+
+	for _, outcome := range []func(){ok, fail, fail} {
+		outcome()
+		m.tick(t.Context())
+	}
+	m.state.notifications.Wait()
+	require.Zero(t, notifier.callCount())
+
+	fail()
+	m.tick(t.Context())
+	m.state.notifications.Wait()
+
+	require.Equal(t, []string{"Poll failure rate: 3 of the last 3 polls have failed (100%, at or above 100%)"}, notifier.getCalls())
+}
+
+// Expectation: tick should neither track nor alert about the poll failure rate if disabled.
+func Test_DeviceMonitor_tick_PollFailureRate_Disabled_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errors.New("poll failure"))
+	notifier := newMockNotifier()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollAttempts:     ptr(1),
+			PollBackoffAfter: ptr(20),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		slog.New(slog.DiscardHandler),
+		notifier,
+	)
+
+	for range 12 {
+		m.tick(t.Context())
+	}
+	m.state.notifications.Wait()
+
+	require.Zero(t, notifier.callCount())
+	require.Nil(t, m.state.pollWindow)
+	require.Nil(t, m.PollStats().RecentFailureRate)
+}
+
+// Expectation: the on-stop command should run once monitoring has stopped (with a failure only logged).
+func Test_DeviceMonitor_Start_OnStopCommand_Success(t *testing.T) {
+	t.Parallel()
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errors.New("on-stop failure"))
+
+	var buf safeBuffer
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25", Address: "0x5000"},
+		&DeviceMonitorConfig{
+			PollInterval:     ptr(time.Hour),
+			PollAttempts:     ptr(1),
+			PollBackoffAfter: ptr(10),
+			OnStopCommand:    ptr("/usr/local/bin/on-stop"),
+			OnStopTimeout:    ptr(5 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		runner,
+		NewLogger(&buf, false, true),
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	m.Start(ctx)
+
+	require.Eventually(t, func() bool { return runner.callCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case <-m.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Monitor did not stop within timeout")
+	}
+
+	require.Equal(t, 2, runner.callCount())
+
+	cfg := runner.lastConfig()
+	require.Equal(t, "/usr/local/bin/on-stop", cfg.Command)
+	require.Equal(t, []string{"/dev/sg25", "0x5000"}, cfg.Args)
+	require.Equal(t, 1, cfg.Attempts)
+	require.Equal(t, 5*time.Second, cfg.AttemptTimeout)
+	require.Contains(t, buf.String(), "Error running on-stop command: on-stop failure")
+}
@@ -0,0 +1,362 @@
+package sesmon
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultMQTTBaseTopic is the base topic used if none is configured.
+	defaultMQTTBaseTopic = "sesmon"
+
+	// mqttDisconnectQuiesce is how long (in milliseconds) to wait for pending work on disconnect.
+	mqttDisconnectQuiesce = 250
+)
+
+// errMQTTNotConnected occurs when the MQTT broker connection cannot be (re-)established.
+var errMQTTNotConnected = errors.New("not connected to broker")
+
+// mqttBrokerSchemes are the accepted URL schemes of an MQTT broker.
+var mqttBrokerSchemes = []string{"tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss"}
+
+// MQTTNotifierConfig is the configuration for a [MQTTNotifier] implementation.
+type MQTTNotifierConfig struct {
+	// How often to attempt a notification (must be > 0).
+	NotifyAttempts *int `yaml:"notify_attempts"`
+
+	// How long a notification attempt can take (multiplies with attempts).
+	NotifyAttemptTimeout *time.Duration `yaml:"notify_attempt_timeout"`
+
+	// How long to wait between notification attempts (in case of failure).
+	NotifyAttemptInterval *time.Duration `yaml:"notify_attempt_interval"`
+
+	// Quality of service level to publish the messages with (0, 1 or 2).
+	QoS *int `yaml:"qos"`
+
+	// Publish the element status messages as retained messages.
+	Retain *bool `yaml:"retain"`
+
+	// Skip verification of the broker's TLS certificate.
+	TLSSkipVerify *bool `yaml:"tls_skip_verify"`
+}
+
+// MarshalJSON is a custom JSON marshaller for user readable [time.Duration] strings.
+func (c MQTTNotifierConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct { //nolint:wrapcheck
+		NotifyAttempts        *int    `json:"notify_attempts"`
+		NotifyAttemptTimeout  *string `json:"notify_attempt_timeout"`
+		NotifyAttemptInterval *string `json:"notify_attempt_interval"`
+		QoS                   *int    `json:"qos"`
+		Retain                *bool   `json:"retain"`
+		TLSSkipVerify         *bool   `json:"tls_skip_verify"`
+	}{
+		NotifyAttempts:        c.NotifyAttempts,
+		NotifyAttemptTimeout:  durPtrToStrPtr(c.NotifyAttemptTimeout),
+		NotifyAttemptInterval: durPtrToStrPtr(c.NotifyAttemptInterval),
+		QoS:                   c.QoS,
+		Retain:                c.Retain,
+		TLSSkipVerify:         c.TLSSkipVerify,
+	})
+}
+
+// UnmarshalYAML is a custom YAML unmarshaller accepting bare integers (as seconds) for durations.
+func (c *MQTTNotifierConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain MQTTNotifierConfig
+
+	return decodeYAMLDurations(value, (*plain)(c), reflect.TypeFor[MQTTNotifierConfig]())
+}
+
+// DefaultMQTTNotifierConfig returns a pointer to a default [MQTTNotifierConfig].
+//
+//nolint:mnd
+func DefaultMQTTNotifierConfig() *MQTTNotifierConfig {
+	return &MQTTNotifierConfig{
+		NotifyAttempts:        ptr(3),
+		NotifyAttemptTimeout:  ptr(15 * time.Second),
+		NotifyAttemptInterval: ptr(15 * time.Second),
+		QoS:                   ptr(1),
+		Retain:                ptr(true),
+		TLSSkipVerify:         ptr(false),
+	}
+}
+
+// MQTTElementStatus is the JSON message published per affected element by a [MQTTNotifier].
+type MQTTElementStatus struct {
+	Device     Device  `json:"device"`
+	DetectedAt string  `json:"detected_at"`
+	Kind       string  `json:"kind"`
+	Severity   string  `json:"severity"`
+	TypeDesc   *string `json:"element_type_desc,omitempty"`
+	Descriptor *string `json:"descriptor,omitempty"`
+	Status     *int    `json:"status,omitempty"`
+	StatusDesc *string `json:"status_desc,omitempty"`
+	Reason     *string `json:"reason,omitempty"`
+	Element    *Result `json:"element,omitempty"`
+}
+
+var _ Notifier = (*MQTTNotifier)(nil)
+
+// MQTTNotifier is a [Notifier] publishing to an MQTT broker. For a [ChangeReport],
+// a (retained) [MQTTElementStatus] is published per affected element to the topic
+// "<base>/<device>/<element>/status", any other notification message is published
+// (as text) to the topic "<base>/<device>/message". The broker connection is only
+// established on the first notification and automatically re-established if lost.
+type MQTTNotifier struct {
+	// URL of the MQTT broker (e.g. "tcp://broker:1883").
+	broker string
+
+	// Username to authenticate with at the broker (if not empty).
+	username string
+
+	// Base topic to publish the messages below.
+	baseTopic string
+
+	mu     sync.Mutex
+	client mqtt.Client
+	logger *slog.Logger
+
+	cfg *MQTTNotifierConfig
+}
+
+// NewMQTTNotifier returns a pointer to a new [MQTTNotifier].
+func NewMQTTNotifier(
+	broker string, username string, password string, baseTopic string,
+	cfg *MQTTNotifierConfig, logger *slog.Logger,
+) (*MQTTNotifier, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	if broker == "" {
+		return nil, fmt.Errorf("%w: no broker provided", errInvalidArgument)
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w: failure parsing broker url: %w", broker, errInvalidArgument, err)
+	}
+	if !slices.Contains(mqttBrokerSchemes, u.Scheme) || u.Host == "" {
+		return nil, fmt.Errorf("%q: %w: broker url needs to be scheme://host:port (scheme one of %v)",
+			broker, errInvalidArgument, mqttBrokerSchemes)
+	}
+
+	baseTopic = strings.Trim(fne(baseTopic, defaultMQTTBaseTopic), "/")
+	if baseTopic == "" || strings.ContainsAny(baseTopic, "+#") {
+		return nil, fmt.Errorf("%q: %w: base topic cannot be empty or contain wildcards", baseTopic, errInvalidArgument)
+	}
+
+	mcfg, err := mergeMQTTNotifierConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	clientID := make([]byte, 8) //nolint:mnd
+	_, _ = rand.Read(clientID)
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID("sesmon-" + hex.EncodeToString(clientID)).
+		SetUsername(username).
+		SetPassword(password).
+		SetTLSConfig(&tls.Config{InsecureSkipVerify: *mcfg.TLSSkipVerify}). //nolint:gosec
+		SetAutoReconnect(true).
+		SetConnectRetry(false).
+		SetConnectTimeout(*mcfg.NotifyAttemptTimeout).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Warn(fmt.Sprintf("%q: broker connection lost (reconnecting): %v", broker, err), LogEvent("mqtt_connection_lost"))
+		})
+
+	return &MQTTNotifier{
+		broker:    broker,
+		username:  username,
+		baseTopic: baseTopic,
+		client:    mqtt.NewClient(opts),
+		logger:    logger,
+		cfg:       mcfg,
+	}, nil
+}
+
+// Notify publishes the messages to the MQTT broker (connecting to it if needed).
+// It both observes and respects context cancellations for earlier notification terminations.
+func (n *MQTTNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	msgs, err := n.messages(device, message, extra)
+	if err != nil {
+		return fmt.Errorf("%q: failure building messages: %w", n.broker, err)
+	}
+
+	attempt, err := withRetries(
+		ctx,
+		func() error {
+			return n.publish(ctx, msgs)
+		},
+		func(attempt int, err error) {
+			n.logger.Warn(fmt.Sprintf("%q: [%d/%d] notification failure: %v",
+				n.broker, attempt, *n.cfg.NotifyAttempts, err), LogEvent("notify_retry"))
+		},
+		*n.cfg.NotifyAttempts,
+		*n.cfg.NotifyAttemptInterval,
+	)
+	if err != nil {
+		return fmt.Errorf("%q: [%d/%d] notification failure: %w",
+			n.broker, attempt, *n.cfg.NotifyAttempts, err)
+	}
+
+	return nil
+}
+
+// mqttMessage is a single message to be published by a [MQTTNotifier].
+type mqttMessage struct {
+	topic    string
+	retained bool
+	payload  []byte
+}
+
+// messages builds the messages to publish for a notification.
+func (n *MQTTNotifier) messages(device Device, message string, extra any) ([]mqttMessage, error) {
+	deviceTopic := n.baseTopic + "/" + mqttTopicSegment(fne(device.Address, filepath.Base(device.Path)))
+
+	var report ChangeReport
+	switch r := extra.(type) {
+	case ChangeReport:
+		report = r
+	case *ChangeReport:
+		if r == nil {
+			return []mqttMessage{{topic: deviceTopic + "/message", payload: []byte(message)}}, nil
+		}
+		report = *r
+	default:
+		return []mqttMessage{{topic: deviceTopic + "/message", payload: []byte(message)}}, nil
+	}
+
+	msgs := make([]mqttMessage, 0, len(report.Changes))
+	for _, ch := range report.Changes {
+		status := MQTTElementStatus{
+			Device:     device,
+			DetectedAt: report.DetectedAt,
+			Kind:       ch.Kind,
+			Severity:   changeSeverity(ch),
+			TypeDesc:   ch.TypeDesc,
+			Descriptor: ch.Descriptor,
+			Reason:     ch.Reason,
+			Element:    ch.After,
+		}
+		if ch.After != nil {
+			status.Status = ch.After.Status
+			status.StatusDesc = ch.After.StatusDesc
+		}
+
+		payload, err := json.Marshal(status)
+		if err != nil {
+			return nil, fmt.Errorf("failure marshalling to JSON: %w", err)
+		}
+
+		msgs = append(msgs, mqttMessage{
+			topic:    deviceTopic + "/" + mqttTopicSegment(ch.ID) + "/status",
+			retained: *n.cfg.Retain,
+			payload:  payload,
+		})
+	}
+
+	return msgs, nil
+}
+
+// publish is a single notification attempt, publishing all messages to the broker.
+func (n *MQTTNotifier) publish(ctx context.Context, msgs []mqttMessage) error {
+	reqCtx, reqCancel := context.WithTimeout(ctx, *n.cfg.NotifyAttemptTimeout)
+	defer reqCancel()
+
+	if err := n.connect(reqCtx); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		token := n.client.Publish(msg.topic, byte(*n.cfg.QoS), msg.retained, msg.payload) //nolint:gosec
+		if err := waitMQTTToken(reqCtx, token); err != nil {
+			return fmt.Errorf("failure publishing to %q: %w", msg.topic, err)
+		}
+	}
+
+	return nil
+}
+
+// connect establishes the broker connection, unless it is already established
+// (or currently being re-established automatically after a connection loss).
+func (n *MQTTNotifier) connect(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.client.IsConnected() {
+		return nil
+	}
+
+	if err := waitMQTTToken(ctx, n.client.Connect()); err != nil {
+		return fmt.Errorf("%w: %w", errMQTTNotConnected, err)
+	}
+
+	return nil
+}
+
+// Close disconnects from the broker (if connected).
+func (n *MQTTNotifier) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.client.IsConnected() {
+		n.client.Disconnect(mqttDisconnectQuiesce)
+	}
+}
+
+// Name returns the name of the notification agent as a string.
+func (n *MQTTNotifier) Name() string {
+	return "mqtt_notifier"
+}
+
+// Config returns the configuration of the notification agent as a string.
+// The password is never part of the output.
+func (n *MQTTNotifier) Config() string {
+	cfgJSON, err := json.Marshal(n.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+
+	return fmt.Sprintf("%q:%s:username=%s:topic=%q",
+		n.broker, cfgJSON, fne(n.username, "-"), n.baseTopic)
+}
+
+// waitMQTTToken waits for an [mqtt.Token] to complete, respecting context cancellations.
+func waitMQTTToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error() //nolint:wrapcheck
+	case <-ctx.Done():
+		return fmt.Errorf("context error: %w", ctx.Err())
+	}
+}
+
+// mqttTopicSegment returns a string usable as a single MQTT topic level,
+// replacing the level separator, wildcards and whitespace with underscores.
+func mqttTopicSegment(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', ' ', '\t':
+			return '_'
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,307 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMQTTToken struct {
+	done chan struct{}
+	err  error
+}
+
+func newMockMQTTToken(err error, completed bool) *mockMQTTToken {
+	t := &mockMQTTToken{done: make(chan struct{}), err: err}
+	if completed {
+		close(t.done)
+	}
+
+	return t
+}
+
+func (t *mockMQTTToken) Wait() bool {
+	<-t.done
+
+	return true
+}
+
+func (t *mockMQTTToken) WaitTimeout(d time.Duration) bool {
+	select {
+	case <-t.done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (t *mockMQTTToken) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *mockMQTTToken) Error() error {
+	return t.err
+}
+
+type mockMQTTPublish struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+type mockMQTTClient struct {
+	mqtt.Client
+
+	mu         sync.Mutex
+	connected  bool
+	connects   int
+	connectErr error
+	publishErr error
+	hang       bool
+	published  []mockMQTTPublish
+}
+
+func (c *mockMQTTClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.connected
+}
+
+func (c *mockMQTTClient) Connect() mqtt.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.connects++
+	if c.connectErr == nil {
+		c.connected = true
+	}
+
+	return newMockMQTTToken(c.connectErr, true)
+}
+
+func (c *mockMQTTClient) Disconnect(_ uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.connected = false
+}
+
+func (c *mockMQTTClient) Publish(topic string, qos byte, retained bool, payload any) mqtt.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, _ := payload.([]byte)
+	c.published = append(c.published, mockMQTTPublish{topic: topic, qos: qos, retained: retained, payload: b})
+
+	return newMockMQTTToken(c.publishErr, !c.hang)
+}
+
+func newTestMQTTNotifier(t *testing.T, client *mockMQTTClient, cfg *MQTTNotifierConfig) *MQTTNotifier {
+	t.Helper()
+
+	n, err := NewMQTTNotifier("tcp://broker:1883", "user", "pass", "", cfg, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	n.client = client
+
+	return n
+}
+
+// Expectation: MQTTNotifierConfig MarshalJSON should correctly serialize durations as strings.
+func Test_MQTTNotifierConfig_MarshalJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(DefaultMQTTNotifierConfig())
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	require.Equal(t, "15s", result["notify_attempt_timeout"])
+	require.Equal(t, "15s", result["notify_attempt_interval"])
+	require.InDelta(t, 1, result["qos"], 0.001)
+	require.Equal(t, true, result["retain"])
+}
+
+// Expectation: NewMQTTNotifier should successfully create notifier with a valid broker URL.
+func Test_NewMQTTNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewMQTTNotifier("tcp://broker:1883", "", "", "home/sesmon/", nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.Equal(t, "home/sesmon", n.baseTopic)
+	require.Equal(t, DefaultMQTTNotifierConfig(), n.cfg)
+}
+
+// Expectation: NewMQTTNotifier should return an error for invalid arguments.
+func Test_NewMQTTNotifier_InvalidArguments_Error(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	_, err := NewMQTTNotifier("", "", "", "", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewMQTTNotifier("http://broker:1883", "", "", "", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewMQTTNotifier("tcp://broker:1883", "", "", "sesmon/#", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewMQTTNotifier("tcp://broker:1883", "", "", "", &MQTTNotifierConfig{QoS: ptr(3)}, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewMQTTNotifier("tcp://broker:1883", "", "", "", nil, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: MQTTNotifier should connect once and publish a retained status per affected element.
+func Test_MQTTNotifier_Notify_Success(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{}
+	n := newTestMQTTNotifier(t, client, nil)
+
+	device := Device{Path: "/dev/sg25", Address: "0x500a0980"}
+	report := ChangeReport{
+		Device:     device,
+		DetectedAt: "now",
+		Changes: []Change{
+			{
+				ID: "23#0", Kind: ChangeKindDegraded, Descriptor: ptr("Slot 00"),
+				After: &Result{Status: ptr(sesStatusCritical), StatusDesc: ptr("Critical")},
+			},
+			{ID: "2#1", Kind: ChangeKindRecovered, After: &Result{Status: ptr(sesStatusOK)}},
+		},
+	}
+
+	require.NoError(t, n.Notify(t.Context(), device, "test message", report))
+	require.NoError(t, n.Notify(t.Context(), device, "test message", &report))
+
+	require.Equal(t, 1, client.connects)
+	require.Len(t, client.published, 4)
+
+	require.Equal(t, "sesmon/0x500a0980/23_0/status", client.published[0].topic)
+	require.Equal(t, "sesmon/0x500a0980/2_1/status", client.published[1].topic)
+	require.Equal(t, byte(1), client.published[0].qos)
+	require.True(t, client.published[0].retained)
+
+	var status MQTTElementStatus
+	require.NoError(t, json.Unmarshal(client.published[0].payload, &status))
+	require.Equal(t, ChangeKindDegraded, status.Kind)
+	require.Equal(t, SeverityCritical, status.Severity)
+	require.Equal(t, "Slot 00", *status.Descriptor)
+	require.Equal(t, sesStatusCritical, *status.Status)
+}
+
+// Expectation: MQTTNotifier should publish a non-retained message without a change report.
+func Test_MQTTNotifier_Notify_NoReport_Success(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{}
+	n := newTestMQTTNotifier(t, client, nil)
+
+	require.NoError(t, n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "Heartbeat: alive", nil))
+
+	require.Len(t, client.published, 1)
+	require.Equal(t, "sesmon/sg25/message", client.published[0].topic)
+	require.False(t, client.published[0].retained)
+	require.Equal(t, "Heartbeat: alive", string(client.published[0].payload))
+}
+
+// Expectation: MQTTNotifier should retry connecting and return an error after all attempts.
+func Test_MQTTNotifier_Notify_ConnectError(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{connectErr: errors.New("connection refused")}
+	n := newTestMQTTNotifier(t, client, &MQTTNotifierConfig{
+		NotifyAttempts:        ptr(2),
+		NotifyAttemptInterval: ptr(time.Millisecond),
+	})
+
+	err := n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", ChangeReport{})
+	require.ErrorIs(t, err, errMQTTNotConnected)
+	require.Equal(t, 2, client.connects)
+	require.Empty(t, client.published)
+}
+
+// Expectation: MQTTNotifier should return an error when publishing fails.
+func Test_MQTTNotifier_Notify_PublishError(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{publishErr: errors.New("publish failed")}
+	n := newTestMQTTNotifier(t, client, &MQTTNotifierConfig{NotifyAttempts: ptr(1)})
+
+	err := n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "publish failed")
+}
+
+// Expectation: MQTTNotifier should stop waiting for a publish on context cancellation.
+func Test_MQTTNotifier_Notify_ContextCancelled_Error(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{hang: true}
+	n := newTestMQTTNotifier(t, client, &MQTTNotifierConfig{NotifyAttempts: ptr(1)})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := n.Notify(ctx, Device{Path: "/dev/sg25"}, "test message", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+// Expectation: MQTTNotifier Close should disconnect from the broker.
+func Test_MQTTNotifier_Close_Success(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{}
+	n := newTestMQTTNotifier(t, client, nil)
+
+	require.NoError(t, n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil))
+	require.True(t, client.IsConnected())
+
+	n.Close()
+	require.False(t, client.IsConnected())
+}
+
+// Expectation: MQTTNotifier Config should not contain the password.
+func Test_MQTTNotifier_Config_Success(t *testing.T) {
+	t.Parallel()
+
+	n := newTestMQTTNotifier(t, &mockMQTTClient{}, nil)
+
+	require.Equal(t, "mqtt_notifier", n.Name())
+	require.Contains(t, n.Config(), "username=user")
+	require.NotContains(t, n.Config(), "pass\"")
+}
+
+// Expectation: mqttTopicSegment should replace separators, wildcards and whitespace.
+func Test_mqttTopicSegment_Success(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "23_0", mqttTopicSegment("23#0"))
+	require.Equal(t, "a_b_c_d", mqttTopicSegment("a/b+c d"))
+}
+
+// Expectation: MultiNotifier Close should close the contained closable notification agents.
+func Test_MultiNotifier_Close_Success(t *testing.T) {
+	t.Parallel()
+
+	client := &mockMQTTClient{connected: true}
+	multi, err := NewMultiNotifier(newMockNotifier(), newTestMQTTNotifier(t, client, nil))
+	require.NoError(t, err)
+
+	multi.Close()
+	require.False(t, client.IsConnected())
+}
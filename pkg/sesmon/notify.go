@@ -0,0 +1,466 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	executableModeMask = 0o111
+)
+
+// Alert levels of a [Change] (as used by notification agents for formatting).
+const (
+	alertLevelRecovery = iota
+	alertLevelWarning
+	alertLevelCritical
+)
+
+// errNotExecutable occurs when a target binary/script is not executable.
+var errNotExecutable = errors.New("not executable permissions")
+
+// Notifier is the contract for a notification agent as part of a [Program].
+type Notifier interface {
+	Notify(ctx context.Context, device Device, message string, extra any) error
+	Name() string
+	Config() string
+}
+
+// changeAlertLevel returns the alert level of a [Change] by its severity. The severities
+// [SeverityCritical] and [SeverityUnrecoverable] are considered as critical, recoveries as
+// such and any other degradation (including of [SeverityInfo]) as a warning.
+func changeAlertLevel(ch Change) int {
+	switch changeSeverity(ch) {
+	case SeverityOK:
+		return alertLevelRecovery
+	case SeverityCritical, SeverityUnrecoverable:
+		return alertLevelCritical
+	default:
+		return alertLevelWarning
+	}
+}
+
+// reportAlertLevel returns the highest alert level of the changes of a [ChangeReport].
+func reportAlertLevel(report ChangeReport) int {
+	level := alertLevelRecovery
+	for _, ch := range report.Changes {
+		level = max(level, changeAlertLevel(ch))
+	}
+
+	return level
+}
+
+// describableNotifier is a [Notifier] that can describe a notification without dispatching
+// it (e.g. for a dry run), such as with the full argv of a notification script.
+type describableNotifier interface {
+	Describe(device Device, message string, extra any) string
+}
+
+// describeNotification describes a notification of a [Notifier] without dispatching it.
+// For a [Notifier] not implementing [describableNotifier], the message is returned quoted.
+func describeNotification(notifier Notifier, device Device, message string, extra any) string {
+	if d, ok := notifier.(describableNotifier); ok {
+		return d.Describe(device, message, extra)
+	}
+
+	return strconv.Quote(message)
+}
+
+// closableNotifier is a [Notifier] holding resources (e.g. connections),
+// which are to be released once the notification agent is no longer needed.
+type closableNotifier interface {
+	Notifier
+	Close()
+}
+
+// ScriptNotifierConfig is the configuration for a [ScriptNotifier] implementation.
+type ScriptNotifierConfig struct {
+	// How often to attempt a notification (must be > 0).
+	NotifyAttempts *int `yaml:"notify_attempts"`
+
+	// How long a notification attempt can take (multiplies with attempts).
+	NotifyAttemptTimeout *time.Duration `yaml:"notify_attempt_timeout"`
+
+	// How long to wait between notification attempts (in case of failure).
+	NotifyAttemptInterval *time.Duration `yaml:"notify_attempt_interval"`
+
+	// Write the change report (JSON) to the script's standard input instead of
+	// passing it as the fifth argument (avoids limits on large argument sizes).
+	StdinPayload *bool `yaml:"stdin_payload"`
+
+	// Export the notification also as environment variables to the script.
+	// This is in addition to the positional arguments (which remain as-is).
+	ExportEnv *bool `yaml:"export_env"`
+
+	// Template (Go text/template) for the notification message, evaluated with
+	// a [MessageTemplateData] (empty = default message). Validated on load.
+	MessageTemplate *string `yaml:"message_template"`
+}
+
+// MarshalJSON is a custom JSON marshaller for user readable [time.Duration] strings.
+func (c ScriptNotifierConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct { //nolint:wrapcheck
+		NotifyAttempts        *int    `json:"notify_attempts"`
+		NotifyAttemptTimeout  *string `json:"notify_attempt_timeout"`
+		NotifyAttemptInterval *string `json:"notify_attempt_interval"`
+		StdinPayload          *bool   `json:"stdin_payload"`
+		ExportEnv             *bool   `json:"export_env"`
+		MessageTemplate       *string `json:"message_template"`
+	}{
+		NotifyAttempts:        c.NotifyAttempts,
+		NotifyAttemptTimeout:  durPtrToStrPtr(c.NotifyAttemptTimeout),
+		NotifyAttemptInterval: durPtrToStrPtr(c.NotifyAttemptInterval),
+		StdinPayload:          c.StdinPayload,
+		ExportEnv:             c.ExportEnv,
+		MessageTemplate:       c.MessageTemplate,
+	})
+}
+
+// UnmarshalYAML is a custom YAML unmarshaller accepting bare integers (as seconds) for durations.
+func (c *ScriptNotifierConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain ScriptNotifierConfig
+
+	return decodeYAMLDurations(value, (*plain)(c), reflect.TypeFor[ScriptNotifierConfig]())
+}
+
+// DefaultScriptNotifierConfig returns a pointer to a default [ScriptNotifierConfig].
+//
+//nolint:mnd
+func DefaultScriptNotifierConfig() *ScriptNotifierConfig {
+	return &ScriptNotifierConfig{
+		NotifyAttempts:        ptr(3),
+		NotifyAttemptTimeout:  ptr(15 * time.Second),
+		NotifyAttemptInterval: ptr(15 * time.Second),
+		StdinPayload:          ptr(false),
+		ExportEnv:             ptr(false),
+	}
+}
+
+var _ Notifier = (*ScriptNotifier)(nil)
+
+// ScriptNotifier is a [Notifier] executing a custom user-defined script.
+// The script receives these arguments as part of the command's execution:
+//   - $1: Device path (e.g., /dev/sg25)
+//   - $2: SAS address (e.g., 0x500a098012345678)
+//   - $3: Device description (e.g., "JBOD")
+//   - $4: Notification message text
+//   - $5: Change report in JSON format (where applicable)
+//
+// With [ScriptNotifierConfig.StdinPayload] the change report is not passed as
+// the fifth argument, but is written to the standard input of the script instead.
+//
+// With [ScriptNotifierConfig.ExportEnv] the script receives these environment variables:
+//   - SESMON_DEVICE: Device path
+//   - SESMON_ADDRESS: SAS address
+//   - SESMON_DESCRIPTION: Device description
+//   - SESMON_MESSAGE: Notification message text
+//   - SESMON_CHANGES_JSON: Change report in JSON format (empty if not applicable)
+type ScriptNotifier struct {
+	// Path to executable notification script.
+	script string
+
+	fsys   afero.Fs
+	runner CommandRunner
+	logger *slog.Logger
+
+	cfg  *ScriptNotifierConfig
+	tmpl *template.Template
+}
+
+// NewScriptNotifier returns a pointer to a new [ScriptNotifier].
+func NewScriptNotifier(
+	script string, cfg *ScriptNotifierConfig,
+	fsys afero.Fs, runner CommandRunner, logger *slog.Logger,
+) (*ScriptNotifier, error) {
+	if fsys == nil || runner == nil || logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	if script == "" {
+		return nil, fmt.Errorf("%w: no script provided", errInvalidArgument)
+	}
+	st, err := fsys.Stat(script)
+	if err != nil {
+		return nil, fmt.Errorf("%q: stat script failure: %w", script, err)
+	}
+	if (st.Mode() & executableModeMask) == 0 {
+		return nil, fmt.Errorf("%q: %w", script, errNotExecutable)
+	}
+
+	scfg, err := mergeScriptNotifierConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	tmpl, err := parseMessageTemplate(fmtPtrStr(scfg.MessageTemplate, ""))
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	return &ScriptNotifier{
+		script: script,
+		cfg:    scfg,
+		tmpl:   tmpl,
+		fsys:   fsys,
+		runner: runner,
+		logger: logger,
+	}, nil
+}
+
+// Notify executes the user-defined script using the internal [CommandRunner].
+// It hands over as arguments the device, SAS address, device description and message.
+// It both observes and respects context cancellations for earlier notification terminations.
+func (n *ScriptNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	cfg, err := n.runConfig(device, message, extra)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := n.runner.Run(ctx, cfg); err != nil {
+		return fmt.Errorf("%q: %w", n.script, err)
+	}
+
+	return nil
+}
+
+// Describe returns the full argv the notification script would be executed with
+// (and whether the extra would be passed as a payload on standard input).
+func (n *ScriptNotifier) Describe(device Device, message string, extra any) string {
+	cfg, err := n.runConfig(device, message, extra)
+	if err != nil {
+		return err.Error()
+	}
+
+	desc := "[" + quoteArgv(cfg.Command, cfg.Args) + "]"
+	if cfg.Stdin != nil {
+		desc += fmt.Sprintf(" with stdin [%s]", cfg.Stdin)
+	}
+
+	return desc
+}
+
+// runConfig returns the [RunCommandConfig] for executing the notification script.
+func (n *ScriptNotifier) runConfig(device Device, message string, extra any) (RunCommandConfig, error) {
+	message, err := renderMessage(n.tmpl, device, message, extra)
+	if err != nil {
+		n.logger.Warn(fmt.Sprintf("%q: %v (using default message)", n.script, err), LogEvent("template_failure"))
+	}
+
+	args := []string{device.Path, device.Address, device.Description, message}
+
+	var stdin []byte
+	var extraJSON string
+	if extra != nil {
+		b, err := json.Marshal(extra)
+		if err != nil {
+			return RunCommandConfig{}, fmt.Errorf("%q: failure marshalling extra to JSON: %w", n.script, err)
+		}
+		if *n.cfg.StdinPayload {
+			stdin = b
+		} else {
+			args = append(args, string(b))
+		}
+		extraJSON = string(b)
+	}
+
+	var env []string
+	if *n.cfg.ExportEnv {
+		env = []string{
+			"SESMON_DEVICE=" + device.Path,
+			"SESMON_ADDRESS=" + device.Address,
+			"SESMON_DESCRIPTION=" + device.Description,
+			"SESMON_MESSAGE=" + message,
+			"SESMON_CHANGES_JSON=" + extraJSON,
+		}
+	}
+
+	return RunCommandConfig{
+		Description:     fmt.Sprintf("%q", n.script),
+		Command:         n.script,
+		Args:            args,
+		Stdin:           stdin,
+		Env:             env,
+		Attempts:        *n.cfg.NotifyAttempts,
+		AttemptTimeout:  *n.cfg.NotifyAttemptTimeout,
+		AttemptInterval: *n.cfg.NotifyAttemptInterval,
+		PrintErrors:     true,
+	}, nil
+}
+
+// Name returns the name of the notification agent as a string.
+func (n *ScriptNotifier) Name() string {
+	return "script_notifier"
+}
+
+// Config returns the configuration of the notification agent as a string.
+func (n *ScriptNotifier) Config() string {
+	cfgJSON, err := json.Marshal(n.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+
+	return fmt.Sprintf("%q:%s", n.script, cfgJSON)
+}
+
+var _ Notifier = (*MultiNotifier)(nil)
+
+// MultiNotifier is a [Notifier] dispatching to multiple other [Notifier].
+// All notification agents are called, regardless of any individual failures.
+type MultiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier returns a pointer to a new [MultiNotifier].
+func NewMultiNotifier(notifiers ...Notifier) (*MultiNotifier, error) {
+	if len(notifiers) == 0 {
+		return nil, fmt.Errorf("%w: no notifiers provided", errInvalidArgument)
+	}
+
+	return &MultiNotifier{
+		notifiers: notifiers,
+	}, nil
+}
+
+// Notify calls all contained [Notifier] in sequence and returns their joined errors.
+// It both observes and respects context cancellations for earlier notification terminations.
+func (n *MultiNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	var errs []error
+
+	for _, notifier := range n.notifiers {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("context error: %w", err))
+
+			break
+		}
+		if err := notifier.Notify(ctx, device, message, extra); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Describe returns the descriptions of all contained notification agents as a string.
+func (n *MultiNotifier) Describe(device Device, message string, extra any) string {
+	descs := make([]string, 0, len(n.notifiers))
+	for _, notifier := range n.notifiers {
+		descs = append(descs, notifier.Name()+"="+describeNotification(notifier, device, message, extra))
+	}
+
+	return strings.Join(descs, ", ")
+}
+
+// Close releases the resources held by any of the contained notification agents.
+func (n *MultiNotifier) Close() {
+	for _, notifier := range n.notifiers {
+		if c, ok := notifier.(closableNotifier); ok {
+			c.Close()
+		}
+	}
+}
+
+// Name returns the names of all contained notification agents as a string.
+func (n *MultiNotifier) Name() string {
+	names := make([]string, 0, len(n.notifiers))
+	for _, notifier := range n.notifiers {
+		names = append(names, notifier.Name())
+	}
+
+	return strings.Join(names, "+")
+}
+
+// Config returns the configurations of all contained notification agents as a string.
+func (n *MultiNotifier) Config() string {
+	configs := make([]string, 0, len(n.notifiers))
+	for _, notifier := range n.notifiers {
+		configs = append(configs, notifier.Name()+"="+notifier.Config())
+	}
+
+	return strings.Join(configs, ", ")
+}
+
+var _ Notifier = (*FallbackNotifier)(nil)
+
+// FallbackNotifier is a [Notifier] dispatching to a primary [Notifier] and, only if that
+// has failed (after its own retries), to a fallback [Notifier] (e.g. email for a webhook).
+type FallbackNotifier struct {
+	primary  Notifier
+	fallback Notifier
+	logger   *slog.Logger
+}
+
+// NewFallbackNotifier returns a pointer to a new [FallbackNotifier].
+func NewFallbackNotifier(primary Notifier, fallback Notifier, logger *slog.Logger) (*FallbackNotifier, error) {
+	if primary == nil || fallback == nil || logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	return &FallbackNotifier{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}, nil
+}
+
+// Notify calls the primary [Notifier] and the fallback [Notifier] only if the primary has failed.
+// An error is returned only if both have failed, in which case the failure of the fallback is also
+// logged. It both observes and respects context cancellations for earlier notification terminations.
+func (n *FallbackNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	err := n.primary.Notify(ctx, device, message, extra)
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf("%s: %w", n.primary.Name(), err)
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return errors.Join(err, fmt.Errorf("context error: %w", ctxErr))
+	}
+
+	n.logger.Warn(fmt.Sprintf("Notification agent [%s] failed - falling back to [%s]: %v",
+		n.primary.Name(), n.fallback.Name(), err), LogEvent("warning"))
+
+	if ferr := n.fallback.Notify(ctx, device, message, extra); ferr != nil {
+		ferr = fmt.Errorf("%s (fallback): %w", n.fallback.Name(), ferr)
+		n.logger.Error(fmt.Sprintf("Error dispatching through fallback notification agent: %v", ferr), LogEvent("error"))
+
+		return errors.Join(err, ferr)
+	}
+
+	return nil
+}
+
+// Describe returns the description of the primary notification agent (as the fallback
+// is only called on failure) with the name of the fallback notification agent as a string.
+func (n *FallbackNotifier) Describe(device Device, message string, extra any) string {
+	return describeNotification(n.primary, device, message, extra) + " (fallback: " + n.fallback.Name() + ")"
+}
+
+// Close releases the resources held by the primary or fallback notification agents.
+func (n *FallbackNotifier) Close() {
+	for _, notifier := range []Notifier{n.primary, n.fallback} {
+		if c, ok := notifier.(closableNotifier); ok {
+			c.Close()
+		}
+	}
+}
+
+// Name returns the names of the primary and fallback notification agents as a string.
+func (n *FallbackNotifier) Name() string {
+	return n.primary.Name() + "|" + n.fallback.Name()
+}
+
+// Config returns the configurations of the primary and fallback notification agents as a string.
+func (n *FallbackNotifier) Config() string {
+	return n.primary.Config() + ", fallback " + n.fallback.Name() + "=" + n.fallback.Config()
+}
@@ -1,11 +1,10 @@
-package main
+package sesmon
 
 import (
 	"context"
 	"encoding/json"
 	"errors"
-	"io"
-	"log"
+	"log/slog"
 	"sync"
 	"testing"
 	"time"
@@ -161,7 +160,7 @@ func Test_NewScriptNotifier_Success(t *testing.T) {
 	cfg := DefaultScriptNotifierConfig()
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 	require.NotNil(t, notifier)
 	require.Equal(t, scriptPath, notifier.script)
@@ -175,7 +174,7 @@ func Test_NewScriptNotifier_NoScriptGiven_Error(t *testing.T) {
 	cfg := DefaultScriptNotifierConfig()
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier("", cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier("", cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.Error(t, err)
 	require.Nil(t, notifier)
 	require.Contains(t, err.Error(), "no script")
@@ -189,7 +188,7 @@ func Test_NewScriptNotifier_ScriptNotExist_Error(t *testing.T) {
 	cfg := DefaultScriptNotifierConfig()
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier("/tmp/nonexistent.sh", cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier("/tmp/nonexistent.sh", cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.Error(t, err)
 	require.Nil(t, notifier)
 	require.Contains(t, err.Error(), "stat script failure")
@@ -202,7 +201,7 @@ func Test_NewScriptNotifier_NoDependenciesGiven_Error(t *testing.T) {
 	cfg := DefaultScriptNotifierConfig()
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier("/tmp/nonexistent.sh", cfg, nil, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier("/tmp/nonexistent.sh", cfg, nil, runner, slog.New(slog.DiscardHandler))
 	require.Error(t, err)
 	require.Nil(t, notifier)
 	require.Contains(t, err.Error(), "dependency is nil")
@@ -220,7 +219,7 @@ func Test_NewScriptNotifier_NotExecutable_Error(t *testing.T) {
 	cfg := DefaultScriptNotifierConfig()
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.Error(t, err)
 	require.Nil(t, notifier)
 	require.ErrorIs(t, err, errNotExecutable)
@@ -237,7 +236,7 @@ func Test_NewScriptNotifier_NilConfig_UsesDefault_Success(t *testing.T) {
 
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier(scriptPath, nil, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, nil, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 	require.NotNil(t, notifier)
 	require.Equal(t, DefaultScriptNotifierConfig(), notifier.cfg)
@@ -261,7 +260,7 @@ func Test_ScriptNotifier_Notify_Success(t *testing.T) {
 		NotifyAttemptInterval: ptr(100 * time.Millisecond),
 	}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -289,7 +288,7 @@ func Test_ScriptNotifier_Notify_CorrectArguments_Success(t *testing.T) {
 		NotifyAttemptInterval: ptr(100 * time.Millisecond),
 	}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -325,7 +324,7 @@ func Test_ScriptNotifier_Notify_CorrectArguments_WithExtra_Success(t *testing.T)
 		NotifyAttemptInterval: ptr(100 * time.Millisecond),
 	}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -366,7 +365,7 @@ func Test_ScriptNotifier_Notify_RunnerError_Error(t *testing.T) {
 		NotifyAttemptInterval: ptr(100 * time.Millisecond),
 	}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -396,7 +395,7 @@ func Test_ScriptNotifier_Notify_JSONError_Error(t *testing.T) {
 		NotifyAttemptInterval: ptr(100 * time.Millisecond),
 	}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -424,7 +423,7 @@ func Test_ScriptNotifier_Notify_ErrorWrapping_Error(t *testing.T) {
 		NotifyAttemptInterval: ptr(100 * time.Millisecond),
 	}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	ctx := t.Context()
@@ -446,7 +445,7 @@ func Test_ScriptNotifier_Name_Success(t *testing.T) {
 	cfg := DefaultScriptNotifierConfig()
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 	require.Equal(t, "script_notifier", notifier.Name())
 }
@@ -467,7 +466,7 @@ func Test_ScriptNotifier_Config_Success(t *testing.T) {
 	}
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	config := notifier.Config()
@@ -489,9 +488,335 @@ func Test_ScriptNotifier_Config_NilValues_Success(t *testing.T) {
 	cfg := &ScriptNotifierConfig{}
 	runner := &mockCommandRunner{}
 
-	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, log.New(io.Discard, "", 0))
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
 	require.NoError(t, err)
 
 	config := notifier.Config()
 	require.Contains(t, config, scriptPath)
 }
+
+// Expectation: NewMultiNotifier should return an error when no notifiers are provided.
+func Test_NewMultiNotifier_NoNotifiers_Error(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewMultiNotifier()
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, notifier)
+}
+
+// Expectation: MultiNotifier should call all notifiers, even when one of them fails.
+func Test_MultiNotifier_Notify_AllCalled_Error(t *testing.T) {
+	t.Parallel()
+
+	n1 := newMockNotifier()
+	n2 := newMockNotifier()
+	n1.setError(errors.New("first failed"))
+
+	notifier, err := NewMultiNotifier(n1, n2)
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.ErrorContains(t, err, "first failed")
+	require.Equal(t, 1, n1.callCount())
+	require.Equal(t, 1, n2.callCount())
+}
+
+// Expectation: MultiNotifier Name and Config should combine all contained notifiers.
+func Test_MultiNotifier_NameConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewMultiNotifier(newMockNotifier(), newMockNotifier())
+	require.NoError(t, err)
+
+	require.Equal(t, "mock_notifier+mock_notifier", notifier.Name())
+	require.Equal(t, "mock_notifier=-, mock_notifier=-", notifier.Config())
+}
+
+// Expectation: FallbackNotifier should not call the fallback when the primary has succeeded.
+func Test_FallbackNotifier_Notify_PrimarySuccess_Success(t *testing.T) {
+	t.Parallel()
+
+	primary := newMockNotifier()
+	fallback := newMockNotifier()
+
+	notifier, err := NewFallbackNotifier(primary, fallback, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, primary.callCount())
+	require.Equal(t, 0, fallback.callCount())
+}
+
+// Expectation: FallbackNotifier should call the fallback and succeed when only the primary has failed.
+func Test_FallbackNotifier_Notify_PrimaryFailed_Success(t *testing.T) {
+	t.Parallel()
+
+	primary := newMockNotifier()
+	fallback := newMockNotifier()
+	primary.setError(errors.New("primary failed"))
+
+	var buf safeBuffer
+	notifier, err := NewFallbackNotifier(primary, fallback, NewLogger(&buf, false, true))
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, primary.callCount())
+	require.Equal(t, []string{"test message"}, fallback.getCalls())
+	require.Contains(t, buf.String(), "falling back")
+	require.Contains(t, buf.String(), "primary failed")
+}
+
+// Expectation: FallbackNotifier should return and log an error when both have failed.
+func Test_FallbackNotifier_Notify_BothFailed_Error(t *testing.T) {
+	t.Parallel()
+
+	primary := newMockNotifier()
+	fallback := newMockNotifier()
+	primary.setError(errors.New("primary failed"))
+	fallback.setError(errors.New("fallback failed"))
+
+	var buf safeBuffer
+	notifier, err := NewFallbackNotifier(primary, fallback, NewLogger(&buf, false, true))
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.ErrorContains(t, err, "primary failed")
+	require.ErrorContains(t, err, "fallback failed")
+	require.Equal(t, 1, fallback.callCount())
+	require.Contains(t, buf.String(), "Error dispatching through fallback notification agent")
+}
+
+// Expectation: FallbackNotifier should not call the fallback when the context was cancelled.
+func Test_FallbackNotifier_Notify_ContextCancelled_Error(t *testing.T) {
+	t.Parallel()
+
+	primary := newMockNotifier()
+	fallback := newMockNotifier()
+	primary.setError(context.Canceled)
+
+	notifier, err := NewFallbackNotifier(primary, fallback, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err = notifier.Notify(ctx, Device{Path: "/dev/sg25"}, "test message", nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 0, fallback.callCount())
+}
+
+// Expectation: NewFallbackNotifier should return an error when a dependency is nil.
+func Test_NewFallbackNotifier_NilDependency_Error(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewFallbackNotifier(newMockNotifier(), nil, slog.New(slog.DiscardHandler))
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, notifier)
+}
+
+// Expectation: FallbackNotifier Name and Config should combine the primary and the fallback.
+func Test_FallbackNotifier_NameConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewFallbackNotifier(newMockNotifier(), newMockNotifier(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, "mock_notifier|mock_notifier", notifier.Name())
+	require.Equal(t, "-, fallback mock_notifier=-", notifier.Config())
+}
+
+// Expectation: ScriptNotifier should write the change report to standard input when configured.
+func Test_ScriptNotifier_Notify_StdinPayload_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	err := afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755)
+	require.NoError(t, err)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("success", "", nil)
+
+	cfg := &ScriptNotifierConfig{
+		StdinPayload: ptr(true),
+	}
+
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Address: "0x00", Description: "Test Device"}
+	report := ChangeReport{Device: device, DetectedAt: "now"}
+
+	err = notifier.Notify(t.Context(), device, "test message", report)
+	require.NoError(t, err)
+
+	config := runner.lastConfig()
+	require.Equal(t, []string{"/dev/sg25", "0x00", "Test Device", "test message"}, config.Args)
+
+	var received ChangeReport
+	require.NoError(t, json.Unmarshal(config.Stdin, &received))
+	require.Equal(t, report, received)
+}
+
+// Expectation: ScriptNotifier should not write to standard input when there is no change report.
+func Test_ScriptNotifier_Notify_StdinPayloadNoExtra_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	err := afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755)
+	require.NoError(t, err)
+
+	runner := &mockCommandRunner{}
+
+	notifier, err := NewScriptNotifier(scriptPath, &ScriptNotifierConfig{StdinPayload: ptr(true)},
+		fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.NoError(t, err)
+
+	config := runner.lastConfig()
+	require.Len(t, config.Args, 4)
+	require.Nil(t, config.Stdin)
+}
+
+// Expectation: ScriptNotifier should export environment variables when configured.
+func Test_ScriptNotifier_Notify_ExportEnv_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	err := afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755)
+	require.NoError(t, err)
+
+	runner := &mockCommandRunner{}
+
+	notifier, err := NewScriptNotifier(scriptPath, &ScriptNotifierConfig{ExportEnv: ptr(true)},
+		fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Address: "0x00", Description: "Test Device"}
+	err = notifier.Notify(t.Context(), device, "test message", map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	config := runner.lastConfig()
+	require.Len(t, config.Args, 5)
+	require.Equal(t, []string{
+		"SESMON_DEVICE=/dev/sg25",
+		"SESMON_ADDRESS=0x00",
+		"SESMON_DESCRIPTION=Test Device",
+		"SESMON_MESSAGE=test message",
+		`SESMON_CHANGES_JSON={"key":"value"}`,
+	}, config.Env)
+}
+
+// Expectation: ScriptNotifier should not export environment variables by default.
+func Test_ScriptNotifier_Notify_ExportEnvDefault_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	err := afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755)
+	require.NoError(t, err)
+
+	runner := &mockCommandRunner{}
+
+	notifier, err := NewScriptNotifier(scriptPath, nil, fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.NoError(t, err)
+	require.Empty(t, runner.lastConfig().Env)
+}
+
+// Expectation: ScriptNotifier should pass the message evaluated from the message template.
+func Test_ScriptNotifier_Notify_MessageTemplate_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	require.NoError(t, afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755))
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("success", "", nil)
+
+	cfg := &ScriptNotifierConfig{
+		MessageTemplate: ptr(`{{.Device.Description}}: {{len .Changes}} changes`),
+	}
+
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Description: "JBOD"}
+	report := ChangeReport{Device: device, Changes: []Change{{ID: "23#0"}}}
+
+	require.NoError(t, notifier.Notify(t.Context(), device, "test message", report))
+	require.Equal(t, "JBOD: 1 changes", runner.lastConfig().Args[3])
+}
+
+// Expectation: NewScriptNotifier should return an error for an invalid message template.
+func Test_NewScriptNotifier_InvalidMessageTemplate_Error(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	require.NoError(t, afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755))
+
+	cfg := &ScriptNotifierConfig{MessageTemplate: ptr(`{{.Devices}}`)}
+
+	notifier, err := NewScriptNotifier(scriptPath, cfg, fsys, &mockCommandRunner{}, slog.New(slog.DiscardHandler))
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, notifier)
+}
+
+// Expectation: ScriptNotifier Describe should return the full argv without executing the script.
+func Test_ScriptNotifier_Describe_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	scriptPath := "/tmp/notify.sh"
+	require.NoError(t, afero.WriteFile(fsys, scriptPath, []byte("#!/bin/bash\necho test"), 0o755))
+
+	runner := &mockCommandRunner{}
+	device := Device{Path: "/dev/sg25", Address: "0x00", Description: "Test Device"}
+
+	notifier, err := NewScriptNotifier(scriptPath, nil, fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, `["/tmp/notify.sh" "/dev/sg25" "0x00" "Test Device" "test message"]`,
+		notifier.Describe(device, "test message", nil))
+	require.Equal(t, `["/tmp/notify.sh" "/dev/sg25" "0x00" "Test Device" "test message" "{\"id\":\"15#0\"}"]`,
+		notifier.Describe(device, "test message", map[string]string{"id": "15#0"}))
+
+	notifier, err = NewScriptNotifier(scriptPath, &ScriptNotifierConfig{StdinPayload: ptr(true)},
+		fsys, runner, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, `["/tmp/notify.sh" "/dev/sg25" "0x00" "Test Device" "test message"] with stdin [{"id":"15#0"}]`,
+		notifier.Describe(device, "test message", map[string]string{"id": "15#0"}))
+
+	require.Zero(t, runner.callCount())
+}
+
+// Expectation: describeNotification should describe all contained agents of a MultiNotifier (quoting the message otherwise).
+func Test_describeNotification_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/tmp/notify.sh", []byte("#!/bin/bash\necho test"), 0o755))
+
+	script, err := NewScriptNotifier("/tmp/notify.sh", nil, fsys, &mockCommandRunner{}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	multi, err := NewMultiNotifier(script, newMockNotifier())
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25"}
+
+	require.Equal(t, `"test message"`, describeNotification(newMockNotifier(), device, "test message", nil))
+	require.Equal(t, `script_notifier=["/tmp/notify.sh" "/dev/sg25" "" "" "test message"], mock_notifier="test message"`,
+		describeNotification(multi, device, "test message", nil))
+}
@@ -0,0 +1,789 @@
+package sesmon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxElementType is the highest SES element type code (a single byte).
+const maxElementType = 255
+
+// maxElementStatus is the highest SES element status code (four bits).
+const maxElementStatus = 15
+
+const (
+	// sesStatusUnsupported is the SES element status code for "Unsupported".
+	sesStatusUnsupported = 0
+
+	// sesStatusOK is the SES element status code for "OK".
+	sesStatusOK = 1
+
+	// sesStatusCritical is the SES element status code for "Critical".
+	sesStatusCritical = 2
+
+	// sesStatusNoncritical is the SES element status code for "Noncritical".
+	sesStatusNoncritical = 3
+
+	// sesStatusUnrecoverable is the SES element status code for "Unrecoverable".
+	sesStatusUnrecoverable = 4
+
+	// sesStatusNotInstalled is the SES element status code for "Not installed".
+	sesStatusNotInstalled = 5
+
+	// sesStatusUnknown is the SES element status code for "Unknown".
+	sesStatusUnknown = 6
+
+	// sesStatusNotAvailable is the SES element status code for "Not available".
+	sesStatusNotAvailable = 7
+
+	// sesStatusNoAccessAllowed is the SES element status code for "No access allowed".
+	sesStatusNoAccessAllowed = 8
+
+	// ChangeKindDegraded is a [Change] that is not a recovery.
+	ChangeKindDegraded = "degraded"
+
+	// ChangeKindRecovered is a [Change] of an element returning to status OK.
+	ChangeKindRecovered = "recovered"
+
+	// SeverityOK is the severity of a [Change] that is a recovery.
+	SeverityOK = "ok"
+
+	// SeverityInfo is the severity of a [Change] to an SES status not indicating a
+	// failure ("Unsupported", "Not installed", "Not available", "No access allowed").
+	SeverityInfo = "info"
+
+	// SeverityWarning is the severity of a [Change] to the SES status "Noncritical"
+	// or "Unknown", or of any other degradation (e.g. a predicted failure).
+	SeverityWarning = "warning"
+
+	// SeverityCritical is the severity of a [Change] to the SES status "Critical".
+	SeverityCritical = "critical"
+
+	// SeverityUnrecoverable is the severity of a [Change] to the SES status "Unrecoverable".
+	SeverityUnrecoverable = "unrecoverable"
+)
+
+// severityOrder are the severities of a [Change] in their order (from lowest to highest).
+var severityOrder = []string{SeverityOK, SeverityInfo, SeverityWarning, SeverityCritical, SeverityUnrecoverable}
+
+const (
+	tempLevelNormal = iota
+	tempLevelWarning
+	tempLevelCritical
+)
+
+// parseSES is the principal function for unmarshalling JSON-wrapped SES
+// output into the program's internal map[string]Result result structure.
+// Elements without an element type or number (which are required for their
+// ID) are skipped, with their raw indices in the element list also returned.
+// Elements of a secondary subenclosure (e.g. with HBAs enumerating multiple
+// subenclosures in one join page) are distinguished by their identifier.
+//
+//nolint:nestif,gocognit
+func parseSES(b []byte) (map[string]Result, []int, error) {
+	var root Root
+
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, nil, fmt.Errorf("failure unmarshalling JSON: %w", err)
+	}
+
+	m := make(map[string]Result)
+	var skipped []int
+	for i, el := range root.Join.ElementList {
+		r := Result{}
+		if el.ElementType != nil {
+			if el.ElementType.I != nil {
+				r.Type = *el.ElementType.I
+			} else {
+				skipped = append(skipped, i)
+
+				continue // required for ID
+			}
+			if el.ElementType.Meaning != nil {
+				r.TypeDesc = ptr(strings.TrimSpace(*el.ElementType.Meaning))
+			}
+		}
+		if el.ElementNumber != nil {
+			r.TypeNum = *el.ElementNumber
+		} else {
+			skipped = append(skipped, i)
+
+			continue // required for ID
+		}
+		if el.SubenclosureID != nil && *el.SubenclosureID != 0 {
+			r.Subenclosure = ptr(*el.SubenclosureID) // primary (0) keeps its IDs
+		}
+		if el.Descriptor != nil {
+			if desc := strings.TrimSpace(*el.Descriptor); desc != "" {
+				r.Descriptor = &desc
+			}
+		}
+		if el.StatusDescriptor != nil {
+			if el.StatusDescriptor.Status != nil {
+				if el.StatusDescriptor.Status.I != nil {
+					r.Status = el.StatusDescriptor.Status.I
+				}
+				if el.StatusDescriptor.Status.Meaning != nil {
+					r.StatusDesc = ptr(strings.TrimSpace(*el.StatusDescriptor.Status.Meaning))
+				}
+			}
+			if el.StatusDescriptor.PrdFail != nil {
+				r.PrdFail = el.StatusDescriptor.PrdFail
+			}
+			if el.StatusDescriptor.Disabled != nil {
+				r.Disabled = el.StatusDescriptor.Disabled
+			}
+			if el.StatusDescriptor.Swap != nil {
+				r.Swap = el.StatusDescriptor.Swap
+			}
+			if el.StatusDescriptor.Temperature != nil {
+				if el.StatusDescriptor.Temperature.Meaning != nil {
+					r.Temperature = ptr(strings.TrimSpace(*el.StatusDescriptor.Temperature.Meaning))
+				}
+				r.TemperatureC = parseTemperature(el.StatusDescriptor.Temperature)
+			}
+			if el.StatusDescriptor.Voltage != nil {
+				r.Voltage = ptr(strings.TrimSpace(*el.StatusDescriptor.Voltage.ValueInVolts))
+			}
+			if el.StatusDescriptor.Current != nil {
+				r.Amperage = ptr(strings.TrimSpace(*el.StatusDescriptor.Current.ValueInAmps))
+			}
+			if el.StatusDescriptor.ActualSpeedRPM != nil {
+				r.FanRPM = el.StatusDescriptor.ActualSpeedRPM
+			}
+			if el.StatusDescriptor.SpeedCode != nil {
+				if el.StatusDescriptor.SpeedCode.I != nil {
+					r.FanSpeed = el.StatusDescriptor.SpeedCode.I
+				}
+				if el.StatusDescriptor.SpeedCode.Meaning != nil {
+					r.FanSpeedDesc = ptr(strings.TrimSpace(*el.StatusDescriptor.SpeedCode.Meaning))
+				}
+			}
+		}
+		m[keyFor(r)] = r
+	}
+
+	return m, skipped, nil
+}
+
+// splitJSONPreamble splits any (non-JSON) preamble text preceding the first
+// opening brace off the data, returning both the preamble and the remainder.
+func splitJSONPreamble(b []byte) ([]byte, []byte) {
+	i := bytes.IndexByte(b, '{')
+	if i <= 0 {
+		return nil, b
+	}
+
+	return b[:i], b[i:]
+}
+
+// parseTemperature extracts the numeric temperature (in Celsius) from a [CodeMeaning].
+// The textual meaning (e.g., "25 C") is preferred, with the integer value as fallback.
+func parseTemperature(cm *CodeMeaning) *int {
+	if cm.Meaning != nil {
+		fields := strings.Fields(*cm.Meaning)
+		if len(fields) > 0 {
+			if v, err := strconv.Atoi(strings.TrimSuffix(fields[0], "C")); err == nil {
+				return &v
+			}
+		}
+	}
+	if cm.I != nil {
+		return ptr(*cm.I)
+	}
+
+	return nil
+}
+
+// tempLevel returns the new temperature level of an element based on the previous level.
+// Escalations happen as soon as a threshold is reached, de-escalations only happen once
+// the temperature has fallen below the previously crossed threshold minus the hysteresis.
+func tempLevel(prevLevel int, temp int, warn, crit *int, hysteresis int) int {
+	target := tempLevelNormal
+	if crit != nil && temp >= *crit {
+		target = tempLevelCritical
+	} else if warn != nil && temp >= *warn {
+		target = tempLevelWarning
+	}
+
+	if target >= prevLevel {
+		return target
+	}
+
+	level := prevLevel
+	for level > target {
+		var threshold *int
+		if level == tempLevelCritical {
+			threshold = crit
+		} else {
+			threshold = warn
+		}
+		if threshold != nil && temp >= *threshold-hysteresis {
+			break
+		}
+		level--
+	}
+
+	return level
+}
+
+// tempLevelName returns a textual representation of a temperature level.
+func tempLevelName(level int) string {
+	switch level {
+	case tempLevelWarning:
+		return "warning"
+	case tempLevelCritical:
+		return "critical"
+	default:
+		return "normal"
+	}
+}
+
+// temperatureDiff compares current temperatures against the thresholds and previous levels.
+// It returns a slice of [Change] for all elements whose temperature level has changed,
+// along with the new temperature levels (for passing in as previous levels on next call).
+func temperatureDiff(prevLevels map[string]int, prev, curr map[string]Result, warn, crit *int, hysteresis int) ([]Change, map[string]int) {
+	var out []Change
+	levels := make(map[string]int)
+
+	for k, c := range curr {
+		if c.TemperatureC == nil {
+			continue
+		}
+
+		prevLevel := prevLevels[k]
+		level := tempLevel(prevLevel, *c.TemperatureC, warn, crit, hysteresis)
+		levels[k] = level
+
+		if level == prevLevel {
+			continue
+		}
+
+		ch := Change{
+			ID: k, Type: c.Type, TypeNum: c.TypeNum,
+			TypeDesc: c.TypeDesc, Descriptor: c.Descriptor, After: &c,
+		}
+		if p, ok := prev[k]; ok {
+			ch.Before = &p
+		}
+		if level > prevLevel {
+			ch.Kind = ChangeKindDegraded
+			ch.Severity = SeverityWarning
+			if level == tempLevelCritical {
+				ch.Severity = SeverityCritical
+			}
+			ch.Reason = ptr(fmt.Sprintf("temperature %d C reached %s threshold",
+				*c.TemperatureC, tempLevelName(level)))
+		} else {
+			ch.Kind = ChangeKindRecovered
+			ch.Severity = SeverityOK
+			ch.Reason = ptr(fmt.Sprintf("temperature %d C fell below %s threshold",
+				*c.TemperatureC, tempLevelName(prevLevel)))
+		}
+		out = append(out, ch)
+	}
+
+	return out, levels
+}
+
+// fanDiff compares the fan speeds of two map[string]Result and returns a slice of
+// [Change]. A fan stopping (0 rpm) or starting again always results in a change,
+// whereas changes of the speed class only do so if speedChanges is set.
+func fanDiff(prev, curr map[string]Result, speedChanges bool) []Change {
+	var out []Change
+
+	for k, c := range curr {
+		p, ok := prev[k]
+		if !ok {
+			continue
+		}
+
+		ch := Change{
+			ID: k, Type: c.Type, TypeNum: c.TypeNum,
+			TypeDesc: c.TypeDesc, Descriptor: c.Descriptor, Before: &p, After: &c,
+		}
+
+		switch {
+		case p.FanRPM != nil && c.FanRPM != nil && *p.FanRPM != 0 && *c.FanRPM == 0:
+			ch.Kind = ChangeKindDegraded
+			ch.Severity = SeverityWarning
+			ch.Reason = ptr(fmt.Sprintf("fan stopped (%d rpm to 0 rpm)", *p.FanRPM))
+		case p.FanRPM != nil && c.FanRPM != nil && *p.FanRPM == 0 && *c.FanRPM != 0:
+			ch.Kind = ChangeKindRecovered
+			ch.Severity = SeverityOK
+			ch.Reason = ptr(fmt.Sprintf("fan started again (0 rpm to %d rpm)", *c.FanRPM))
+		case speedChanges && p.FanSpeed != nil && c.FanSpeed != nil && *p.FanSpeed != *c.FanSpeed:
+			ch.Kind = ChangeKindDegraded
+			ch.Severity = SeverityInfo
+			ch.Reason = ptr(fmt.Sprintf("fan speed changed from %s to %s",
+				fmtPtrQStr(p.FanSpeedDesc, strconv.Itoa(*p.FanSpeed)),
+				fmtPtrQStr(c.FanSpeedDesc, strconv.Itoa(*c.FanSpeed))))
+		default:
+			continue
+		}
+
+		out = append(out, ch)
+	}
+
+	return out
+}
+
+// rowsDiff compares two map[string]Result and returns a slice of [Change].
+func rowsDiff(prev, curr map[string]Result) []Change {
+	var out []Change
+	seen := make(map[string]struct{})
+
+	for k := range prev {
+		seen[k] = struct{}{}
+	}
+	for k := range curr {
+		seen[k] = struct{}{}
+	}
+
+	for k := range seen {
+		p, pok := prev[k]
+		c, cok := curr[k]
+		if !pok || !cok || !rowsEqual(p, c) {
+			ch := Change{ID: k, Type: fnz(c.Type, p.Type), TypeNum: fnz(c.TypeNum, p.TypeNum)}
+			if c.TypeDesc != nil || p.TypeDesc != nil {
+				ch.TypeDesc = ptr(fne(fmtPtrStr(c.TypeDesc, ""), fmtPtrStr(p.TypeDesc, "")))
+			}
+			if c.Descriptor != nil || p.Descriptor != nil {
+				ch.Descriptor = ptr(fne(fmtPtrStr(c.Descriptor, ""), fmtPtrStr(p.Descriptor, "")))
+			}
+			if pok {
+				ch.Before = &p
+			}
+			if cok {
+				ch.After = &c
+			}
+			if pok && cok {
+				ch.Reason = bitsReason(p, c)
+			}
+			ch.Kind = changeKind(ch)
+			ch.Severity = changeSeverity(ch)
+			out = append(out, ch)
+		}
+	}
+
+	return out
+}
+
+// unhealthyChanges returns a [Change] (without a before state) for every element with problems
+// (see [countProblems]), as raised on the initial poll if configured with [AlertOnStartUnhealthy].
+func unhealthyChanges(results map[string]Result) []Change {
+	var out []Change
+	for k, r := range results {
+		if checkStateForStatus(r.Status) == CheckStateOK {
+			continue
+		}
+
+		ch := Change{
+			ID:         k,
+			Type:       r.Type,
+			TypeNum:    r.TypeNum,
+			TypeDesc:   r.TypeDesc,
+			Descriptor: r.Descriptor,
+			Reason:     ptr("unhealthy at start of monitoring"),
+			After:      &r,
+		}
+		ch.Kind = changeKind(ch)
+		ch.Severity = changeSeverity(ch)
+		out = append(out, ch)
+	}
+
+	return out
+}
+
+// filterResults returns the map[string]Result with only the elements of the given types.
+// If no element types are given, the map[string]Result is returned as it is.
+func filterResults(results map[string]Result, types []int) map[string]Result {
+	if len(types) == 0 {
+		return results
+	}
+
+	out := make(map[string]Result, len(results))
+	for k, r := range results {
+		if slices.Contains(types, r.Type) {
+			out[k] = r
+		}
+	}
+
+	return out
+}
+
+// sesStatusMeanings are the canonical meanings of the SES element status codes
+// (as of SES-4), as status descriptions stable across versions of the fetching tools.
+var sesStatusMeanings = map[int]string{
+	sesStatusUnsupported:     "Unsupported",
+	sesStatusOK:              "OK",
+	sesStatusCritical:        "Critical",
+	sesStatusNoncritical:     "Noncritical",
+	sesStatusUnrecoverable:   "Unrecoverable",
+	sesStatusNotInstalled:    "Not installed",
+	sesStatusUnknown:         "Unknown",
+	sesStatusNotAvailable:    "Not available",
+	sesStatusNoAccessAllowed: "No access allowed",
+}
+
+// applyStatusMeanings sets the status descriptions of the results from their status codes,
+// with the given meanings taking precedence over the canonical meanings (if canonical).
+// Results without a status code, or of a status code without meaning, keep their description.
+func applyStatusMeanings(results map[string]Result, canonical bool, meanings map[int]string) {
+	if !canonical && len(meanings) == 0 {
+		return
+	}
+
+	for k, r := range results {
+		if r.Status == nil {
+			continue
+		}
+
+		if meaning, ok := meanings[*r.Status]; ok {
+			r.StatusDesc = ptr(meaning)
+		} else if meaning, ok := sesStatusMeanings[*r.Status]; ok && canonical {
+			r.StatusDesc = ptr(meaning)
+		} else {
+			continue
+		}
+
+		results[k] = r
+	}
+}
+
+// countProblems returns the amount of elements with a status other than OK
+// (that is, of a warning or critical state, as with "check-status").
+func countProblems(results map[string]Result) int {
+	var problems int
+	for _, r := range results {
+		if checkStateForStatus(r.Status) != CheckStateOK {
+			problems++
+		}
+	}
+
+	return problems
+}
+
+// typeSummary returns a one-line summary of the elements by their element type
+// (ordered by the type), e.g. "Array device slot: 12 (1 with problems), Enclosure: 1".
+func typeSummary(results map[string]Result) string {
+	type typeCount struct {
+		name     string
+		total    int
+		problems int
+	}
+
+	counts := make(map[int]*typeCount)
+	for _, r := range results {
+		c, ok := counts[r.Type]
+		if !ok {
+			c = &typeCount{name: fmtPtrStr(r.TypeDesc, "type "+strconv.Itoa(r.Type))}
+			counts[r.Type] = c
+		}
+		c.total++
+		if checkStateForStatus(r.Status) != CheckStateOK {
+			c.problems++
+		}
+	}
+
+	parts := make([]string, 0, len(counts))
+	for _, t := range slices.Sorted(maps.Keys(counts)) {
+		c := counts[t]
+		part := fmt.Sprintf("%s: %d", c.name, c.total)
+		if c.problems > 0 {
+			part += fmt.Sprintf(" (%d with problems)", c.problems)
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// suppressChanges returns the slice of [Change] without any changes of the given element types.
+func suppressChanges(changes []Change, types []int) []Change {
+	if len(types) == 0 {
+		return changes
+	}
+
+	return slices.DeleteFunc(changes, func(ch Change) bool {
+		return slices.Contains(types, ch.Type)
+	})
+}
+
+// bitsReason returns the reason for a change of the predicted failure or swap bits of an
+// element (or nil if neither changed), so that these stand out from other status changes.
+func bitsReason(prev, curr Result) *string {
+	var reasons []string
+
+	switch {
+	case !bitSet(prev.PrdFail) && bitSet(curr.PrdFail):
+		reasons = append(reasons, "predicted failure")
+	case bitSet(prev.PrdFail) && !bitSet(curr.PrdFail):
+		reasons = append(reasons, "predicted failure cleared")
+	}
+
+	switch {
+	case !bitSet(prev.Swap) && bitSet(curr.Swap):
+		reasons = append(reasons, "hot-swap (swap bit set)")
+	case bitSet(prev.Swap) && !bitSet(curr.Swap):
+		reasons = append(reasons, "hot-swap (swap bit cleared)")
+	}
+
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	return ptr(strings.Join(reasons, ", "))
+}
+
+// bitSet returns if a bit of an element (e.g. [Result.PrdFail]) is set.
+func bitSet(bit *int) bool {
+	return bit != nil && *bit != 0
+}
+
+// isPredictedFailure returns if a [Change] is the predicted failure bit of an element getting set.
+func isPredictedFailure(ch Change) bool {
+	return ch.Before != nil && ch.After != nil && !bitSet(ch.Before.PrdFail) && bitSet(ch.After.PrdFail)
+}
+
+// escalatePredictedFailures raises the severity of predicted failures (see [isPredictedFailure])
+// within a slice of [Change] to [SeverityCritical] (in-place), as configured with [PrdFailCritical].
+func escalatePredictedFailures(changes []Change) {
+	for i, ch := range changes {
+		if isPredictedFailure(ch) && ch.Kind != ChangeKindRecovered && ch.Severity != SeverityUnrecoverable {
+			changes[i].Severity = SeverityCritical
+		}
+	}
+}
+
+// rowsEqual returns if two [Result] should be considered as equal.
+func rowsEqual(a, b Result) bool {
+	return ptrIntEqual(a.Status, b.Status) &&
+		ptrStrEqualFold(a.StatusDesc, b.StatusDesc) &&
+		ptrIntEqual(a.PrdFail, b.PrdFail) &&
+		ptrIntEqual(a.Disabled, b.Disabled) &&
+		ptrIntEqual(a.Swap, b.Swap)
+}
+
+// changeKind classifies a [Change] as either degraded or recovered.
+// A recovery is a transition of the status code to OK from any other status.
+func changeKind(ch Change) string {
+	if ch.Before != nil && ch.After != nil &&
+		ch.After.Status != nil && *ch.After.Status == sesStatusOK &&
+		(ch.Before.Status == nil || *ch.Before.Status != sesStatusOK) {
+		return ChangeKindRecovered
+	}
+
+	return ChangeKindDegraded
+}
+
+// reportKind classifies a slice of [Change] as either degraded or recovered.
+// It is only considered a recovery if all of the contained changes are recoveries.
+func reportKind(changes []Change) string {
+	if len(changes) == 0 {
+		return ChangeKindDegraded
+	}
+	for _, ch := range changes {
+		if ch.Kind != ChangeKindRecovered {
+			return ChangeKindDegraded
+		}
+	}
+
+	return ChangeKindRecovered
+}
+
+// statusSeverity returns the severity of an SES status code (as per the SES status code table).
+// A missing status code is considered as [SeverityInfo], any reserved one as [SeverityWarning].
+func statusSeverity(status *int) string {
+	if status == nil {
+		return SeverityInfo
+	}
+
+	switch *status {
+	case sesStatusOK:
+		return SeverityOK
+	case sesStatusUnsupported, sesStatusNotInstalled, sesStatusNotAvailable, sesStatusNoAccessAllowed:
+		return SeverityInfo
+	case sesStatusCritical:
+		return SeverityCritical
+	case sesStatusUnrecoverable:
+		return SeverityUnrecoverable
+	default: // sesStatusNoncritical, sesStatusUnknown and reserved
+		return SeverityWarning
+	}
+}
+
+// changeSeverity returns the severity of a [Change] (if not already set) derived from the SES
+// status code after the change. Degradations without a failing SES status code (e.g. a predicted
+// failure of an element still reporting "OK") and removed elements are considered as [SeverityWarning].
+func changeSeverity(ch Change) string {
+	if ch.Severity != "" {
+		return ch.Severity
+	}
+	if ch.Kind == ChangeKindRecovered {
+		return SeverityOK
+	}
+	if ch.After == nil {
+		return SeverityWarning
+	}
+
+	if severity := statusSeverity(ch.After.Status); severity != SeverityOK {
+		return severity
+	}
+
+	return SeverityWarning
+}
+
+// reportSeverity returns the highest severity of a slice of [Change] (or [SeverityOK] if empty).
+func reportSeverity(changes []Change) string {
+	severity := SeverityOK
+	for _, ch := range changes {
+		if s := changeSeverity(ch); slices.Index(severityOrder, s) > slices.Index(severityOrder, severity) {
+			severity = s
+		}
+	}
+
+	return severity
+}
+
+// meetsSeverity returns if a severity is at least as high as the minimum severity.
+func meetsSeverity(severity string, minSeverity string) bool {
+	return slices.Index(severityOrder, severity) >= slices.Index(severityOrder, minSeverity)
+}
+
+// buildMessage builds a string from a slice of strings.
+func buildMessage(lines []string) string {
+	return strings.Join(lines, " ")
+}
+
+// changeEvent returns the [ChangeEvent] of a [ChangeReport] (see [LogChangeEvents]).
+func changeEvent(report ChangeReport) ChangeEvent {
+	event := ChangeEvent{
+		Device:      report.Device.Path,
+		Address:     report.Device.Address,
+		DetectedAt:  report.DetectedAt,
+		Kind:        report.Kind,
+		Severity:    report.Severity,
+		Transitions: make([]ChangeTransition, 0, len(report.Changes)),
+	}
+
+	for _, ch := range report.Changes {
+		tr := ChangeTransition{
+			ID:         ch.ID,
+			Kind:       ch.Kind,
+			Severity:   changeSeverity(ch),
+			TypeDesc:   ch.TypeDesc,
+			Descriptor: ch.Descriptor,
+			Reason:     ch.Reason,
+		}
+		if ch.Before != nil {
+			tr.Before, tr.BeforeDesc = ch.Before.Status, ch.Before.StatusDesc
+		}
+		if ch.After != nil {
+			tr.After, tr.AfterDesc = ch.After.Status, ch.After.StatusDesc
+		}
+		event.Transitions = append(event.Transitions, tr)
+	}
+
+	return event
+}
+
+// sortChanges sorts a slice of [Change] (in-place) by their element type and number,
+// and by their ID for the same element type and number (of different subenclosures).
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Type == changes[j].Type {
+			if changes[i].TypeNum == changes[j].TypeNum {
+				return changes[i].ID < changes[j].ID
+			}
+
+			return changes[i].TypeNum < changes[j].TypeNum
+		}
+
+		return changes[i].Type < changes[j].Type
+	})
+}
+
+// changesAsText formats a slice of [Change] into a textual representation.
+func changesAsText(changes []Change) []string {
+	out := make([]string, 0, len(changes))
+	sortChanges(changes)
+	for _, ch := range changes {
+		before := "-"
+		if ch.Before != nil {
+			before = fmt.Sprintf("status=%s status_txt=%s prdfail=%s disabled=%s swap=%s temp=%s volt=%s amp=%s rpm=%s",
+				fmtPtrInt(ch.Before.Status, "-"), fmtPtrQStr(ch.Before.StatusDesc, "-"),
+				fmtPtrInt(ch.Before.PrdFail, "-"), fmtPtrInt(ch.Before.Disabled, "-"), fmtPtrInt(ch.Before.Swap, "-"),
+				fmtPtrQStr(ch.Before.Temperature, "-"), fmtPtrQStr(ch.Before.Voltage, "-"), fmtPtrQStr(ch.Before.Amperage, "-"),
+				fmtPtrInt(ch.Before.FanRPM, "-"))
+		}
+		after := "-"
+		if ch.After != nil {
+			after = fmt.Sprintf("status=%s status_txt=%s prdfail=%s disabled=%s swap=%s temp=%s volt=%s amp=%s rpm=%s",
+				fmtPtrInt(ch.After.Status, "-"), fmtPtrQStr(ch.After.StatusDesc, "-"),
+				fmtPtrInt(ch.After.PrdFail, "-"), fmtPtrInt(ch.After.Disabled, "-"), fmtPtrInt(ch.After.Swap, "-"),
+				fmtPtrQStr(ch.After.Temperature, "-"), fmtPtrQStr(ch.After.Voltage, "-"), fmtPtrQStr(ch.After.Amperage, "-"),
+				fmtPtrInt(ch.After.FanRPM, "-"))
+		}
+		descriptor := ""
+		if ch.Descriptor != nil {
+			descriptor = fmt.Sprintf(" descriptor=%q", *ch.Descriptor)
+		}
+		reason := ""
+		if ch.Reason != nil {
+			reason = fmt.Sprintf(" reason=%q", *ch.Reason)
+		}
+		out = append(out, fmt.Sprintf("[element=%q kind=%s severity=%s type=%s number=%d%s%s / Before: (%s) / After: (%s)]",
+			ch.ID, fne(ch.Kind, "-"), changeSeverity(ch), fmtPtrQStr(ch.TypeDesc, "-"), ch.TypeNum, descriptor, reason, before, after))
+	}
+
+	return out
+}
+
+// changesAsCompactText formats a slice of [Change] into a compact textual representation,
+// with only the element and its status transition per change (e.g. "[Slot 03: OK -> Critical]").
+func changesAsCompactText(changes []Change) []string {
+	out := make([]string, 0, len(changes))
+	sortChanges(changes)
+	for _, ch := range changes {
+		element := ch.ID
+		if ch.Descriptor != nil && *ch.Descriptor != "" {
+			element = *ch.Descriptor
+		} else if ch.TypeDesc != nil {
+			element = fmt.Sprintf("%s %d", *ch.TypeDesc, ch.TypeNum)
+		}
+		reason := ""
+		if ch.Reason != nil {
+			reason = fmt.Sprintf(" (%s)", *ch.Reason)
+		}
+		out = append(out, fmt.Sprintf("[%s: %s -> %s%s]", element, statusAsText(ch.Before), statusAsText(ch.After), reason))
+	}
+
+	return out
+}
+
+// statusAsText returns the status description of a [Result] (or its status code, if without),
+// or "-" if unknown (e.g. for the missing side of an appeared or disappeared element).
+func statusAsText(r *Result) string {
+	if r == nil {
+		return "-"
+	}
+
+	return fmtPtrStr(r.StatusDesc, fmtPtrInt(r.Status, "-"))
+}
+
+// keyFor is a helper function to derive a key from a [Result].
+// Elements of a secondary subenclosure are prefixed with its identifier.
+func keyFor(r Result) string {
+	if r.Subenclosure != nil {
+		return fmt.Sprintf("%d#%d#%d", *r.Subenclosure, r.Type, r.TypeNum) // Subenclosure#Type#TypeNum
+	}
+
+	return fmt.Sprintf("%d#%d", r.Type, r.TypeNum) // Type#TypeNum
+}
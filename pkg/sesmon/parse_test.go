@@ -0,0 +1,1175 @@
+package sesmon
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: parseSES should correctly unmarshal SES JSON data.
+func Test_parseSES_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {
+						"status": {"i": 1, "meaning": "OK"},
+						"prdfail": 0,
+						"disabled": 0,
+						"swap": 0
+					}
+				},
+				{
+					"element_type": {"i": 23, "meaning": "Temperature sensor"},
+					"element_number": 1,
+					"status_descriptor": {
+						"status": {"i": 1, "meaning": "OK"},
+						"temperature": {"i": 25, "meaning": "25 C"}
+					}
+				}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	enclosure := results["15#0"]
+	require.Equal(t, 15, enclosure.Type)
+	require.Equal(t, "Enclosure", *enclosure.TypeDesc)
+	require.Equal(t, 0, enclosure.TypeNum)
+	require.Equal(t, 1, *enclosure.Status)
+	require.Equal(t, "OK", *enclosure.StatusDesc)
+	require.Equal(t, 0, *enclosure.PrdFail)
+	require.Equal(t, 0, *enclosure.Disabled)
+	require.Equal(t, 0, *enclosure.Swap)
+
+	temp := results["23#1"]
+	require.Equal(t, 23, temp.Type)
+	require.Equal(t, "Temperature sensor", *temp.TypeDesc)
+	require.Equal(t, 1, temp.TypeNum)
+	require.Equal(t, "25 C", *temp.Temperature)
+	require.Equal(t, 25, *temp.TemperatureC)
+}
+
+// Expectation: parseSES should handle voltage and current fields.
+func Test_parseSES_WithVoltageAndCurrent_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 2, "meaning": "Power supply"},
+					"element_number": 0,
+					"status_descriptor": {
+						"status": {"i": 1, "meaning": "OK"},
+						"voltage": {"raw_value": 120, "value_in_volts": "12.0 V"},
+						"current": {"raw_value": 50, "value_in_amps": "5.0 A"}
+					}
+				}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	psu := results["2#0"]
+	require.Equal(t, "12.0 V", *psu.Voltage)
+	require.Equal(t, "5.0 A", *psu.Amperage)
+}
+
+// Expectation: parseSES should handle missing required fields.
+func Test_parseSES_MissingRequiredFields_Type_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"meaning": "Device"},
+					"element_number": 5
+				}
+			]
+		}
+	}`)
+
+	results, skipped, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	require.Equal(t, []int{0}, skipped)
+}
+
+// Expectation: parseSES should handle missing required fields.
+func Test_parseSES_MissingRequired_TypeNum_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 10, "meaning": "Device"}
+				}
+			]
+		}
+	}`)
+
+	results, skipped, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Empty(t, results)
+	require.Equal(t, []int{0}, skipped)
+}
+
+// Expectation: parseSES should handle missing optional fields.
+func Test_parseSES_MissingOptionalFields_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 10},
+					"element_number": 5
+				}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// Required fields must be there.
+	dev := results["10#5"]
+	require.Equal(t, 10, dev.Type)
+	require.Equal(t, 5, dev.TypeNum)
+
+	// Optional fields should be nil, not zero.
+	require.Nil(t, dev.TypeDesc)
+	require.Nil(t, dev.Status)
+	require.Nil(t, dev.StatusDesc)
+	require.Nil(t, dev.PrdFail)
+	require.Nil(t, dev.Disabled)
+	require.Nil(t, dev.Swap)
+	require.Nil(t, dev.Temperature)
+	require.Nil(t, dev.Voltage)
+	require.Nil(t, dev.Amperage)
+}
+
+// Expectation: parseSES should trim whitespace from string fields.
+func Test_parseSES_TrimsWhitespace_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "  Enclosure  "},
+					"element_number": 0,
+					"descriptor": "  Slot 03  ",
+					"status_descriptor": {
+						"status": {"i": 1, "meaning": "  OK  "},
+						"temperature": {"i": 25, "meaning": "  25 C  "},
+						"voltage": {"value_in_volts": "  12.0 V  "},
+						"current": {"value_in_amps": "  5.0 A  "}
+					}
+				}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+
+	r := results["15#0"]
+	require.Equal(t, "Enclosure", *r.TypeDesc)
+	require.Equal(t, "Slot 03", *r.Descriptor)
+	require.Equal(t, "OK", *r.StatusDesc)
+	require.Equal(t, "25 C", *r.Temperature)
+	require.Equal(t, "12.0 V", *r.Voltage)
+	require.Equal(t, "5.0 A", *r.Amperage)
+}
+
+// Expectation: parseSES should leave the descriptor nil when it is missing or empty.
+func Test_parseSES_EmptyDescriptor_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{"element_type": {"i": 23}, "element_number": 0, "descriptor": "   "},
+				{"element_type": {"i": 23}, "element_number": 1}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Nil(t, results["23#0"].Descriptor)
+	require.Nil(t, results["23#1"].Descriptor)
+}
+
+// Expectation: parseSES should distinguish the elements of secondary subenclosures (keeping the primary ones).
+func Test_parseSES_Subenclosures_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{"subenclosure_identifier": 0, "element_type": {"i": 23}, "element_number": 0, "descriptor": "Slot 00"},
+				{"subenclosure_identifier": 1, "element_type": {"i": 23}, "element_number": 0, "descriptor": "Slot 00 (B)"},
+				{"subenclosure_identifier": 2, "element_type": {"i": 23}, "element_number": 0, "descriptor": "Slot 00 (C)"},
+				{"element_type": {"i": 2}, "element_number": 0}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	require.Equal(t, ptr("Slot 00"), results["23#0"].Descriptor)
+	require.Nil(t, results["23#0"].Subenclosure)
+	require.Equal(t, ptr("Slot 00 (B)"), results["1#23#0"].Descriptor)
+	require.Equal(t, ptr(1), results["1#23#0"].Subenclosure)
+	require.Equal(t, ptr("Slot 00 (C)"), results["2#23#0"].Descriptor)
+	require.Contains(t, results, "2#0")
+}
+
+// Expectation: parseSES should fail on invalid JSON.
+func Test_parseSES_InvalidJSON_Error(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`not json`)
+
+	results, _, err := parseSES(jsonData)
+	require.Error(t, err)
+	require.Nil(t, results)
+	require.Contains(t, err.Error(), "failure unmarshalling JSON")
+}
+
+// Expectation: parseSES should handle empty element list.
+func Test_parseSES_EmptyElementList_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": []
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
+
+// Expectation: rowsDiff should detect no changes when results are equal.
+func Test_rowsDiff_NoChanges_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+	curr := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Empty(t, changes)
+}
+
+// Expectation: rowsDiff should detect status changes.
+func Test_rowsDiff_StatusChange_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+	curr := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(2), StatusDesc: ptr("Critical")},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Len(t, changes, 1)
+	require.Equal(t, "15#0", changes[0].ID)
+	require.NotNil(t, changes[0].Before)
+	require.NotNil(t, changes[0].After)
+	require.Equal(t, 1, *changes[0].Before.Status)
+	require.Equal(t, 2, *changes[0].After.Status)
+}
+
+// Expectation: rowsDiff should carry the element descriptor into the change.
+func Test_rowsDiff_Descriptor_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"23#3": {Type: 23, TypeNum: 3, Descriptor: ptr("Slot 03"), Status: ptr(1)},
+	}
+	curr := map[string]Result{
+		"23#3": {Type: 23, TypeNum: 3, Descriptor: ptr("Slot 03"), Status: ptr(2)},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Len(t, changes, 1)
+	require.NotNil(t, changes[0].Descriptor)
+	require.Equal(t, "Slot 03", *changes[0].Descriptor)
+
+	lines := changesAsText(changes)
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], "descriptor=\"Slot 03\"")
+}
+
+// Expectation: rowsDiff should detect new elements.
+func Test_rowsDiff_NewElement_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+	curr := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+		"23#1": {Type: 23, TypeDesc: ptr("Temperature"), TypeNum: 1, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Len(t, changes, 1)
+	require.Equal(t, "23#1", changes[0].ID)
+	require.Nil(t, changes[0].Before)
+	require.NotNil(t, changes[0].After)
+}
+
+// Expectation: rowsDiff should detect removed elements.
+func Test_rowsDiff_RemovedElement_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+		"23#1": {Type: 23, TypeDesc: ptr("Temperature"), TypeNum: 1, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+	curr := map[string]Result{
+		"15#0": {Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, Status: ptr(1), StatusDesc: ptr("OK")},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Len(t, changes, 1)
+	require.Equal(t, "23#1", changes[0].ID)
+	require.NotNil(t, changes[0].Before)
+	require.Nil(t, changes[0].After)
+}
+
+// Expectation: rowsDiff should classify changes back to status OK as recoveries.
+func Test_rowsDiff_ClassifiesKind_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"15#0": {Type: 15, TypeNum: 0, Status: ptr(2)},
+		"15#1": {Type: 15, TypeNum: 1, Status: ptr(1)},
+	}
+	curr := map[string]Result{
+		"15#0": {Type: 15, TypeNum: 0, Status: ptr(1)},
+		"15#1": {Type: 15, TypeNum: 1, Status: ptr(3)},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Len(t, changes, 2)
+
+	kinds := map[string]string{}
+	for _, ch := range changes {
+		kinds[ch.ID] = ch.Kind
+	}
+	require.Equal(t, ChangeKindRecovered, kinds["15#0"])
+	require.Equal(t, ChangeKindDegraded, kinds["15#1"])
+}
+
+// Expectation: rowsDiff should call out predicted failure and swap bit changes as their reason.
+func Test_rowsDiff_BitsReason_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"23#0": {Type: 23, TypeNum: 0, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(0)},
+		"23#1": {Type: 23, TypeNum: 1, Status: ptr(1), PrdFail: ptr(1), Swap: ptr(0)},
+		"23#2": {Type: 23, TypeNum: 2, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(0)},
+		"23#3": {Type: 23, TypeNum: 3, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(1)},
+		"23#4": {Type: 23, TypeNum: 4, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(0)},
+	}
+	curr := map[string]Result{
+		"23#0": {Type: 23, TypeNum: 0, Status: ptr(1), PrdFail: ptr(1), Swap: ptr(0)},
+		"23#1": {Type: 23, TypeNum: 1, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(0)},
+		"23#2": {Type: 23, TypeNum: 2, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(1)},
+		"23#3": {Type: 23, TypeNum: 3, Status: ptr(1), PrdFail: ptr(1), Swap: ptr(0)},
+		"23#4": {Type: 23, TypeNum: 4, Status: ptr(2), PrdFail: ptr(0), Swap: ptr(0)},
+	}
+
+	reasons := map[string]*string{}
+	for _, ch := range rowsDiff(prev, curr) {
+		reasons[ch.ID] = ch.Reason
+	}
+	require.Len(t, reasons, 5)
+	require.Equal(t, "predicted failure", *reasons["23#0"])
+	require.Equal(t, "predicted failure cleared", *reasons["23#1"])
+	require.Equal(t, "hot-swap (swap bit set)", *reasons["23#2"])
+	require.Equal(t, "predicted failure, hot-swap (swap bit cleared)", *reasons["23#3"])
+	require.Nil(t, reasons["23#4"])
+}
+
+// Expectation: escalatePredictedFailures should only raise the severity of predicted failures.
+func Test_escalatePredictedFailures_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := rowsDiff(
+		map[string]Result{
+			"23#0": {Type: 23, TypeNum: 0, Status: ptr(1), PrdFail: ptr(0)},
+			"23#1": {Type: 23, TypeNum: 1, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(0)},
+			"23#2": {Type: 23, TypeNum: 2, Status: ptr(1), PrdFail: ptr(0)},
+		},
+		map[string]Result{
+			"23#0": {Type: 23, TypeNum: 0, Status: ptr(1), PrdFail: ptr(1)},
+			"23#1": {Type: 23, TypeNum: 1, Status: ptr(1), PrdFail: ptr(0), Swap: ptr(1)},
+			"23#2": {Type: 23, TypeNum: 2, Status: ptr(4), PrdFail: ptr(1)},
+		},
+	)
+
+	escalatePredictedFailures(changes)
+
+	severities := map[string]string{}
+	for _, ch := range changes {
+		severities[ch.ID] = ch.Severity
+	}
+	require.Equal(t, SeverityCritical, severities["23#0"])
+	require.Equal(t, SeverityWarning, severities["23#1"])
+	require.Equal(t, SeverityUnrecoverable, severities["23#2"])
+}
+
+// Expectation: unhealthyChanges should return a change (without before state) for every element with problems.
+func Test_unhealthyChanges_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := unhealthyChanges(map[string]Result{
+		"23#0": {Type: 23, TypeNum: 0, Status: ptr(1)},
+		"23#1": {Type: 23, TypeNum: 1, Status: ptr(2), Descriptor: ptr("Slot 01")},
+		"23#2": {Type: 23, TypeNum: 2, Status: ptr(3)},
+		"23#3": {Type: 23, TypeNum: 3, Status: ptr(5)},
+		"23#4": {Type: 23, TypeNum: 4},
+	})
+	require.Len(t, changes, 2)
+
+	sortChanges(changes)
+	require.Equal(t, "23#1", changes[0].ID)
+	require.Equal(t, "Slot 01", *changes[0].Descriptor)
+	require.Nil(t, changes[0].Before)
+	require.Equal(t, 2, *changes[0].After.Status)
+	require.Equal(t, ChangeKindDegraded, changes[0].Kind)
+	require.Equal(t, SeverityCritical, changes[0].Severity)
+	require.Equal(t, "unhealthy at start of monitoring", *changes[0].Reason)
+
+	require.Equal(t, "23#2", changes[1].ID)
+	require.Equal(t, SeverityWarning, changes[1].Severity)
+}
+
+// Expectation: changeKind should meet the table's expectations.
+func Test_changeKind_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		change   Change
+		expected string
+	}{
+		{"critical to ok", Change{Before: &Result{Status: ptr(2)}, After: &Result{Status: ptr(1)}}, ChangeKindRecovered},
+		{"unknown to ok", Change{Before: &Result{}, After: &Result{Status: ptr(1)}}, ChangeKindRecovered},
+		{"ok to critical", Change{Before: &Result{Status: ptr(1)}, After: &Result{Status: ptr(2)}}, ChangeKindDegraded},
+		{"ok to ok", Change{Before: &Result{Status: ptr(1)}, After: &Result{Status: ptr(1), PrdFail: ptr(1)}}, ChangeKindDegraded},
+		{"new element", Change{After: &Result{Status: ptr(1)}}, ChangeKindDegraded},
+		{"removed element", Change{Before: &Result{Status: ptr(2)}}, ChangeKindDegraded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, changeKind(tt.change))
+		})
+	}
+}
+
+// Expectation: reportKind should only be a recovery if all changes are recoveries.
+func Test_reportKind_Success(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, ChangeKindDegraded, reportKind(nil))
+	require.Equal(t, ChangeKindRecovered, reportKind([]Change{{Kind: ChangeKindRecovered}, {Kind: ChangeKindRecovered}}))
+	require.Equal(t, ChangeKindDegraded, reportKind([]Change{{Kind: ChangeKindRecovered}, {Kind: ChangeKindDegraded}}))
+}
+
+// Expectation: statusSeverity should follow the SES status code table.
+func Test_statusSeverity_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status   *int
+		expected string
+	}{
+		{nil, SeverityInfo},
+		{ptr(sesStatusUnsupported), SeverityInfo},
+		{ptr(sesStatusOK), SeverityOK},
+		{ptr(sesStatusCritical), SeverityCritical},
+		{ptr(sesStatusNoncritical), SeverityWarning},
+		{ptr(sesStatusUnrecoverable), SeverityUnrecoverable},
+		{ptr(sesStatusNotInstalled), SeverityInfo},
+		{ptr(sesStatusUnknown), SeverityWarning},
+		{ptr(sesStatusNotAvailable), SeverityInfo},
+		{ptr(sesStatusNoAccessAllowed), SeverityInfo},
+		{ptr(12), SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, statusSeverity(tt.status), fmtPtrInt(tt.status, "-"))
+	}
+}
+
+// Expectation: changeSeverity should meet the table's expectations.
+func Test_changeSeverity_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		change   Change
+		expected string
+	}{
+		{"already set", Change{Severity: SeverityInfo, After: &Result{Status: ptr(2)}}, SeverityInfo},
+		{"recovery", Change{Kind: ChangeKindRecovered, After: &Result{Status: ptr(1)}}, SeverityOK},
+		{"ok to critical", Change{Kind: ChangeKindDegraded, After: &Result{Status: ptr(2)}}, SeverityCritical},
+		{"ok to noncritical", Change{Kind: ChangeKindDegraded, After: &Result{Status: ptr(3)}}, SeverityWarning},
+		{"ok to unrecoverable", Change{Kind: ChangeKindDegraded, After: &Result{Status: ptr(4)}}, SeverityUnrecoverable},
+		{"ok to not installed", Change{Kind: ChangeKindDegraded, After: &Result{Status: ptr(5)}}, SeverityInfo},
+		{"ok to ok (prdfail)", Change{Kind: ChangeKindDegraded, After: &Result{Status: ptr(1), PrdFail: ptr(1)}}, SeverityWarning},
+		{"removed element", Change{Kind: ChangeKindDegraded, Before: &Result{Status: ptr(1)}}, SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, changeSeverity(tt.change))
+		})
+	}
+}
+
+// Expectation: reportSeverity should return the highest severity of all changes.
+func Test_reportSeverity_Success(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, SeverityOK, reportSeverity(nil))
+	require.Equal(t, SeverityWarning, reportSeverity([]Change{{Severity: SeverityInfo}, {Severity: SeverityWarning}}))
+	require.Equal(t, SeverityUnrecoverable, reportSeverity([]Change{
+		{Severity: SeverityUnrecoverable}, {Severity: SeverityCritical}, {Severity: SeverityOK},
+	}))
+}
+
+// Expectation: meetsSeverity should compare severities by their order.
+func Test_meetsSeverity_Success(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, meetsSeverity(SeverityInfo, SeverityOK))
+	require.True(t, meetsSeverity(SeverityCritical, SeverityCritical))
+	require.True(t, meetsSeverity(SeverityUnrecoverable, SeverityCritical))
+	require.False(t, meetsSeverity(SeverityWarning, SeverityCritical))
+}
+
+// Expectation: changeEvent should flatten the changes of a report into their status transitions.
+func Test_changeEvent_Success(t *testing.T) {
+	t.Parallel()
+
+	report := ChangeReport{
+		Device:     Device{Path: "/dev/sg0", Address: "0x5000"},
+		DetectedAt: "2025-01-01T00:00:00Z",
+		Kind:       ChangeKindDegraded,
+		Severity:   SeverityCritical,
+		Changes: []Change{
+			{
+				ID: "23#0", Kind: ChangeKindDegraded, Type: 23, TypeDesc: ptr("Array device slot"),
+				Descriptor: ptr("Slot 00"),
+				Before:     &Result{Status: ptr(1), StatusDesc: ptr("OK")},
+				After:      &Result{Status: ptr(2), StatusDesc: ptr("Critical")},
+			},
+			{ID: "4#0", Kind: ChangeKindDegraded, Severity: SeverityWarning, Reason: ptr("temperature above warning")},
+		},
+	}
+
+	event := changeEvent(report)
+	require.Equal(t, "/dev/sg0", event.Device)
+	require.Equal(t, "0x5000", event.Address)
+	require.Equal(t, "2025-01-01T00:00:00Z", event.DetectedAt)
+	require.Equal(t, SeverityCritical, event.Severity)
+	require.Len(t, event.Transitions, 2)
+
+	require.Equal(t, ChangeTransition{
+		ID: "23#0", Kind: ChangeKindDegraded, Severity: SeverityCritical,
+		TypeDesc: ptr("Array device slot"), Descriptor: ptr("Slot 00"),
+		Before: ptr(1), BeforeDesc: ptr("OK"), After: ptr(2), AfterDesc: ptr("Critical"),
+	}, event.Transitions[0])
+
+	require.Equal(t, SeverityWarning, event.Transitions[1].Severity)
+	require.Equal(t, ptr("temperature above warning"), event.Transitions[1].Reason)
+	require.Nil(t, event.Transitions[1].Before)
+	require.Nil(t, event.Transitions[1].After)
+}
+
+// Expectation: rowsDiff and temperatureDiff should classify the severity of their changes.
+func Test_Diff_Severity_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"23#0": {Type: 23, Status: ptr(1)},
+		"23#1": {Type: 23, Status: ptr(1)},
+		"4#0":  {Type: 4, Status: ptr(1), TemperatureC: ptr(30)},
+	}
+	curr := map[string]Result{
+		"23#0": {Type: 23, Status: ptr(2)},
+		"23#1": {Type: 23, Status: ptr(3)},
+		"4#0":  {Type: 4, Status: ptr(1), TemperatureC: ptr(60)},
+	}
+
+	severities := make(map[string]string)
+	for _, ch := range rowsDiff(prev, curr) {
+		severities[ch.ID] = ch.Severity
+	}
+	require.Equal(t, map[string]string{"23#0": SeverityCritical, "23#1": SeverityWarning}, severities)
+
+	changes, levels := temperatureDiff(nil, prev, curr, ptr(40), ptr(50), 2)
+	require.Len(t, changes, 1)
+	require.Equal(t, SeverityCritical, changes[0].Severity)
+
+	curr["4#0"] = Result{Type: 4, Status: ptr(1), TemperatureC: ptr(30)}
+	changes, _ = temperatureDiff(levels, prev, curr, ptr(40), ptr(50), 2)
+	require.Len(t, changes, 1)
+	require.Equal(t, SeverityOK, changes[0].Severity)
+}
+
+// Expectation: parseTemperature should meet the table's expectations.
+func Test_parseTemperature_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cm       *CodeMeaning
+		expected *int
+	}{
+		{"meaning with unit", &CodeMeaning{I: ptr(45), Meaning: ptr("25 C")}, ptr(25)},
+		{"meaning without space", &CodeMeaning{Meaning: ptr("31C")}, ptr(31)},
+		{"negative meaning", &CodeMeaning{Meaning: ptr("-5 C")}, ptr(-5)},
+		{"unparseable meaning uses integer", &CodeMeaning{I: ptr(40), Meaning: ptr("reserved")}, ptr(40)},
+		{"only integer", &CodeMeaning{I: ptr(33)}, ptr(33)},
+		{"nothing", &CodeMeaning{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, parseTemperature(tt.cm))
+		})
+	}
+}
+
+// Expectation: tempLevel should meet the table's expectations (including hysteresis).
+func Test_tempLevel_Success(t *testing.T) {
+	t.Parallel()
+
+	warn, crit := ptr(40), ptr(50)
+
+	tests := []struct {
+		name     string
+		prev     int
+		temp     int
+		expected int
+	}{
+		{"normal stays normal", tempLevelNormal, 30, tempLevelNormal},
+		{"normal to warning", tempLevelNormal, 40, tempLevelWarning},
+		{"normal to critical", tempLevelNormal, 55, tempLevelCritical},
+		{"warning stays within hysteresis", tempLevelWarning, 38, tempLevelWarning},
+		{"warning clears below hysteresis", tempLevelWarning, 37, tempLevelNormal},
+		{"critical stays within hysteresis", tempLevelCritical, 48, tempLevelCritical},
+		{"critical to warning below hysteresis", tempLevelCritical, 47, tempLevelWarning},
+		{"critical to normal below both", tempLevelCritical, 30, tempLevelNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, tempLevel(tt.prev, tt.temp, warn, crit, 2))
+		})
+	}
+}
+
+// Expectation: tempLevel should never escalate without configured thresholds.
+func Test_tempLevel_NoThresholds_Success(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, tempLevelNormal, tempLevel(tempLevelNormal, 100, nil, nil, 2))
+	require.Equal(t, tempLevelCritical, tempLevel(tempLevelNormal, 100, nil, ptr(90), 2))
+	require.Equal(t, tempLevelNormal, tempLevel(tempLevelCritical, 89, nil, ptr(90), 0))
+}
+
+// Expectation: temperatureDiff should emit changes only when the temperature level changes.
+func Test_temperatureDiff_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"4#0": {Type: 4, TypeNum: 0, TemperatureC: ptr(30)},
+		"4#1": {Type: 4, TypeNum: 1, TemperatureC: ptr(45)},
+	}
+	curr := map[string]Result{
+		"4#0": {Type: 4, TypeNum: 0, TemperatureC: ptr(42)},
+		"4#1": {Type: 4, TypeNum: 1, TemperatureC: ptr(30)},
+		"4#2": {Type: 4, TypeNum: 2},
+	}
+	prevLevels := map[string]int{"4#0": tempLevelNormal, "4#1": tempLevelWarning}
+
+	changes, levels := temperatureDiff(prevLevels, prev, curr, ptr(40), ptr(50), 2)
+	require.Len(t, changes, 2)
+	require.Equal(t, map[string]int{"4#0": tempLevelWarning, "4#1": tempLevelNormal}, levels)
+
+	byID := map[string]Change{}
+	for _, ch := range changes {
+		byID[ch.ID] = ch
+	}
+	require.Equal(t, ChangeKindDegraded, byID["4#0"].Kind)
+	require.Contains(t, *byID["4#0"].Reason, "warning threshold")
+	require.NotNil(t, byID["4#0"].Before)
+	require.Equal(t, ChangeKindRecovered, byID["4#1"].Kind)
+	require.Contains(t, *byID["4#1"].Reason, "fell below warning")
+
+	changes, _ = temperatureDiff(levels, curr, curr, ptr(40), ptr(50), 2)
+	require.Empty(t, changes)
+}
+
+// Expectation: parseSES should parse the fan speed of cooling elements.
+func Test_parseSES_FanSpeed_Success(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 3, "meaning": "Cooling"},
+					"element_number": 0,
+					"status_descriptor": {
+						"status": {"i": 1, "meaning": "OK"},
+						"actual_speed_rpm": 7230,
+						"speed_code": {"i": 7, "meaning": "  Fan at highest speed  "}
+					}
+				}
+			]
+		}
+	}`)
+
+	results, _, err := parseSES(jsonData)
+	require.NoError(t, err)
+
+	r := results["3#0"]
+	require.Equal(t, 7230, *r.FanRPM)
+	require.Equal(t, 7, *r.FanSpeed)
+	require.Equal(t, "Fan at highest speed", *r.FanSpeedDesc)
+}
+
+// Expectation: fanDiff should always detect fans stopping and starting again.
+func Test_fanDiff_ZeroRPM_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"3#0": {Type: 3, TypeNum: 0, FanRPM: ptr(7230), FanSpeed: ptr(7)},
+		"3#1": {Type: 3, TypeNum: 1, FanRPM: ptr(0), FanSpeed: ptr(0)},
+		"3#2": {Type: 3, TypeNum: 2, FanRPM: ptr(5000), FanSpeed: ptr(5)},
+	}
+	curr := map[string]Result{
+		"3#0": {Type: 3, TypeNum: 0, FanRPM: ptr(0), FanSpeed: ptr(0)},
+		"3#1": {Type: 3, TypeNum: 1, FanRPM: ptr(4000), FanSpeed: ptr(4)},
+		"3#2": {Type: 3, TypeNum: 2, FanRPM: ptr(6000), FanSpeed: ptr(6)},
+	}
+
+	changes := fanDiff(prev, curr, false)
+	require.Len(t, changes, 2)
+
+	byID := map[string]Change{}
+	for _, ch := range changes {
+		byID[ch.ID] = ch
+	}
+	require.Equal(t, ChangeKindDegraded, byID["3#0"].Kind)
+	require.Contains(t, *byID["3#0"].Reason, "fan stopped")
+	require.Equal(t, ChangeKindRecovered, byID["3#1"].Kind)
+	require.Contains(t, *byID["3#1"].Reason, "fan started again")
+
+	lines := changesAsText(changes)
+	require.Contains(t, lines[0], "rpm=7230")
+	require.Contains(t, lines[0], "rpm=0")
+}
+
+// Expectation: fanDiff should only detect speed class changes when enabled.
+func Test_fanDiff_SpeedChange_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"3#0": {Type: 3, TypeNum: 0, FanRPM: ptr(5000), FanSpeed: ptr(5), FanSpeedDesc: ptr("Fan at fifth highest speed")},
+	}
+	curr := map[string]Result{
+		"3#0": {Type: 3, TypeNum: 0, FanRPM: ptr(7230), FanSpeed: ptr(7), FanSpeedDesc: ptr("Fan at highest speed")},
+	}
+
+	require.Empty(t, fanDiff(prev, curr, false))
+
+	changes := fanDiff(prev, curr, true)
+	require.Len(t, changes, 1)
+	require.Equal(t, ChangeKindDegraded, changes[0].Kind)
+	require.Contains(t, *changes[0].Reason, "\"Fan at highest speed\"")
+
+	require.Empty(t, fanDiff(curr, curr, true))
+}
+
+// Expectation: rowsDiff should ignore temperature, voltage, amperage changes.
+func Test_rowsDiff_IgnoresMetrics_Success(t *testing.T) {
+	t.Parallel()
+
+	prev := map[string]Result{
+		"23#1": {Type: 23, TypeNum: 1, Status: ptr(1), StatusDesc: ptr("OK"), Temperature: ptr("25 C"), Voltage: ptr("12.0 V"), Amperage: ptr("5.0 A"), FanRPM: ptr(5000)},
+	}
+	curr := map[string]Result{
+		"23#1": {Type: 23, TypeNum: 1, Status: ptr(1), StatusDesc: ptr("OK"), Temperature: ptr("30 C"), Voltage: ptr("12.5 V"), Amperage: ptr("5.5 A"), FanRPM: ptr(0)},
+	}
+
+	changes := rowsDiff(prev, curr)
+	require.Empty(t, changes)
+}
+
+// Expectation: rowsEqual should return true for identical results.
+func Test_rowsEqual_Identical_Success(t *testing.T) {
+	t.Parallel()
+
+	a := Result{Status: ptr(1), StatusDesc: ptr("OK"), PrdFail: ptr(0), Disabled: ptr(0), Swap: ptr(0)}
+	b := Result{Status: ptr(1), StatusDesc: ptr("OK"), PrdFail: ptr(0), Disabled: ptr(0), Swap: ptr(0)}
+
+	require.True(t, rowsEqual(a, b))
+}
+
+// Expectation: rowsEqual should be case-insensitive for StatusDesc.
+func Test_rowsEqual_CaseInsensitive_Success(t *testing.T) {
+	t.Parallel()
+
+	a := Result{Status: ptr(1), StatusDesc: ptr("OK"), PrdFail: ptr(0), Disabled: ptr(0), Swap: ptr(0)}
+	b := Result{Status: ptr(1), StatusDesc: ptr("OK"), PrdFail: ptr(0), Disabled: ptr(0), Swap: ptr(0)}
+
+	require.True(t, rowsEqual(a, b))
+}
+
+// Expectation: rowsEqual should return false for different status.
+func Test_rowsEqual_DifferentStatus_Success(t *testing.T) {
+	t.Parallel()
+
+	a := Result{Status: ptr(1), StatusDesc: ptr("OK")}
+	b := Result{Status: ptr(2), StatusDesc: ptr("OK")}
+
+	require.False(t, rowsEqual(a, b))
+}
+
+// Expectation: applyStatusMeanings should derive the status descriptions from the status codes.
+func Test_applyStatusMeanings_Success(t *testing.T) {
+	t.Parallel()
+
+	results := func() map[string]Result {
+		return map[string]Result{
+			"23#0": {Status: ptr(3), StatusDesc: ptr("Non-critical")},
+			"23#1": {Status: ptr(2), StatusDesc: ptr("critical")},
+			"23#2": {Status: ptr(12), StatusDesc: ptr("Reserved [12]")},
+			"23#3": {StatusDesc: ptr("no status")},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		canonical bool
+		meanings  map[int]string
+		expected  map[string]string
+	}{
+		{
+			name:     "disabled keeps descriptions",
+			expected: map[string]string{"23#0": "Non-critical", "23#1": "critical", "23#2": "Reserved [12]", "23#3": "no status"},
+		},
+		{
+			name:      "canonical meanings",
+			canonical: true,
+			expected:  map[string]string{"23#0": "Noncritical", "23#1": "Critical", "23#2": "Reserved [12]", "23#3": "no status"},
+		},
+		{
+			name:      "canonical meanings with overrides",
+			canonical: true,
+			meanings:  map[int]string{3: "Warning", 12: "Vendor"},
+			expected:  map[string]string{"23#0": "Warning", "23#1": "Critical", "23#2": "Vendor", "23#3": "no status"},
+		},
+		{
+			name:     "only overrides",
+			meanings: map[int]string{3: "Warning"},
+			expected: map[string]string{"23#0": "Warning", "23#1": "critical", "23#2": "Reserved [12]", "23#3": "no status"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := results()
+			applyStatusMeanings(r, tt.canonical, tt.meanings)
+
+			for k, desc := range tt.expected {
+				require.Equal(t, desc, *r[k].StatusDesc, k)
+			}
+		})
+	}
+}
+
+// Expectation: filterResults should keep only the elements of the given element types.
+func Test_filterResults_Success(t *testing.T) {
+	t.Parallel()
+
+	results := map[string]Result{
+		"2#0":  {Type: 2},
+		"16#0": {Type: 16},
+		"23#0": {Type: 23},
+	}
+
+	require.Len(t, filterResults(results, nil), 3)
+
+	out := filterResults(results, []int{2, 23})
+	require.Len(t, out, 2)
+	require.Contains(t, out, "2#0")
+	require.Contains(t, out, "23#0")
+	require.Len(t, results, 3)
+}
+
+// Expectation: countProblems should count the elements with a status other than OK.
+func Test_countProblems_Success(t *testing.T) {
+	t.Parallel()
+
+	results := map[string]Result{
+		"2#0":  {Type: 2, Status: ptr(sesStatusOK)},
+		"23#0": {Type: 23, Status: ptr(sesStatusCritical)},
+		"23#1": {Type: 23, Status: ptr(sesStatusNoncritical)},
+		"23#2": {Type: 23},
+	}
+
+	require.Equal(t, 2, countProblems(results))
+	require.Zero(t, countProblems(nil))
+}
+
+// Expectation: typeSummary should summarize the elements by their type (ordered by the type).
+func Test_typeSummary_Success(t *testing.T) {
+	t.Parallel()
+
+	results := map[string]Result{
+		"23#0": {Type: 23, TypeDesc: ptr("Array device slot"), Status: ptr(sesStatusOK)},
+		"23#1": {Type: 23, TypeDesc: ptr("Array device slot"), Status: ptr(sesStatusCritical)},
+		"2#0":  {Type: 2, TypeDesc: ptr("Power supply"), Status: ptr(sesStatusOK)},
+		"99#0": {Type: 99},
+	}
+
+	require.Equal(t, "Power supply: 1, Array device slot: 2 (1 with problems), type 99: 1", typeSummary(results))
+	require.Empty(t, typeSummary(nil))
+}
+
+// Expectation: suppressChanges should drop only the changes of the given element types.
+func Test_suppressChanges_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{ID: "16#0", Type: 16},
+		{ID: "23#0", Type: 23},
+		{ID: "2#0", Type: 2},
+	}
+
+	require.Len(t, suppressChanges(slices.Clone(changes), nil), 3)
+
+	out := suppressChanges(slices.Clone(changes), []int{16, 2})
+	require.Len(t, out, 1)
+	require.Equal(t, "23#0", out[0].ID)
+}
+
+// Expectation: buildMessage should concatenate lines with spaces.
+func Test_buildMessage_Success(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{"line1", "line2", "line3"}
+	msg := buildMessage(lines)
+
+	require.Equal(t, "line1 line2 line3", msg)
+}
+
+// Expectation: buildMessage should handle empty lines.
+func Test_buildMessage_EmptyLines_Success(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{}
+	msg := buildMessage(lines)
+
+	require.Empty(t, msg)
+}
+
+// Expectation: changesAsText should format changes correctly.
+func Test_changesAsText_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{
+			ID:       "15#0",
+			Type:     15,
+			TypeDesc: ptr("Enclosure"),
+			TypeNum:  0,
+			Before:   &Result{Status: ptr(1), StatusDesc: ptr("OK"), PrdFail: ptr(0), Disabled: ptr(0), Swap: ptr(0)},
+			After:    &Result{Status: ptr(2), StatusDesc: ptr("Critical"), PrdFail: ptr(1), Disabled: ptr(0), Swap: ptr(0)},
+		},
+	}
+
+	lines := changesAsText(changes)
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], "element=\"15#0\"")
+	require.Contains(t, lines[0], "type=\"Enclosure\"")
+	require.Contains(t, lines[0], "number=0")
+	require.Contains(t, lines[0], "status=1")
+	require.Contains(t, lines[0], "status=2")
+	require.Contains(t, lines[0], "severity=critical")
+	require.NotContains(t, lines[0], "descriptor=")
+}
+
+// Expectation: changesAsText should sort changes by ID.
+func Test_changesAsText_Sorted_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{ID: "23#1", Type: 23, TypeDesc: ptr("Temp"), TypeNum: 1, After: &Result{Status: ptr(1)}},
+		{ID: "23#2", Type: 23, TypeDesc: ptr("Temp"), TypeNum: 2, After: &Result{Status: ptr(1)}},
+		{ID: "15#0", Type: 15, TypeDesc: ptr("Enclosure"), TypeNum: 0, After: &Result{Status: ptr(1)}},
+		{ID: "2#5", Type: 2, TypeDesc: ptr("PSU"), TypeNum: 5, After: &Result{Status: ptr(1)}},
+	}
+
+	lines := changesAsText(changes)
+	require.Len(t, lines, 4)
+	require.Contains(t, lines[0], "2#5")
+	require.Contains(t, lines[1], "15#0")
+	require.Contains(t, lines[2], "23#1")
+	require.Contains(t, lines[3], "23#2")
+}
+
+// Expectation: changesAsText should handle nil Before.
+func Test_changesAsText_NilBefore_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{
+			ID:       "15#0",
+			Type:     15,
+			TypeDesc: ptr("Enclosure"),
+			TypeNum:  0,
+			Before:   nil,
+			After:    &Result{Status: ptr(1), StatusDesc: ptr("OK")},
+		},
+	}
+
+	lines := changesAsText(changes)
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], "Before: (-)")
+}
+
+// Expectation: changesAsText should handle nil After.
+func Test_changesAsText_NilAfter_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{
+			ID:       "15#0",
+			Type:     15,
+			TypeDesc: ptr("Enclosure"),
+			TypeNum:  0,
+			Before:   &Result{Status: ptr(1), StatusDesc: ptr("OK")},
+			After:    nil,
+		},
+	}
+
+	lines := changesAsText(changes)
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], "After: (-)")
+}
+
+// Expectation: changesAsCompactText should only contain the element and its status transition.
+func Test_changesAsCompactText_Success(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{
+			ID:         "23#0",
+			Type:       23,
+			TypeDesc:   ptr("Array device slot"),
+			TypeNum:    3,
+			Descriptor: ptr("Slot 03"),
+			Before:     &Result{Status: ptr(1), StatusDesc: ptr("OK"), PrdFail: ptr(0)},
+			After:      &Result{Status: ptr(2), StatusDesc: ptr("Critical"), PrdFail: ptr(1)},
+		},
+		{
+			ID:       "4#0",
+			Type:     4,
+			TypeDesc: ptr("Temperature sensor"),
+			TypeNum:  0,
+			Reason:   ptr("temperature 50 C reached warning threshold"),
+			Before:   &Result{Status: ptr(1)},
+			After:    &Result{Status: ptr(1)},
+		},
+		{ID: "15#0", Type: 15, TypeNum: 0, Before: nil, After: &Result{Status: ptr(1), StatusDesc: ptr("OK")}},
+	}
+
+	lines := changesAsCompactText(changes)
+	require.Equal(t, []string{
+		"[Temperature sensor 0: 1 -> 1 (temperature 50 C reached warning threshold)]",
+		"[15#0: - -> OK]",
+		"[Slot 03: OK -> Critical]",
+	}, lines)
+}
+
+// Expectation: keyFor should generate consistent keys from Result.
+func Test_keyFor_Success(t *testing.T) {
+	t.Parallel()
+
+	r := Result{Type: 15, TypeNum: 3}
+	key := keyFor(r)
+	require.Equal(t, "15#3", key)
+
+	r2 := Result{Type: 0, TypeNum: 0}
+	key2 := keyFor(r2)
+	require.Equal(t, "0#0", key2)
+
+	r3 := Result{Type: 23, TypeNum: 4, Subenclosure: ptr(1)}
+	key3 := keyFor(r3)
+	require.Equal(t, "1#23#4", key3)
+}
+
+// Expectation: splitJSONPreamble should split off any text preceding the first opening brace.
+func Test_splitJSONPreamble_Success(t *testing.T) {
+	t.Parallel()
+
+	preamble, data := splitJSONPreamble([]byte("warning: quirk\n{\"a\": 1}"))
+	require.Equal(t, "warning: quirk\n", string(preamble))
+	require.Equal(t, `{"a": 1}`, string(data))
+
+	preamble, data = splitJSONPreamble([]byte(`{"a": 1}`))
+	require.Nil(t, preamble)
+	require.Equal(t, `{"a": 1}`, string(data))
+
+	preamble, data = splitJSONPreamble([]byte("no json"))
+	require.Nil(t, preamble)
+	require.Equal(t, "no json", string(data))
+}
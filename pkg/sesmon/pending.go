@@ -0,0 +1,125 @@
+package sesmon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/afero"
+)
+
+// pendingFilename is the file in [DeviceMonitorConfig.OutputDir] the changes held back (see
+// [DeviceMonitorConfig.ChangeDebounce] and [DeviceMonitorConfig.TransientStatuses]) and
+// the alerted transient statuses are persisted to by [DeviceMonitor.RunOnce],
+// as these would otherwise not survive between the runs (with the next run's previous
+// results already containing the changed elements, so that these would never be alerted).
+const pendingFilename = "pending.json"
+
+// pendingState is the state of the changes held back as persisted to [pendingFilename].
+type pendingState struct {
+	Changes         map[string]pendingChangeState `json:"changes"`
+	TransientAlerts []string                      `json:"transient_alerts"`
+}
+
+// pendingChangeState is a single [pendingChange] as persisted to [pendingFilename].
+type pendingChangeState struct {
+	Before *Result `json:"before"`
+	Polls  int     `json:"polls"`
+}
+
+// debounces returns if any changes can be held back (see [DeviceMonitor.debounceChanges]).
+func (d *DeviceMonitor) debounces() bool {
+	return *d.cfg.ChangeDebounce > 1 || len(d.cfg.TransientStatuses) > 0
+}
+
+// persistPending writes the changes currently held back to [DeviceMonitorConfig.OutputDir]
+// (if any changes can be held back at all), only logging any failure.
+func (d *DeviceMonitor) persistPending() {
+	if d.cfg.OutputDir == nil || !d.debounces() {
+		return
+	}
+
+	state := pendingState{
+		Changes:         make(map[string]pendingChangeState, len(d.state.pendingChanges)),
+		TransientAlerts: make([]string, 0, len(d.state.transientAlerts)),
+	}
+	for id, pc := range d.state.pendingChanges {
+		state.Changes[id] = pendingChangeState{Before: pc.before, Polls: pc.polls}
+	}
+	state.TransientAlerts = slices.AppendSeq(state.TransientAlerts, maps.Keys(d.state.transientAlerts))
+	slices.Sort(state.TransientAlerts)
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error marshalling held back changes to JSON: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	deviceDir, err := d.ensureDeviceFolder()
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("Error persisting held back changes: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	if err := writeFileAtomic(d.fsys, filepath.Join(deviceDir, d.outputName(pendingFilename)), data, d.outputFileMode()); err != nil {
+		d.logger.Error(fmt.Sprintf("Error persisting held back changes: %v", err), LogEvent("error"))
+	}
+}
+
+// retainPending drops the changes held back that are obsolete after a back-off period, which
+// are the ones of elements that have reverted or that are among the net changes (alerted now).
+// All others are kept, as the previous results already contain their changed elements, so that
+// these would otherwise never be alerted (e.g. elements remaining in a transient status).
+func (d *DeviceMonitor) retainPending(changes []Change, curr map[string]Result) {
+	for _, ch := range changes {
+		delete(d.state.pendingChanges, ch.ID)
+	}
+
+	for id, pc := range d.state.pendingChanges {
+		c, cok := curr[id]
+		if (pc.before == nil && !cok) || (pc.before != nil && cok && rowsEqual(*pc.before, c)) {
+			delete(d.state.pendingChanges, id)
+		}
+	}
+}
+
+// loadPending reads the persisted changes held back from [DeviceMonitorConfig.OutputDir]
+// (if any changes can be held back at all), where a missing file is no error.
+func (d *DeviceMonitor) loadPending() error {
+	if d.cfg.OutputDir == nil || !d.debounces() {
+		return nil
+	}
+
+	data, err := afero.ReadFile(d.fsys, filepath.Join(*d.cfg.OutputDir, d.outputName(pendingFilename)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failure reading from file: %w", err)
+	}
+
+	var state pendingState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failure parsing held back changes: %w", err)
+	}
+
+	d.state.pendingChanges = make(map[string]*pendingChange, len(state.Changes))
+	for id, pc := range state.Changes {
+		d.state.pendingChanges[id] = &pendingChange{before: pc.Before, polls: pc.Polls}
+	}
+	d.state.transientAlerts = make(map[string]struct{}, len(state.TransientAlerts))
+	for _, id := range state.TransientAlerts {
+		d.state.transientAlerts[id] = struct{}{}
+	}
+
+	if len(state.Changes) > 0 {
+		d.logger.Info(fmt.Sprintf("Loaded %d held back changes from the output folder", len(state.Changes)), LogEvent("state_loaded"))
+	}
+
+	return nil
+}
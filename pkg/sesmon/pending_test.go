@@ -0,0 +1,158 @@
+package sesmon
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: RunOnce should persist the changes held back, alerting them once persisted across the runs.
+func Test_DeviceMonitor_RunOnce_ChangeDebounce_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	runOnce := func(out string) {
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:   ptr(1),
+				OutputDir:      ptr("/output"),
+				ChangeDebounce: ptr(2),
+			},
+			fsys,
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.RunOnce(t.Context()))
+	}
+
+	runOnce(jsonAckElement(1, 0))
+	runOnce(jsonAckElement(2, 0))
+	require.Zero(t, notifier.callCount())
+
+	data, err := afero.ReadFile(fsys, "/output/pending.json")
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"23#0"`)
+
+	runOnce(jsonAckElement(2, 0))
+	require.Equal(t, 1, notifier.callCount())
+	require.Contains(t, notifier.getCalls()[0], "23#0")
+
+	runOnce(jsonAckElement(2, 0))
+	require.Equal(t, 1, notifier.callCount())
+
+	data, err = afero.ReadFile(fsys, "/output/pending.json")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"changes":{},"transient_alerts":[]}`, string(data))
+}
+
+// Expectation: RunOnce should not alert changes held back that were reverted in a following run.
+func Test_DeviceMonitor_RunOnce_ChangeDebounce_Reverted_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	for _, out := range []string{jsonAckElement(1, 0), jsonAckElement(2, 0), jsonAckElement(1, 0), jsonAckElement(1, 0)} {
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:   ptr(1),
+				OutputDir:      ptr("/output"),
+				ChangeDebounce: ptr(2),
+			},
+			fsys,
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.RunOnce(t.Context()))
+	}
+
+	require.Zero(t, notifier.callCount())
+}
+
+// Expectation: RunOnce should persist the changes into transient statuses and their alerts,
+// alerting these once persisting across the TransientDebounce runs and their recovery then.
+func Test_DeviceMonitor_RunOnce_TransientStatuses_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+	notifier := newMockNotifier()
+
+	runOnce := func(out string) {
+		m := newTestDeviceMonitor(t,
+			Device{Type: 0, Path: "/dev/sg25"},
+			&DeviceMonitorConfig{
+				PollAttempts:      ptr(1),
+				OutputDir:         ptr("/output"),
+				TransientStatuses: []int{5},
+				TransientDebounce: ptr(2),
+			},
+			fsys,
+			runner,
+			slog.New(slog.DiscardHandler),
+			notifier,
+		)
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.RunOnce(t.Context()))
+	}
+
+	runOnce(jsonAckElement(1, 0))
+	runOnce(jsonAckElement(5, 0))
+	require.Zero(t, notifier.callCount())
+
+	runOnce(jsonAckElement(5, 0))
+	require.Equal(t, 1, notifier.callCount())
+
+	data, err := afero.ReadFile(fsys, "/output/pending.json")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"changes":{},"transient_alerts":["23#0"]}`, string(data))
+
+	runOnce(jsonAckElement(1, 0))
+	require.Equal(t, 2, notifier.callCount())
+	require.Contains(t, notifier.getCalls()[1], "23#0")
+}
+
+// Expectation: retainPending should keep only the changes held back whose elements are still changed,
+// dropping the reverted ones and the ones among the net changes after a back-off period.
+func Test_DeviceMonitor_retainPending_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{TransientStatuses: []int{5}},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		slog.New(slog.DiscardHandler),
+		nil,
+	)
+
+	m.state.pendingChanges = map[string]*pendingChange{
+		"23#0": {before: &Result{Status: ptr(1)}, polls: 1},
+		"23#1": {before: &Result{Status: ptr(1)}, polls: 1},
+		"23#2": {before: &Result{Status: ptr(1)}, polls: 1},
+		"23#3": {before: nil, polls: 1},
+	}
+
+	curr := map[string]Result{
+		"23#0": {Status: ptr(5)},
+		"23#1": {Status: ptr(1)},
+		"23#2": {Status: ptr(2)},
+	}
+
+	m.retainPending([]Change{{ID: "23#2"}}, curr)
+
+	require.Len(t, m.state.pendingChanges, 1)
+	require.Contains(t, m.state.pendingChanges, "23#0")
+}
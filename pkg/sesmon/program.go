@@ -0,0 +1,1600 @@
+package sesmon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultShutdownTimeout is how long to wait for all monitors to stop when shutting down,
+// leaving time to spare before systemd's default stop timeout (TimeoutStopSec) of 90s.
+const defaultShutdownTimeout = 30 * time.Second
+
+var (
+	// errProgramStopped occurs when an operation requires a still running [Program].
+	errProgramStopped = errors.New("program has already stopped")
+
+	// errDeviceNotConfigured occurs when a device is not configured (or not enabled).
+	errDeviceNotConfigured = errors.New("device not configured")
+
+	// errNoNotifier occurs when a device has no notification agent configured.
+	errNoNotifier = errors.New("no notification agent configured")
+
+	// errDeviceLookupFailed occurs when a lookup with [DeviceLookuper] fails.
+	errDeviceLookupFailed = errors.New("device lookup failed")
+
+	// errInvalidArgument occurs whenever a given argument is invalid or missing.
+	errInvalidArgument = errors.New("invalid argument")
+
+	// errInvalidJSON occurs whenever data that is expected as JSON is invalid.
+	errInvalidJSON = errors.New("invalid JSON")
+
+	// errNoDevices occurs when no devices were configured or enabled for monitoring.
+	errNoDevices = errors.New("no devices configured")
+
+	// errMonitorStopped occurs when an operation requires a still running [DeviceMonitor].
+	errMonitorStopped = errors.New("monitor has already stopped")
+
+	// errEnvNotSet occurs when a configuration references an unset environment variable.
+	errEnvNotSet = errors.New("environment variable not set")
+
+	// errPollFailed occurs when a single device poll (see [DeviceMonitor.RunOnce]) has failed.
+	errPollFailed = errors.New("device poll failed")
+
+	// errDeviceGone occurs when the path of a device no longer exists (e.g. unplugged).
+	errDeviceGone = errors.New("device has disappeared")
+
+	// errUnknownQuery occurs when a query is not understood by the status socket.
+	errUnknownQuery = errors.New("unknown query")
+
+	// errStatusQueryFailed occurs when a query to the status socket was answered with an error.
+	errStatusQueryFailed = errors.New("status query failed")
+
+	// errNotWritable occurs when a configured output directory is not writable.
+	errNotWritable = errors.New("not writable")
+
+	// errShutdownTimeout occurs when not all monitors have stopped within the shutdown timeout.
+	errShutdownTimeout = errors.New("shutdown timeout exceeded")
+
+	// errElementNotFound occurs when an element is not among the results of a device.
+	errElementNotFound = errors.New("element not found")
+
+	// errNoConfigFiles occurs when a configuration directory has no configuration files.
+	errNoConfigFiles = errors.New("no configuration files (*.yaml) in directory")
+
+	// errNoGlobMatch occurs when no file matches the glob pattern of a file-type device.
+	errNoGlobMatch = errors.New("no file matches the pattern")
+
+	// errToolNotFound occurs when the command fetching from a device (e.g. sg_ses) cannot be found.
+	errToolNotFound = errors.New("command not found")
+)
+
+// ConfigYAML represents the YAML configuration structure.
+type ConfigYAML struct {
+	DisableTimestamps  bool           `yaml:"disable_timestamps"`
+	LogJSON            bool           `yaml:"log_json"`
+	LogDescriptions    bool           `yaml:"log_descriptions"`
+	LogFile            string         `yaml:"log_file"`
+	LogFileMaxSize     *int           `yaml:"log_file_max_size"`
+	LogFileBackups     *int           `yaml:"log_file_backups"`
+	DryRun             bool           `yaml:"dry_run"`
+	ShutdownTimeout    *time.Duration `yaml:"shutdown_timeout"`
+	OverviewFile       string         `yaml:"overview_file"`
+	MaxConcurrentPolls int            `yaml:"max_concurrent_polls"`
+	HealthAddr         string         `yaml:"health_addr"`
+	StatusSocket       string         `yaml:"status_socket"`
+	SysfsRoot          string         `yaml:"sysfs_root"`
+	OutputBase         string         `yaml:"output_base"`
+	Defaults           *DeviceYAML    `yaml:"defaults,omitempty"`
+	AutoDiscover       bool           `yaml:"auto_discover"`
+	AutoDiscoverYAML   *DeviceYAML    `yaml:"auto_discover_defaults,omitempty"`
+	Exclude            []string       `yaml:"exclude"`
+	Devices            []DeviceYAML   `yaml:"devices"`
+
+	// Notify the service manager (if any) of the program state, see [WithSystemdNotify].
+	systemdNotify bool
+}
+
+// ProgramOption is a functional option for establishing a [Program].
+type ProgramOption func(*ConfigYAML)
+
+// WithLogJSON returns a [ProgramOption] forcing structured (JSON) log output,
+// regardless of what is configured in the YAML configuration (e.g. for CLI flags).
+func WithLogJSON() ProgramOption {
+	return func(c *ConfigYAML) {
+		c.LogJSON = true
+	}
+}
+
+// WithDryRun returns a [ProgramOption] forcing a dry run (notifications are only logged),
+// regardless of what is configured in the YAML configuration (e.g. for CLI flags).
+func WithDryRun() ProgramOption {
+	return func(c *ConfigYAML) {
+		c.DryRun = true
+	}
+}
+
+// WithSystemdNotify returns a [ProgramOption] notifying the service manager of the program
+// state (and its watchdog, if requested), as passed with NOTIFY_SOCKET and WATCHDOG_USEC by
+// systemd. Without it, the environment is ignored (e.g. for programs embedded in another).
+func WithSystemdNotify() ProgramOption {
+	return func(c *ConfigYAML) {
+		c.systemdNotify = true
+	}
+}
+
+// DeviceYAML represents a single device configuration in YAML.
+type DeviceYAML struct {
+	Device          string               `yaml:"device"`
+	Address         string               `yaml:"address"`
+	Description     string               `yaml:"description"`
+	Type            int                  `yaml:"type"`
+	Enabled         bool                 `yaml:"enabled"`
+	Maintenance     bool                 `yaml:"maintenance"`
+	Pool            string               `yaml:"pool"`
+	MonitorConfig   *DeviceMonitorConfig `yaml:"config,omitempty"`
+	ScriptNotifier  *ScriptNotifierYAML  `yaml:"script_notifier,omitempty"`
+	WebhookNotifier *WebhookNotifierYAML `yaml:"webhook_notifier,omitempty"`
+	SlackNotifier   *SlackNotifierYAML   `yaml:"slack_notifier,omitempty"`
+	MQTTNotifier    *MQTTNotifierYAML    `yaml:"mqtt_notifier,omitempty"`
+	PushNotifier    *PushNotifierYAML    `yaml:"push_notifier,omitempty"`
+	FileNotifier    *FileNotifierYAML    `yaml:"file_notifier,omitempty"`
+}
+
+// ScriptNotifierYAML represents a [ScriptNotifier] configuration in YAML.
+type ScriptNotifierYAML struct {
+	Script   string                `yaml:"script"`
+	Config   *ScriptNotifierConfig `yaml:"config,omitempty"`
+	Fallback *NotifierFallbackYAML `yaml:"fallback,omitempty"`
+}
+
+// WebhookNotifierYAML represents a [WebhookNotifier] configuration in YAML.
+type WebhookNotifierYAML struct {
+	URL      string                 `yaml:"url"`
+	Headers  map[string]string      `yaml:"headers,omitempty"`
+	Token    string                 `yaml:"token"`
+	Config   *WebhookNotifierConfig `yaml:"config,omitempty"`
+	Fallback *NotifierFallbackYAML  `yaml:"fallback,omitempty"`
+}
+
+// SlackNotifierYAML represents a [SlackNotifier] configuration in YAML.
+type SlackNotifierYAML struct {
+	URL      string                 `yaml:"url"`
+	Config   *WebhookNotifierConfig `yaml:"config,omitempty"`
+	Fallback *NotifierFallbackYAML  `yaml:"fallback,omitempty"`
+}
+
+// MQTTNotifierYAML represents a [MQTTNotifier] configuration in YAML.
+type MQTTNotifierYAML struct {
+	Broker    string                `yaml:"broker"`
+	Username  string                `yaml:"username"`
+	Password  string                `yaml:"password"`
+	BaseTopic string                `yaml:"base_topic"`
+	Config    *MQTTNotifierConfig   `yaml:"config,omitempty"`
+	Fallback  *NotifierFallbackYAML `yaml:"fallback,omitempty"`
+}
+
+// PushNotifierYAML represents a [PushNotifier] configuration in YAML.
+type PushNotifierYAML struct {
+	Service  string                `yaml:"service"`
+	URL      string                `yaml:"url"`
+	Token    string                `yaml:"token"`
+	Config   *PushNotifierConfig   `yaml:"config,omitempty"`
+	Fallback *NotifierFallbackYAML `yaml:"fallback,omitempty"`
+}
+
+// FileNotifierYAML represents a [FileNotifier] configuration in YAML.
+type FileNotifierYAML struct {
+	Path     string                `yaml:"path"`
+	Config   *FileNotifierConfig   `yaml:"config,omitempty"`
+	Fallback *NotifierFallbackYAML `yaml:"fallback,omitempty"`
+}
+
+// NotifierFallbackYAML represents the fallback notification agents of a notification agent in YAML,
+// which are only called if the notification agent has failed (see [FallbackNotifier]).
+type NotifierFallbackYAML struct {
+	ScriptNotifier  *ScriptNotifierYAML  `yaml:"script_notifier,omitempty"`
+	WebhookNotifier *WebhookNotifierYAML `yaml:"webhook_notifier,omitempty"`
+	SlackNotifier   *SlackNotifierYAML   `yaml:"slack_notifier,omitempty"`
+	MQTTNotifier    *MQTTNotifierYAML    `yaml:"mqtt_notifier,omitempty"`
+	PushNotifier    *PushNotifierYAML    `yaml:"push_notifier,omitempty"`
+	FileNotifier    *FileNotifierYAML    `yaml:"file_notifier,omitempty"`
+}
+
+// Program is the primary implementation and manages multiple device monitors.
+type Program struct {
+	// Global configuration (without devices) the program was established with.
+	config ConfigYAML
+
+	// Device configurations (after lookups) that the monitors were established with.
+	// Both these and the monitors are keyed by the stable identity of their devices
+	// (see [deviceKey]), so that these survive a renumbering of the device paths.
+	deviceCfgs map[string]DeviceYAML
+
+	monitors map[string]*DeviceMonitor
+	logger   *slog.Logger
+
+	// Serializes writes of the overview file (from the monitors' status hooks).
+	overviewMu sync.Mutex
+
+	// Semaphores shared between the monitors of a pool (keyed by [DeviceYAML.Pool], with
+	// "" as the default pool) to limit their concurrent device polls per pool (nil if
+	// unlimited), as is configured with [ConfigYAML.MaxConcurrentPolls].
+	pollSems map[string]chan struct{}
+
+	// HTTP server for the health endpoints (nil if not configured or not started).
+	health     *http.Server
+	healthAddr net.Addr
+
+	// Listener of the status socket (nil if not configured or not started).
+	status net.Listener
+
+	// Dependencies as injected into [NewProgram] (for re-establishing on reloads).
+	fsys   afero.Fs
+	finder DeviceLookuper
+	runner CommandRunner
+	out    io.Writer
+	opts   []ProgramOption
+
+	// Notification socket and watchdog interval of the service manager (empty and zero
+	// if not running as a systemd notify service or without [WithSystemdNotify]).
+	notifySocket     string
+	watchdogInterval time.Duration
+
+	// Context the program was started with (nil if not yet started).
+	ctx context.Context //nolint:containedctx
+
+	// Amount of currently running monitors (closes done when reaching zero).
+	running int
+
+	// Closing of done signals the consumers that all monitors are done.
+	done     chan struct{}
+	doneOnce sync.Once
+
+	mu sync.Mutex
+}
+
+// NewProgram creates a new Program from a YAML configuration string.
+func NewProgram(
+	yamlConfig []byte, f afero.Fs, d DeviceLookuper, r CommandRunner, o io.Writer, opts ...ProgramOption,
+) (*Program, error) {
+	config, err := ParseConfigYAML(yamlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if len(config.Devices) == 0 && !config.AutoDiscover {
+		return nil, errNoDevices
+	}
+
+	if config.MaxConcurrentPolls < 0 {
+		return nil, fmt.Errorf("%w: max_concurrent_polls must be >= 0", errInvalidArgument)
+	}
+
+	if config.ShutdownTimeout != nil && *config.ShutdownTimeout < 0 {
+		return nil, fmt.Errorf("%w: shutdown_timeout must be >= 0", errInvalidArgument)
+	}
+
+	if config.LogFileMaxSize != nil && *config.LogFileMaxSize < 0 {
+		return nil, fmt.Errorf("%w: log_file_max_size must be >= 0", errInvalidArgument)
+	}
+
+	if config.LogFileBackups != nil && *config.LogFileBackups < 0 {
+		return nil, fmt.Errorf("%w: log_file_backups must be >= 0", errInvalidArgument)
+	}
+
+	if config.SysfsRoot == "" {
+		config.SysfsRoot = DefaultSysfsRoot
+	}
+
+	if config.Defaults != nil && (config.Defaults.Device != "" || config.Defaults.Address != "") {
+		return nil, fmt.Errorf("%w: defaults cannot have device or address", errInvalidArgument)
+	}
+
+	var fsys afero.Fs
+	if f != nil {
+		fsys = f
+	} else {
+		fsys = afero.NewOsFs()
+	}
+
+	logger := newLogger(o, config.LogJSON, config.DisableTimestamps, "", "", "")
+	if config.DryRun {
+		logger.Info("Dry run - notifications are only logged (and not dispatched)", LogEvent("dry_run"))
+	}
+
+	p := &Program{
+		config:     config,
+		deviceCfgs: make(map[string]DeviceYAML),
+		monitors:   make(map[string]*DeviceMonitor),
+		logger:     logger,
+		fsys:       fsys,
+		finder:     d,
+		runner:     r,
+		out:        o,
+		opts:       opts,
+		done:       make(chan struct{}),
+	}
+	if config.systemdNotify {
+		p.notifySocket = os.Getenv("NOTIFY_SOCKET")
+		p.watchdogInterval = sdWatchdogInterval(os.LookupEnv)
+	}
+	p.config.Devices = nil
+
+	getFinder := func() DeviceLookuper { return d }
+	if d == nil {
+		getFinder = (&lazyDeviceLookuper{fsys: fsys, sysfsRoot: config.SysfsRoot, logger: logger}).get
+	}
+
+	type seenDevice struct {
+		index   int
+		address string
+	}
+
+	devices, err := expandDeviceGlobs(config.Devices, fsys, getFinder, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	seenOutputDirs := make(map[string]bool)
+	seenDevices := make(map[string]seenDevice)
+	for _, entry := range devices {
+		i, deviceCfg := entry.index, entry.cfg
+		if !deviceCfg.Enabled {
+			continue
+		}
+
+		if deviceCfg.Device == "" && deviceCfg.Address == "" {
+			return nil, fmt.Errorf("[config:%d] %w: missing device and address "+
+				"(needs to have at least one to be monitorable)", i, errInvalidArgument)
+		}
+
+		var finder DeviceLookuper
+		if deviceCfg.Type != DeviceTypeFile || deviceCfg.Address != "" {
+			finder = getFinder()
+		}
+
+		if err := lookupDevice(&deviceCfg, finder, logger); err != nil {
+			return nil, fmt.Errorf("[config:%d] %w", i, err)
+		}
+
+		if entry.pattern != "" {
+			if _, exists := p.monitors[deviceKey(deviceCfg)]; exists || p.monitorsPath(deviceCfg.Device) {
+				logger.Info(fmt.Sprintf("Device [%s] matching pattern [%s] is already monitored (skipping it)",
+					deviceCfg.Device, entry.pattern), LogEvent("device_glob"))
+
+				continue
+			}
+			if deviceCfg.MonitorConfig != nil && deviceCfg.MonitorConfig.OutputDir != nil {
+				mcfg := *deviceCfg.MonitorConfig
+				mcfg.OutputDir = ptr(filepath.Join(*mcfg.OutputDir,
+					sanitizeDirName(fne(deviceCfg.Address, deviceCfg.Device))))
+				deviceCfg.MonitorConfig = &mcfg
+			}
+		}
+
+		// Different SAS addresses resolving to the same device are most likely a copy-paste
+		// mistake, so both are named (rather than the device) to help with finding the mistake.
+		if prev, ok := seenDevices[deviceCfg.Device]; ok && prev.address != "" && deviceCfg.Address != "" &&
+			normalizeSASAddress(prev.address) != normalizeSASAddress(deviceCfg.Address) {
+			return nil, fmt.Errorf("[config:%d] %w: SAS addresses [%s] (config:%d) and [%s] both resolve "+
+				"to device [%s] (cannot monitor it multiple times)", i, errInvalidArgument,
+				prev.address, prev.index, deviceCfg.Address, deviceCfg.Device)
+		}
+		seenDevices[deviceCfg.Device] = seenDevice{index: i, address: deviceCfg.Address}
+
+		deviceCfg = applyDeviceDefaults(deviceCfg, config.Defaults)
+		deviceCfg = applyOutputBase(deviceCfg, config.OutputBase)
+
+		if deviceCfg.MonitorConfig != nil && deviceCfg.MonitorConfig.OutputDir != nil {
+			if seenOutputDirs[*deviceCfg.MonitorConfig.OutputDir] {
+				return nil, fmt.Errorf("[config:%d] %w: cannot use same output directory [%s] "+
+					"for multiple devices", i, errInvalidArgument, *deviceCfg.MonitorConfig.OutputDir)
+			}
+			seenOutputDirs[*deviceCfg.MonitorConfig.OutputDir] = true
+		}
+
+		key := deviceKey(deviceCfg)
+		if _, exists := p.monitors[key]; exists || p.monitorsPath(deviceCfg.Device) {
+			return nil, fmt.Errorf("[config:%d] %w: cannot monitor [%s:%s] multiple times",
+				i, errInvalidArgument, deviceCfg.Device, deviceCfg.Address)
+		}
+
+		monitor, err := setupDeviceMonitor(config, deviceCfg, fsys, r, o)
+		if err != nil {
+			return nil, fmt.Errorf("[config:%d:%s:%s] %w", i, deviceCfg.Device, deviceCfg.Address, err)
+		}
+
+		p.monitors[key] = monitor
+		p.deviceCfgs[key] = deviceCfg
+	}
+
+	if config.AutoDiscover {
+		if err := p.discoverDevices(config, getFinder(), fsys, r, o, seenOutputDirs); err != nil {
+			return nil, fmt.Errorf("[discover] %w", err)
+		}
+	}
+
+	if len(p.monitors) == 0 && len(config.Devices) == 0 {
+		return nil, errNoDevices
+	}
+
+	if config.MaxConcurrentPolls > 0 {
+		p.pollSems = make(map[string]chan struct{})
+		for _, monitor := range p.monitors {
+			if _, ok := p.pollSems[monitor.pool]; !ok {
+				p.pollSems[monitor.pool] = make(chan struct{}, config.MaxConcurrentPolls)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// ParseConfigYAML expands the environment variables of a YAML (or JSON) configuration
+// and parses it into a [ConfigYAML], rejecting any unknown fields.
+func ParseConfigYAML(yamlConfig []byte) (ConfigYAML, error) {
+	format, err := configFormat(yamlConfig)
+	if err != nil {
+		return ConfigYAML{}, err
+	}
+
+	yamlConfig, err = expandEnvYAML(yamlConfig, os.LookupEnv)
+	if err != nil {
+		return ConfigYAML{}, fmt.Errorf("failure expanding environment variables: %w", err)
+	}
+
+	return decodeConfig(yamlConfig, format)
+}
+
+// CheckConfig checks if a configuration is syntactically parseable (as YAML or JSON),
+// without any unknown fields. Unlike [ParseConfigYAML], environment variables are not
+// expanded, so that a configuration can be checked without them being set.
+func CheckConfig(yamlConfig []byte) error {
+	format, err := configFormat(yamlConfig)
+	if err != nil {
+		return err
+	}
+
+	if _, err := decodeConfig(yamlConfig, format); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// configFormat returns the format of a configuration, which is "JSON" for a JSON object
+// (as emitted by provisioning systems) and "YAML" otherwise. A JSON configuration is also
+// validated as such, so that its syntax errors are reported with their offset.
+func configFormat(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return "YAML", nil
+	}
+
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return "", fmt.Errorf("failure parsing JSON: %w: %w", errInvalidJSON, err)
+	}
+
+	return "JSON", nil
+}
+
+// decodeConfig decodes a configuration into a [ConfigYAML], rejecting any unknown fields.
+// JSON is decoded as YAML (being a subset of it), with the same field names as for YAML.
+// Bare integers for the top-level durations are interpreted as seconds (as for the nested
+// ones, see [decodeYAMLDurations]), being replaced within the configuration for the strict
+// decoder (see [replaceYAMLScalars]), so that it reports the line numbers of the original.
+func decodeConfig(data []byte, format string) (ConfigYAML, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return ConfigYAML{}, fmt.Errorf("failure parsing %s: %w", format, err)
+	}
+	if len(doc.Content) > 0 {
+		root, changed, err := yamlSecondsToDurations(doc.Content[0], reflect.TypeFor[ConfigYAML]())
+		if err != nil {
+			return ConfigYAML{}, fmt.Errorf("failure parsing %s: %w", format, err)
+		}
+		if replaced, ok := replaceYAMLScalars(data, doc.Content[0], root); changed && ok {
+			data = replaced
+		} else if changed {
+			// Re-encoded as a last resort (with the line numbers no longer those of the original).
+			doc.Content[0] = root
+			if data, err = yaml.Marshal(&doc); err != nil {
+				return ConfigYAML{}, fmt.Errorf("failure encoding %s: %w", format, err)
+			}
+		}
+	}
+
+	var config ConfigYAML
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil {
+		return ConfigYAML{}, fmt.Errorf("failure parsing %s: %w", format, err)
+	}
+
+	return config, nil
+}
+
+// configuredDevice is a [DeviceYAML] with the index of its entry in the configuration.
+type configuredDevice struct {
+	index   int
+	cfg     DeviceYAML
+	pattern string // glob pattern the device was expanded from (if any)
+}
+
+// isExplicitDevice returns if a device path (or its SAS address, as per the [DeviceLookuper]) is
+// among the paths and addresses of the explicitly configured devices (see [expandDeviceGlobs]).
+func isExplicitDevice(explicit map[string]struct{}, dev string, finder DeviceLookuper) bool {
+	if _, ok := explicit[strings.ToLower(dev)]; ok {
+		return true
+	}
+
+	if finder != nil {
+		if addr, ok := finder.FindAddress(dev); ok && addr != "" {
+			_, ok := explicit[normalizeSASAddress(addr)]
+
+			return ok
+		}
+	}
+
+	return false
+}
+
+// expandDeviceGlobs expands the enabled devices with a glob pattern as device path (e.g. "/dev/sg*")
+// into one device per matching SES enclosure, each inheriting the settings of its entry. Matches
+// that are not SES enclosures (as per the [DeviceLookuper]) are skipped with a warning, matches that
+// are configured explicitly (by device path or SAS address, regardless if enabled) are skipped as well.
+// The expanded devices are returned after all other ones, so explicitly configured devices take precedence.
+// Devices of [DeviceTypeFile] keep their pattern, as the newest matching file is read on every poll.
+func expandDeviceGlobs(
+	devices []DeviceYAML, fsys afero.Fs, getFinder func() DeviceLookuper, logger *slog.Logger,
+) ([]configuredDevice, error) {
+	out := make([]configuredDevice, 0, len(devices))
+	var expanded []configuredDevice
+
+	explicit := make(map[string]struct{})
+	for _, deviceCfg := range devices {
+		if deviceCfg.Type == DeviceTypeFile || isGlobPattern(deviceCfg.Device) {
+			continue
+		}
+		if deviceCfg.Device != "" {
+			explicit[strings.ToLower(deviceCfg.Device)] = struct{}{}
+		}
+		if deviceCfg.Address != "" {
+			explicit[normalizeSASAddress(deviceCfg.Address)] = struct{}{}
+		}
+	}
+
+	for i, deviceCfg := range devices {
+		if !deviceCfg.Enabled || deviceCfg.Type == DeviceTypeFile || !isGlobPattern(deviceCfg.Device) {
+			out = append(out, configuredDevice{index: i, cfg: deviceCfg})
+
+			continue
+		}
+
+		if deviceCfg.Address != "" {
+			return nil, fmt.Errorf("[config:%d] %w: device pattern [%s] cannot have an address",
+				i, errInvalidArgument, deviceCfg.Device)
+		}
+
+		if _, err := filepath.Match(deviceCfg.Device, ""); err != nil {
+			return nil, fmt.Errorf("[config:%d] %w: device pattern [%s] failure: %w",
+				i, errInvalidArgument, deviceCfg.Device, err)
+		}
+
+		matches, err := afero.Glob(fsys, deviceCfg.Device)
+		if err != nil {
+			return nil, fmt.Errorf("[config:%d] failure globbing device pattern [%s]: %w",
+				i, deviceCfg.Device, err)
+		}
+
+		finder := getFinder()
+
+		var enclosures map[string]struct{}
+		if finder != nil {
+			enclosures = make(map[string]struct{})
+			for _, dev := range finder.FindEnclosures() {
+				enclosures[dev] = struct{}{}
+			}
+		} else {
+			logger.Warn(fmt.Sprintf("Devices matching pattern [%s] cannot be verified as SES enclosures "+
+				"(no lookup table)", deviceCfg.Device), LogEvent("warning"))
+		}
+
+		var matched int
+		for _, match := range matches {
+			if _, ok := enclosures[match]; enclosures != nil && !ok {
+				logger.Warn(fmt.Sprintf("Device [%s] matching pattern [%s] is not an SES enclosure (skipping it)",
+					match, deviceCfg.Device), LogEvent("warning"))
+
+				continue
+			}
+			if isExplicitDevice(explicit, match, finder) {
+				logger.Info(fmt.Sprintf("Device [%s] matching pattern [%s] is configured explicitly (skipping it)",
+					match, deviceCfg.Device), LogEvent("device_glob"))
+
+				continue
+			}
+
+			matchCfg := deviceCfg
+			matchCfg.Device = match
+			expanded = append(expanded, configuredDevice{index: i, cfg: matchCfg, pattern: deviceCfg.Device})
+			matched++
+		}
+
+		if matched == 0 {
+			logger.Warn(fmt.Sprintf("Device pattern [%s] does not match any SES enclosures", deviceCfg.Device), LogEvent("warning"))
+		} else {
+			logger.Info(fmt.Sprintf("Device pattern [%s] was expanded to %d SES enclosures", deviceCfg.Device, matched), LogEvent("device_glob"))
+		}
+	}
+
+	return append(out, expanded...), nil
+}
+
+// discoverDevices establishes monitors for all SES enclosures found by a [DeviceLookuper],
+// which are neither already configured (explicitly, regardless if enabled) nor excluded.
+// The monitors are established with the shared defaults (if any) for discovered devices.
+func (p *Program) discoverDevices(
+	config ConfigYAML, finder DeviceLookuper, fsys afero.Fs, r CommandRunner, o io.Writer,
+	seenOutputDirs map[string]bool,
+) error {
+	if finder == nil {
+		return fmt.Errorf("%w: auto discovery needs the address lookup table (not available)",
+			errDeviceLookupFailed)
+	}
+
+	template := DeviceYAML{}
+	if config.AutoDiscoverYAML != nil {
+		template = *config.AutoDiscoverYAML
+		if template.Device != "" || template.Address != "" {
+			return fmt.Errorf("%w: defaults for discovered devices cannot have device or address",
+				errInvalidArgument)
+		}
+	}
+	if template.Description == "" {
+		template.Description = "Auto-discovered enclosure"
+	}
+
+	excluded := make(map[string]struct{})
+	for _, e := range config.Exclude {
+		if strings.HasPrefix(e, "/") {
+			excluded[strings.ToLower(e)] = struct{}{}
+		} else {
+			excluded[normalizeSASAddress(e)] = struct{}{}
+		}
+	}
+	for _, deviceCfg := range config.Devices {
+		if deviceCfg.Device != "" {
+			excluded[strings.ToLower(deviceCfg.Device)] = struct{}{}
+		}
+		if deviceCfg.Address != "" {
+			excluded[normalizeSASAddress(deviceCfg.Address)] = struct{}{}
+		}
+	}
+
+	for _, dev := range finder.FindEnclosures() {
+		addr, _ := finder.FindAddress(dev)
+
+		if _, ok := excluded[strings.ToLower(dev)]; ok {
+			continue
+		}
+		if _, ok := excluded[normalizeSASAddress(addr)]; addr != "" && ok {
+			continue
+		}
+		if p.monitorsPath(dev) {
+			continue
+		}
+		if _, ok := p.monitors[normalizeSASAddress(addr)]; addr != "" && ok {
+			p.logger.Info(fmt.Sprintf("Device [%s] was auto-discovered with SAS address [%s] - "+
+				"already monitored through another device path", dev, addr), LogEvent("discover"))
+
+			continue
+		}
+
+		deviceCfg := template
+		deviceCfg.Device = dev
+		deviceCfg.Address = addr
+		deviceCfg.Enabled = true
+
+		if template.MonitorConfig != nil && template.MonitorConfig.OutputDir != nil {
+			mcfg := *template.MonitorConfig
+			mcfg.OutputDir = ptr(filepath.Join(*template.MonitorConfig.OutputDir, sanitizeDirName(fne(addr, dev))))
+			deviceCfg.MonitorConfig = &mcfg
+		}
+
+		deviceCfg = applyDeviceDefaults(deviceCfg, config.Defaults)
+		deviceCfg = applyOutputBase(deviceCfg, config.OutputBase)
+
+		if deviceCfg.MonitorConfig != nil && deviceCfg.MonitorConfig.OutputDir != nil {
+			if seenOutputDirs[*deviceCfg.MonitorConfig.OutputDir] {
+				return fmt.Errorf("%w: cannot use same output directory [%s] "+
+					"for multiple devices", errInvalidArgument, *deviceCfg.MonitorConfig.OutputDir)
+			}
+			seenOutputDirs[*deviceCfg.MonitorConfig.OutputDir] = true
+		}
+
+		if addr != "" {
+			p.logger.Info(fmt.Sprintf("Device [%s] was auto-discovered with SAS address [%s] - consider "+
+				"[address: %q] for pinning it in your configuration", dev, addr, addr), LogEvent("discover"))
+		} else {
+			p.logger.Info(fmt.Sprintf("Device [%s] was auto-discovered (without SAS address)", dev), LogEvent("discover"))
+		}
+
+		monitor, err := setupDeviceMonitor(config, deviceCfg, fsys, r, o)
+		if err != nil {
+			return fmt.Errorf("[%s:%s] %w", dev, addr, err)
+		}
+
+		p.monitors[deviceKey(deviceCfg)] = monitor
+		p.deviceCfgs[deviceKey(deviceCfg)] = deviceCfg
+	}
+
+	return nil
+}
+
+// deviceKey returns the stable identity of a (looked up) device configuration, which
+// is its SAS address if available (not changing with a renumbering of the device paths,
+// e.g. after a reboot), or its device path otherwise.
+func deviceKey(deviceCfg DeviceYAML) string {
+	if deviceCfg.Address != "" {
+		return normalizeSASAddress(deviceCfg.Address)
+	}
+
+	return deviceCfg.Device
+}
+
+// monitorsPath returns if any of the monitors is already monitoring a device path.
+// The caller is expected to hold the lock of the [Program] (if it is already shared).
+func (p *Program) monitorsPath(path string) bool {
+	for _, monitor := range p.monitors {
+		if monitor.device.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lazyDeviceLookuper establishes a [DeviceFinder] only when it is first needed,
+// so that the lookup table (sysfs) is not scanned when no device needs resolving.
+type lazyDeviceLookuper struct {
+	fsys      afero.Fs
+	sysfsRoot string
+	logger    *slog.Logger
+
+	finder DeviceLookuper
+	once   sync.Once
+}
+
+// get returns the [DeviceLookuper] (or nil if the lookup table is not available).
+func (l *lazyDeviceLookuper) get() DeviceLookuper {
+	l.once.Do(func() {
+		df, err := NewDeviceFinder(l.fsys, l.sysfsRoot, l.logger)
+		if err != nil {
+			l.logger.Warn(fmt.Sprintf("Address lookup table not available: %v "+
+				"(will not be able to monitor devices only defined by SAS address)", err), LogEvent("warning"))
+
+			return
+		}
+		l.finder = df
+	})
+
+	return l.finder
+}
+
+// applyDeviceDefaults returns the [DeviceYAML] with its unset settings taken from the defaults.
+// The monitor configuration is merged per setting, whereas the notification agents are only
+// inherited (as a whole) if the device has none. A default "output_dir" is used as base path,
+// with a subfolder per device (e.g. "sg25"), unless the device has its own "output_dir".
+func applyDeviceDefaults(deviceCfg DeviceYAML, defaults *DeviceYAML) DeviceYAML {
+	if defaults == nil {
+		return deviceCfg
+	}
+
+	if defaults.MonitorConfig != nil {
+		mcfg := overlayConfig(defaults.MonitorConfig, deviceCfg.MonitorConfig)
+		if defaults.MonitorConfig.OutputDir != nil &&
+			(deviceCfg.MonitorConfig == nil || deviceCfg.MonitorConfig.OutputDir == nil) {
+			mcfg.OutputDir = ptr(filepath.Join(*defaults.MonitorConfig.OutputDir,
+				sanitizeDirName(fne(deviceCfg.Address, deviceCfg.Device))))
+		}
+		deviceCfg.MonitorConfig = mcfg
+	}
+
+	if deviceCfg.Pool == "" {
+		deviceCfg.Pool = defaults.Pool
+	}
+
+	if deviceCfg.ScriptNotifier == nil && deviceCfg.WebhookNotifier == nil &&
+		deviceCfg.SlackNotifier == nil && deviceCfg.MQTTNotifier == nil && deviceCfg.PushNotifier == nil &&
+		deviceCfg.FileNotifier == nil {
+		deviceCfg.ScriptNotifier = defaults.ScriptNotifier
+		deviceCfg.WebhookNotifier = defaults.WebhookNotifier
+		deviceCfg.SlackNotifier = defaults.SlackNotifier
+		deviceCfg.MQTTNotifier = defaults.MQTTNotifier
+		deviceCfg.PushNotifier = defaults.PushNotifier
+		deviceCfg.FileNotifier = defaults.FileNotifier
+	}
+
+	return deviceCfg
+}
+
+// applyOutputBase returns the [DeviceYAML] with an "output_dir" derived from the output base
+// path (if not empty), unless the device already has an "output_dir". The subfolder is named
+// after the SAS address (more stable across reboots) or otherwise the device path of the device.
+func applyOutputBase(deviceCfg DeviceYAML, base string) DeviceYAML {
+	if base == "" || (deviceCfg.MonitorConfig != nil && deviceCfg.MonitorConfig.OutputDir != nil) {
+		return deviceCfg
+	}
+
+	var mcfg DeviceMonitorConfig
+	if deviceCfg.MonitorConfig != nil {
+		mcfg = *deviceCfg.MonitorConfig
+	}
+	mcfg.OutputDir = ptr(filepath.Join(base, sanitizeDirName(fne(deviceCfg.Address, deviceCfg.Device))))
+	deviceCfg.MonitorConfig = &mcfg
+
+	return deviceCfg
+}
+
+// lookupDevice attempts to lookup a single [DeviceYAML] using a [DeviceLookuper].
+// It receives a pointer to a [DeviceYAML] configuration and completes the fields in-place.
+func lookupDevice(deviceCfg *DeviceYAML, finder DeviceLookuper, logger *slog.Logger) error {
+	//nolint:nestif
+	if deviceCfg.Address != "" {
+		if finder != nil {
+			if dev, ok := finder.FindDevice(deviceCfg.Address); ok {
+				logger.Info(fmt.Sprintf("SAS address [%s] was resolved to device [%s]",
+					deviceCfg.Address, dev), LogEvent("lookup"))
+				deviceCfg.Device = dev
+			} else if deviceCfg.Device == "" {
+				return fmt.Errorf("%w: SAS address [%s] is not resolvable (not found)",
+					errDeviceLookupFailed, deviceCfg.Address)
+			} else {
+				logger.Warn(fmt.Sprintf("SAS address [%s] is not resolvable (not found), "+
+					"using provided device path instead", deviceCfg.Address), LogEvent("warning"))
+				deviceCfg.Address = ""
+			}
+		} else {
+			if deviceCfg.Device == "" {
+				return fmt.Errorf("%w: SAS address [%s] is not resolvable (no lookup table)",
+					errDeviceLookupFailed, deviceCfg.Address)
+			}
+			logger.Warn(fmt.Sprintf("SAS address [%s] is not resolvable (no lookup table), "+
+				"using provided device path instead", deviceCfg.Address), LogEvent("warning"))
+			deviceCfg.Address = ""
+		}
+	} else if deviceCfg.Device != "" && finder != nil {
+		if addr, ok := finder.FindAddress(deviceCfg.Device); ok {
+			logger.Info(fmt.Sprintf("Device [%s] was resolved to SAS address [%s] - consider [address: %q] "+
+				"instead of [device: %q] for your configuration (more stable across reboots)",
+				deviceCfg.Device, addr, addr, deviceCfg.Device), LogEvent("lookup"))
+			deviceCfg.Address = addr
+		}
+	}
+
+	return nil
+}
+
+// setupDeviceMonitor creates and sets up the [DeviceMonitor] for a [DeviceYAML].
+func setupDeviceMonitor(cfg ConfigYAML, deviceCfg DeviceYAML, fsys afero.Fs, r CommandRunner, o io.Writer) (*DeviceMonitor, error) {
+	var description string
+	if cfg.LogDescriptions {
+		description = deviceCfg.Description
+	}
+	logger := newLogger(o, cfg.LogJSON, cfg.DisableTimestamps, deviceCfg.Device, deviceCfg.Address, description)
+
+	var runner CommandRunner
+	if r != nil {
+		runner = r
+	} else {
+		runner = &RetryCommandRunner{logger: logger}
+	}
+
+	notifier, err := setupNotifier(deviceCfg, fsys, runner, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failure creating notification agent: %w", err)
+	}
+
+	monitor, err := NewDeviceMonitor(
+		Device{
+			Type:        deviceCfg.Type,
+			Path:        deviceCfg.Device,
+			Address:     deviceCfg.Address,
+			Description: deviceCfg.Description,
+		},
+		deviceCfg.MonitorConfig,
+		fsys,
+		runner,
+		logger,
+		notifier,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failure creating monitoring agent: %w", err)
+	}
+
+	if deviceCfg.Maintenance {
+		monitor.SetMaintenance(true)
+	}
+	monitor.dryRun = cfg.DryRun
+	monitor.pool = deviceCfg.Pool
+
+	return monitor, nil
+}
+
+// withFallback wraps a [Notifier] in a [FallbackNotifier] if fallback notification agents
+// are configured for it (with multiple ones wrapped in a [MultiNotifier] as the fallback).
+// Without any fallback notification agents, the [Notifier] is returned as it is.
+func withFallback(
+	n Notifier, fallback *NotifierFallbackYAML, fsys afero.Fs, runner CommandRunner, logger *slog.Logger,
+) (Notifier, error) {
+	if fallback == nil {
+		return n, nil
+	}
+
+	fn, err := setupNotifier(DeviceYAML{
+		ScriptNotifier:  fallback.ScriptNotifier,
+		WebhookNotifier: fallback.WebhookNotifier,
+		SlackNotifier:   fallback.SlackNotifier,
+		MQTTNotifier:    fallback.MQTTNotifier,
+		PushNotifier:    fallback.PushNotifier,
+		FileNotifier:    fallback.FileNotifier,
+	}, fsys, runner, logger)
+	if err != nil {
+		return nil, fmt.Errorf("fallback: %w", err)
+	}
+	if fn == nil {
+		return nil, fmt.Errorf("fallback: %w: no notification agent configured", errInvalidArgument)
+	}
+
+	return NewFallbackNotifier(n, fn, logger)
+}
+
+// setupNotifier creates the [Notifier] for a [DeviceYAML] (nil if none configured).
+// If multiple notification agents are configured, they are wrapped in a [MultiNotifier].
+func setupNotifier(deviceCfg DeviceYAML, fsys afero.Fs, runner CommandRunner, logger *slog.Logger) (Notifier, error) {
+	var notifiers []Notifier
+
+	if deviceCfg.ScriptNotifier != nil {
+		n, err := NewScriptNotifier(
+			deviceCfg.ScriptNotifier.Script, deviceCfg.ScriptNotifier.Config,
+			fsys, runner, logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("script_notifier: %w", err)
+		}
+		fn, err := withFallback(n, deviceCfg.ScriptNotifier.Fallback, fsys, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("script_notifier: %w", err)
+		}
+		notifiers = append(notifiers, fn)
+	}
+
+	if deviceCfg.WebhookNotifier != nil {
+		n, err := NewWebhookNotifier(
+			deviceCfg.WebhookNotifier.URL, deviceCfg.WebhookNotifier.Headers,
+			deviceCfg.WebhookNotifier.Token, deviceCfg.WebhookNotifier.Config,
+			fsys, logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("webhook_notifier: %w", err)
+		}
+		fn, err := withFallback(n, deviceCfg.WebhookNotifier.Fallback, fsys, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("webhook_notifier: %w", err)
+		}
+		notifiers = append(notifiers, fn)
+	}
+
+	if deviceCfg.SlackNotifier != nil {
+		n, err := NewSlackNotifier(deviceCfg.SlackNotifier.URL, deviceCfg.SlackNotifier.Config, fsys, logger)
+		if err != nil {
+			return nil, fmt.Errorf("slack_notifier: %w", err)
+		}
+		fn, err := withFallback(n, deviceCfg.SlackNotifier.Fallback, fsys, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("slack_notifier: %w", err)
+		}
+		notifiers = append(notifiers, fn)
+	}
+
+	if deviceCfg.MQTTNotifier != nil {
+		n, err := NewMQTTNotifier(
+			deviceCfg.MQTTNotifier.Broker, deviceCfg.MQTTNotifier.Username,
+			deviceCfg.MQTTNotifier.Password, deviceCfg.MQTTNotifier.BaseTopic,
+			deviceCfg.MQTTNotifier.Config, logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("mqtt_notifier: %w", err)
+		}
+		fn, err := withFallback(n, deviceCfg.MQTTNotifier.Fallback, fsys, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("mqtt_notifier: %w", err)
+		}
+		notifiers = append(notifiers, fn)
+	}
+
+	if deviceCfg.PushNotifier != nil {
+		n, err := NewPushNotifier(
+			deviceCfg.PushNotifier.Service, deviceCfg.PushNotifier.URL,
+			deviceCfg.PushNotifier.Token, deviceCfg.PushNotifier.Config,
+			logger,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("push_notifier: %w", err)
+		}
+		fn, err := withFallback(n, deviceCfg.PushNotifier.Fallback, fsys, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("push_notifier: %w", err)
+		}
+		notifiers = append(notifiers, fn)
+	}
+
+	if deviceCfg.FileNotifier != nil {
+		n, err := NewFileNotifier(deviceCfg.FileNotifier.Path, deviceCfg.FileNotifier.Config, fsys, logger)
+		if err != nil {
+			return nil, fmt.Errorf("file_notifier: %w", err)
+		}
+		fn, err := withFallback(n, deviceCfg.FileNotifier.Fallback, fsys, runner, logger)
+		if err != nil {
+			return nil, fmt.Errorf("file_notifier: %w", err)
+		}
+		notifiers = append(notifiers, fn)
+	}
+
+	switch len(notifiers) {
+	case 0:
+		return nil, nil //nolint:nilnil
+	case 1:
+		return notifiers[0], nil
+	default:
+		return NewMultiNotifier(notifiers...)
+	}
+}
+
+// Start begins monitoring all enabled devices.
+func (p *Program) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ctx = ctx
+
+	p.startHealthServer()
+	p.startStatusSocket()
+
+	for _, monitor := range p.monitors {
+		p.startMonitor(monitor)
+	}
+
+	if p.running == 0 {
+		p.doneOnce.Do(func() { close(p.done) })
+	}
+
+	if err := sdNotify(p.notifySocket, sdNotifyReady); err != nil {
+		p.logger.Warn(fmt.Sprintf("Failure notifying service manager about readiness: %v", err), LogEvent("warning"))
+	}
+	if p.notifySocket != "" && p.watchdogInterval > 0 {
+		go p.watchdog(ctx)
+	}
+}
+
+// RunOnce polls all enabled devices a single time (concurrently) and returns once done,
+// instead of monitoring them periodically (see [DeviceMonitor.RunOnce]). An error is
+// returned if any of the device polls has failed (with all such failures joined).
+func (p *Program) RunOnce(ctx context.Context) error {
+	monitors := p.getMonitors()
+	keys := slices.Sorted(maps.Keys(monitors))
+
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+
+	for i, key := range keys {
+		monitor := monitors[key]
+		monitor.onStatus = p.writeOverview
+		monitor.pollSem = p.pollSems[monitor.pool]
+
+		wg.Go(func() {
+			defer RecoverGoPanic("monitor", monitor.logger)
+			errs[i] = monitor.RunOnce(ctx)
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// watchdog periodically notifies the service manager (at half of the watchdog interval),
+// as long as at least one of the monitors is making poll progress (see [DeviceMonitor.Progressing]).
+// If none is, the notifications are withheld, so that the service manager restarts the program.
+func (p *Program) watchdog(ctx context.Context) {
+	defer RecoverGoPanic("watchdog", p.Logger())
+
+	ticker := time.NewTicker(p.watchdogInterval / 2) //nolint:mnd
+	defer ticker.Stop()
+
+	withheld := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			if !p.progressing(now) {
+				if !withheld {
+					p.Logger().Warn("No monitor is making poll progress - withholding watchdog notifications", LogEvent("warning"))
+				}
+				withheld = true
+
+				continue
+			}
+			withheld = false
+
+			if err := sdNotify(p.notifySocket, sdNotifyWatchdog); err != nil {
+				p.Logger().Warn(fmt.Sprintf("Failure notifying service manager watchdog: %v", err), LogEvent("warning"))
+			}
+		}
+	}
+}
+
+// progressing returns if any of the monitors is making poll progress (or if there are none).
+func (p *Program) progressing(now time.Time) bool {
+	monitors := p.getMonitors()
+	if len(monitors) == 0 {
+		return true
+	}
+
+	for _, monitor := range monitors {
+		if monitor.Progressing(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// startMonitor starts a [DeviceMonitor] and tracks it until it is done.
+// The caller is expected to hold the lock of the [Program] when calling.
+func (p *Program) startMonitor(monitor *DeviceMonitor) {
+	p.running++
+
+	monitor.onStatus = p.writeOverview
+	monitor.pollSem = p.pollSems[monitor.pool]
+	monitor.Start(p.ctx)
+
+	logger := p.logger
+	go func() {
+		defer RecoverGoPanic("program-waiter", logger)
+		<-monitor.Done()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.running--
+		if p.running == 0 {
+			p.doneOnce.Do(func() { close(p.done) })
+		}
+	}()
+}
+
+// Reload re-establishes the program from a new YAML configuration string.
+// Monitors of removed or changed devices are stopped, monitors of added or
+// changed devices are started, and monitors of unchanged devices keep running.
+// An invalid configuration is rejected, leaving all running monitors untouched.
+func (p *Program) Reload(yamlConfig []byte) error {
+	newProg, err := NewProgram(yamlConfig, p.fsys, p.finder, p.runner, p.out, p.opts...)
+	if err != nil {
+		return fmt.Errorf("failure establishing program: %w", err)
+	}
+
+	// Maintenance modes are toggled only after unlocking, as this calls the status hooks.
+	var toggles []func()
+	defer func() {
+		for _, toggle := range toggles {
+			toggle()
+		}
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return errProgramStopped
+	default:
+	}
+
+	globalChanged := !reflect.DeepEqual(p.config, newProg.config)
+	if globalChanged {
+		// All monitors are re-established, so also the poll semaphores can be.
+		p.pollSems = newProg.pollSems
+	} else {
+		// Running monitors keep the semaphores of their pools, only new pools are added.
+		for pool, sem := range newProg.pollSems {
+			if _, ok := p.pollSems[pool]; !ok {
+				p.pollSems[pool] = sem
+			}
+		}
+	}
+
+	var started, stopped, unchanged int
+	for key, monitor := range newProg.monitors {
+		if old, ok := p.monitors[key]; ok && !globalChanged && !isDone(old) &&
+			deviceCfgsEqual(p.deviceCfgs[key], newProg.deviceCfgs[key]) {
+			maintenance := newProg.deviceCfgs[key].Maintenance
+			toggles = append(toggles, func() { old.SetMaintenance(maintenance) })
+			newProg.monitors[key] = old
+			unchanged++
+
+			continue
+		}
+		if p.ctx != nil {
+			p.startMonitor(monitor)
+		}
+		started++
+	}
+
+	for key, old := range p.monitors {
+		if newProg.monitors[key] != old {
+			old.Stop()
+			stopped++
+		}
+	}
+
+	healthChanged := p.config.HealthAddr != newProg.config.HealthAddr
+	statusChanged := p.config.StatusSocket != newProg.config.StatusSocket
+
+	p.config = newProg.config
+	p.deviceCfgs = newProg.deviceCfgs
+	p.monitors = newProg.monitors
+	p.logger = newProg.logger
+
+	if healthChanged && p.ctx != nil {
+		p.stopHealthServer()
+		p.startHealthServer()
+	}
+	if statusChanged && p.ctx != nil {
+		p.stopStatusSocket()
+		p.startStatusSocket()
+	}
+
+	p.logger.Info(fmt.Sprintf("Configuration was reloaded (%d monitors started, %d stopped, %d unchanged)",
+		started, stopped, unchanged), LogEvent("reload"))
+
+	return nil
+}
+
+// deviceCfgsEqual returns if two [DeviceYAML] are equal, not considering the maintenance
+// mode (which can be toggled on a running [DeviceMonitor] without re-establishing it).
+func deviceCfgsEqual(a, b DeviceYAML) bool {
+	a.Maintenance, b.Maintenance = false, false
+
+	return reflect.DeepEqual(a, b)
+}
+
+// Stop signals all monitors to stop and shuts down the health endpoints and status socket (if any).
+func (p *Program) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := sdNotify(p.notifySocket, sdNotifyStopping); err != nil {
+		p.logger.Warn(fmt.Sprintf("Failure notifying service manager about stopping: %v", err), LogEvent("warning"))
+	}
+
+	for _, monitor := range p.monitors {
+		monitor.Stop()
+	}
+
+	p.stopHealthServer()
+	p.stopStatusSocket()
+}
+
+// Shutdown stops the program (see [Program.Stop]) and waits for all monitors to be done,
+// but no longer than the configured shutdown timeout (if not zero). Once the timeout is
+// exceeded, the monitors that have not stopped are logged and an error is returned, so
+// that the caller can exit regardless (instead of being killed by the service manager).
+func (p *Program) Shutdown() error {
+	p.Stop()
+
+	timeout := p.shutdownTimeout()
+	if timeout == 0 {
+		<-p.done
+
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-timer.C:
+	}
+
+	var pending []string
+	for _, monitor := range p.getMonitors() {
+		if !isDone(monitor) {
+			pending = append(pending, monitor.device.Path)
+		}
+	}
+	slices.Sort(pending)
+
+	p.Logger().Error(fmt.Sprintf("Shutdown timeout (%s) exceeded - exiting with monitors not stopped: [%s]",
+		timeout, strings.Join(pending, ", ")), LogEvent("shutdown_timeout"))
+
+	return fmt.Errorf("%w: %d monitors not stopped", errShutdownTimeout, len(pending))
+}
+
+// shutdownTimeout returns the configured shutdown timeout (or the default if not configured).
+func (p *Program) shutdownTimeout() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.config.ShutdownTimeout == nil {
+		return defaultShutdownTimeout
+	}
+
+	return *p.config.ShutdownTimeout
+}
+
+// Done returns a channel that's closed when all monitors have stopped.
+func (p *Program) Done() <-chan struct{} {
+	return p.done
+}
+
+// Logger returns the current [log.Logger] of the program.
+func (p *Program) Logger() *slog.Logger {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.logger
+}
+
+// writeOverview writes the [ProgramOverview] of all monitors to the overview file
+// (if configured), which happens whenever the status of any monitor has changed.
+func (p *Program) writeOverview() {
+	p.mu.Lock()
+	path := p.config.OverviewFile
+	fsys, logger := p.fsys, p.logger
+	p.mu.Unlock()
+
+	if path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(p.overview(), "", "  ")
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error marshalling program overview to JSON: %v", err), LogEvent("error"))
+
+		return
+	}
+
+	p.overviewMu.Lock()
+	defer p.overviewMu.Unlock()
+
+	if err := fsys.MkdirAll(filepath.Dir(path), baseFolderPerms); err != nil {
+		logger.Error(fmt.Sprintf("Error writing program overview to file: failure creating directory: %v", err), LogEvent("error"))
+
+		return
+	}
+	if err := writeFileAtomic(fsys, path, data, baseFilePerms); err != nil {
+		logger.Error(fmt.Sprintf("Error writing program overview to file: %v", err), LogEvent("error"))
+	}
+}
+
+// overview returns the [ProgramOverview] with the current [DeviceStatus] of all monitors.
+func (p *Program) overview() ProgramOverview {
+	monitors := p.getMonitors()
+
+	overview := ProgramOverview{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Devices:     make([]DeviceStatus, 0, len(monitors)),
+	}
+	for _, m := range monitors {
+		overview.Devices = append(overview.Devices, m.Status())
+	}
+	slices.SortFunc(overview.Devices, func(a, b DeviceStatus) int {
+		return strings.Compare(a.Device.Path, b.Device.Path)
+	})
+
+	return overview
+}
+
+// TestNotify sends a synthetic (clearly marked) notification with a fake
+// [ChangeReport] using the notification agent of a device (path or address).
+// This is meant for verifying notification agents before relying on them.
+func (p *Program) TestNotify(ctx context.Context, device string) error {
+	monitor := p.findMonitor(device)
+	if monitor == nil {
+		return fmt.Errorf("%q: %w", device, errDeviceNotConfigured)
+	}
+	if monitor.notifier == nil {
+		return fmt.Errorf("%q: %w", device, errNoNotifier)
+	}
+
+	report := testChangeReport(monitor.device)
+	msg := "TEST NOTIFICATION (sesmon test-notify): this is a synthetic alert - " +
+		monitor.changesMessage(report.Changes)
+
+	if err := monitor.notifier.Notify(ctx, monitor.device, msg, report); err != nil {
+		return fmt.Errorf("%s: %w", monitor.notifier.Name(), err)
+	}
+
+	return nil
+}
+
+// Dump polls all devices (or only the given device, by path or address) once and writes
+// their parsed results as indented JSON (keyed by the device paths) to the [io.Writer].
+// It both observes and respects the given context for earlier termination.
+func (p *Program) Dump(ctx context.Context, device string, out io.Writer) error {
+	monitors := p.getMonitors()
+	if device != "" {
+		monitor := p.findMonitor(device)
+		if monitor == nil {
+			return fmt.Errorf("%q: %w", device, errDeviceNotConfigured)
+		}
+		monitors = map[string]*DeviceMonitor{monitor.device.Path: monitor}
+	}
+
+	dump := make(map[string]map[string]Result, len(monitors))
+	for _, monitor := range monitors {
+		path := monitor.device.Path
+
+		ret, _, _, err := monitor.fetchFromDevice(ctx)
+		if err != nil {
+			return fmt.Errorf("%q: failure fetching from device: %w", path, err)
+		}
+
+		results, _, err := parseSES(ret)
+		if err != nil {
+			return fmt.Errorf("%q: failure parsing fetched data: %w", path, err)
+		}
+
+		dump[path] = results
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failure marshalling results to JSON: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		return fmt.Errorf("failure writing results: %w", err)
+	}
+
+	return nil
+}
+
+// CheckTools checks that the commands fetching from the devices (sg_ses or any fetch command)
+// can be found, so that their absence fails at startup rather than with the first device polls.
+// Devices of [DeviceTypeFile] are skipped, problems of all other devices are joined.
+func (p *Program) CheckTools() error {
+	monitors := p.getMonitors()
+
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(monitors)) {
+		if err := monitors[key].checkTool(); err != nil {
+			errs = append(errs, fmt.Errorf("%q: %w", monitors[key].device.Path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ConfigDump writes the effective configurations of all devices (or only the given device,
+// by path or address) as indented JSON (keyed by the device paths) to the [io.Writer].
+func (p *Program) ConfigDump(device string, out io.Writer) error {
+	monitors := p.getMonitors()
+	if device != "" {
+		monitor := p.findMonitor(device)
+		if monitor == nil {
+			return fmt.Errorf("%q: %w", device, errDeviceNotConfigured)
+		}
+		monitors = map[string]*DeviceMonitor{monitor.device.Path: monitor}
+	}
+
+	dump := make(map[string]DeviceConfigDump, len(monitors))
+	for _, monitor := range monitors {
+		entry := DeviceConfigDump{
+			Device: monitor.device,
+			Config: monitor.cfg,
+		}
+		if monitor.notifier != nil {
+			entry.Notifier = monitor.notifier.Name()
+			entry.NotifierConfig = monitor.notifier.Config()
+		}
+
+		dump[monitor.device.Path] = entry
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failure marshalling configurations to JSON: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		return fmt.Errorf("failure writing configurations: %w", err)
+	}
+
+	return nil
+}
+
+// findMonitor returns the [DeviceMonitor] for a device (by path or address), or nil if not found.
+func (p *Program) findMonitor(device string) *DeviceMonitor {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, m := range p.monitors {
+		if m.device.Path == device ||
+			(m.device.Address != "" && normalizeSASAddress(m.device.Address) == normalizeSASAddress(device)) {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// testChangeReport returns a fake (clearly marked) [ChangeReport] for a [Device].
+func testChangeReport(device Device) ChangeReport {
+	return ChangeReport{
+		Device:     device,
+		DetectedAt: time.Now().Format(time.RFC3339),
+		Kind:       ChangeKindDegraded,
+		Severity:   SeverityCritical,
+		Changes: []Change{
+			{
+				ID:       "test-notify",
+				Kind:     ChangeKindDegraded,
+				Severity: SeverityCritical,
+				TypeDesc: ptr("Test element (synthetic)"),
+				Reason:   ptr("synthetic test notification (no action required)"),
+				Before: &Result{
+					TypeDesc:   ptr("Test element (synthetic)"),
+					Status:     ptr(sesStatusOK),
+					StatusDesc: ptr("OK"),
+				},
+				After: &Result{
+					TypeDesc:   ptr("Test element (synthetic)"),
+					Status:     ptr(2), //nolint:mnd
+					StatusDesc: ptr("Critical"),
+				},
+			},
+		},
+	}
+}
+
+// getMonitors returns a copy of the monitors map (for testing).
+func (p *Program) getMonitors() map[string]*DeviceMonitor {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[string]*DeviceMonitor, len(p.monitors))
+
+	maps.Copy(result, p.monitors)
+
+	return result
+}
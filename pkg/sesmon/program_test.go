@@ -0,0 +1,3410 @@
+package sesmon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDeviceFinder struct {
+	addrFound    bool
+	addrResponse string
+	devFound     bool
+	devResponse  string
+	enclosures   []string
+
+	// Addresses by device path (overriding addrResponse if not nil).
+	addresses map[string]string
+}
+
+var _ DeviceLookuper = (*mockDeviceFinder)(nil)
+
+func (m *mockDeviceFinder) SetAddressResponse(addrResponse string, addrFound bool) {
+	m.addrResponse = addrResponse
+	m.addrFound = addrFound
+}
+
+func (m *mockDeviceFinder) SetDeviceResponse(devResponse string, devFound bool) {
+	m.devResponse = devResponse
+	m.devFound = devFound
+}
+
+func (m *mockDeviceFinder) FindAddress(devicePath string) (string, bool) {
+	if m.addresses != nil {
+		addr, ok := m.addresses[devicePath]
+
+		return addr, ok
+	}
+
+	return m.addrResponse, m.addrFound
+}
+
+func (m *mockDeviceFinder) FindDevice(deviceAddress string) (string, bool) {
+	return m.devResponse, m.devFound
+}
+
+func (m *mockDeviceFinder) FindEnclosures() []string {
+	return m.enclosures
+}
+
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *safeBuffer) Write(p []byte) (n int, err error) { //nolint:nonamedreturns
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.buf.Write(p) //nolint:wrapcheck
+}
+
+func (sb *safeBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+
+	return sb.buf.String()
+}
+
+// Expectation: NewProgram should use the default fsys and runner (when nil).
+func Test_NewProgram_Success(t *testing.T) {
+	t.Parallel()
+
+	yaml := []byte(`
+devices:
+  - device: /dev/null
+    description: "Device 1"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, nil, nil, nil, &buf)
+
+	require.NoError(t, err)
+
+	require.NotNil(t, program)
+	require.NotNil(t, program.monitors["/dev/null"].fsys)
+	require.NotNil(t, program.monitors["/dev/null"].runner)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+}
+
+// Expectation: NewProgram should return error for invalid YAML syntax.
+func Test_NewProgram_InvalidYAMLSyntax_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure parsing YAML")
+}
+
+// Expectation: NewProgram should return error when devices list is empty.
+func Test_NewProgram_EmptyDevicesList_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	yaml := []byte(`devices: []`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no devices configured")
+}
+
+// Expectation: NewProgram should return error when devices key is missing.
+func Test_NewProgram_MissingDevicesKey_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	yaml := []byte(`disable_timestamps: true`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no devices configured")
+}
+
+// Expectation: NewProgram should return error when unknown fields are present at root level.
+func Test_NewProgram_UnknownFieldAtRoot_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+unknown_field: value
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field unknown_field not found")
+}
+
+// Expectation: NewProgram should accept a configuration given as JSON (with the same field names).
+func Test_NewProgram_JSONConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	json := []byte(`{
+	"disable_timestamps": true,
+	"devices": [
+		{
+			"device": "/dev/sg0",
+			"description": "Test",
+			"enabled": true,
+			"config": {"poll_interval": "2m", "poll_attempts": 2}
+		}
+	]
+}`)
+
+	var buf safeBuffer
+	program, err := NewProgram(json, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	require.True(t, program.config.DisableTimestamps)
+	require.Len(t, program.monitors, 1)
+	require.Equal(t, 2*time.Minute, *program.monitors["/dev/sg0"].cfg.PollInterval)
+	require.Equal(t, 2, *program.monitors["/dev/sg0"].cfg.PollAttempts)
+}
+
+// Expectation: NewProgram should reject JSON configurations with syntax errors or unknown fields.
+func Test_NewProgram_JSONConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	var buf safeBuffer
+	_, err := NewProgram([]byte(`{"devices": [{"device": "/dev/sg0",}]}`), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidJSON)
+	require.ErrorContains(t, err, "failure parsing JSON")
+
+	_, err = NewProgram([]byte(`{"devices": [{"device": "/dev/sg0", "unknown_field": 1}]}`), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorContains(t, err, "failure parsing JSON")
+	require.ErrorContains(t, err, "field unknown_field not found")
+}
+
+// Expectation: CheckConfig should accept valid configurations without expanding environment variables.
+func Test_CheckConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, CheckConfig([]byte("devices:\n  - device: ${SESMON_UNSET_DEVICE}\n")))
+	require.NoError(t, CheckConfig([]byte(`{"devices": [{"device": "/dev/sg0"}]}`)))
+}
+
+// Expectation: CheckConfig should reject configurations with syntax errors or unknown fields.
+func Test_CheckConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorIs(t, CheckConfig([]byte(`{"devices": [}`)), errInvalidJSON)
+	require.ErrorContains(t, CheckConfig([]byte("unknown_field: true\n")), "field unknown_field not found")
+}
+
+// Expectation: NewProgram should return error when unknown fields are present in device config.
+func Test_NewProgram_UnknownFieldInDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+    unknown_device_field: value
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field unknown_device_field not found")
+}
+
+// Expectation: NewProgram should reject unknown fields with their line numbers in the original
+// configuration, also with a bare integer (as seconds) given for a top-level duration.
+func Test_NewProgram_UnknownFieldDurationBareSeconds_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`# Comment
+shutdown_timeout: 45
+
+devices:
+  - device: /dev/sg0
+    enabled: true
+    unknown_device_field: value
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 7: field unknown_device_field not found")
+
+	_, err = NewProgram([]byte(`{"shutdown_timeout": 45, "devices": [{"device": "/dev/sg0", "enabled": true, "unknown": 1}]}`),
+		fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 1: field unknown not found")
+}
+
+// Expectation: NewProgram should return error when unknown fields are present in script_notifier.
+func Test_NewProgram_UnknownFieldInScriptNotifier_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+      unknown_notifier_field: value
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field unknown_notifier_field not found")
+}
+
+// Expectation: NewProgram should return error when unknown fields are present in monitor config.
+func Test_NewProgram_UnknownFieldInMonitorConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+    config:
+      poll_interval: 60s
+      unknown_config_field: value
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field unknown_config_field not found")
+}
+
+// Expectation: NewProgram should create monitors for all enabled devices.
+func Test_NewProgram_AllDevicesEnabled_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Device 1"
+    enabled: true
+  - device: /dev/sg1
+    description: "Device 2"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+}
+
+// Expectation: NewProgram should create zero monitors when all devices are disabled.
+func Test_NewProgram_AllDevicesDisabled_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Device 1"
+    enabled: false
+  - device: /dev/sg1
+    description: "Device 2"
+    enabled: false
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Empty(t, monitors)
+}
+
+// Expectation: NewProgram should create monitors only for enabled devices when mixed.
+func Test_NewProgram_MixedEnabledDisabled_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Enabled"
+    enabled: true
+  - device: /dev/sg1
+    description: "Disabled"
+    enabled: false
+  - device: /dev/sg2
+    description: "Enabled"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+}
+
+// Expectation: NewProgram should treat devices without enabled field as disabled.
+func Test_NewProgram_DefaultEnabledFalse_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "No enabled field"
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Empty(t, monitors)
+}
+
+// Expectation: NewProgram should configure logger with timestamps when enabled.
+func Test_NewProgram_LoggerWithTimestamps_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	ctx := t.Context()
+	program.Start(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	program.Stop()
+	<-program.Done()
+
+	output := buf.String()
+	require.Contains(t, output, strconv.Itoa(time.Now().Year()))
+	require.Contains(t, output, "/dev/sg0:")
+}
+
+// Expectation: NewProgram should configure logger without timestamps when disabled.
+func Test_NewProgram_LoggerWithoutTimestamps_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+disable_timestamps: true
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	ctx := t.Context()
+	program.Start(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	program.Stop()
+	<-program.Done()
+
+	output := buf.String()
+	require.NotContains(t, output, strconv.Itoa(time.Now().Year()))
+	require.Contains(t, output, "/dev/sg0:")
+}
+
+// Expectation: NewProgram should configure separate logger prefix for each device.
+func Test_NewProgram_MultipleDeviceLoggerPrefixes_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Device 0"
+    enabled: true
+  - device: /dev/sg1
+    description: "Device 1"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	ctx := t.Context()
+	program.Start(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	program.Stop()
+	<-program.Done()
+
+	output := buf.String()
+	require.Contains(t, output, "/dev/sg0:")
+	require.Contains(t, output, "/dev/sg1:")
+}
+
+// Expectation: NewProgram should successfully create device with script notifier.
+func Test_NewProgram_DeviceWithScriptNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With notifier"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+	require.NotNil(t, program.monitors["/dev/sg0"].notifier)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+}
+
+// Expectation: NewProgram should wrap a notifier with a nested fallback notifier.
+func Test_NewProgram_DeviceWithFallbackNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With fallback notifier"
+    enabled: true
+    webhook_notifier:
+      url: https://example.com/hook
+      fallback:
+        script_notifier:
+          script: /usr/local/bin/notify.sh
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+	require.IsType(t, &FallbackNotifier{}, program.monitors["/dev/sg0"].notifier)
+	require.Equal(t, "webhook_notifier|script_notifier", program.monitors["/dev/sg0"].notifier.Name())
+}
+
+// Expectation: NewProgram should set up the file notifier (also inherited from the defaults).
+func Test_NewProgram_DeviceWithFileNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+defaults:
+  file_notifier:
+    path: /var/log/sesmon-alerts.log
+    config:
+      max_size: 0
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - device: /dev/sg1
+    enabled: true
+    webhook_notifier:
+      url: https://example.com/hook
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.IsType(t, &FileNotifier{}, program.monitors["/dev/sg0"].notifier)
+	require.Equal(t, 0, *program.monitors["/dev/sg0"].notifier.(*FileNotifier).cfg.MaxSize)
+	require.Equal(t, "webhook_notifier", program.monitors["/dev/sg1"].notifier.Name())
+}
+
+// Expectation: NewProgram should return an error for an empty or invalid fallback notifier.
+func Test_NewProgram_DeviceWithFallbackNotifier_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    webhook_notifier:
+      url: https://example.com/hook
+      fallback: {}
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "webhook_notifier: fallback")
+
+	yaml = []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    webhook_notifier:
+      url: https://example.com/hook
+      fallback:
+        script_notifier:
+          script: /nonexistent/notify.sh
+`)
+
+	_, err = NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorContains(t, err, "webhook_notifier: fallback: script_notifier")
+}
+
+// Expectation: NewProgram should successfully create device without notifier (nil notifier).
+func Test_NewProgram_DeviceWithoutNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "No notifier"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+	require.Nil(t, program.monitors["/dev/sg0"].notifier)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+}
+
+// Expectation: NewProgram should successfully create notifier with custom config.
+func Test_NewProgram_NotifierWithCustomConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Custom config"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+      config:
+        notify_attempts: 5
+        notify_attempt_timeout: 20s
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	require.NotNil(t, program.monitors["/dev/sg0"].notifier)
+	n, ok := program.monitors["/dev/sg0"].notifier.(*ScriptNotifier)
+	require.True(t, ok)
+	require.Equal(t, "/usr/local/bin/notify.sh", n.script)
+	require.Equal(t, 5, *n.cfg.NotifyAttempts)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+}
+
+// Expectation: NewProgram should successfully create device with webhook notifier.
+func Test_NewProgram_DeviceWithWebhookNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With webhook"
+    enabled: true
+    webhook_notifier:
+      url: https://example.com/hook
+      token: secret
+      headers:
+        X-Custom: value
+      config:
+        notify_attempts: 5
+        tls_skip_verify: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	n, ok := program.monitors["/dev/sg0"].notifier.(*WebhookNotifier)
+	require.True(t, ok)
+	require.Equal(t, "https://example.com/hook", n.url)
+	require.Equal(t, "secret", n.token)
+	require.Equal(t, map[string]string{"X-Custom": "value"}, n.headers)
+	require.Equal(t, 5, *n.cfg.NotifyAttempts)
+	require.True(t, *n.cfg.TLSSkipVerify)
+}
+
+// Expectation: NewProgram should successfully create device with slack notifier.
+func Test_NewProgram_DeviceWithSlackNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With slack"
+    enabled: true
+    slack_notifier:
+      url: https://hooks.slack.com/services/T0/B0/X
+      config:
+        notify_attempts: 5
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	n, ok := program.monitors["/dev/sg0"].notifier.(*SlackNotifier)
+	require.True(t, ok)
+	require.Equal(t, "https://hooks.slack.com/services/T0/B0/X", n.webhook.url)
+	require.Equal(t, 5, *n.webhook.cfg.NotifyAttempts)
+}
+
+// Expectation: NewProgram should successfully create device with mqtt notifier.
+func Test_NewProgram_DeviceWithMQTTNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With mqtt"
+    enabled: true
+    mqtt_notifier:
+      broker: tcp://broker:1883
+      username: user
+      password: pass
+      base_topic: home/sesmon
+      config:
+        qos: 2
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	n, ok := program.monitors["/dev/sg0"].notifier.(*MQTTNotifier)
+	require.True(t, ok)
+	require.Equal(t, "tcp://broker:1883", n.broker)
+	require.Equal(t, "home/sesmon", n.baseTopic)
+	require.Equal(t, 2, *n.cfg.QoS)
+}
+
+// Expectation: NewProgram should wrap multiple configured notifiers into a MultiNotifier.
+func Test_NewProgram_DeviceWithMultipleNotifiers_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With notifiers"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+    webhook_notifier:
+      url: https://example.com/hook
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	n, ok := program.monitors["/dev/sg0"].notifier.(*MultiNotifier)
+	require.True(t, ok)
+	require.Len(t, n.notifiers, 2)
+	require.Equal(t, "script_notifier+webhook_notifier", n.Name())
+}
+
+// Expectation: NewProgram should return error when a notifier has an invalid message template.
+func Test_NewProgram_DeviceWithInvalidMessageTemplate_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    webhook_notifier:
+      url: https://example.com/hook
+      config:
+        message_template: "{{.Device.Name}}"
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, program)
+	require.Contains(t, err.Error(), "message_template")
+}
+
+// Expectation: NewProgram should return error when the webhook notifier has an invalid URL.
+func Test_NewProgram_DeviceWithInvalidWebhookNotifier_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    webhook_notifier:
+      url: not-a-url
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "webhook_notifier")
+}
+
+// Expectation: NewProgram should successfully create monitor with default config.
+func Test_NewProgram_MonitorWithDefaultConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Default config"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+}
+
+// Expectation: NewProgram should successfully create monitor with custom config.
+func Test_NewProgram_MonitorWithCustomConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Custom config"
+    enabled: true
+    config:
+      poll_interval: 60s
+      poll_attempts: 5
+      output_dir: /tmp/output
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+	require.Equal(t, 60*time.Second, *program.monitors["/dev/sg0"].cfg.PollInterval)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+}
+
+// Expectation: NewProgram should successfully handle complex multi-device configuration.
+func Test_NewProgram_ComplexConfiguration_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg3", []byte{}, 0o655))
+
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+disable_timestamps: true
+devices:
+  - device: /dev/sg0
+    description: "Primary Storage"
+    enabled: true
+    config:
+      poll_interval: 60s
+      poll_attempts: 5
+      output_dir: /var/log/sg0
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+      config:
+        notify_attempts: 3
+        notify_attempt_timeout: 15s
+
+  - device: /dev/sg1
+    description: "Secondary Storage"
+    enabled: true
+    config:
+      poll_interval: 120s
+
+  - device: /dev/sg2
+    description: "Disabled device"
+    enabled: false
+
+  - device: /dev/sg3
+    description: "Monitor only, no notifications"
+    enabled: true
+    config:
+      output_dir: /var/log/sg3
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 3)
+	require.Equal(t, 60*time.Second, *monitors["/dev/sg0"].cfg.PollInterval)
+	require.Equal(t, 120*time.Second, *monitors["/dev/sg1"].cfg.PollInterval)
+	require.Equal(t, "/var/log/sg3", *monitors["/dev/sg3"].cfg.OutputDir)
+}
+
+// Expectation: NewProgram should return error when both device and address are missing.
+func Test_NewProgram_MissingDeviceAndAddress_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	yaml := []byte(`
+devices:
+  - description: "Missing both"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "missing device and address")
+}
+
+// Expectation: NewProgram should successfully create monitor using address when resolved.
+func Test_NewProgram_AddressResolved_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg0", true)
+
+	yaml := []byte(`
+devices:
+  - address: "0:0:0:0"
+    description: "Using address"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+	require.Equal(t, "/dev/sg0", monitors["0x0:0:0:0"].device.Path)
+}
+
+// Expectation: NewProgram should successfully create monitor using device path fallback.
+func Test_NewProgram_AddressNotResolvedFallbackToDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("", false)
+
+	yaml := []byte(`
+devices:
+  - address: "0:0:0:0"
+    device: /dev/sg0
+    description: "Fallback to device"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+	require.Equal(t, "/dev/sg0", monitors["/dev/sg0"].device.Path)
+}
+
+// Expectation: NewProgram should return error when address cannot be resolved and no device path.
+func Test_NewProgram_AddressNotResolvedNoDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("", false)
+
+	yaml := []byte(`
+devices:
+  - address: "0:0:0:0"
+    description: "No device fallback"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errDeviceLookupFailed)
+	require.Contains(t, err.Error(), "not found")
+}
+
+// Expectation: NewProgram should suggest using address when device resolves to address.
+func Test_NewProgram_DeviceResolvesToAddress_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	finder := &mockDeviceFinder{}
+	finder.SetAddressResponse("0:0:0:0", true)
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Device with address lookup"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+
+	output := buf.String()
+	require.Contains(t, output, "was resolved to")
+	require.Contains(t, output, "0:0:0:0")
+	require.Contains(t, output, "consider [address:")
+}
+
+// Expectation: NewProgram should work with multiple devices using mixed address/device paths.
+func Test_NewProgram_MixedAddressAndDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg0", true)
+	finder.SetAddressResponse("1:0:0:0", true)
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg1
+    description: "Using device"
+    enabled: true
+  - address: "2:0:0:0"
+    device: /dev/sg2
+    description: "Both specified"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+	require.Equal(t, "/dev/sg1", monitors["0x1:0:0:0"].device.Path) // keyed by resolved address
+	require.Equal(t, "/dev/sg0", monitors["0x2:0:0:0"].device.Path) // resolved address to sg0
+}
+
+// Expectation: deviceKey should prefer the (normalized) address over the device path.
+func Test_deviceKey_Success(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "/dev/sg0", deviceKey(DeviceYAML{Device: "/dev/sg0"}))
+	require.Equal(t, "0x5000c500a1b2c3d4", deviceKey(DeviceYAML{Device: "/dev/sg0", Address: "5000C500A1B2C3D4"}))
+	require.Equal(t, "0x5000", deviceKey(DeviceYAML{Address: "0x5000"}))
+}
+
+// Expectation: NewProgram should key the monitors by address, so they are the same after a renumbering.
+func Test_NewProgram_AddressKeyRenumbered_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg5", []byte{}, 0o644))
+
+	yaml := []byte(`
+output_base: /var/lib/sesmon
+devices:
+  - address: "0x5000"
+    enabled: true
+`)
+
+	for _, dev := range []string{"/dev/sg0", "/dev/sg5"} {
+		finder := &mockDeviceFinder{}
+		finder.SetDeviceResponse(dev, true)
+
+		program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &safeBuffer{})
+		require.NoError(t, err)
+
+		monitor := program.getMonitors()["0x5000"]
+		require.NotNil(t, monitor)
+		require.Equal(t, dev, monitor.device.Path)
+		require.Equal(t, "/var/lib/sesmon/0x5000", *monitor.cfg.OutputDir)
+	}
+}
+
+// Expectation: NewProgram should not auto-discover another device path of an already monitored address.
+func Test_NewProgram_AutoDiscoverSameAddress_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+auto_discover: true
+`)
+
+	finder := &mockDeviceFinder{
+		enclosures: []string{"/dev/sg0", "/dev/sg1"},
+		addresses:  map[string]string{"/dev/sg0": "0x5000", "/dev/sg1": "0x5000"},
+	}
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+	require.Equal(t, "/dev/sg0", monitors["0x5000"].device.Path)
+	require.Contains(t, buf.String(), "Device [/dev/sg1] was auto-discovered with SAS address [0x5000] - "+
+		"already monitored through another device path")
+}
+
+// Expectation: NewProgram should error when trying to monitor duplicate devices.
+func Test_NewProgram_DuplicateMonitor_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg0", true)
+	finder.SetAddressResponse("1:0:0:0", true)
+
+	yaml := []byte(`
+devices:
+  - address: "0:0:0:0"
+    description: "Resolves to /dev/sg0"
+    enabled: true
+  - device: /dev/sg1
+    description: "Using device"
+    enabled: true
+  - address: "2:0:0:0"
+    device: /dev/sg2
+    description: "Resolves to /dev/sg0 also (duplicate!)"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "multiple")
+	require.Nil(t, program)
+}
+
+// Expectation: NewProgram should name both SAS addresses when they resolve to the same device.
+func Test_NewProgram_DuplicateAddresses_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg0", true)
+
+	yaml := []byte(`
+devices:
+  - address: "0x500a098012345678"
+    enabled: true
+  - address: "0x500a098087654321"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "[config:1] invalid argument: SAS addresses [0x500a098012345678] (config:0) "+
+		"and [0x500a098087654321] both resolve to device [/dev/sg0]")
+	require.Nil(t, program)
+}
+
+// Expectation: NewProgram should handle nil finder gracefully when only device paths specified.
+func Test_NewProgram_NilFinderDevicePaths_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Device 0"
+    enabled: true
+  - device: /dev/sg1
+    description: "Device 1"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, nil, &mockCommandRunner{}, &buf)
+
+	require.NoError(t, err)
+	require.NotNil(t, program)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+}
+
+// Expectation: NewProgram should return error when multiple devices use the same output directory.
+func Test_NewProgram_SameOutputDirs_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: ""
+    enabled: true
+    config:
+      output_dir: /tmp
+  - device: /dev/sg1
+    description: ""
+    enabled: true
+    config:
+      output_dir: /tmp
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "same output directory")
+}
+
+// Expectation: NewProgram should return error when invalid values are present in monitor config.
+func Test_NewProgram_Integration_InvalidValueInMonitorConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+    config:
+      poll_interval: 60s
+      poll_attempts: 0
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: NewProgram should return error when invalid values are present in notifier config.
+func Test_NewProgram_Integration_InvalidValueInNotifierConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "With notifier"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+      config:
+        notify_attempts: 0
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: Program should start and stop successfully.
+func Test_Program_StartStop_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	program.Start(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+
+	program.Stop()
+	<-program.Done()
+
+	select {
+	case <-program.Done():
+		require.Contains(t, buf.String(), "Monitoring")
+		require.Contains(t, buf.String(), "shutting down")
+	case <-time.After(2 * time.Second):
+		t.Error("Program did not complete within timeout")
+	}
+}
+
+// Expectation: Shutdown should stop all monitors and return once they are done.
+func Test_Program_Shutdown_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+shutdown_timeout: 5s
+devices:
+  - device: /dev/sg0
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, program.Shutdown())
+	require.True(t, isDone(program.getMonitors()["/dev/sg0"]))
+	require.NotContains(t, buf.String(), "Shutdown timeout")
+}
+
+// Expectation: Shutdown should give up on (and log) monitors not stopping within the timeout.
+func Test_Program_Shutdown_Timeout_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+shutdown_timeout: 200ms
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - device: /dev/sg1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	time.Sleep(100 * time.Millisecond)
+
+	// Simulate a notification that is stuck (and not respecting the context).
+	stuck := program.getMonitors()["/dev/sg1"]
+	release := make(chan struct{})
+	stuck.state.notifications.Add(1)
+	defer func() {
+		close(release)
+		<-stuck.Done()
+	}()
+	go func() {
+		<-release
+		stuck.state.notifications.Done()
+	}()
+
+	err = program.Shutdown()
+	require.ErrorIs(t, err, errShutdownTimeout)
+	require.Contains(t, buf.String(), "Shutdown timeout (200ms) exceeded - exiting with monitors not stopped: [/dev/sg1]")
+	require.True(t, isDone(program.getMonitors()["/dev/sg0"]))
+}
+
+// Expectation: NewProgram should reject a negative shutdown timeout.
+func Test_NewProgram_ShutdownTimeout_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+shutdown_timeout: -1s
+devices:
+  - device: /dev/sg0
+    enabled: true
+`)
+
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &safeBuffer{})
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "shutdown_timeout must be >= 0")
+}
+
+// Expectation: NewProgram should include the device descriptions in log lines (only if configured).
+func Test_NewProgram_LogDescriptions_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	for _, enabled := range []bool{false, true} {
+		yaml := []byte(`
+disable_timestamps: true
+log_descriptions: ` + strconv.FormatBool(enabled) + `
+devices:
+  - device: /dev/sg0
+    description: "Top JBOD - Row 2"
+    enabled: true
+`)
+
+		var buf safeBuffer
+		program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+		require.NoError(t, err)
+
+		program.monitors["/dev/sg0"].logger.Info("test")
+		if enabled {
+			require.Equal(t, "/dev/sg0: (Top JBOD - Row 2): test\n", buf.String())
+		} else {
+			require.Equal(t, "/dev/sg0:: test\n", buf.String())
+		}
+	}
+}
+
+// Expectation: NewProgram should reject negative log file rotation settings.
+func Test_NewProgram_LogFile_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	for setting, message := range map[string]string{
+		"log_file_max_size: -1": "log_file_max_size must be >= 0",
+		"log_file_backups: -1":  "log_file_backups must be >= 0",
+	} {
+		yaml := []byte("log_file: /var/log/sesmon.log\n" + setting + "\ndevices:\n  - device: /dev/sg0\n    enabled: true\n")
+
+		_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &safeBuffer{})
+		require.ErrorIs(t, err, errInvalidArgument)
+		require.Contains(t, err.Error(), message)
+	}
+}
+
+// Expectation: Program should start and stop successfully.
+func Test_Program_StartPanicStop_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	// Deliberately cause a nil pointer dereference
+	program.monitors["/dev/sg0"].cfg.PollAttempts = nil
+
+	ctx := t.Context()
+	program.Start(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+
+	program.Stop()
+	<-program.Done()
+
+	select {
+	case <-program.Done():
+		require.Contains(t, buf.String(), "panic recovered")
+		require.Contains(t, buf.String(), "Monitoring")
+		require.Contains(t, buf.String(), "shutting down")
+	case <-time.After(2 * time.Second):
+		t.Error("Program did not complete within timeout")
+	}
+}
+
+// Expectation: getMonitors should return correct number of monitors.
+func Test_Program_getMonitors_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - device: /dev/sg1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+}
+
+// Expectation: getMonitors should return a copy of the slice, not the original.
+func Test_Program_getMonitorsCopy_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - device: /dev/sg1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	delete(monitors, "/dev/sg0")
+
+	monitors2 := program.getMonitors()
+	require.NotNil(t, monitors2["/dev/sg0"])
+}
+
+// Expectation: lookupDevice should resolve address to device path when finder is available.
+func Test_lookupDevice_AddressResolvedToDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg0", true)
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Address: "0:0:0:0",
+	}
+
+	err := lookupDevice(deviceCfg, finder, logger)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", deviceCfg.Device)
+	require.Equal(t, "0:0:0:0", deviceCfg.Address)
+}
+
+// Expectation: lookupDevice should fall back to device path when address not found and device provided.
+func Test_lookupDevice_AddressNotFoundFallbackToDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("", false)
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Address: "0:0:0:0",
+		Device:  "/dev/sg0",
+	}
+
+	err := lookupDevice(deviceCfg, finder, logger)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", deviceCfg.Device)
+	require.Empty(t, deviceCfg.Address)
+	require.Contains(t, buf.String(), "is not resolvable")
+	require.Contains(t, buf.String(), "using provided device path")
+}
+
+// Expectation: lookupDevice should return error when address not found and no device path.
+func Test_lookupDevice_AddressNotFoundNoDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("", false)
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Address: "0:0:0:0",
+	}
+
+	err := lookupDevice(deviceCfg, finder, logger)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errDeviceLookupFailed)
+	require.Contains(t, err.Error(), "not found")
+}
+
+// Expectation: lookupDevice should use device path and warn when no finder available but address specified.
+func Test_lookupDevice_NoFinderAddressSpecifiedWithDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Address: "0:0:0:0",
+		Device:  "/dev/sg0",
+	}
+
+	err := lookupDevice(deviceCfg, nil, logger)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", deviceCfg.Device)
+	require.Empty(t, deviceCfg.Address)
+	require.Contains(t, buf.String(), "no lookup table")
+	require.Contains(t, buf.String(), "using provided device path")
+}
+
+// Expectation: lookupDevice should return error when no finder and address specified without device.
+func Test_lookupDevice_NoFinderAddressSpecifiedNoDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Address: "0:0:0:0",
+	}
+
+	err := lookupDevice(deviceCfg, nil, logger)
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, errDeviceLookupFailed)
+	require.Contains(t, err.Error(), "no lookup table")
+}
+
+// Expectation: lookupDevice should resolve device to address when only device specified.
+func Test_lookupDevice_DeviceResolvedToAddress_Success(t *testing.T) {
+	t.Parallel()
+
+	finder := &mockDeviceFinder{}
+	finder.SetAddressResponse("0:0:0:0", true)
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Device: "/dev/sg0",
+	}
+
+	err := lookupDevice(deviceCfg, finder, logger)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", deviceCfg.Device)
+	require.Equal(t, "0:0:0:0", deviceCfg.Address)
+	require.Contains(t, buf.String(), "was resolved to")
+	require.Contains(t, buf.String(), "consider [address:")
+}
+
+// Expectation: lookupDevice should keep device path when address lookup fails.
+func Test_lookupDevice_DeviceAddressNotFound_Success(t *testing.T) {
+	t.Parallel()
+
+	finder := &mockDeviceFinder{}
+	finder.SetAddressResponse("", false)
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Device: "/dev/sg0",
+	}
+
+	err := lookupDevice(deviceCfg, finder, logger)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", deviceCfg.Device)
+	require.Empty(t, deviceCfg.Address)
+	require.Empty(t, buf.String())
+}
+
+// Expectation: lookupDevice should keep device path when no finder available.
+func Test_lookupDevice_DeviceOnlyNoFinder_Success(t *testing.T) {
+	t.Parallel()
+
+	var buf safeBuffer
+	logger := NewLogger(&buf, false, true)
+
+	deviceCfg := &DeviceYAML{
+		Device: "/dev/sg0",
+	}
+
+	err := lookupDevice(deviceCfg, nil, logger)
+
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", deviceCfg.Device)
+	require.Empty(t, deviceCfg.Address)
+	require.Empty(t, buf.String())
+}
+
+// Expectation: Program Reload should start added, stop removed and keep unchanged monitors.
+func Test_Program_Reload_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Unchanged"
+    enabled: true
+  - device: /dev/sg1
+    description: "Removed"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	defer func() {
+		program.Stop()
+		<-program.Done()
+	}()
+
+	before := program.getMonitors()
+	require.Len(t, before, 2)
+
+	newYaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Unchanged"
+    enabled: true
+  - device: /dev/sg2
+    description: "Added"
+    enabled: true
+`)
+
+	require.NoError(t, program.Reload(newYaml))
+
+	after := program.getMonitors()
+	require.Len(t, after, 2)
+	require.Same(t, before["/dev/sg0"], after["/dev/sg0"])
+	require.Contains(t, after, "/dev/sg2")
+	require.NotContains(t, after, "/dev/sg1")
+
+	select {
+	case <-before["/dev/sg1"].Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Removed monitor did not stop within timeout")
+	}
+
+	select {
+	case <-program.Done():
+		t.Fatal("Program should not be done while monitors are running")
+	default:
+	}
+
+	require.Contains(t, buf.String(), "1 monitors started, 1 stopped, 1 unchanged")
+}
+
+// Expectation: Program Reload should restart monitors of devices with a changed configuration.
+func Test_Program_Reload_ChangedDevice_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Before"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	defer func() {
+		program.Stop()
+		<-program.Done()
+	}()
+
+	before := program.getMonitors()
+
+	newYaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "After"
+    enabled: true
+`)
+
+	require.NoError(t, program.Reload(newYaml))
+
+	after := program.getMonitors()
+	require.Len(t, after, 1)
+	require.NotSame(t, before["/dev/sg0"], after["/dev/sg0"])
+
+	select {
+	case <-before["/dev/sg0"].Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Changed monitor did not stop within timeout")
+	}
+}
+
+// Expectation: Program Reload should reject an invalid configuration and keep all monitors running.
+func Test_Program_Reload_InvalidConfig_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	defer func() {
+		program.Stop()
+		<-program.Done()
+	}()
+
+	before := program.getMonitors()
+
+	err = program.Reload([]byte(`invalid: [yaml`))
+	require.Error(t, err)
+
+	after := program.getMonitors()
+	require.Same(t, before["/dev/sg0"], after["/dev/sg0"])
+
+	select {
+	case <-before["/dev/sg0"].Done():
+		t.Fatal("Monitor should not be stopped on an invalid reload")
+	default:
+	}
+}
+
+// Expectation: Program Reload should return an error when the program has already stopped.
+func Test_Program_Reload_Stopped_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	program.Stop()
+	<-program.Done()
+
+	err = program.Reload(yaml)
+	require.ErrorIs(t, err, errProgramStopped)
+}
+
+// Expectation: NewProgram should produce structured JSON log lines when configured.
+func Test_NewProgram_LogJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+log_json: true
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	program.Stop()
+	<-program.Done()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.NotEmpty(t, lines)
+
+	for _, line := range lines {
+		var entry jsonLogEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		require.Equal(t, "/dev/sg0", entry.Device)
+		require.NotEmpty(t, entry.Time)
+	}
+	require.Contains(t, buf.String(), `"event":"monitor_start"`)
+}
+
+// Expectation: NewProgram should produce structured JSON log lines when forced with an option.
+func Test_NewProgram_WithLogJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+disable_timestamps: true
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf, WithLogJSON())
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	program.Stop()
+	<-program.Done()
+
+	var entry jsonLogEntry
+	line, _, _ := strings.Cut(buf.String(), "\n")
+	require.NoError(t, json.Unmarshal([]byte(line), &entry))
+	require.Empty(t, entry.Time)
+	require.Equal(t, "/dev/sg0", entry.Device)
+}
+
+// Expectation: Program TestNotify should send a synthetic notification with the device's notifier.
+func Test_Program_TestNotify_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    address: "0x5000"
+    description: "Test"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+`)
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg0", true)
+
+	var buf safeBuffer
+	runner := &mockCommandRunner{}
+	program, err := NewProgram(yaml, fs, finder, runner, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, program.TestNotify(t.Context(), "0x5000"))
+	require.Equal(t, 1, runner.callCount())
+
+	cfg := runner.configs[0]
+	require.Equal(t, "/usr/local/bin/notify.sh", cfg.Command)
+	require.Len(t, cfg.Args, 5)
+	require.Equal(t, "/dev/sg0", cfg.Args[0])
+	require.Equal(t, "0x5000", cfg.Args[1])
+	require.Contains(t, cfg.Args[3], "TEST NOTIFICATION")
+
+	var report ChangeReport
+	require.NoError(t, json.Unmarshal([]byte(cfg.Args[4]), &report))
+	require.Len(t, report.Changes, 1)
+	require.Equal(t, "test-notify", report.Changes[0].ID)
+}
+
+// Expectation: Program TestNotify should return an error when the notifier fails.
+func Test_Program_TestNotify_NotifierFailure_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+`)
+
+	var buf safeBuffer
+	runner := &mockCommandRunner{err: errors.New("script failure")}
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	err = program.TestNotify(t.Context(), "/dev/sg0")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "script failure")
+}
+
+// Expectation: Program TestNotify should return an error for unknown devices or devices without notifier.
+func Test_Program_TestNotify_NotConfigured_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    description: "Test"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	err = program.TestNotify(t.Context(), "/dev/sg9")
+	require.ErrorIs(t, err, errDeviceNotConfigured)
+
+	err = program.TestNotify(t.Context(), "/dev/sg0")
+	require.ErrorIs(t, err, errNoNotifier)
+}
+
+// Expectation: NewProgram should expand a device glob pattern into a monitor per matching SES enclosure.
+func Test_NewProgram_DeviceGlob_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	for _, dev := range []string{"/dev/sg0", "/dev/sg1", "/dev/sg2", "/dev/sg3", "/dev/sg10"} {
+		require.NoError(t, afero.WriteFile(fs, dev, []byte{}, 0o644))
+	}
+
+	yaml := []byte(`
+output_base: /var/lib/sesmon
+devices:
+  - device: "/dev/sg[0-9]"
+    description: "Shelf"
+    enabled: true
+    config:
+      poll_interval: 5m
+  - device: /dev/sg3
+    description: "Explicit"
+    enabled: true
+`)
+
+	finder := &mockDeviceFinder{
+		enclosures: []string{"/dev/sg0", "/dev/sg1", "/dev/sg3", "/dev/sg10"},
+		addresses:  map[string]string{"/dev/sg1": "0x5000"},
+	}
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 3)
+	require.Contains(t, monitors, "/dev/sg0")
+	require.Contains(t, monitors, "0x5000")
+	require.Contains(t, monitors, "/dev/sg3")
+
+	require.Equal(t, "Shelf", monitors["/dev/sg0"].device.Description)
+	require.Equal(t, 5*time.Minute, *monitors["/dev/sg0"].cfg.PollInterval)
+	require.Equal(t, "/var/lib/sesmon/sg0", *monitors["/dev/sg0"].cfg.OutputDir)
+	require.Equal(t, "/dev/sg1", monitors["0x5000"].device.Path)
+	require.Equal(t, "/var/lib/sesmon/0x5000", *monitors["0x5000"].cfg.OutputDir)
+	require.Equal(t, "Explicit", monitors["/dev/sg3"].device.Description)
+
+	require.Contains(t, buf.String(), "Device [/dev/sg2] matching pattern [/dev/sg[0-9]] is not an SES enclosure")
+	require.Contains(t, buf.String(), "Device [/dev/sg3] matching pattern [/dev/sg[0-9]] is configured explicitly")
+}
+
+// Expectation: NewProgram should skip the matches of a device glob pattern that are configured explicitly
+// (by device path or SAS address), even if the explicitly configured devices are not enabled.
+func Test_NewProgram_DeviceGlobExplicit_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	for _, dev := range []string{"/dev/sg0", "/dev/sg1", "/dev/sg2"} {
+		require.NoError(t, afero.WriteFile(fs, dev, []byte{}, 0o644))
+	}
+
+	yaml := []byte(`
+devices:
+  - device: "/dev/sg*"
+    enabled: true
+  - device: /dev/sg1
+    enabled: false
+  - address: "0x5000"
+    enabled: false
+`)
+
+	finder := &mockDeviceFinder{
+		enclosures: []string{"/dev/sg0", "/dev/sg1", "/dev/sg2"},
+		addresses:  map[string]string{"/dev/sg2": "0x5000"},
+	}
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 1)
+	require.Contains(t, monitors, "/dev/sg0")
+
+	require.Contains(t, buf.String(), "Device [/dev/sg1] matching pattern [/dev/sg*] is configured explicitly (skipping it)")
+	require.Contains(t, buf.String(), "Device [/dev/sg2] matching pattern [/dev/sg*] is configured explicitly (skipping it)")
+}
+
+// Expectation: NewProgram should derive a distinct output folder per device from the "output_dir" of a device glob pattern.
+func Test_NewProgram_DeviceGlobOutputDir_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: "/dev/sg*"
+    enabled: true
+    config:
+      output_dir: /out
+`)
+
+	finder := &mockDeviceFinder{enclosures: []string{"/dev/sg0", "/dev/sg1"}}
+
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &safeBuffer{})
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+	require.Equal(t, "/out/sg0", *monitors["/dev/sg0"].cfg.OutputDir)
+	require.Equal(t, "/out/sg1", *monitors["/dev/sg1"].cfg.OutputDir)
+}
+
+// Expectation: NewProgram should return an error for an invalid device glob pattern (or one with an address).
+func Test_NewProgram_DeviceGlob_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	finder := &mockDeviceFinder{}
+
+	_, err := NewProgram([]byte(`
+devices:
+  - device: "/dev/sg["
+    enabled: true
+`), fs, finder, &mockCommandRunner{}, &safeBuffer{})
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "[config:0]")
+
+	_, err = NewProgram([]byte(`
+devices:
+  - device: "/dev/sg*"
+    address: "0x5000"
+    enabled: true
+`), fs, finder, &mockCommandRunner{}, &safeBuffer{})
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "cannot have an address")
+}
+
+// Expectation: NewProgram should establish monitors for auto-discovered devices (except excluded ones).
+func Test_NewProgram_AutoDiscover_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg3", []byte{}, 0o644))
+
+	yaml := []byte(`
+auto_discover: true
+auto_discover_defaults:
+  description: "Discovered"
+  config:
+    poll_interval: 5m
+    output_dir: /var/lib/sesmon
+exclude:
+  - /dev/sg2
+devices:
+  - device: /dev/sg0
+    description: "Explicit"
+    enabled: true
+  - device: /dev/sg3
+    description: "Disabled"
+    enabled: false
+`)
+
+	finder := &mockDeviceFinder{
+		enclosures: []string{"/dev/sg0", "/dev/sg1", "/dev/sg2", "/dev/sg3"},
+		addresses:  map[string]string{"/dev/sg0": "0x4000", "/dev/sg1": "0x5000", "/dev/sg2": "0x6000", "/dev/sg3": "0x7000"},
+	}
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 2)
+	require.Contains(t, monitors, "0x4000")
+	require.Contains(t, monitors, "0x5000")
+
+	discovered := monitors["0x5000"]
+	require.Equal(t, "/dev/sg1", discovered.device.Path)
+	require.Equal(t, "Discovered", discovered.device.Description)
+	require.Equal(t, "0x5000", discovered.device.Address)
+	require.Equal(t, 5*time.Minute, *discovered.cfg.PollInterval)
+	require.Equal(t, "/var/lib/sesmon/0x5000", *discovered.cfg.OutputDir)
+
+	require.Equal(t, "Explicit", monitors["0x4000"].device.Description)
+	require.Contains(t, buf.String(), "Device [/dev/sg1] was auto-discovered with SAS address [0x5000]")
+}
+
+// Expectation: NewProgram should exclude auto-discovered devices by their SAS address.
+func Test_NewProgram_AutoDiscoverExcludeAddress_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+auto_discover: true
+exclude:
+  - "0x5000ABC"
+`)
+
+	finder := &mockDeviceFinder{enclosures: []string{"/dev/sg1"}}
+	finder.SetAddressResponse("0x5000abc", true)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errNoDevices)
+}
+
+// Expectation: NewProgram should return an error when auto-discovery defaults contain a device.
+func Test_NewProgram_AutoDiscoverDefaultsWithDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+auto_discover: true
+auto_discover_defaults:
+  device: /dev/sg1
+`)
+
+	finder := &mockDeviceFinder{enclosures: []string{"/dev/sg1"}}
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// sysfsCountingFs is an [afero.Fs] counting the accesses to the sysfs lookup table.
+type sysfsCountingFs struct {
+	afero.Fs
+
+	accesses atomic.Int32
+}
+
+func (f *sysfsCountingFs) Open(name string) (afero.File, error) {
+	if strings.HasPrefix(name, "/sys/") {
+		f.accesses.Add(1)
+	}
+
+	return f.Fs.Open(name) //nolint:wrapcheck
+}
+
+// Expectation: NewProgram should not scan the lookup table when no device needs resolving.
+func Test_NewProgram_FileDevicesNoLookup_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := &sysfsCountingFs{Fs: afero.NewMemMapFs()}
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/tmp/dump.json", []byte("{}"), 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /tmp/dump.json
+    type: 1
+    description: "File"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, nil, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.Len(t, program.getMonitors(), 1)
+
+	require.Zero(t, fs.accesses.Load())
+}
+
+// Expectation: NewProgram should scan the lookup table (once) when devices need resolving.
+func Test_NewProgram_DevicesLookupOnce_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := &sysfsCountingFs{Fs: afero.NewMemMapFs()}
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - address: "0x5000"
+    description: "Address"
+    enabled: true
+  - device: /dev/sg1
+    description: "Device"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, nil, &mockCommandRunner{}, &buf)
+	require.ErrorContains(t, err, "stat device failure") // /dev/sg0 does not exist
+
+	accesses := fs.accesses.Load() // single scan (failed at first device)
+	require.Positive(t, accesses)
+
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	fs.accesses.Store(0)
+
+	program, err := NewProgram(yaml, fs, nil, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.Len(t, program.getMonitors(), 2)
+	require.Equal(t, accesses, fs.accesses.Load())
+}
+
+// Expectation: NewProgram should look up the devices relative to the configured sysfs_root.
+func Test_NewProgram_SysfsRoot_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/host/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/host/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+sysfs_root: /host/sys
+devices:
+  - address: "0x5000"
+    description: "Address"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, nil, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", program.getMonitors()["0x5000"].device.Path)
+}
+
+// Expectation: NewProgram should resolve SAS addresses configured without the "0x" prefix.
+func Test_NewProgram_AddressWithoutPrefix_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/class/scsi_generic/sg0/device", 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/sys/class/scsi_generic/sg0/device/sas_address", []byte("0x5000c500a1b2c3d4"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - address: "5000C500A1B2C3D4"
+    description: "Address"
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, nil, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", program.getMonitors()["0x5000c500a1b2c3d4"].device.Path)
+	require.NotNil(t, program.findMonitor("0x5000c500a1b2c3d4"))
+}
+
+// Expectation: NewProgram should not auto-discover devices excluded by address without the "0x" prefix.
+func Test_NewProgram_AutoDiscoverExcludeAddressWithoutPrefix_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+auto_discover: true
+exclude:
+  - "5000ABC"
+`)
+
+	finder := &mockDeviceFinder{enclosures: []string{"/dev/sg1"}}
+	finder.SetAddressResponse("0x5000abc", true)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errNoDevices)
+}
+
+// Expectation: NewProgram should use the defaults as base for the device settings (per setting).
+func Test_NewProgram_Defaults_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/usr/local/bin/notify.sh", []byte{}, 0o755))
+
+	yaml := []byte(`
+defaults:
+  config:
+    poll_interval: 5m
+    poll_attempts: 5
+    output_dir: /var/lib/sesmon
+  webhook_notifier:
+    url: "https://example.com/hook"
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - device: /dev/sg1
+    enabled: true
+    config:
+      poll_interval: 1m
+      output_dir: /data/sg1
+  - device: /dev/sg2
+    enabled: true
+    script_notifier:
+      script: /usr/local/bin/notify.sh
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 3)
+
+	require.Equal(t, 5*time.Minute, *monitors["/dev/sg0"].cfg.PollInterval)
+	require.Equal(t, 5, *monitors["/dev/sg0"].cfg.PollAttempts)
+	require.Equal(t, "/var/lib/sesmon/sg0", *monitors["/dev/sg0"].cfg.OutputDir)
+	require.Equal(t, "webhook_notifier", monitors["/dev/sg0"].notifier.Name())
+
+	require.Equal(t, time.Minute, *monitors["/dev/sg1"].cfg.PollInterval)
+	require.Equal(t, 5, *monitors["/dev/sg1"].cfg.PollAttempts)
+	require.Equal(t, "/data/sg1", *monitors["/dev/sg1"].cfg.OutputDir)
+
+	require.Equal(t, "/var/lib/sesmon/sg2", *monitors["/dev/sg2"].cfg.OutputDir)
+	require.Equal(t, "script_notifier", monitors["/dev/sg2"].notifier.Name())
+}
+
+// Expectation: NewProgram should reject defaults with a device or address.
+func Test_NewProgram_DefaultsWithDevice_Error(t *testing.T) {
+	t.Parallel()
+
+	yaml := []byte(`
+defaults:
+  device: /dev/sg0
+devices:
+  - device: /dev/sg0
+    enabled: true
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, afero.NewMemMapFs(), &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: NewProgram should reject output directories derived from the defaults colliding with others.
+func Test_NewProgram_DefaultsOutputDirCollision_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+defaults:
+  config:
+    output_dir: /var/lib/sesmon
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      output_dir: /var/lib/sesmon/sg1
+  - device: /dev/sg1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.ErrorContains(t, err, "same output directory")
+}
+
+// Expectation: NewProgram should expand environment variables in the configuration.
+//
+//nolint:paralleltest
+func Test_NewProgram_ExpandEnv_Success(t *testing.T) {
+	// Note: Cannot use t.Parallel() due to environment manipulation
+	t.Setenv("SESMON_TEST_DEVICE", "/dev/sg0")
+	t.Setenv("SESMON_TEST_URL", "https://example.com/hook")
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: ${SESMON_TEST_DEVICE}
+    enabled: true
+    webhook_notifier:
+      url: "${SESMON_TEST_URL}"
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.Contains(t, program.getMonitors(), "/dev/sg0")
+	require.Contains(t, program.monitors["/dev/sg0"].notifier.Config(), "https://example.com/hook")
+}
+
+// Expectation: NewProgram should return an error for unset environment variables in the configuration.
+func Test_NewProgram_ExpandEnvUnset_Error(t *testing.T) {
+	t.Parallel()
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    webhook_notifier:
+      url: "${SESMON_TEST_SURELY_UNSET}"
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, afero.NewMemMapFs(), &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errEnvNotSet)
+}
+
+// Expectation: NewProgram should derive output directories from output_base (unless set explicitly).
+func Test_NewProgram_OutputBase_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg2", []byte{}, 0o644))
+
+	yaml := []byte(`
+output_base: /var/lib/sesmon
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - address: "0x5000"
+    enabled: true
+  - device: /dev/sg2
+    enabled: true
+    config:
+      output_dir: /data/sg2
+`)
+
+	finder := &mockDeviceFinder{}
+	finder.SetDeviceResponse("/dev/sg1", true)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, finder, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	monitors := program.getMonitors()
+	require.Len(t, monitors, 3)
+	require.Equal(t, "/var/lib/sesmon/sg0", *monitors["/dev/sg0"].cfg.OutputDir)
+	require.Equal(t, "/var/lib/sesmon/0x5000", *monitors["0x5000"].cfg.OutputDir)
+	require.Equal(t, "/data/sg2", *monitors["/dev/sg2"].cfg.OutputDir)
+}
+
+// Expectation: Program should write the overview file with the status of all monitors.
+func Test_Program_Overview_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+overview_file: /var/lib/sesmon/overview.json
+devices:
+  - device: /dev/sg1
+    description: "Device 2"
+    enabled: true
+  - device: /dev/sg0
+    description: "Device 1"
+    enabled: true
+`)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{
+		"join_of_diagnostic_pages": {
+			"element_list": [
+				{
+					"element_type": {"i": 15, "meaning": "Enclosure"},
+					"element_number": 0,
+					"status_descriptor": {"status": {"i": 1, "meaning": "OK"}}
+				}
+			]
+		}
+	}`, "", nil)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	defer func() {
+		program.Stop()
+		<-program.Done()
+	}()
+
+	require.Eventually(t, func() bool {
+		data, err := afero.ReadFile(fs, "/var/lib/sesmon/overview.json")
+		if err != nil {
+			return false
+		}
+
+		var overview ProgramOverview
+		if err := json.Unmarshal(data, &overview); err != nil || len(overview.Devices) != 2 {
+			return false
+		}
+
+		return overview.Devices[0].LastPollAt != "" && overview.Devices[1].LastPollAt != ""
+	}, 2*time.Second, 10*time.Millisecond)
+
+	data, err := afero.ReadFile(fs, "/var/lib/sesmon/overview.json")
+	require.NoError(t, err)
+
+	var overview ProgramOverview
+	require.NoError(t, json.Unmarshal(data, &overview))
+	require.NotEmpty(t, overview.GeneratedAt)
+	require.Equal(t, "/dev/sg0", overview.Devices[0].Device.Path)
+	require.Equal(t, "/dev/sg1", overview.Devices[1].Device.Path)
+	require.Len(t, overview.Devices[0].Results, 1)
+}
+
+// Expectation: NewProgram should establish the shared poll semaphore with the configured size.
+func Test_NewProgram_MaxConcurrentPolls_Success(t *testing.T) {
+	t.Parallel()
+
+	yaml := []byte(`
+max_concurrent_polls: 2
+devices:
+  - device: /dev/null
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, nil, nil, nil, &buf)
+	require.NoError(t, err)
+
+	require.Len(t, program.pollSems, 1)
+	require.Equal(t, 2, cap(program.pollSems[""]))
+}
+
+// Expectation: NewProgram should establish a poll semaphore per pool, with devices
+// without a pool (or one inherited from the defaults) sharing the default pool.
+func Test_NewProgram_MaxConcurrentPollsPools_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	for _, dev := range []string{"/dev/sg0", "/dev/sg1", "/dev/sg2"} {
+		require.NoError(t, afero.WriteFile(fs, dev, []byte{}, 0o644))
+	}
+
+	yaml := []byte(`
+max_concurrent_polls: 1
+defaults:
+  pool: hba1
+devices:
+  - device: /dev/sg0
+    pool: hba0
+    enabled: true
+  - device: /dev/sg1
+    pool: hba0
+    enabled: true
+  - device: /dev/sg2
+    enabled: true
+`)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	require.Len(t, program.pollSems, 2)
+	require.Equal(t, 1, cap(program.pollSems["hba0"]))
+	require.Equal(t, 1, cap(program.pollSems["hba1"]))
+
+	monitors := program.getMonitors()
+	require.Equal(t, "hba0", monitors["/dev/sg0"].pool)
+	require.Equal(t, "hba1", monitors["/dev/sg2"].pool)
+
+	require.NoError(t, program.RunOnce(t.Context()))
+	require.Equal(t, monitors["/dev/sg0"].pollSem, monitors["/dev/sg1"].pollSem)
+	require.NotEqual(t, monitors["/dev/sg0"].pollSem, monitors["/dev/sg2"].pollSem)
+}
+
+// Expectation: Reload should keep the poll semaphores of unchanged pools and add those of new pools.
+func Test_Program_Reload_PoolSemaphores_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	var buf safeBuffer
+	program, err := NewProgram([]byte(`
+max_concurrent_polls: 1
+devices:
+  - device: /dev/sg0
+    enabled: true
+`), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	sem := program.pollSems[""]
+	require.NotNil(t, sem)
+
+	require.NoError(t, program.Reload([]byte(`
+max_concurrent_polls: 1
+devices:
+  - device: /dev/sg0
+    enabled: true
+  - device: /dev/sg1
+    pool: hba1
+    enabled: true
+`)))
+
+	require.Len(t, program.pollSems, 2)
+	require.Equal(t, sem, program.pollSems[""])
+	require.NotNil(t, program.pollSems["hba1"])
+}
+
+// Expectation: NewProgram should not establish a poll semaphore when unlimited (the default).
+func Test_NewProgram_MaxConcurrentPollsUnlimited_Success(t *testing.T) {
+	t.Parallel()
+
+	yaml := []byte(`
+devices:
+  - device: /dev/null
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, nil, nil, nil, &buf)
+	require.NoError(t, err)
+
+	require.Nil(t, program.pollSems)
+}
+
+// Expectation: NewProgram should return an error for a negative max_concurrent_polls.
+func Test_NewProgram_MaxConcurrentPollsNegative_Error(t *testing.T) {
+	t.Parallel()
+
+	yaml := []byte(`
+max_concurrent_polls: -1
+devices:
+  - device: /dev/null
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, nil, nil, nil, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, program)
+}
+
+// Expectation: Dump should write the parsed results of all devices as JSON keyed by device path.
+func Test_Program_Dump_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte(`{"join_of_diagnostic_pages": {"element_list": [
+		{"element_type": {"i": 23, "meaning": "Array device slot"}, "element_number": 3, "descriptor": "Slot 03", "status_descriptor": {"status": {"i": 1, "meaning": "OK"}}}
+	]}}`), 0o644))
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages": {"element_list": [
+		{"element_type": {"i": 4}, "element_number": 0, "status_descriptor": {"temperature": {"i": 25, "meaning": "25 C"}}}
+	]}}`, "", nil)
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    type: 1
+    enabled: true
+  - device: /dev/sg1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, prog.Dump(t.Context(), "", &out))
+
+	var dump map[string]map[string]Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dump))
+	require.Len(t, dump, 2)
+	require.Equal(t, "Slot 03", *dump["/dev/sg0"]["23#3"].Descriptor)
+	require.Equal(t, 25, *dump["/dev/sg1"]["4#0"].TemperatureC)
+
+	out.Reset()
+	require.NoError(t, prog.Dump(t.Context(), "/dev/sg0", &out))
+
+	dump = nil
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dump))
+	require.Len(t, dump, 1)
+	require.Contains(t, dump, "/dev/sg0")
+}
+
+// Expectation: Dump and CheckStatus should run the pre-poll command before fetching from a device.
+func Test_Program_Dump_CheckStatus_PrePollCommand_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages": {"element_list": [
+		{"element_type": {"i": 4}, "element_number": 0, "status_descriptor": {"status": {"i": 1}}}
+	]}}`, "", nil)
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg1
+    enabled: true
+    config:
+      pre_poll_command: /usr/local/bin/pre-poll
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, prog.Dump(t.Context(), "", &out))
+	require.Equal(t, CheckStateOK, prog.CheckStatus(t.Context()).State)
+
+	require.Len(t, runner.configs, 4)
+	for i, command := range []string{"/usr/local/bin/pre-poll", "sg_ses", "/usr/local/bin/pre-poll", "sg_ses"} {
+		require.Equal(t, command, runner.configs[i].Command)
+	}
+}
+
+// Expectation: Dump should return an error for a device that is not configured.
+func Test_Program_Dump_DeviceNotConfigured_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte(`{}`), 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    type: 1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = prog.Dump(t.Context(), "/dev/sg9", &out)
+	require.ErrorIs(t, err, errDeviceNotConfigured)
+	require.Empty(t, out.String())
+}
+
+// Expectation: Dump should return an error when the fetched data cannot be parsed.
+func Test_Program_Dump_InvalidData_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte(`not json`), 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    type: 1
+    enabled: true
+    config:
+      poll_attempts: 1
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = prog.Dump(t.Context(), "", &out)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "/dev/sg0")
+	require.Empty(t, out.String())
+}
+
+// Expectation: CheckTools should check the fetching commands of all devices, skipping file-type devices.
+func Test_Program_CheckTools_Success(t *testing.T) {
+	t.Parallel()
+
+	tool := filepath.Join(t.TempDir(), "sg_ses")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o755))
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/tmp/device.json", []byte(`{}`), 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      sg_ses_path: ` + tool + `
+  - device: /dev/sg1
+    enabled: true
+    config:
+      fetch_command: ` + tool + `
+  - device: /tmp/device.json
+    type: 1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.NoError(t, prog.CheckTools())
+}
+
+// Expectation: CheckTools should return an error for every device whose fetching command cannot be found.
+func Test_Program_CheckTools_Error(t *testing.T) {
+	t.Parallel()
+
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      sg_ses_path: ` + missing + `
+  - device: /dev/sg1
+    enabled: true
+    config:
+      fetch_command: ` + missing + `
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	err = prog.CheckTools()
+	require.ErrorIs(t, err, errToolNotFound)
+	require.Contains(t, err.Error(), `"/dev/sg0": command not found: sg_ses_path [`+missing+`]`)
+	require.Contains(t, err.Error(), `"/dev/sg1": command not found: fetch_command [`+missing+`]`)
+}
+
+// Expectation: ConfigDump should write the merged configurations of all devices as JSON keyed by device path.
+func Test_Program_ConfigDump_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte(`{}`), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/bin/true", []byte{}, 0o755))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte(`{}`), 0o644))
+
+	yaml := []byte(`
+defaults:
+  config:
+    poll_attempts: 5
+devices:
+  - device: /dev/sg0
+    type: 1
+    enabled: true
+    config:
+      poll_attempts: 2
+    script_notifier:
+      script: /bin/true
+  - device: /dev/sg1
+    type: 1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, prog.ConfigDump("", &out))
+
+	var dump map[string]struct {
+		Device         Device         `json:"device"`
+		Config         map[string]any `json:"config"`
+		Notifier       string         `json:"notifier"`
+		NotifierConfig string         `json:"notifier_config"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dump))
+	require.Len(t, dump, 2)
+	require.InDelta(t, 2, dump["/dev/sg0"].Config["poll_attempts"], 0)
+	require.InDelta(t, 5, dump["/dev/sg1"].Config["poll_attempts"], 0)
+	require.Equal(t, "15s", dump["/dev/sg1"].Config["poll_attempt_timeout"])
+	require.Equal(t, "script_notifier", dump["/dev/sg0"].Notifier)
+	require.Contains(t, dump["/dev/sg0"].NotifierConfig, "/bin/true")
+	require.Empty(t, dump["/dev/sg1"].Notifier)
+
+	out.Reset()
+	require.NoError(t, prog.ConfigDump("/dev/sg1", &out))
+
+	dump = nil
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dump))
+	require.Len(t, dump, 1)
+	require.Contains(t, dump, "/dev/sg1")
+}
+
+// Expectation: ConfigDump should return an error for a device that is not configured.
+func Test_Program_ConfigDump_DeviceNotConfigured_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte(`{}`), 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    type: 1
+    enabled: true
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = prog.ConfigDump("/dev/sg9", &out)
+	require.ErrorIs(t, err, errDeviceNotConfigured)
+	require.Empty(t, out.String())
+}
+
+// Expectation: NewProgram should interpret bare integers for durations as seconds.
+func Test_NewProgram_DurationBareSeconds_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+shutdown_timeout: 45
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      poll_interval: 60
+      poll_attempt_interval: 10s
+`)
+
+	var buf safeBuffer
+	prog, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	require.Equal(t, 45*time.Second, prog.shutdownTimeout())
+	require.Equal(t, 60*time.Second, *prog.monitors["/dev/sg0"].cfg.PollInterval)
+	require.Equal(t, 10*time.Second, *prog.monitors["/dev/sg0"].cfg.PollAttemptInterval)
+
+	prog, err = NewProgram([]byte(`
+shutdown_timeout: 1m30s
+devices:
+  - device: /dev/sg0
+    enabled: true
+`), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, prog.shutdownTimeout())
+}
+
+// Expectation: NewProgram should reject a negative duration given as bare integer.
+func Test_NewProgram_DurationNegativeSeconds_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      poll_interval: -60
+`)
+
+	var buf safeBuffer
+	_, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewProgram([]byte(`
+shutdown_timeout: -45
+devices:
+  - device: /dev/sg0
+    enabled: true
+`), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: Program Reload should toggle the maintenance mode without restarting the monitor.
+func Test_Program_Reload_Maintenance_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    maintenance: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.Start(t.Context())
+	defer func() {
+		program.Stop()
+		<-program.Done()
+	}()
+
+	before := program.getMonitors()
+	require.True(t, before["/dev/sg0"].Status().Maintenance)
+	require.Contains(t, buf.String(), "Maintenance mode entered")
+
+	newYaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+`)
+
+	require.NoError(t, program.Reload(newYaml))
+
+	after := program.getMonitors()
+	require.Same(t, before["/dev/sg0"], after["/dev/sg0"])
+	require.False(t, after["/dev/sg0"].Status().Maintenance)
+	require.Contains(t, buf.String(), "Maintenance mode exited")
+	require.Contains(t, buf.String(), "0 monitors started, 0 stopped, 1 unchanged")
+}
+
+// Expectation: Program RunOnce should poll all enabled devices once and return.
+func Test_Program_RunOnce_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg1", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      output_dir: /output/sg0
+  - device: /dev/sg1
+    enabled: true
+    config:
+      output_dir: /output/sg1
+`)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse(`{"join_of_diagnostic_pages":{"element_list":[]}}`, "", nil)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, program.RunOnce(t.Context()))
+	require.Contains(t, buf.String(), "Polling [/dev/sg0:] once")
+	require.Contains(t, buf.String(), "Polling [/dev/sg1:] once")
+
+	for _, dir := range []string{"/output/sg0", "/output/sg1"} {
+		exists, err := afero.Exists(fs, dir+"/current_parsed.json")
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+}
+
+// Expectation: Program RunOnce should return an error if any device poll has failed.
+func Test_Program_RunOnce_Error(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      poll_attempts: 1
+`)
+
+	runner := &mockCommandRunner{}
+	runner.setResponse("", "", errInvalidJSON)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, runner, &buf)
+	require.NoError(t, err)
+
+	err = program.RunOnce(t.Context())
+	require.ErrorIs(t, err, errPollFailed)
+}
+
+// Expectation: NewProgram should set up the monitors for a dry run (if configured or forced by option).
+func Test_NewProgram_DryRun_Success(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	for _, tt := range []struct {
+		yaml     string
+		opts     []ProgramOption
+		expected bool
+	}{
+		{"devices:\n  - device: /dev/sg0\n    enabled: true\n", nil, false},
+		{"dry_run: true\ndevices:\n  - device: /dev/sg0\n    enabled: true\n", nil, true},
+		{"devices:\n  - device: /dev/sg0\n    enabled: true\n", []ProgramOption{WithDryRun()}, true},
+	} {
+		var buf safeBuffer
+		program, err := NewProgram([]byte(tt.yaml), fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf, tt.opts...)
+		require.NoError(t, err)
+
+		require.Equal(t, tt.expected, program.getMonitors()["/dev/sg0"].dryRun)
+		require.Equal(t, tt.expected, strings.Contains(buf.String(), "Dry run - notifications are only logged"))
+	}
+}
@@ -0,0 +1,318 @@
+package sesmon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// pushServiceNtfy is the push service of an ntfy server (https://ntfy.sh).
+	pushServiceNtfy = "ntfy"
+
+	// pushServiceGotify is the push service of a Gotify server (https://gotify.net).
+	pushServiceGotify = "gotify"
+
+	// ntfyMaxPriority is the highest message priority supported by ntfy.
+	ntfyMaxPriority = 5
+
+	// gotifyMaxPriority is the highest message priority (commonly) used with Gotify.
+	gotifyMaxPriority = 10
+)
+
+// PushNotifierConfig is the configuration for a [PushNotifier] implementation.
+type PushNotifierConfig struct {
+	// How often to attempt a notification (must be > 0).
+	NotifyAttempts *int `yaml:"notify_attempts"`
+
+	// How long a notification attempt can take (multiplies with attempts).
+	NotifyAttemptTimeout *time.Duration `yaml:"notify_attempt_timeout"`
+
+	// How long to wait between notification attempts (in case of failure).
+	NotifyAttemptInterval *time.Duration `yaml:"notify_attempt_interval"`
+
+	// Message priority for alerts with critical element transitions.
+	PriorityCritical *int `yaml:"priority_critical"`
+
+	// Message priority for alerts with any other element degradations.
+	PriorityWarning *int `yaml:"priority_warning"`
+
+	// Message priority for recoveries and other notifications (e.g. heartbeats).
+	PriorityRecovery *int `yaml:"priority_recovery"`
+
+	// Skip verification of the remote endpoint's TLS certificate.
+	TLSSkipVerify *bool `yaml:"tls_skip_verify"`
+}
+
+// MarshalJSON is a custom JSON marshaller for user readable [time.Duration] strings.
+func (c PushNotifierConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct { //nolint:wrapcheck
+		NotifyAttempts        *int    `json:"notify_attempts"`
+		NotifyAttemptTimeout  *string `json:"notify_attempt_timeout"`
+		NotifyAttemptInterval *string `json:"notify_attempt_interval"`
+		PriorityCritical      *int    `json:"priority_critical"`
+		PriorityWarning       *int    `json:"priority_warning"`
+		PriorityRecovery      *int    `json:"priority_recovery"`
+		TLSSkipVerify         *bool   `json:"tls_skip_verify"`
+	}{
+		NotifyAttempts:        c.NotifyAttempts,
+		NotifyAttemptTimeout:  durPtrToStrPtr(c.NotifyAttemptTimeout),
+		NotifyAttemptInterval: durPtrToStrPtr(c.NotifyAttemptInterval),
+		PriorityCritical:      c.PriorityCritical,
+		PriorityWarning:       c.PriorityWarning,
+		PriorityRecovery:      c.PriorityRecovery,
+		TLSSkipVerify:         c.TLSSkipVerify,
+	})
+}
+
+// UnmarshalYAML is a custom YAML unmarshaller accepting bare integers (as seconds) for durations.
+func (c *PushNotifierConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain PushNotifierConfig
+
+	return decodeYAMLDurations(value, (*plain)(c), reflect.TypeFor[PushNotifierConfig]())
+}
+
+// DefaultPushNotifierConfig returns a pointer to a default [PushNotifierConfig].
+//
+//nolint:mnd
+func DefaultPushNotifierConfig() *PushNotifierConfig {
+	return &PushNotifierConfig{
+		NotifyAttempts:        ptr(3),
+		NotifyAttemptTimeout:  ptr(15 * time.Second),
+		NotifyAttemptInterval: ptr(15 * time.Second),
+		PriorityCritical:      ptr(5),
+		PriorityWarning:       ptr(4),
+		PriorityRecovery:      ptr(3),
+		TLSSkipVerify:         ptr(false),
+	}
+}
+
+// gotifyMessage is the JSON body that is sent to a Gotify server.
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+}
+
+var _ Notifier = (*PushNotifier)(nil)
+
+// PushNotifier is a [Notifier] sending push notifications through an ntfy
+// server topic or a Gotify server using an HTTP POST request. The title and
+// priority of the notification are derived from the alert level of the
+// [ChangeReport] (critical, warning or recovery), the message is sent as-is.
+type PushNotifier struct {
+	// Push service of the server ("ntfy" or "gotify").
+	service string
+
+	// URL to send the HTTP POST request to (ntfy topic or Gotify message URL).
+	url string
+
+	// Access token to authenticate with (if not empty).
+	token string
+
+	client *http.Client
+	logger *slog.Logger
+
+	cfg *PushNotifierConfig
+}
+
+// NewPushNotifier returns a pointer to a new [PushNotifier]. For ntfy the
+// target is the URL of the topic (e.g. "https://ntfy.sh/mytopic"), for
+// Gotify the URL of the server (with the token being the application token).
+func NewPushNotifier(
+	service string, target string, token string,
+	cfg *PushNotifierConfig, logger *slog.Logger,
+) (*PushNotifier, error) {
+	if logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	if target == "" {
+		return nil, fmt.Errorf("%w: no url provided", errInvalidArgument)
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w: failure parsing url: %w", target, errInvalidArgument, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q: %w: url needs to be http(s)://host[:port][/path]", target, errInvalidArgument)
+	}
+
+	pcfg, err := mergePushNotifierConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	var maxPriority int
+	switch service {
+	case pushServiceNtfy:
+		maxPriority = ntfyMaxPriority
+		if strings.Trim(u.Path, "/") == "" {
+			return nil, fmt.Errorf("%q: %w: url needs to contain the ntfy topic", target, errInvalidArgument)
+		}
+	case pushServiceGotify:
+		maxPriority = gotifyMaxPriority
+		if token == "" {
+			return nil, fmt.Errorf("%w: gotify needs an (application) token", errInvalidArgument)
+		}
+		target = strings.TrimSuffix(target, "/") + "/message"
+	default:
+		return nil, fmt.Errorf("%q: %w: service needs to be %q or %q",
+			service, errInvalidArgument, pushServiceNtfy, pushServiceGotify)
+	}
+	for _, p := range []int{*pcfg.PriorityCritical, *pcfg.PriorityWarning, *pcfg.PriorityRecovery} {
+		if p < 1 || p > maxPriority {
+			return nil, fmt.Errorf("%w: priorities must be between 1 and %d for %s",
+				errInvalidArgument, maxPriority, service)
+		}
+	}
+
+	client, err := newHTTPClient(&tls.Config{InsecureSkipVerify: *pcfg.TLSSkipVerify}) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	return &PushNotifier{
+		service: service,
+		url:     target,
+		token:   token,
+		client:  client,
+		logger:  logger,
+		cfg:     pcfg,
+	}, nil
+}
+
+// Notify sends the push notification to the server with HTTP POST.
+// Any response status code other than 2xx is considered as a failed attempt.
+// It both observes and respects context cancellations for earlier notification terminations.
+func (n *PushNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	title, priority := n.titlePriority(device, extra)
+
+	attempt, err := withRetries(
+		ctx,
+		func() error {
+			return n.post(ctx, title, priority, message)
+		},
+		func(attempt int, err error) {
+			n.logger.Warn(fmt.Sprintf("%q: [%d/%d] notification failure: %v",
+				n.url, attempt, *n.cfg.NotifyAttempts, err), LogEvent("notify_retry"))
+		},
+		*n.cfg.NotifyAttempts,
+		*n.cfg.NotifyAttemptInterval,
+	)
+	if err != nil {
+		return fmt.Errorf("%q: [%d/%d] notification failure: %w",
+			n.url, attempt, *n.cfg.NotifyAttempts, err)
+	}
+
+	return nil
+}
+
+// titlePriority returns the title and priority of a notification,
+// as derived from the alert level of the extra (if a [ChangeReport]).
+func (n *PushNotifier) titlePriority(device Device, extra any) (string, int) {
+	name := fne(device.Description, device.Path)
+
+	var report ChangeReport
+	switch r := extra.(type) {
+	case ChangeReport:
+		report = r
+	case *ChangeReport:
+		if r == nil {
+			return "SES notification on " + name, *n.cfg.PriorityRecovery
+		}
+		report = *r
+	default:
+		return "SES notification on " + name, *n.cfg.PriorityRecovery
+	}
+
+	switch reportAlertLevel(report) {
+	case alertLevelCritical:
+		return "SES critical alert on " + name, *n.cfg.PriorityCritical
+	case alertLevelWarning:
+		return "SES alert on " + name, *n.cfg.PriorityWarning
+	default:
+		return "SES recovery on " + name, *n.cfg.PriorityRecovery
+	}
+}
+
+// post is a single notification attempt (HTTP POST) to the server.
+func (n *PushNotifier) post(ctx context.Context, title string, priority int, message string) error {
+	reqCtx, reqCancel := context.WithTimeout(ctx, *n.cfg.NotifyAttemptTimeout)
+	defer reqCancel()
+
+	var body []byte
+	if n.service == pushServiceGotify {
+		b, err := json.Marshal(gotifyMessage{Title: title, Message: message, Priority: priority})
+		if err != nil {
+			return fmt.Errorf("failure marshalling payload to JSON: %w", err)
+		}
+		body = b
+	} else {
+		body = []byte(message)
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failure creating request: %w", err)
+	}
+
+	if n.service == pushServiceGotify {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Gotify-Key", n.token)
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		req.Header.Set("Title", title)
+		req.Header.Set("Priority", strconv.Itoa(priority))
+		if n.token != "" {
+			req.Header.Set("Authorization", "Bearer "+n.token)
+		}
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failure sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", errUnexpectedStatus, resp.Status)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notification agent as a string.
+func (n *PushNotifier) Name() string {
+	return "push_notifier"
+}
+
+// Config returns the configuration of the notification agent as a string.
+// The access token is redacted from the output.
+func (n *PushNotifier) Config() string {
+	cfgJSON, err := json.Marshal(n.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+
+	token := "-"
+	if n.token != "" {
+		token = "[redacted]"
+	}
+
+	return fmt.Sprintf("%s:%q:%s:token=%s", n.service, n.url, cfgJSON, token)
+}
@@ -0,0 +1,180 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: NewPushNotifier should successfully create ntfy and gotify notifiers.
+func Test_NewPushNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewPushNotifier(pushServiceNtfy, "https://ntfy.sh/sesmon", "", nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.Equal(t, "https://ntfy.sh/sesmon", n.url)
+	require.Equal(t, DefaultPushNotifierConfig(), n.cfg)
+
+	n, err = NewPushNotifier(pushServiceGotify, "https://gotify.example.com/", "token", nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.Equal(t, "https://gotify.example.com/message", n.url)
+}
+
+// Expectation: NewPushNotifier should return an error for invalid arguments.
+func Test_NewPushNotifier_InvalidArguments_Error(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.DiscardHandler)
+
+	_, err := NewPushNotifier("pushover", "https://example.com/sesmon", "", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewPushNotifier(pushServiceNtfy, "", "", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewPushNotifier(pushServiceNtfy, "https://ntfy.sh/", "", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewPushNotifier(pushServiceGotify, "https://gotify.example.com", "", nil, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewPushNotifier(pushServiceNtfy, "https://ntfy.sh/sesmon", "",
+		&PushNotifierConfig{PriorityCritical: ptr(8)}, logger)
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = NewPushNotifier(pushServiceNtfy, "https://ntfy.sh/sesmon", "", nil, nil)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: PushNotifier should post to ntfy with the title and priority headers derived from the alert level.
+func Test_PushNotifier_Notify_Ntfy_Success(t *testing.T) {
+	t.Parallel()
+
+	var headers http.Header
+	var body []byte
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n, err := NewPushNotifier(pushServiceNtfy, srv.URL+"/sesmon", "secret", nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Description: "JBOD"}
+	report := ChangeReport{Changes: []Change{
+		{ID: "23#0", Kind: ChangeKindDegraded, After: &Result{Status: ptr(sesStatusCritical)}},
+		{ID: "23#1", Kind: ChangeKindDegraded, After: &Result{Status: ptr(sesStatusNoncritical)}},
+	}}
+
+	require.NoError(t, n.Notify(t.Context(), device, "test message", report))
+	require.Equal(t, "SES critical alert on JBOD", headers.Get("Title"))
+	require.Equal(t, "5", headers.Get("Priority"))
+	require.Equal(t, "Bearer secret", headers.Get("Authorization"))
+	require.Equal(t, "test message", string(body))
+
+	report.Changes = report.Changes[1:]
+	require.NoError(t, n.Notify(t.Context(), device, "test message", &report))
+	require.Equal(t, "SES alert on JBOD", headers.Get("Title"))
+	require.Equal(t, "4", headers.Get("Priority"))
+
+	require.NoError(t, n.Notify(t.Context(), device, "Heartbeat: alive", nil))
+	require.Equal(t, "SES notification on JBOD", headers.Get("Title"))
+	require.Equal(t, "3", headers.Get("Priority"))
+}
+
+// Expectation: PushNotifier should post a JSON message with the application token to gotify.
+func Test_PushNotifier_Notify_Gotify_Success(t *testing.T) {
+	t.Parallel()
+
+	var path string
+	var headers http.Header
+	var received gotifyMessage
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		headers = r.Header.Clone()
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n, err := NewPushNotifier(pushServiceGotify, srv.URL, "app-token", nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	report := ChangeReport{Kind: ChangeKindRecovered, Changes: []Change{{ID: "23#0", Kind: ChangeKindRecovered}}}
+
+	require.NoError(t, n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", report))
+	require.Equal(t, "/message", path)
+	require.Equal(t, "app-token", headers.Get("X-Gotify-Key"))
+	require.Equal(t, gotifyMessage{Title: "SES recovery on /dev/sg25", Message: "test message", Priority: 3}, received)
+}
+
+// Expectation: PushNotifier should terminate the HTTP call on context cancellation.
+func Test_PushNotifier_Notify_ContextCancelled_Error(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	n, err := NewPushNotifier(pushServiceNtfy, srv.URL+"/sesmon", "", &PushNotifierConfig{
+		NotifyAttempts:        ptr(3),
+		NotifyAttemptInterval: ptr(10 * time.Millisecond),
+	}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = n.Notify(ctx, Device{Path: "/dev/sg25"}, "test message", nil)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+// Expectation: PushNotifier should return an error on a non-2xx status.
+func Test_PushNotifier_Notify_BadStatus_Error(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	n, err := NewPushNotifier(pushServiceNtfy, srv.URL+"/sesmon", "", &PushNotifierConfig{
+		NotifyAttempts: ptr(1),
+	}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	err = n.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", nil)
+	require.ErrorIs(t, err, errUnexpectedStatus)
+}
+
+// Expectation: PushNotifier Config should not reveal the token.
+func Test_PushNotifier_Config_RedactsToken_Success(t *testing.T) {
+	t.Parallel()
+
+	n, err := NewPushNotifier(pushServiceGotify, "https://gotify.example.com", "secret-token", nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, "push_notifier", n.Name())
+	require.NotContains(t, n.Config(), "secret-token")
+	require.Contains(t, n.Config(), "token=[redacted]")
+}
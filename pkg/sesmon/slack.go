@@ -0,0 +1,173 @@
+package sesmon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// slackColorCritical is the attachment color of critical element transitions (red).
+	slackColorCritical = "danger"
+
+	// slackColorWarning is the attachment color of other element degradations (yellow).
+	slackColorWarning = "warning"
+
+	// slackColorRecovery is the attachment color of element recoveries (green).
+	slackColorRecovery = "good"
+)
+
+// SlackPayload is the JSON body that is sent by a [SlackNotifier]
+// (in the format of a Slack incoming webhook with attachments).
+type SlackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []SlackAttachment `json:"attachments,omitempty"`
+}
+
+// SlackAttachment is a single (colored) attachment of a [SlackPayload].
+type SlackAttachment struct {
+	Color    string `json:"color"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	Fallback string `json:"fallback"`
+}
+
+var _ Notifier = (*SlackNotifier)(nil)
+
+// SlackNotifier is a [Notifier] sending a formatted [SlackPayload] to a
+// Slack incoming webhook URL using an HTTP POST request. The affected
+// elements of a [ChangeReport] are listed in attachments colored by
+// their severity (red for critical, yellow for warnings, green for recoveries).
+// A [WebhookNotifierConfig.MessageTemplate] replaces the text above the attachments.
+type SlackNotifier struct {
+	webhook *WebhookNotifier
+}
+
+// NewSlackNotifier returns a pointer to a new [SlackNotifier].
+func NewSlackNotifier(target string, cfg *WebhookNotifierConfig, fsys afero.Fs, logger *slog.Logger) (*SlackNotifier, error) {
+	webhook, err := newWebhookNotifier(target, redactedURL(target), nil, "", cfg, fsys, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SlackNotifier{webhook: webhook}, nil
+}
+
+// Notify sends the [SlackPayload] to the Slack incoming webhook URL with HTTP POST.
+// Any response status code other than 2xx is considered as a failed attempt.
+// It both observes and respects context cancellations for earlier notification terminations.
+func (n *SlackNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	payload := slackPayload(device, message, extra)
+	if n.webhook.tmpl != nil {
+		rendered, err := renderMessage(n.webhook.tmpl, device, message, extra)
+		if err != nil {
+			n.webhook.logger.Warn(fmt.Sprintf("%q: %v (using default message)", n.webhook.display, err), LogEvent("template_failure"))
+		} else {
+			payload.Text = rendered
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%q: failure marshalling payload to JSON: %w", n.webhook.display, err)
+	}
+
+	return n.webhook.deliver(ctx, body)
+}
+
+// Name returns the name of the notification agent as a string.
+func (n *SlackNotifier) Name() string {
+	return "slack_notifier"
+}
+
+// Config returns the configuration of the notification agent as a string.
+// The webhook URL is redacted from the output (as it contains the secret).
+func (n *SlackNotifier) Config() string {
+	cfgJSON, err := json.Marshal(n.webhook.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+
+	return fmt.Sprintf("[redacted]:%s", cfgJSON)
+}
+
+// slackPayload builds the [SlackPayload] for a notification. If the extra is a
+// [ChangeReport], the affected elements are grouped into colored attachments,
+// otherwise the message is sent as it is (e.g. for heartbeats).
+func slackPayload(device Device, message string, extra any) SlackPayload {
+	var report ChangeReport
+	switch r := extra.(type) {
+	case ChangeReport:
+		report = r
+	case *ChangeReport:
+		if r == nil {
+			return SlackPayload{Text: message}
+		}
+		report = *r
+	default:
+		return SlackPayload{Text: message}
+	}
+
+	name := fne(device.Description, device.Path)
+	title := "SES alert on " + name
+	if report.Kind == ChangeKindRecovered {
+		title = "SES recovery on " + name
+	}
+
+	groups := make(map[string][]string)
+	for _, ch := range report.Changes {
+		color := slackColor(ch)
+		groups[color] = append(groups[color], "• "+slackChangeLine(ch))
+	}
+
+	payload := SlackPayload{Text: fmt.Sprintf("%s (%s)", title, device.Path)}
+	for _, group := range []struct{ color, title string }{
+		{slackColorCritical, "Critical"},
+		{slackColorWarning, "Warning"},
+		{slackColorRecovery, "Recovered"},
+	} {
+		lines, ok := groups[group.color]
+		if !ok {
+			continue
+		}
+		text := strings.Join(lines, "\n")
+		payload.Attachments = append(payload.Attachments, SlackAttachment{
+			Color:    group.color,
+			Title:    fmt.Sprintf("%s (%d)", group.title, len(lines)),
+			Text:     text,
+			Fallback: group.title + ": " + text,
+		})
+	}
+
+	return payload
+}
+
+// slackColor returns the attachment color for a [Change] by its alert level.
+func slackColor(ch Change) string {
+	switch changeAlertLevel(ch) {
+	case alertLevelRecovery:
+		return slackColorRecovery
+	case alertLevelCritical:
+		return slackColorCritical
+	default:
+		return slackColorWarning
+	}
+}
+
+// slackChangeLine formats a [Change] as a single line, listing the affected element
+// by its descriptor (falling back to the element type) with the status transition.
+func slackChangeLine(ch Change) string {
+	name := fmtPtrStr(ch.Descriptor, fmtPtrStr(ch.TypeDesc, ch.ID))
+
+	line := fmt.Sprintf("*%s* (%s %s): %s → %s", name,
+		fmtPtrStr(ch.TypeDesc, "-"), ch.ID, statusAsText(ch.Before), statusAsText(ch.After))
+	if ch.Reason != nil {
+		line += " - " + *ch.Reason
+	}
+
+	return line
+}
@@ -0,0 +1,173 @@
+package sesmon
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: NewSlackNotifier should successfully create notifier with a valid URL.
+func Test_NewSlackNotifier_Success(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewSlackNotifier("https://hooks.slack.com/services/T0/B0/X", nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.NotNil(t, notifier)
+	require.Equal(t, DefaultWebhookNotifierConfig(), notifier.webhook.cfg)
+}
+
+// Expectation: NewSlackNotifier should return an error when the URL is not http(s).
+func Test_NewSlackNotifier_InvalidURL_Error(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewSlackNotifier("ftp://example.com", nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Nil(t, notifier)
+}
+
+// Expectation: SlackNotifier should post attachments colored by severity listing elements by descriptor.
+func Test_SlackNotifier_Notify_Success(t *testing.T) {
+	t.Parallel()
+
+	var received SlackPayload
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	notifier, err := NewSlackNotifier(srv.URL, nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Description: "JBOD"}
+	report := ChangeReport{
+		Device: device,
+		Kind:   ChangeKindDegraded,
+		Changes: []Change{
+			{
+				ID: "23#0", Kind: ChangeKindDegraded, TypeDesc: ptr("Array device slot"), Descriptor: ptr("Slot 00"),
+				Before: &Result{Status: ptr(sesStatusOK), StatusDesc: ptr("OK")},
+				After:  &Result{Status: ptr(sesStatusCritical), StatusDesc: ptr("Critical")},
+			},
+			{
+				ID: "23#1", Kind: ChangeKindDegraded, TypeDesc: ptr("Array device slot"), Descriptor: ptr("Slot 01"),
+				Before: &Result{Status: ptr(sesStatusOK), StatusDesc: ptr("OK")},
+				After:  &Result{Status: ptr(sesStatusNoncritical), StatusDesc: ptr("Noncritical")},
+			},
+			{
+				ID: "3#0", Kind: ChangeKindRecovered, TypeDesc: ptr("Cooling"),
+				Before: &Result{Status: ptr(sesStatusCritical), StatusDesc: ptr("Critical")},
+				After:  &Result{Status: ptr(sesStatusOK), StatusDesc: ptr("OK")},
+			},
+		},
+	}
+
+	require.NoError(t, notifier.Notify(t.Context(), device, "test message", report))
+
+	require.Equal(t, "SES alert on JBOD (/dev/sg25)", received.Text)
+	require.Len(t, received.Attachments, 3)
+
+	require.Equal(t, slackColorCritical, received.Attachments[0].Color)
+	require.Contains(t, received.Attachments[0].Text, "*Slot 00*")
+	require.Contains(t, received.Attachments[0].Text, "OK → Critical")
+
+	require.Equal(t, slackColorWarning, received.Attachments[1].Color)
+	require.Contains(t, received.Attachments[1].Text, "*Slot 01*")
+
+	require.Equal(t, slackColorRecovery, received.Attachments[2].Color)
+	require.Contains(t, received.Attachments[2].Text, "*Cooling*")
+}
+
+// Expectation: SlackNotifier should send the message as it is without a change report.
+func Test_SlackNotifier_Notify_NoReport_Success(t *testing.T) {
+	t.Parallel()
+
+	var received SlackPayload
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	notifier, err := NewSlackNotifier(srv.URL, nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "Heartbeat: alive", nil))
+
+	require.Equal(t, "Heartbeat: alive", received.Text)
+	require.Empty(t, received.Attachments)
+}
+
+// Expectation: SlackNotifier should retry on non-2xx status and return an error after all attempts.
+func Test_SlackNotifier_Notify_BadStatus_Error(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	notifier, err := NewSlackNotifier(srv.URL, &WebhookNotifierConfig{
+		NotifyAttempts:        ptr(2),
+		NotifyAttemptInterval: ptr(time.Millisecond),
+	}, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", ChangeReport{})
+	require.ErrorIs(t, err, errUnexpectedStatus)
+	require.Equal(t, int32(2), calls.Load())
+}
+
+// Expectation: SlackNotifier should not contain the secret path of the webhook URL
+// in any of the errors and logs of failed deliveries (by status or by connection).
+func Test_SlackNotifier_Notify_RedactsURL_Error(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	for _, base := range []string{srv.URL, closed.URL} {
+		var buf safeBuffer
+		notifier, err := NewSlackNotifier(base+"/services/T0/B0/SECRET", &WebhookNotifierConfig{
+			NotifyAttempts:        ptr(2),
+			NotifyAttemptInterval: ptr(time.Millisecond),
+		}, afero.NewMemMapFs(), NewLogger(&buf, false, true))
+		require.NoError(t, err)
+
+		err = notifier.Notify(t.Context(), Device{Path: "/dev/sg25"}, "test message", ChangeReport{})
+		require.Error(t, err)
+		require.NotContains(t, err.Error(), "SECRET")
+		require.Contains(t, err.Error(), "/[redacted]")
+		require.NotEmpty(t, buf.String())
+		require.NotContains(t, buf.String(), "SECRET")
+	}
+}
+
+// Expectation: SlackNotifier Config should redact the webhook URL.
+func Test_SlackNotifier_Config_RedactsURL_Success(t *testing.T) {
+	t.Parallel()
+
+	notifier, err := NewSlackNotifier("https://hooks.slack.com/services/T0/B0/SECRET", nil, afero.NewMemMapFs(), slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	require.Equal(t, "slack_notifier", notifier.Name())
+	require.NotContains(t, notifier.Config(), "SECRET")
+	require.Contains(t, notifier.Config(), "[redacted]")
+}
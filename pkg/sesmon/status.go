@@ -0,0 +1,201 @@
+package sesmon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// statusSocketPerms are the permissions of the status socket (owner and group only).
+	statusSocketPerms = 0o660
+
+	// statusSocketTimeout is the maximum time to handle a single status query.
+	statusSocketTimeout = 5 * time.Second
+
+	// statusMaxRequestSize is the maximum size (in bytes) of a single status query line.
+	statusMaxRequestSize = 1024
+)
+
+// StatusResponse is the JSON response to a query on the status socket (one line).
+type StatusResponse struct {
+	Error    string           `json:"error,omitempty"`
+	Overview *ProgramOverview `json:"overview,omitempty"`
+	Device   *DeviceStatus    `json:"device,omitempty"`
+	Ack      *ElementAck      `json:"ack,omitempty"`
+}
+
+// startStatusSocket starts listening on the Unix socket for status queries (if configured).
+// A stale socket file (e.g. of a previous program that was killed) is replaced.
+// The caller is expected to hold the lock of the [Program] when calling.
+func (p *Program) startStatusSocket() {
+	path := p.config.StatusSocket
+	if path == "" {
+		return
+	}
+
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		p.logger.Error(fmt.Sprintf("Error starting status socket on [%s]: %v", path, err), LogEvent("error"))
+
+		return
+	}
+	if err := os.Chmod(path, statusSocketPerms); err != nil {
+		p.logger.Warn(fmt.Sprintf("Failure setting permissions of status socket: %v", err), LogEvent("warning"))
+	}
+
+	p.status = ln
+
+	logger := p.logger
+	go func() {
+		defer RecoverGoPanic("status", logger)
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					logger.Error(fmt.Sprintf("Error serving status socket: %v", err), LogEvent("error"))
+				}
+
+				return
+			}
+			go p.serveStatus(conn)
+		}
+	}()
+
+	p.logger.Info(fmt.Sprintf("Serving status queries on socket [%s]", path), LogEvent("status"))
+}
+
+// stopStatusSocket stops listening on the Unix socket for status queries (if running),
+// which also removes the socket file. The caller is expected to hold the lock of the [Program].
+func (p *Program) stopStatusSocket() {
+	if p.status == nil {
+		return
+	}
+
+	_ = p.status.Close()
+	p.status = nil
+}
+
+// serveStatus answers a single status query (one line) of a connection with a JSON
+// [StatusResponse] (one line), before closing the connection. Supported queries are
+// "status" for all devices, "status <device>" for a device (by path or address) and
+// "ack <device> <element>" for acknowledging an element (see [DeviceMonitor.Acknowledge]).
+func (p *Program) serveStatus(conn net.Conn) {
+	defer RecoverGoPanic("status-conn", p.Logger())
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(statusSocketTimeout))
+
+	line, err := bufio.NewReader(io.LimitReader(conn, statusMaxRequestSize)).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+
+	resp := p.statusQuery(line)
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(StatusResponse{Error: fmt.Sprintf("failure marshalling response to JSON: %v", err)})
+	}
+
+	_, _ = conn.Write(append(data, '\n'))
+}
+
+// statusQuery returns the [StatusResponse] to a single status query (see [Program.serveStatus]).
+func (p *Program) statusQuery(query string) StatusResponse {
+	fields := strings.Fields(query)
+	if len(fields) == 3 && fields[0] == "ack" { //nolint:mnd
+		return p.ackQuery(fields[1], fields[2])
+	}
+	if len(fields) == 0 || fields[0] != "status" || len(fields) > 2 { //nolint:mnd
+		return StatusResponse{Error: fmt.Sprintf("%v: %q (expected \"status [device]\" or \"ack <device> <element>\")",
+			errUnknownQuery, strings.TrimSpace(query))}
+	}
+
+	if len(fields) == 1 {
+		overview := p.overview()
+
+		return StatusResponse{Overview: &overview}
+	}
+
+	monitor := p.findMonitor(fields[1])
+	if monitor == nil {
+		return StatusResponse{Error: fmt.Sprintf("%q: %v", fields[1], errDeviceNotConfigured)}
+	}
+	status := monitor.Status()
+
+	return StatusResponse{Device: &status}
+}
+
+// ackQuery returns the [StatusResponse] to an acknowledgement query (see [Program.serveStatus]).
+func (p *Program) ackQuery(device string, element string) StatusResponse {
+	monitor := p.findMonitor(device)
+	if monitor == nil {
+		return StatusResponse{Error: fmt.Sprintf("%q: %v", device, errDeviceNotConfigured)}
+	}
+
+	ack, err := monitor.Acknowledge(element)
+	if err != nil {
+		return StatusResponse{Error: fmt.Sprintf("%q: %v", device, err)}
+	}
+
+	return StatusResponse{Ack: &ack}
+}
+
+// QueryStatus sends a status query (see [Program.serveStatus]) to the status socket
+// of a running program and returns its [StatusResponse], or an error if it failed.
+func QueryStatus(path string, device string) (StatusResponse, error) {
+	query := "status"
+	if device != "" {
+		query += " " + device
+	}
+
+	return sendQuery(path, query)
+}
+
+// QueryAck sends an acknowledgement query for an element of a device (see [Program.serveStatus])
+// to the status socket of a running program and returns its [StatusResponse], or an error if it failed.
+func QueryAck(path string, device string, element string) (StatusResponse, error) {
+	return sendQuery(path, "ack "+device+" "+element)
+}
+
+// sendQuery sends a query (see [Program.serveStatus]) to the status socket
+// of a running program and returns its [StatusResponse], or an error if it failed.
+func sendQuery(path string, query string) (StatusResponse, error) {
+	conn, err := net.DialTimeout("unix", path, statusSocketTimeout)
+	if err != nil {
+		return StatusResponse{}, fmt.Errorf("failure connecting to status socket: %w", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(statusSocketTimeout))
+
+	if _, err := conn.Write([]byte(query + "\n")); err != nil {
+		return StatusResponse{}, fmt.Errorf("failure writing to status socket: %w", err)
+	}
+
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		return StatusResponse{}, fmt.Errorf("failure reading from status socket: %w", err)
+	}
+
+	var resp StatusResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return StatusResponse{}, fmt.Errorf("failure parsing response: %w: %w", errInvalidJSON, err)
+	}
+	if resp.Error != "" {
+		return StatusResponse{}, fmt.Errorf("%w: %s", errStatusQueryFailed, resp.Error)
+	}
+
+	return resp, nil
+}
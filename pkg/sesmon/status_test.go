@@ -0,0 +1,110 @@
+package sesmon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStatusSocketPath returns a short path for a status socket (within the limits of Unix sockets).
+func newTestStatusSocketPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "sesmon")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "status.sock")
+}
+
+// Expectation: statusQuery should answer status queries for all devices or one device.
+func Test_Program_statusQuery_Success(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestHealthProgram(t, `{}`, "")
+
+	resp := prog.statusQuery("status\n")
+	require.Empty(t, resp.Error)
+	require.NotNil(t, resp.Overview)
+	require.Len(t, resp.Overview.Devices, 1)
+	require.Equal(t, "/dev/sg0", resp.Overview.Devices[0].Device.Path)
+
+	resp = prog.statusQuery("status /dev/sg0")
+	require.Empty(t, resp.Error)
+	require.Nil(t, resp.Overview)
+	require.NotNil(t, resp.Device)
+	require.Equal(t, "/dev/sg0", resp.Device.Device.Path)
+}
+
+// Expectation: statusQuery should answer unknown queries and devices with an error.
+func Test_Program_statusQuery_Error(t *testing.T) {
+	t.Parallel()
+
+	prog := newTestHealthProgram(t, `{}`, "")
+
+	resp := prog.statusQuery("")
+	require.Contains(t, resp.Error, errUnknownQuery.Error())
+
+	resp = prog.statusQuery("restart /dev/sg0")
+	require.Contains(t, resp.Error, errUnknownQuery.Error())
+
+	resp = prog.statusQuery("status /dev/sg0 /dev/sg1")
+	require.Contains(t, resp.Error, errUnknownQuery.Error())
+
+	resp = prog.statusQuery("status /dev/sg9")
+	require.Contains(t, resp.Error, errDeviceNotConfigured.Error())
+	require.Nil(t, resp.Device)
+
+	resp = prog.statusQuery("ack /dev/sg9 23#0")
+	require.Contains(t, resp.Error, errDeviceNotConfigured.Error())
+
+	resp = prog.statusQuery("ack /dev/sg0 23#0")
+	require.Contains(t, resp.Error, errElementNotFound.Error())
+	require.Nil(t, resp.Ack)
+}
+
+// Expectation: The status socket should serve status queries while running and be removed on stop.
+func Test_Program_StatusSocket_Success(t *testing.T) {
+	t.Parallel()
+
+	path := newTestStatusSocketPath(t)
+
+	// A stale socket file (of a killed program) should be replaced.
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	prog := newTestHealthProgram(t, `{}`, "status_socket: "+path+"\n")
+	prog.Start(t.Context())
+
+	require.Eventually(t, func() bool {
+		resp, err := QueryStatus(path, "")
+
+		return err == nil && resp.Overview.Devices[0].PollStats.SuccessfulPolls == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(statusSocketPerms), fi.Mode().Perm())
+
+	resp, err := QueryStatus(path, "/dev/sg0")
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", resp.Device.Device.Path)
+
+	_, err = QueryStatus(path, "/dev/sg9")
+	require.ErrorIs(t, err, errStatusQueryFailed)
+
+	prog.Stop()
+	<-prog.Done()
+
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = QueryStatus(path, "")
+	require.Error(t, err)
+}
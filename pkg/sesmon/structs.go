@@ -0,0 +1,223 @@
+package sesmon
+
+import (
+	"encoding/json"
+)
+
+// SES Structure (as returned by the "sg_ses" program)
+// ------------------------------------------------------
+
+type Root struct {
+	Join JoinPages `json:"join_of_diagnostic_pages"`
+}
+
+type JoinPages struct {
+	ElementList []Element `json:"element_list"`
+}
+
+type Element struct {
+	SubenclosureID   *int              `json:"subenclosure_identifier,omitempty"`
+	ElementType      *ElementType      `json:"element_type,omitempty"`
+	ElementNumber    *int              `json:"element_number,omitempty"`
+	Descriptor       *string           `json:"descriptor,omitempty"`
+	StatusDescriptor *StatusDescriptor `json:"status_descriptor,omitempty"`
+}
+
+type ElementType struct {
+	I       *int    `json:"i,omitempty"`
+	Meaning *string `json:"meaning,omitempty"`
+}
+
+type StatusDescriptor struct {
+	Status   *CodeMeaning `json:"status,omitempty"`
+	PrdFail  *int         `json:"prdfail,omitempty"`
+	Disabled *int         `json:"disabled,omitempty"`
+	Swap     *int         `json:"swap,omitempty"`
+
+	Temperature *CodeMeaning `json:"temperature,omitempty"`
+	Voltage     *Voltage     `json:"voltage,omitempty"`
+	Current     *Current     `json:"current,omitempty"`
+
+	ActualSpeedRPM *int         `json:"actual_speed_rpm,omitempty"`
+	SpeedCode      *CodeMeaning `json:"speed_code,omitempty"`
+}
+
+type CodeMeaning struct {
+	I       *int    `json:"i,omitempty"`
+	Meaning *string `json:"meaning,omitempty"`
+}
+
+type Voltage struct {
+	RawValue     *int    `json:"raw_value,omitempty"`
+	ValueInVolts *string `json:"value_in_volts,omitempty"`
+}
+
+type Current struct {
+	RawValue    *int    `json:"raw_value,omitempty"`
+	ValueInAmps *string `json:"value_in_amps,omitempty"`
+}
+
+// Internal Structure
+// ------------------------------------------------------
+
+// Device is the device information needed for monitoring.
+type Device struct {
+	Type        int    `json:"type"` // 0 = Device, 1 = JSON File
+	Path        string `json:"path"`
+	Address     string `json:"address"`
+	Description string `json:"description"`
+}
+
+// DeviceSnapshot is a snapshot of the [Device] in a certain state.
+type DeviceSnapshot struct {
+	Device     Device          `json:"device"`
+	CapturedAt string          `json:"captured_at"`
+	Warnings   string          `json:"warnings,omitempty"` // standard error of the poll (if any)
+	Raw        json.RawMessage `json:"raw"`
+}
+
+// Result is a single parsed [Element] with the fields relevant for us.
+type Result struct {
+	Type    int `json:"element_type"`        // element type (as integer)
+	TypeNum int `json:"element_type_number"` // element number (of type)
+
+	// Subenclosure identifier of the element (nil for the primary subenclosure).
+	Subenclosure *int `json:"subenclosure,omitempty"`
+
+	TypeDesc   *string `json:"element_type_desc,omitempty"` // element type (as text)
+	Descriptor *string `json:"descriptor,omitempty"`        // element descriptor (e.g. "Slot 03")
+	Status     *int    `json:"status,omitempty"`
+	StatusDesc *string `json:"status_desc,omitempty"`
+	PrdFail    *int    `json:"prdfail,omitempty"`
+	Disabled   *int    `json:"disabled,omitempty"`
+	Swap       *int    `json:"swap,omitempty"`
+
+	Temperature  *string `json:"temperature,omitempty"`
+	TemperatureC *int    `json:"temperature_c,omitempty"` // numeric (Celsius)
+	Voltage      *string `json:"voltage,omitempty"`       // value_in_volts
+	Amperage     *string `json:"amperage,omitempty"`      // value_in_amps
+
+	FanRPM       *int    `json:"fan_rpm,omitempty"`        // actual_speed_rpm
+	FanSpeed     *int    `json:"fan_speed,omitempty"`      // speed code (class)
+	FanSpeedDesc *string `json:"fan_speed_desc,omitempty"` // speed code (as text)
+}
+
+// Change is a single change between two [Element] (internally [Result]).
+type Change struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`     // degraded or recovered
+	Severity string `json:"severity"` // ok, info, warning, critical or unrecoverable
+	Type     int    `json:"element_type"`
+	TypeNum  int    `json:"element_type_number"`
+
+	TypeDesc   *string `json:"element_type_desc,omitempty"`
+	Descriptor *string `json:"descriptor,omitempty"` // element descriptor (e.g. "Slot 03")
+	Reason     *string `json:"reason,omitempty"`     // non-status changes (e.g. thresholds)
+	Before     *Result `json:"before,omitempty"`
+	After      *Result `json:"after,omitempty"`
+}
+
+// ChangeReport is a report of all [Change] between two [Device] polls.
+type ChangeReport struct {
+	Device     Device   `json:"device"`
+	DetectedAt string   `json:"detected_at"`
+	Kind       string   `json:"kind"`     // recovered if all changes are recoveries
+	Severity   string   `json:"severity"` // highest severity of all changes
+	Changes    []Change `json:"changes"`
+	Warnings   string   `json:"warnings,omitempty"` // standard error of the poll (if any)
+
+	// Full parsed results of the device at the time of the changes (if [ReportResults]).
+	Results map[string]Result `json:"results,omitempty"`
+}
+
+// ChangeEvent is the structured record of a [ChangeReport] as logged with [LogChangeEvents].
+// It flattens the [Change] into their status transitions, as a stable format for log processing.
+type ChangeEvent struct {
+	Device      string             `json:"device"`
+	Address     string             `json:"address"`
+	DetectedAt  string             `json:"detected_at"`
+	Kind        string             `json:"kind"`
+	Severity    string             `json:"severity"`
+	Transitions []ChangeTransition `json:"transitions"`
+}
+
+// ChangeTransition is the status transition of a single element within a [ChangeEvent].
+type ChangeTransition struct {
+	ID         string  `json:"id"`
+	Kind       string  `json:"kind"`
+	Severity   string  `json:"severity"`
+	TypeDesc   *string `json:"element_type_desc"`
+	Descriptor *string `json:"descriptor"`
+	Reason     *string `json:"reason"`
+	Before     *int    `json:"before_status"`
+	BeforeDesc *string `json:"before_status_desc"`
+	After      *int    `json:"after_status"`
+	AfterDesc  *string `json:"after_status_desc"`
+}
+
+// DeviceStatus is the current status of a [DeviceMonitor] (e.g. for overviews).
+type DeviceStatus struct {
+	Device        Device            `json:"device"`
+	LastPollAt    string            `json:"last_poll_at,omitempty"`
+	LastPollError string            `json:"last_poll_error,omitempty"`
+	PollFailures  int               `json:"poll_failures"`
+	InBackoff     bool              `json:"in_backoff"`
+	BackoffUntil  string            `json:"backoff_until,omitempty"`
+	Maintenance   bool              `json:"maintenance"`
+	PollStats     PollStats         `json:"poll_stats"`
+	Results       map[string]Result `json:"results"`
+
+	Acks map[string]ElementAck `json:"acks,omitempty"`
+}
+
+// ElementAck is the acknowledgement of an element in a status (see [DeviceMonitor.Acknowledge]).
+type ElementAck struct {
+	Element    string  `json:"element"`
+	Descriptor *string `json:"descriptor,omitempty"`
+	Status     *int    `json:"status"`
+	StatusDesc *string `json:"status_desc,omitempty"`
+	AckedAt    string  `json:"acked_at"`
+}
+
+// ElementHistory is the history of the status changes per element, as written to
+// element-history.json (if [DeviceMonitorConfig.ElementHistory] is set).
+type ElementHistory struct {
+	Device    Device                           `json:"device"`
+	UpdatedAt string                           `json:"updated_at"`
+	Elements  map[string][]ElementHistoryEntry `json:"elements"` // oldest first
+}
+
+// ElementHistoryEntry is a single status of an element within the [ElementHistory].
+type ElementHistoryEntry struct {
+	At         string  `json:"at"`
+	Status     *int    `json:"status"`
+	StatusDesc *string `json:"status_desc,omitempty"`
+	Missing    bool    `json:"missing,omitempty"` // element disappeared from the results
+}
+
+// PollStats are the device poll statistics of a [DeviceMonitor] (since its start).
+type PollStats struct {
+	TotalPolls       int    `json:"total_polls"`
+	SuccessfulPolls  int    `json:"successful_polls"`
+	TotalRetries     int    `json:"total_retries"`                // re-attempts (beyond the first attempt)
+	LastPollDuration string `json:"last_poll_duration,omitempty"` // including all attempts
+	LastSuccessAt    string `json:"last_success_at,omitempty"`
+
+	// Fraction of failed polls within the window (if [PollFailureRateAlert] is enabled).
+	RecentFailureRate *float64 `json:"recent_failure_rate,omitempty"`
+}
+
+// DeviceConfigDump is the effective configuration of a [DeviceMonitor] (as merged
+// from the defaults, the global defaults and the device configuration) and its agent.
+type DeviceConfigDump struct {
+	Device         Device               `json:"device"`
+	Config         *DeviceMonitorConfig `json:"config"`
+	Notifier       string               `json:"notifier,omitempty"`
+	NotifierConfig string               `json:"notifier_config,omitempty"`
+}
+
+// ProgramOverview is an overview of all [DeviceStatus] of a [Program].
+type ProgramOverview struct {
+	GeneratedAt string         `json:"generated_at"`
+	Devices     []DeviceStatus `json:"devices"`
+}
@@ -0,0 +1,64 @@
+package sesmon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// States as sent to the service manager (see sd_notify(3) of systemd).
+const (
+	sdNotifyReady    = "READY=1"
+	sdNotifyStopping = "STOPPING=1"
+	sdNotifyWatchdog = "WATCHDOG=1"
+)
+
+// sdNotify sends a state to the service manager on the given notification socket
+// (as passed with NOTIFY_SOCKET by systemd). It is a no-op if the socket is empty.
+func sdNotify(socket string, state string) error {
+	if socket == "" {
+		return nil
+	}
+
+	// Sockets starting with "@" are in the abstract namespace.
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failure connecting to notification socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failure writing to notification socket: %w", err)
+	}
+
+	return nil
+}
+
+// sdWatchdogInterval returns the watchdog interval as requested by the service manager
+// (with WATCHDOG_USEC and WATCHDOG_PID by systemd), or zero if none was requested for us.
+func sdWatchdogInterval(lookupEnv func(string) (string, bool)) time.Duration {
+	usecStr, ok := lookupEnv("WATCHDOG_USEC")
+	if !ok {
+		return 0
+	}
+
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+
+	if pidStr, ok := lookupEnv("WATCHDOG_PID"); ok {
+		if pid, err := strconv.Atoi(pidStr); err != nil || pid != os.Getpid() {
+			return 0
+		}
+	}
+
+	return time.Duration(usec) * time.Microsecond
+}
@@ -0,0 +1,232 @@
+package sesmon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// listenNotifySocket returns a listening notification socket (with a short path).
+func listenNotifySocket(t *testing.T) (string, *net.UnixConn) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "sd")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return socket, conn
+}
+
+// readNotifyState reads a single state from the notification socket.
+func readNotifyState(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	buf := make([]byte, 256)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	return string(buf[:n])
+}
+
+// Expectation: sdNotify should send the state to the notification socket.
+func Test_sdNotify_Success(t *testing.T) {
+	t.Parallel()
+
+	socket, conn := listenNotifySocket(t)
+
+	require.NoError(t, sdNotify(socket, sdNotifyReady))
+	require.Equal(t, sdNotifyReady, readNotifyState(t, conn))
+}
+
+// Expectation: sdNotify should be a no-op without a notification socket.
+func Test_sdNotify_NoSocket_Success(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, sdNotify("", sdNotifyReady))
+}
+
+// Expectation: sdNotify should return an error when the notification socket does not exist.
+func Test_sdNotify_MissingSocket_Error(t *testing.T) {
+	t.Parallel()
+
+	err := sdNotify("/nonexistent/notify.sock", sdNotifyReady)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure connecting")
+}
+
+// Expectation: sdWatchdogInterval should return the watchdog interval only if requested for us.
+func Test_sdWatchdogInterval_Success(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(env map[string]string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			v, ok := env[key]
+
+			return v, ok
+		}
+	}
+	pid := strconv.Itoa(os.Getpid())
+
+	require.Zero(t, sdWatchdogInterval(lookup(nil)))
+	require.Zero(t, sdWatchdogInterval(lookup(map[string]string{"WATCHDOG_USEC": "invalid"})))
+	require.Zero(t, sdWatchdogInterval(lookup(map[string]string{"WATCHDOG_USEC": "30000000", "WATCHDOG_PID": "1"})))
+	require.Equal(t, 30*time.Second, sdWatchdogInterval(lookup(map[string]string{"WATCHDOG_USEC": "30000000"})))
+	require.Equal(t, 30*time.Second, sdWatchdogInterval(lookup(map[string]string{"WATCHDOG_USEC": "30000000", "WATCHDOG_PID": pid})))
+}
+
+// Expectation: DeviceMonitor Progressing should evaluate the last successful poll (or otherwise the start).
+func Test_DeviceMonitor_Progressing_Success(t *testing.T) {
+	t.Parallel()
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			PollInterval:        ptr(time.Minute),
+			PollAttempts:        ptr(1),
+			PollAttemptTimeout:  ptr(10 * time.Second),
+			PollAttemptInterval: ptr(0 * time.Second),
+		},
+		afero.NewMemMapFs(),
+		&mockCommandRunner{},
+		nil,
+		nil,
+	)
+
+	now := time.Now()
+	require.False(t, m.Progressing(now))
+
+	m.state.startedAt.Store(now.Add(-time.Minute).UnixNano())
+	require.True(t, m.Progressing(now))
+
+	m.state.startedAt.Store(now.Add(-time.Hour).UnixNano())
+	require.False(t, m.Progressing(now))
+
+	m.state.lastPollSuccess.Store(now.Add(-2 * time.Minute).UnixNano())
+	require.True(t, m.Progressing(now))
+
+	m.state.lastPollSuccess.Store(now.Add(-3 * time.Minute).UnixNano())
+	require.False(t, m.Progressing(now))
+}
+
+// Expectation: NewProgram should only take the notification socket and watchdog interval
+// of the service manager from the environment with the WithSystemdNotify option.
+//
+//nolint:paralleltest
+func Test_NewProgram_WithSystemdNotify_Success(t *testing.T) {
+	// Note: Cannot use t.Parallel() due to environment manipulation
+	t.Setenv("NOTIFY_SOCKET", "/run/systemd/notify")
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+`)
+
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &safeBuffer{})
+	require.NoError(t, err)
+	require.Empty(t, program.notifySocket)
+	require.Zero(t, program.watchdogInterval)
+
+	program, err = NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &safeBuffer{}, WithSystemdNotify())
+	require.NoError(t, err)
+	require.Equal(t, "/run/systemd/notify", program.notifySocket)
+	require.Equal(t, 30*time.Second, program.watchdogInterval)
+}
+
+// Expectation: Program should notify the service manager about readiness, the watchdog and stopping.
+func Test_Program_SystemdNotify_Success(t *testing.T) {
+	t.Parallel()
+
+	socket, conn := listenNotifySocket(t)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.notifySocket = socket
+	program.watchdogInterval = 100 * time.Millisecond
+
+	program.Start(t.Context())
+
+	require.Equal(t, sdNotifyReady, readNotifyState(t, conn))
+	require.Equal(t, sdNotifyWatchdog, readNotifyState(t, conn))
+
+	program.Stop()
+	<-program.Done()
+
+	for {
+		if state := readNotifyState(t, conn); state != sdNotifyWatchdog {
+			require.Equal(t, sdNotifyStopping, state)
+
+			break
+		}
+	}
+}
+
+// Expectation: Program should withhold the watchdog notifications when no monitor is making poll progress.
+func Test_Program_SystemdWatchdog_Withheld_Success(t *testing.T) {
+	t.Parallel()
+
+	socket, conn := listenNotifySocket(t)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dev/sg0", []byte{}, 0o644))
+
+	yaml := []byte(`
+devices:
+  - device: /dev/sg0
+    enabled: true
+    config:
+      poll_interval: 1s
+      poll_attempts: 1
+      poll_attempt_timeout: 1s
+      poll_attempt_interval: 0s
+`)
+
+	var buf safeBuffer
+	program, err := NewProgram(yaml, fs, &mockDeviceFinder{}, &mockCommandRunner{}, &buf)
+	require.NoError(t, err)
+
+	program.notifySocket = socket
+	program.watchdogInterval = 100 * time.Millisecond
+
+	program.Start(t.Context())
+	defer func() {
+		program.Stop()
+		<-program.Done()
+	}()
+	require.Equal(t, sdNotifyReady, readNotifyState(t, conn))
+
+	program.getMonitors()["/dev/sg0"].state.startedAt.Store(time.Now().Add(-time.Hour).UnixNano())
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "withholding watchdog notifications")
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,98 @@
+package sesmon
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// MessageTemplateData is the data a message template (of a [Notifier]) is evaluated with.
+// For notifications without a [ChangeReport] (e.g. heartbeats), Kind and Changes are empty.
+type MessageTemplateData struct {
+	Device     Device   // device of the notification
+	DetectedAt string   // time of the detected changes (if any)
+	Kind       string   // kind of the detected changes (if any)
+	Severity   string   // highest severity of the detected changes (if any)
+	Changes    []Change // detected changes (if any)
+	Message    string   // default (built-in) notification message
+}
+
+// parseMessageTemplate parses a message template (as [text/template]), which is then also
+// evaluated once with a synthetic change report, so that unknown fields are detected early.
+// An empty message template returns a nil [template.Template] (using the default message).
+func parseMessageTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	tmpl, err := template.New("message_template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failure parsing message_template: %w", errInvalidArgument, err)
+	}
+
+	report := testChangeReport(Device{Path: "/dev/sg0", Description: "test"})
+	if err := tmpl.Execute(io.Discard, newMessageTemplateData(report.Device, "test", report)); err != nil {
+		return nil, fmt.Errorf("%w: failure evaluating message_template: %w", errInvalidArgument, err)
+	}
+
+	return tmpl, nil
+}
+
+// newMessageTemplateData returns the [MessageTemplateData] for a notification,
+// with the change report taken from the extra (if it is a [ChangeReport]).
+func newMessageTemplateData(device Device, message string, extra any) MessageTemplateData {
+	data := MessageTemplateData{Device: device, Message: message}
+
+	var report *ChangeReport
+	switch r := extra.(type) {
+	case ChangeReport:
+		report = &r
+	case *ChangeReport:
+		report = r
+	}
+	if report != nil {
+		data.DetectedAt = report.DetectedAt
+		data.Kind = report.Kind
+		data.Severity = report.Severity
+		data.Changes = report.Changes
+	}
+
+	return data
+}
+
+// renderMessage evaluates the message template (if not nil) for a notification.
+// The default message is returned as it is if no message template is set.
+func renderMessage(tmpl *template.Template, device Device, message string, extra any) (string, error) {
+	if tmpl == nil {
+		return message, nil
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, newMessageTemplateData(device, message, extra)); err != nil {
+		return message, fmt.Errorf("failure evaluating message_template: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// renderFetchArgs evaluates the arguments of a fetch command (each as [text/template])
+// with the [Device], so that e.g. "{{.Path}}" is replaced with the device path.
+func renderFetchArgs(args []string, device Device) ([]string, error) {
+	rendered := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		tmpl, err := template.New("fetch_args").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failure parsing fetch_args %q: %w", errInvalidArgument, arg, err)
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, device); err != nil {
+			return nil, fmt.Errorf("%w: failure evaluating fetch_args %q: %w", errInvalidArgument, arg, err)
+		}
+		rendered = append(rendered, sb.String())
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,92 @@
+package sesmon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Expectation: parseMessageTemplate should return a nil template for an empty message template.
+func Test_parseMessageTemplate_Empty_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := parseMessageTemplate("  ")
+	require.NoError(t, err)
+	require.Nil(t, tmpl)
+}
+
+// Expectation: parseMessageTemplate should reject templates with syntax errors or unknown fields.
+func Test_parseMessageTemplate_Invalid_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseMessageTemplate("{{.Device.Path")
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "failure parsing")
+
+	_, err = parseMessageTemplate("{{.Unknown}}")
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "failure evaluating")
+}
+
+// Expectation: renderMessage should evaluate the template with the device and changes of a report.
+func Test_renderMessage_Success(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := parseMessageTemplate(
+		`{{.Device.Description}}: {{len .Changes}} {{.Kind}} ({{.Severity}}){{range .Changes}} [{{.Descriptor}}]{{end}}`)
+	require.NoError(t, err)
+
+	device := Device{Path: "/dev/sg25", Description: "JBOD"}
+	report := ChangeReport{
+		Kind:     ChangeKindDegraded,
+		Severity: SeverityCritical,
+		Changes:  []Change{{ID: "23#0", Descriptor: ptr("Slot 00")}, {ID: "23#1", Descriptor: ptr("Slot 01")}},
+	}
+
+	msg, err := renderMessage(tmpl, device, "default", report)
+	require.NoError(t, err)
+	require.Equal(t, "JBOD: 2 degraded (critical) [Slot 00] [Slot 01]", msg)
+
+	msg, err = renderMessage(tmpl, device, "default", &report)
+	require.NoError(t, err)
+	require.Equal(t, "JBOD: 2 degraded (critical) [Slot 00] [Slot 01]", msg)
+}
+
+// Expectation: renderMessage should expose the default message and return it without a template.
+func Test_renderMessage_DefaultMessage_Success(t *testing.T) {
+	t.Parallel()
+
+	msg, err := renderMessage(nil, Device{}, "default", nil)
+	require.NoError(t, err)
+	require.Equal(t, "default", msg)
+
+	tmpl, err := parseMessageTemplate(`SES: {{.Message}}`)
+	require.NoError(t, err)
+
+	msg, err = renderMessage(tmpl, Device{}, "Heartbeat: alive", nil)
+	require.NoError(t, err)
+	require.Equal(t, "SES: Heartbeat: alive", msg)
+}
+
+// Expectation: renderFetchArgs should evaluate each argument with the device.
+func Test_renderFetchArgs_Success(t *testing.T) {
+	t.Parallel()
+
+	args, err := renderFetchArgs(
+		[]string{"--json", "--device={{.Path}}", "{{.Address}}"},
+		Device{Path: "/dev/sg25", Address: "0x500a098012345678"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"--json", "--device=/dev/sg25", "0x500a098012345678"}, args)
+}
+
+// Expectation: renderFetchArgs should return an error for invalid templates.
+func Test_renderFetchArgs_Invalid_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := renderFetchArgs([]string{"{{.Path"}, Device{Path: "/dev/sg25"})
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = renderFetchArgs([]string{"{{.Serial}}"}, Device{Path: "/dev/sg25"})
+	require.ErrorIs(t, err, errInvalidArgument)
+}
@@ -0,0 +1,1345 @@
+package sesmon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// fne returns the first non-empty string of two strings.
+func fne(a, b string) string {
+	if a != "" {
+		return a
+	}
+
+	return b
+}
+
+// fnz returns the first non-zero integer of two integers.
+func fnz(a, b int) int {
+	if a != 0 {
+		return a
+	}
+
+	return b
+}
+
+// ptr converts a value of type [T] to a pointer of type [*T].
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// ptrIntEqual compares two integer pointers (nil safe).
+func ptrIntEqual(a, b *int) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+
+	return *a == *b
+}
+
+// ptrStrEqualFold compares case insensitive two string pointers (nil safe).
+func ptrStrEqualFold(a, b *string) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+
+	return strings.EqualFold(*a, *b)
+}
+
+// fmtPtrInt converts an integer pointer to a string or returns [e] if nil.
+func fmtPtrInt(p *int, e string) string {
+	if p == nil {
+		return e
+	}
+
+	return strconv.Itoa(*p)
+}
+
+// fmtPtrStr dereferences a string pointer to a string or returns [e] if nil.
+func fmtPtrStr(p *string, e string) string {
+	if p == nil {
+		return e
+	}
+
+	return *p
+}
+
+// fmtPtrQStr dereferences a string pointer to a %q string or returns [e] if nil.
+func fmtPtrQStr(p *string, e string) string {
+	if p == nil {
+		return e
+	}
+
+	return fmt.Sprintf("%q", *p)
+}
+
+// durPtrToStrPtr converts a [time.Duration] pointer to a string pointer (nil safe).
+func durPtrToStrPtr(d *time.Duration) *string {
+	if d == nil {
+		return nil
+	}
+	s := d.String()
+
+	return &s
+}
+
+// minPollInterval is the minimum allowed [DeviceMonitorConfig.PollInterval].
+const minPollInterval = 100 * time.Millisecond
+
+// durationPtrType is the [reflect.Type] of a [time.Duration] pointer.
+var durationPtrType = reflect.TypeFor[*time.Duration]()
+
+// decodeYAMLDurations decodes a YAML mapping node into out (a pointer to a struct
+// of type typ, or of a type with the same underlying struct), where any bare
+// integers given for [time.Duration] pointer fields are interpreted as seconds.
+// Unknown fields are rejected, as the node is decoded outside the strict decoder.
+func decodeYAMLDurations(value *yaml.Node, out any, typ reflect.Type) error {
+	if value.Kind == yaml.AliasNode && value.Alias != nil {
+		value = value.Alias
+	}
+	if value.Kind != yaml.MappingNode {
+		return value.Decode(out) //nolint:wrapcheck
+	}
+
+	fields := yamlFields(typ)
+
+	var unknown []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i]
+		if _, ok := fields[key.Value]; !ok {
+			unknown = append(unknown, fmt.Sprintf("line %d: field %s not found in type %s",
+				key.Line, key.Value, typ.String()))
+		}
+	}
+	if len(unknown) > 0 {
+		return &yaml.TypeError{Errors: unknown}
+	}
+
+	node, _, err := yamlSecondsToDurations(value, typ)
+	if err != nil {
+		return err
+	}
+
+	return node.Decode(out) //nolint:wrapcheck
+}
+
+// yamlSecondsToDurations returns a copy of a YAML mapping node (for a struct of type typ),
+// where any bare integers given for [time.Duration] pointer fields are replaced with the
+// duration strings of as many seconds, and if any were replaced. Other nodes are returned
+// as they are, and unknown fields are left as they are (for the decoder to reject them).
+func yamlSecondsToDurations(value *yaml.Node, typ reflect.Type) (*yaml.Node, bool, error) {
+	if value.Kind != yaml.MappingNode {
+		return value, false, nil
+	}
+
+	fields := yamlFields(typ)
+
+	node := *value
+	node.Content = slices.Clone(value.Content)
+
+	var changed bool
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, val := node.Content[i], node.Content[i+1]
+
+		if fields[key.Value] != durationPtrType || val.Kind != yaml.ScalarNode || val.ShortTag() != "!!int" {
+			continue
+		}
+
+		secs, err := strconv.ParseInt(val.Value, 0, 64)
+		if err != nil || secs > math.MaxInt64/int64(time.Second) || secs < math.MinInt64/int64(time.Second) {
+			return nil, false, fmt.Errorf("line %d: %w: %s is not a valid amount of seconds", val.Line, errInvalidArgument, key.Value)
+		}
+
+		dur := *val
+		dur.Tag = "!!str"
+		dur.Value = (time.Duration(secs) * time.Second).String()
+		node.Content[i+1] = &dur
+		changed = true
+	}
+
+	return &node, changed, nil
+}
+
+// replaceYAMLScalars returns the YAML data with the values of a mapping node replaced in place
+// by the ones of its rewritten copy (see [yamlSecondsToDurations]), as double-quoted strings, so
+// that the line numbers stay the same. It returns false if any of the values to be replaced is
+// not found at its position in the data as it is (e.g. with an explicit tag).
+func replaceYAMLScalars(data []byte, orig *yaml.Node, rewritten *yaml.Node) ([]byte, bool) {
+	type replacement struct {
+		line, column int
+		old, new     string
+	}
+
+	var replacements []replacement
+	for i := 1; i < len(orig.Content) && i < len(rewritten.Content); i += 2 {
+		if val := orig.Content[i]; val != rewritten.Content[i] {
+			replacements = append(replacements, replacement{
+				line: val.Line, column: val.Column, old: val.Value, new: strconv.Quote(rewritten.Content[i].Value),
+			})
+		}
+	}
+
+	// Replaced from the end, so that the columns of the other values on the same line stay valid.
+	slices.SortFunc(replacements, func(a, b replacement) int {
+		if a.line != b.line {
+			return a.line - b.line
+		}
+
+		return b.column - a.column
+	})
+
+	lines := strings.Split(string(data), "\n")
+	for _, r := range replacements {
+		if r.line < 1 || r.line > len(lines) {
+			return nil, false
+		}
+
+		line := []rune(lines[r.line-1])
+		start, end := r.column-1, r.column-1+len([]rune(r.old))
+		if start < 0 || end > len(line) || string(line[start:end]) != r.old {
+			return nil, false
+		}
+
+		lines[r.line-1] = string(line[:start]) + r.new + string(line[end:])
+	}
+
+	return []byte(strings.Join(lines, "\n")), true
+}
+
+// yamlFields returns the types of the exported fields of a struct type by their YAML names.
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, typ.NumField())
+	for i := range typ.NumField() {
+		if !typ.Field(i).IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		fields[name] = typ.Field(i).Type
+	}
+
+	return fields
+}
+
+// mergeDeviceMonitorConfig merges a user-provided config with defaults.
+// Any nil fields in the user config will be replaced with values from the default config.
+func mergeDeviceMonitorConfig(userCfg *DeviceMonitorConfig) (*DeviceMonitorConfig, error) {
+	if userCfg == nil {
+		return DefaultDeviceMonitorConfig(), nil
+	}
+
+	merged := &DeviceMonitorConfig{}
+	defaultCfg := DefaultDeviceMonitorConfig()
+
+	if userCfg.PollInterval != nil {
+		if *userCfg.PollInterval < minPollInterval {
+			return nil, fmt.Errorf("%w: poll_interval must be >= %s", errInvalidArgument, minPollInterval)
+		}
+		merged.PollInterval = userCfg.PollInterval
+	} else {
+		merged.PollInterval = defaultCfg.PollInterval
+	}
+
+	if userCfg.PollJitter != nil {
+		if *userCfg.PollJitter < 0 {
+			return nil, fmt.Errorf("%w: poll_jitter must be >= 0", errInvalidArgument)
+		}
+		merged.PollJitter = userCfg.PollJitter
+	} else {
+		merged.PollJitter = defaultCfg.PollJitter
+	}
+
+	if userCfg.PollAttempts != nil {
+		if *userCfg.PollAttempts <= 0 {
+			return nil, fmt.Errorf("%w: poll_attempts must be > 0", errInvalidArgument)
+		}
+		merged.PollAttempts = userCfg.PollAttempts
+	} else {
+		merged.PollAttempts = defaultCfg.PollAttempts
+	}
+
+	if userCfg.PollAttemptTimeout != nil {
+		if *userCfg.PollAttemptTimeout < 0 {
+			return nil, fmt.Errorf("%w: poll_attempt_timeout must be >= 0", errInvalidArgument)
+		}
+		merged.PollAttemptTimeout = userCfg.PollAttemptTimeout
+	} else {
+		merged.PollAttemptTimeout = defaultCfg.PollAttemptTimeout
+	}
+
+	if userCfg.PollAttemptInterval != nil {
+		if *userCfg.PollAttemptInterval < 0 {
+			return nil, fmt.Errorf("%w: poll_attempt_interval must be >= 0", errInvalidArgument)
+		}
+		merged.PollAttemptInterval = userCfg.PollAttemptInterval
+	} else {
+		merged.PollAttemptInterval = defaultCfg.PollAttemptInterval
+	}
+
+	if userCfg.PollAttemptBackoff != nil {
+		if *userCfg.PollAttemptBackoff < 1 {
+			return nil, fmt.Errorf("%w: poll_attempt_backoff must be >= 1", errInvalidArgument)
+		}
+		merged.PollAttemptBackoff = userCfg.PollAttemptBackoff
+	} else {
+		merged.PollAttemptBackoff = defaultCfg.PollAttemptBackoff
+	}
+
+	if userCfg.PollAttemptBackoffMax != nil {
+		if *userCfg.PollAttemptBackoffMax < 0 {
+			return nil, fmt.Errorf("%w: poll_attempt_backoff_max must be >= 0", errInvalidArgument)
+		}
+		merged.PollAttemptBackoffMax = userCfg.PollAttemptBackoffMax
+	} else {
+		merged.PollAttemptBackoffMax = defaultCfg.PollAttemptBackoffMax
+	}
+
+	if userCfg.PollAttemptJitter != nil {
+		if *userCfg.PollAttemptJitter < 0 || *userCfg.PollAttemptJitter > 1 {
+			return nil, fmt.Errorf("%w: poll_attempt_jitter must be between 0 and 1", errInvalidArgument)
+		}
+		merged.PollAttemptJitter = userCfg.PollAttemptJitter
+	} else {
+		merged.PollAttemptJitter = defaultCfg.PollAttemptJitter
+	}
+
+	if userCfg.PollWaitDelay != nil {
+		if *userCfg.PollWaitDelay <= 0 {
+			return nil, fmt.Errorf("%w: poll_wait_delay must be > 0", errInvalidArgument)
+		}
+		merged.PollWaitDelay = userCfg.PollWaitDelay
+	} else {
+		merged.PollWaitDelay = defaultCfg.PollWaitDelay
+	}
+
+	if userCfg.PollBudgetStrict != nil {
+		merged.PollBudgetStrict = userCfg.PollBudgetStrict
+	} else {
+		merged.PollBudgetStrict = defaultCfg.PollBudgetStrict
+	}
+
+	if userCfg.PollBackoffAfter != nil {
+		merged.PollBackoffAfter = userCfg.PollBackoffAfter
+	} else {
+		merged.PollBackoffAfter = defaultCfg.PollBackoffAfter
+	}
+
+	if userCfg.PollBackoffTime != nil {
+		if *userCfg.PollBackoffTime < 0 {
+			return nil, fmt.Errorf("%w: poll_backoff_time must be >= 0", errInvalidArgument)
+		}
+		merged.PollBackoffTime = userCfg.PollBackoffTime
+	} else {
+		merged.PollBackoffTime = defaultCfg.PollBackoffTime
+	}
+
+	if userCfg.PollBackoffNotify != nil {
+		merged.PollBackoffNotify = userCfg.PollBackoffNotify
+	} else {
+		merged.PollBackoffNotify = defaultCfg.PollBackoffNotify
+	}
+
+	if userCfg.PollBackoffStopMonitor != nil {
+		merged.PollBackoffStopMonitor = userCfg.PollBackoffStopMonitor
+	} else {
+		merged.PollBackoffStopMonitor = defaultCfg.PollBackoffStopMonitor
+	}
+
+	if userCfg.BackoffResetBaseline != nil {
+		merged.BackoffResetBaseline = userCfg.BackoffResetBaseline
+	} else {
+		merged.BackoffResetBaseline = defaultCfg.BackoffResetBaseline
+	}
+
+	if userCfg.PollFailureRateAlert != nil {
+		if *userCfg.PollFailureRateAlert < 0 || *userCfg.PollFailureRateAlert > 1 {
+			return nil, fmt.Errorf("%w: poll_failure_rate_alert must be between 0 and 1", errInvalidArgument)
+		}
+		merged.PollFailureRateAlert = userCfg.PollFailureRateAlert
+	} else {
+		merged.PollFailureRateAlert = defaultCfg.PollFailureRateAlert
+	}
+
+	if userCfg.PollFailureRateWindow != nil {
+		if *userCfg.PollFailureRateWindow <= 0 {
+			return nil, fmt.Errorf("%w: poll_failure_rate_window must be > 0", errInvalidArgument)
+		}
+		merged.PollFailureRateWindow = userCfg.PollFailureRateWindow
+	} else {
+		merged.PollFailureRateWindow = defaultCfg.PollFailureRateWindow
+	}
+
+	if userCfg.OnStopCommand != nil {
+		if strings.TrimSpace(*userCfg.OnStopCommand) == "" {
+			return nil, fmt.Errorf("%w: on_stop_command must not be empty", errInvalidArgument)
+		}
+		merged.OnStopCommand = userCfg.OnStopCommand
+	} else {
+		merged.OnStopCommand = defaultCfg.OnStopCommand
+	}
+
+	if userCfg.OnStopTimeout != nil {
+		if *userCfg.OnStopTimeout <= 0 {
+			return nil, fmt.Errorf("%w: on_stop_timeout must be > 0", errInvalidArgument)
+		}
+		merged.OnStopTimeout = userCfg.OnStopTimeout
+	} else {
+		merged.OnStopTimeout = defaultCfg.OnStopTimeout
+	}
+
+	if userCfg.PrePollCommand != nil {
+		if strings.TrimSpace(*userCfg.PrePollCommand) == "" {
+			return nil, fmt.Errorf("%w: pre_poll_command must not be empty", errInvalidArgument)
+		}
+		merged.PrePollCommand = userCfg.PrePollCommand
+	} else {
+		merged.PrePollCommand = defaultCfg.PrePollCommand
+	}
+
+	if userCfg.PrePollTimeout != nil {
+		if *userCfg.PrePollTimeout <= 0 {
+			return nil, fmt.Errorf("%w: pre_poll_timeout must be > 0", errInvalidArgument)
+		}
+		merged.PrePollTimeout = userCfg.PrePollTimeout
+	} else {
+		merged.PrePollTimeout = defaultCfg.PrePollTimeout
+	}
+
+	if userCfg.PrePollAbort != nil {
+		merged.PrePollAbort = userCfg.PrePollAbort
+	} else {
+		merged.PrePollAbort = defaultCfg.PrePollAbort
+	}
+
+	if userCfg.SgSesPath != nil {
+		if strings.TrimSpace(*userCfg.SgSesPath) == "" {
+			return nil, fmt.Errorf("%w: sg_ses_path must not be empty", errInvalidArgument)
+		}
+		merged.SgSesPath = userCfg.SgSesPath
+	} else {
+		merged.SgSesPath = defaultCfg.SgSesPath
+	}
+
+	if userCfg.SgSesArgs != nil {
+		merged.SgSesArgs = userCfg.SgSesArgs
+	} else {
+		merged.SgSesArgs = defaultCfg.SgSesArgs
+	}
+
+	if userCfg.FetchCommand != nil {
+		if strings.TrimSpace(*userCfg.FetchCommand) == "" {
+			return nil, fmt.Errorf("%w: fetch_command must not be empty", errInvalidArgument)
+		}
+		merged.FetchCommand = userCfg.FetchCommand
+	} else {
+		merged.FetchCommand = defaultCfg.FetchCommand
+	}
+
+	if userCfg.FetchArgs != nil {
+		if _, err := renderFetchArgs(userCfg.FetchArgs, Device{Path: "/dev/sg0"}); err != nil {
+			return nil, err
+		}
+		merged.FetchArgs = userCfg.FetchArgs
+	} else {
+		merged.FetchArgs = defaultCfg.FetchArgs
+	}
+
+	if userCfg.ToleratePreamble != nil {
+		merged.ToleratePreamble = userCfg.ToleratePreamble
+	} else {
+		merged.ToleratePreamble = defaultCfg.ToleratePreamble
+	}
+
+	if userCfg.NotifyOnRecovery != nil {
+		merged.NotifyOnRecovery = userCfg.NotifyOnRecovery
+	} else {
+		merged.NotifyOnRecovery = defaultCfg.NotifyOnRecovery
+	}
+
+	if userCfg.NotifyMinSeverity != nil {
+		if !slices.Contains(severityOrder, *userCfg.NotifyMinSeverity) {
+			return nil, fmt.Errorf("%w: notify_min_severity must be one of %v", errInvalidArgument, severityOrder)
+		}
+		merged.NotifyMinSeverity = userCfg.NotifyMinSeverity
+	} else {
+		merged.NotifyMinSeverity = defaultCfg.NotifyMinSeverity
+	}
+
+	if userCfg.AlertOnStartUnhealthy != nil {
+		merged.AlertOnStartUnhealthy = userCfg.AlertOnStartUnhealthy
+	} else {
+		merged.AlertOnStartUnhealthy = defaultCfg.AlertOnStartUnhealthy
+	}
+
+	if userCfg.NotifyMinInterval != nil {
+		if *userCfg.NotifyMinInterval < 0 {
+			return nil, fmt.Errorf("%w: notify_min_interval must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyMinInterval = userCfg.NotifyMinInterval
+	} else {
+		merged.NotifyMinInterval = defaultCfg.NotifyMinInterval
+	}
+
+	if userCfg.AlertDedupTTL != nil {
+		if *userCfg.AlertDedupTTL < 0 {
+			return nil, fmt.Errorf("%w: alert_dedup_ttl must be >= 0", errInvalidArgument)
+		}
+		merged.AlertDedupTTL = userCfg.AlertDedupTTL
+	} else {
+		merged.AlertDedupTTL = defaultCfg.AlertDedupTTL
+	}
+
+	if userCfg.CompactMessages != nil {
+		merged.CompactMessages = userCfg.CompactMessages
+	} else {
+		merged.CompactMessages = defaultCfg.CompactMessages
+	}
+
+	if userCfg.HeartbeatInterval != nil {
+		if *userCfg.HeartbeatInterval < 0 {
+			return nil, fmt.Errorf("%w: heartbeat_interval must be >= 0", errInvalidArgument)
+		}
+		merged.HeartbeatInterval = userCfg.HeartbeatInterval
+	} else {
+		merged.HeartbeatInterval = defaultCfg.HeartbeatInterval
+	}
+
+	if userCfg.TempWarn != nil {
+		merged.TempWarn = userCfg.TempWarn
+	} else {
+		merged.TempWarn = defaultCfg.TempWarn
+	}
+
+	if userCfg.TempCrit != nil {
+		merged.TempCrit = userCfg.TempCrit
+	} else {
+		merged.TempCrit = defaultCfg.TempCrit
+	}
+
+	if merged.TempWarn != nil && merged.TempCrit != nil && *merged.TempWarn >= *merged.TempCrit {
+		return nil, fmt.Errorf("%w: temp_warn must be < temp_crit", errInvalidArgument)
+	}
+
+	if userCfg.TempHysteresis != nil {
+		if *userCfg.TempHysteresis < 0 {
+			return nil, fmt.Errorf("%w: temp_hysteresis must be >= 0", errInvalidArgument)
+		}
+		merged.TempHysteresis = userCfg.TempHysteresis
+	} else {
+		merged.TempHysteresis = defaultCfg.TempHysteresis
+	}
+
+	if userCfg.AlertFanSpeed != nil {
+		merged.AlertFanSpeed = userCfg.AlertFanSpeed
+	} else {
+		merged.AlertFanSpeed = defaultCfg.AlertFanSpeed
+	}
+
+	if userCfg.PrdFailCritical != nil {
+		merged.PrdFailCritical = userCfg.PrdFailCritical
+	} else {
+		merged.PrdFailCritical = defaultCfg.PrdFailCritical
+	}
+
+	if userCfg.MonitorTypes != nil {
+		if err := validateElementTypes("monitor_types", userCfg.MonitorTypes); err != nil {
+			return nil, err
+		}
+		merged.MonitorTypes = userCfg.MonitorTypes
+	} else {
+		merged.MonitorTypes = defaultCfg.MonitorTypes
+	}
+
+	if userCfg.SuppressTypes != nil {
+		if err := validateElementTypes("suppress_types", userCfg.SuppressTypes); err != nil {
+			return nil, err
+		}
+		merged.SuppressTypes = userCfg.SuppressTypes
+	} else {
+		merged.SuppressTypes = defaultCfg.SuppressTypes
+	}
+
+	if userCfg.ChangeDebounce != nil {
+		if *userCfg.ChangeDebounce <= 0 {
+			return nil, fmt.Errorf("%w: change_debounce must be > 0", errInvalidArgument)
+		}
+		merged.ChangeDebounce = userCfg.ChangeDebounce
+	} else {
+		merged.ChangeDebounce = defaultCfg.ChangeDebounce
+	}
+
+	if userCfg.TransientStatuses != nil {
+		for _, code := range userCfg.TransientStatuses {
+			if code < 0 || code > maxElementStatus {
+				return nil, fmt.Errorf("%w: transient_statuses must only contain status codes 0-%d (got %d)",
+					errInvalidArgument, maxElementStatus, code)
+			}
+		}
+		merged.TransientStatuses = userCfg.TransientStatuses
+	} else {
+		merged.TransientStatuses = defaultCfg.TransientStatuses
+	}
+
+	if userCfg.TransientDebounce != nil {
+		if *userCfg.TransientDebounce <= 0 {
+			return nil, fmt.Errorf("%w: transient_debounce must be > 0", errInvalidArgument)
+		}
+		merged.TransientDebounce = userCfg.TransientDebounce
+	} else {
+		merged.TransientDebounce = defaultCfg.TransientDebounce
+	}
+
+	if userCfg.ElementDropThreshold != nil {
+		if *userCfg.ElementDropThreshold < 0 || *userCfg.ElementDropThreshold > 1 {
+			return nil, fmt.Errorf("%w: element_drop_threshold must be between 0 and 1", errInvalidArgument)
+		}
+		merged.ElementDropThreshold = userCfg.ElementDropThreshold
+	} else {
+		merged.ElementDropThreshold = defaultCfg.ElementDropThreshold
+	}
+
+	if userCfg.CanonicalStatusMeanings != nil {
+		merged.CanonicalStatusMeanings = userCfg.CanonicalStatusMeanings
+	} else {
+		merged.CanonicalStatusMeanings = defaultCfg.CanonicalStatusMeanings
+	}
+
+	if userCfg.StatusMeanings != nil {
+		if err := validateStatusMeanings(userCfg.StatusMeanings); err != nil {
+			return nil, err
+		}
+		merged.StatusMeanings = userCfg.StatusMeanings
+	} else {
+		merged.StatusMeanings = defaultCfg.StatusMeanings
+	}
+
+	if userCfg.OutputDir != nil && *userCfg.OutputDir != "" {
+		merged.OutputDir = ptr(filepath.Clean(*userCfg.OutputDir))
+	} else {
+		merged.OutputDir = defaultCfg.OutputDir
+	}
+
+	if userCfg.OutputPrefix != nil {
+		if strings.ContainsAny(*userCfg.OutputPrefix, `/\`) {
+			return nil, fmt.Errorf("%w: output_prefix must not contain path separators", errInvalidArgument)
+		}
+		merged.OutputPrefix = userCfg.OutputPrefix
+	} else {
+		merged.OutputPrefix = defaultCfg.OutputPrefix
+	}
+
+	if userCfg.WriteSnapshots != nil {
+		merged.WriteSnapshots = userCfg.WriteSnapshots
+	} else {
+		merged.WriteSnapshots = defaultCfg.WriteSnapshots
+	}
+
+	if userCfg.WriteChangeReports != nil {
+		merged.WriteChangeReports = userCfg.WriteChangeReports
+	} else {
+		merged.WriteChangeReports = defaultCfg.WriteChangeReports
+	}
+
+	if userCfg.ReportResults != nil {
+		merged.ReportResults = userCfg.ReportResults
+	} else {
+		merged.ReportResults = defaultCfg.ReportResults
+	}
+
+	if userCfg.OutputMaxReports != nil {
+		if *userCfg.OutputMaxReports < 0 {
+			return nil, fmt.Errorf("%w: output_max_reports must be >= 0", errInvalidArgument)
+		}
+		merged.OutputMaxReports = userCfg.OutputMaxReports
+	} else {
+		merged.OutputMaxReports = defaultCfg.OutputMaxReports
+	}
+
+	if userCfg.OutputMaxAge != nil {
+		if *userCfg.OutputMaxAge < 0 {
+			return nil, fmt.Errorf("%w: output_max_age must be >= 0", errInvalidArgument)
+		}
+		merged.OutputMaxAge = userCfg.OutputMaxAge
+	} else {
+		merged.OutputMaxAge = defaultCfg.OutputMaxAge
+	}
+
+	if userCfg.OutputChangelog != nil {
+		merged.OutputChangelog = userCfg.OutputChangelog
+	} else {
+		merged.OutputChangelog = defaultCfg.OutputChangelog
+	}
+
+	if userCfg.OutputCompress != nil {
+		merged.OutputCompress = userCfg.OutputCompress
+	} else {
+		merged.OutputCompress = defaultCfg.OutputCompress
+	}
+
+	if userCfg.OutputFileMode != nil {
+		if _, err := parseFileMode("output_file_mode", *userCfg.OutputFileMode); err != nil {
+			return nil, err
+		}
+		merged.OutputFileMode = userCfg.OutputFileMode
+	} else {
+		merged.OutputFileMode = defaultCfg.OutputFileMode
+	}
+
+	if userCfg.OutputDirMode != nil {
+		if _, err := parseFileMode("output_dir_mode", *userCfg.OutputDirMode); err != nil {
+			return nil, err
+		}
+		merged.OutputDirMode = userCfg.OutputDirMode
+	} else {
+		merged.OutputDirMode = defaultCfg.OutputDirMode
+	}
+
+	if userCfg.PersistAcks != nil {
+		merged.PersistAcks = userCfg.PersistAcks
+	} else {
+		merged.PersistAcks = defaultCfg.PersistAcks
+	}
+
+	if userCfg.SnapshotHistory != nil {
+		if *userCfg.SnapshotHistory < 0 {
+			return nil, fmt.Errorf("%w: snapshot_history must be >= 0", errInvalidArgument)
+		}
+		merged.SnapshotHistory = userCfg.SnapshotHistory
+	} else {
+		merged.SnapshotHistory = defaultCfg.SnapshotHistory
+	}
+
+	if userCfg.SnapshotEveryPoll != nil {
+		merged.SnapshotEveryPoll = userCfg.SnapshotEveryPoll
+	} else {
+		merged.SnapshotEveryPoll = defaultCfg.SnapshotEveryPoll
+	}
+
+	if userCfg.ElementHistory != nil {
+		if *userCfg.ElementHistory < 0 {
+			return nil, fmt.Errorf("%w: element_history must be >= 0", errInvalidArgument)
+		}
+		merged.ElementHistory = userCfg.ElementHistory
+	} else {
+		merged.ElementHistory = defaultCfg.ElementHistory
+	}
+
+	if userCfg.LogChangeEvents != nil {
+		merged.LogChangeEvents = userCfg.LogChangeEvents
+	} else {
+		merged.LogChangeEvents = defaultCfg.LogChangeEvents
+	}
+
+	if userCfg.Verbose != nil {
+		merged.Verbose = userCfg.Verbose
+	} else {
+		merged.Verbose = defaultCfg.Verbose
+	}
+
+	if len(merged.FetchArgs) > 0 && merged.FetchCommand == nil {
+		return nil, fmt.Errorf("%w: fetch_args requires a fetch_command", errInvalidArgument)
+	}
+
+	return merged, nil
+}
+
+// mergeScriptNotifierConfig merges a user-provided config with defaults.
+// Any nil fields in the user config will be replaced with values from the default config.
+func mergeScriptNotifierConfig(userCfg *ScriptNotifierConfig) (*ScriptNotifierConfig, error) {
+	if userCfg == nil {
+		return DefaultScriptNotifierConfig(), nil
+	}
+
+	merged := &ScriptNotifierConfig{}
+	defaultCfg := DefaultScriptNotifierConfig()
+
+	if userCfg.NotifyAttempts != nil {
+		if *userCfg.NotifyAttempts <= 0 {
+			return nil, fmt.Errorf("%w: notify_attempts must be > 0", errInvalidArgument)
+		}
+		merged.NotifyAttempts = userCfg.NotifyAttempts
+	} else {
+		merged.NotifyAttempts = defaultCfg.NotifyAttempts
+	}
+
+	if userCfg.NotifyAttemptTimeout != nil {
+		if *userCfg.NotifyAttemptTimeout < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_timeout must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptTimeout = userCfg.NotifyAttemptTimeout
+	} else {
+		merged.NotifyAttemptTimeout = defaultCfg.NotifyAttemptTimeout
+	}
+
+	if userCfg.NotifyAttemptInterval != nil {
+		if *userCfg.NotifyAttemptInterval < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_interval must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptInterval = userCfg.NotifyAttemptInterval
+	} else {
+		merged.NotifyAttemptInterval = defaultCfg.NotifyAttemptInterval
+	}
+
+	if userCfg.StdinPayload != nil {
+		merged.StdinPayload = userCfg.StdinPayload
+	} else {
+		merged.StdinPayload = defaultCfg.StdinPayload
+	}
+
+	if userCfg.ExportEnv != nil {
+		merged.ExportEnv = userCfg.ExportEnv
+	} else {
+		merged.ExportEnv = defaultCfg.ExportEnv
+	}
+
+	if userCfg.MessageTemplate != nil {
+		merged.MessageTemplate = userCfg.MessageTemplate
+	} else {
+		merged.MessageTemplate = defaultCfg.MessageTemplate
+	}
+
+	return merged, nil
+}
+
+// mergeWebhookNotifierConfig merges a user-provided config with defaults.
+// Any nil fields in the user config will be replaced with values from the default config.
+func mergeWebhookNotifierConfig(userCfg *WebhookNotifierConfig) (*WebhookNotifierConfig, error) {
+	if userCfg == nil {
+		return DefaultWebhookNotifierConfig(), nil
+	}
+
+	merged := &WebhookNotifierConfig{}
+	defaultCfg := DefaultWebhookNotifierConfig()
+
+	if userCfg.NotifyAttempts != nil {
+		if *userCfg.NotifyAttempts <= 0 {
+			return nil, fmt.Errorf("%w: notify_attempts must be > 0", errInvalidArgument)
+		}
+		merged.NotifyAttempts = userCfg.NotifyAttempts
+	} else {
+		merged.NotifyAttempts = defaultCfg.NotifyAttempts
+	}
+
+	if userCfg.NotifyAttemptTimeout != nil {
+		if *userCfg.NotifyAttemptTimeout < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_timeout must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptTimeout = userCfg.NotifyAttemptTimeout
+	} else {
+		merged.NotifyAttemptTimeout = defaultCfg.NotifyAttemptTimeout
+	}
+
+	if userCfg.NotifyAttemptInterval != nil {
+		if *userCfg.NotifyAttemptInterval < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_interval must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptInterval = userCfg.NotifyAttemptInterval
+	} else {
+		merged.NotifyAttemptInterval = defaultCfg.NotifyAttemptInterval
+	}
+
+	if userCfg.TLSSkipVerify != nil {
+		merged.TLSSkipVerify = userCfg.TLSSkipVerify
+	} else {
+		merged.TLSSkipVerify = defaultCfg.TLSSkipVerify
+	}
+
+	if (fmtPtrStr(userCfg.TLSClientCert, "") == "") != (fmtPtrStr(userCfg.TLSClientKey, "") == "") {
+		return nil, fmt.Errorf("%w: tls_client_cert and tls_client_key must be set together", errInvalidArgument)
+	}
+	if userCfg.TLSClientCert != nil {
+		merged.TLSClientCert = userCfg.TLSClientCert
+	} else {
+		merged.TLSClientCert = defaultCfg.TLSClientCert
+	}
+	if userCfg.TLSClientKey != nil {
+		merged.TLSClientKey = userCfg.TLSClientKey
+	} else {
+		merged.TLSClientKey = defaultCfg.TLSClientKey
+	}
+
+	if userCfg.TLSCACert != nil {
+		merged.TLSCACert = userCfg.TLSCACert
+	} else {
+		merged.TLSCACert = defaultCfg.TLSCACert
+	}
+
+	if userCfg.MessageTemplate != nil {
+		merged.MessageTemplate = userCfg.MessageTemplate
+	} else {
+		merged.MessageTemplate = defaultCfg.MessageTemplate
+	}
+
+	return merged, nil
+}
+
+// mergeMQTTNotifierConfig merges a user-provided config with defaults.
+// Any nil fields in the user config will be replaced with values from the default config.
+func mergeMQTTNotifierConfig(userCfg *MQTTNotifierConfig) (*MQTTNotifierConfig, error) {
+	if userCfg == nil {
+		return DefaultMQTTNotifierConfig(), nil
+	}
+
+	merged := &MQTTNotifierConfig{}
+	defaultCfg := DefaultMQTTNotifierConfig()
+
+	if userCfg.NotifyAttempts != nil {
+		if *userCfg.NotifyAttempts <= 0 {
+			return nil, fmt.Errorf("%w: notify_attempts must be > 0", errInvalidArgument)
+		}
+		merged.NotifyAttempts = userCfg.NotifyAttempts
+	} else {
+		merged.NotifyAttempts = defaultCfg.NotifyAttempts
+	}
+
+	if userCfg.NotifyAttemptTimeout != nil {
+		if *userCfg.NotifyAttemptTimeout <= 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_timeout must be > 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptTimeout = userCfg.NotifyAttemptTimeout
+	} else {
+		merged.NotifyAttemptTimeout = defaultCfg.NotifyAttemptTimeout
+	}
+
+	if userCfg.NotifyAttemptInterval != nil {
+		if *userCfg.NotifyAttemptInterval < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_interval must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptInterval = userCfg.NotifyAttemptInterval
+	} else {
+		merged.NotifyAttemptInterval = defaultCfg.NotifyAttemptInterval
+	}
+
+	if userCfg.QoS != nil {
+		if *userCfg.QoS < 0 || *userCfg.QoS > 2 {
+			return nil, fmt.Errorf("%w: qos must be 0, 1 or 2", errInvalidArgument)
+		}
+		merged.QoS = userCfg.QoS
+	} else {
+		merged.QoS = defaultCfg.QoS
+	}
+
+	if userCfg.Retain != nil {
+		merged.Retain = userCfg.Retain
+	} else {
+		merged.Retain = defaultCfg.Retain
+	}
+
+	if userCfg.TLSSkipVerify != nil {
+		merged.TLSSkipVerify = userCfg.TLSSkipVerify
+	} else {
+		merged.TLSSkipVerify = defaultCfg.TLSSkipVerify
+	}
+
+	return merged, nil
+}
+
+// mergePushNotifierConfig merges a user-provided config with defaults.
+// Any nil fields in the user config will be replaced with values from the default config.
+func mergePushNotifierConfig(userCfg *PushNotifierConfig) (*PushNotifierConfig, error) {
+	if userCfg == nil {
+		return DefaultPushNotifierConfig(), nil
+	}
+
+	merged := &PushNotifierConfig{}
+	defaultCfg := DefaultPushNotifierConfig()
+
+	if userCfg.NotifyAttempts != nil {
+		if *userCfg.NotifyAttempts <= 0 {
+			return nil, fmt.Errorf("%w: notify_attempts must be > 0", errInvalidArgument)
+		}
+		merged.NotifyAttempts = userCfg.NotifyAttempts
+	} else {
+		merged.NotifyAttempts = defaultCfg.NotifyAttempts
+	}
+
+	if userCfg.NotifyAttemptTimeout != nil {
+		if *userCfg.NotifyAttemptTimeout < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_timeout must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptTimeout = userCfg.NotifyAttemptTimeout
+	} else {
+		merged.NotifyAttemptTimeout = defaultCfg.NotifyAttemptTimeout
+	}
+
+	if userCfg.NotifyAttemptInterval != nil {
+		if *userCfg.NotifyAttemptInterval < 0 {
+			return nil, fmt.Errorf("%w: notify_attempt_interval must be >= 0", errInvalidArgument)
+		}
+		merged.NotifyAttemptInterval = userCfg.NotifyAttemptInterval
+	} else {
+		merged.NotifyAttemptInterval = defaultCfg.NotifyAttemptInterval
+	}
+
+	if userCfg.PriorityCritical != nil {
+		merged.PriorityCritical = userCfg.PriorityCritical
+	} else {
+		merged.PriorityCritical = defaultCfg.PriorityCritical
+	}
+
+	if userCfg.PriorityWarning != nil {
+		merged.PriorityWarning = userCfg.PriorityWarning
+	} else {
+		merged.PriorityWarning = defaultCfg.PriorityWarning
+	}
+
+	if userCfg.PriorityRecovery != nil {
+		merged.PriorityRecovery = userCfg.PriorityRecovery
+	} else {
+		merged.PriorityRecovery = defaultCfg.PriorityRecovery
+	}
+
+	if userCfg.TLSSkipVerify != nil {
+		merged.TLSSkipVerify = userCfg.TLSSkipVerify
+	} else {
+		merged.TLSSkipVerify = defaultCfg.TLSSkipVerify
+	}
+
+	return merged, nil
+}
+
+// mergeFileNotifierConfig merges a user-provided config with defaults.
+// Any nil fields in the user config will be replaced with values from the default config.
+func mergeFileNotifierConfig(userCfg *FileNotifierConfig) (*FileNotifierConfig, error) {
+	if userCfg == nil {
+		return DefaultFileNotifierConfig(), nil
+	}
+
+	merged := &FileNotifierConfig{}
+	defaultCfg := DefaultFileNotifierConfig()
+
+	if userCfg.MaxSize != nil {
+		if *userCfg.MaxSize < 0 {
+			return nil, fmt.Errorf("%w: max_size must be >= 0", errInvalidArgument)
+		}
+		merged.MaxSize = userCfg.MaxSize
+	} else {
+		merged.MaxSize = defaultCfg.MaxSize
+	}
+
+	if userCfg.Backups != nil {
+		if *userCfg.Backups < 0 {
+			return nil, fmt.Errorf("%w: backups must be >= 0", errInvalidArgument)
+		}
+		merged.Backups = userCfg.Backups
+	} else {
+		merged.Backups = defaultCfg.Backups
+	}
+
+	if userCfg.MessageTemplate != nil {
+		merged.MessageTemplate = userCfg.MessageTemplate
+	} else {
+		merged.MessageTemplate = defaultCfg.MessageTemplate
+	}
+
+	return merged, nil
+}
+
+// RetryBackoff configures the growth of the waits between the attempts of a retried
+// operation, where the zero value keeps the waits at their fixed interval.
+type RetryBackoff struct {
+	// Multiplier of the wait after every failed attempt (<= 1 = fixed interval).
+	Multiplier float64
+
+	// Upper bound of the grown wait, before any jitter is applied (0 = unbounded).
+	Max time.Duration
+
+	// Fraction of the wait that is randomly added to or subtracted from it (0 = none).
+	Jitter float64
+
+	// Returns a random number in [0, 1) for the jitter (nil = [rand.Float64]).
+	rand func() float64
+}
+
+// wait returns the wait after a (1-based) failed attempt of a retried operation.
+func (b RetryBackoff) wait(interval time.Duration, attempt int) time.Duration {
+	wait := b.grown(interval, attempt)
+
+	if b.Jitter > 0 {
+		random := b.rand
+		if random == nil {
+			random = rand.Float64
+		}
+		wait = time.Duration(float64(wait) * (1 + b.Jitter*(2*random()-1)))
+	}
+
+	return max(wait, 0)
+}
+
+// maxWait returns the longest possible wait after a (1-based) failed attempt.
+func (b RetryBackoff) maxWait(interval time.Duration, attempt int) time.Duration {
+	return time.Duration(float64(b.grown(interval, attempt)) * (1 + max(b.Jitter, 0)))
+}
+
+// grown returns the wait after a (1-based) failed attempt before any jitter is applied.
+func (b RetryBackoff) grown(interval time.Duration, attempt int) time.Duration {
+	if b.Multiplier <= 1 {
+		return interval
+	}
+
+	wait := float64(interval) * math.Pow(b.Multiplier, float64(attempt-1))
+	if b.Max > 0 && wait > float64(b.Max) {
+		return max(b.Max, interval)
+	}
+
+	return time.Duration(wait)
+}
+
+// withRetries executes a fn() with retries and a onAttemptErr() callback.
+func withRetries(ctx context.Context, fn func() error, onAttemptErr func(attempt int, err error), attempts int, interval time.Duration) (int, error) {
+	return withBackoffRetries(ctx, fn, onAttemptErr, attempts, interval, RetryBackoff{})
+}
+
+// withBackoffRetries executes a fn() with retries and a onAttemptErr() callback,
+// where the waits between the attempts grow from the interval per [RetryBackoff].
+func withBackoffRetries(ctx context.Context, fn func() error, onAttemptErr func(attempt int, err error),
+	attempts int, interval time.Duration, backoff RetryBackoff,
+) (int, error) {
+	var e error
+	var attempt int
+
+	if fn == nil {
+		return attempt, fmt.Errorf("%w: function cannot be nil", errInvalidArgument)
+	}
+
+	for range attempts {
+		if err := ctx.Err(); err != nil {
+			return attempt, fmt.Errorf("context error: %w", err)
+		}
+
+		e = fn()
+		attempt++
+
+		if e == nil {
+			return attempt, nil
+		}
+
+		if onAttemptErr != nil {
+			onAttemptErr(attempt, e)
+		}
+
+		if attempt < attempts {
+			select {
+			case <-ctx.Done():
+				return attempt, fmt.Errorf("context error: %w", ctx.Err())
+			case <-time.After(backoff.wait(interval, attempt)):
+			}
+		}
+	}
+
+	return attempt, e
+}
+
+// isDone returns if a [DeviceMonitor] is done (without blocking).
+func isDone(d *DeviceMonitor) bool {
+	select {
+	case <-d.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// RecoverGoPanic recovers a panic and logs to [slog.Logger] or [os.Stderr] (if nil).
+// It needs to be deferred directly by the goroutine (e.g. defer RecoverGoPanic("signals", nil)).
+func RecoverGoPanic(desc string, logger *slog.Logger) {
+	r := recover()
+	if r != nil {
+		buf := debug.Stack()
+		if logger != nil {
+			logger.Error(fmt.Sprintf("(%s) panic recovered: %v: %s", desc, r, buf), LogEvent("panic"))
+		} else {
+			fmt.Fprintf(os.Stderr, "(%s) panic recovered: %v: %s\n", desc, r, buf)
+		}
+	}
+}
+
+// validateElementTypes returns an error if any of the given SES element types is out of range.
+func validateElementTypes(name string, types []int) error {
+	for _, t := range types {
+		if t < 0 || t > maxElementType {
+			return fmt.Errorf("%w: %s must only contain element types 0-%d (got %d)",
+				errInvalidArgument, name, maxElementType, t)
+		}
+	}
+
+	return nil
+}
+
+// validateStatusMeanings returns an error if any of the given status codes is out of range
+// or any of the given status descriptions is empty.
+func validateStatusMeanings(meanings map[int]string) error {
+	for code, meaning := range meanings {
+		if code < 0 || code > maxElementStatus {
+			return fmt.Errorf("%w: status_meanings must only contain status codes 0-%d (got %d)",
+				errInvalidArgument, maxElementStatus, code)
+		}
+		if strings.TrimSpace(meaning) == "" {
+			return fmt.Errorf("%w: status_meanings must not contain empty descriptions (for %d)",
+				errInvalidArgument, code)
+		}
+	}
+
+	return nil
+}
+
+// isGlobPattern returns if a path contains any of the special characters of a glob pattern.
+func isGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// newestGlobMatch returns the most recently modified file matching a glob pattern,
+// where files of the same modification time are ordered by their (later) name.
+func newestGlobMatch(fsys afero.Fs, pattern string) (string, error) {
+	matches, err := afero.Glob(fsys, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failure globbing %q: %w", pattern, err)
+	}
+
+	var newest string
+	var newestMod time.Time
+	for _, match := range matches {
+		fi, err := fsys.Stat(match)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if newest == "" || fi.ModTime().After(newestMod) || (fi.ModTime().Equal(newestMod) && match > newest) {
+			newest, newestMod = match, fi.ModTime()
+		}
+	}
+
+	if newest == "" {
+		return "", fmt.Errorf("%q: %w", pattern, errNoGlobMatch)
+	}
+
+	return newest, nil
+}
+
+// parseFileMode parses permissions in octal notation (e.g. "0640", "640" or "0o640"),
+// returning an error if they are not valid octal permissions (within 0-777).
+func parseFileMode(name string, s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(s), "0o"), 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("%w: %s must be octal permissions within 0-777 (got %q)", errInvalidArgument, name, s)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// overlayConfig returns a copy of the base configuration with all set (non-nil) settings
+// of the override configuration applied on top of it. It expects configuration structs
+// consisting of only pointer (or slice) fields, such as [DeviceMonitorConfig].
+func overlayConfig[T any](base, override *T) *T {
+	merged := new(T)
+	if base != nil {
+		*merged = *base
+	}
+	if override == nil {
+		return merged
+	}
+
+	mv, ov := reflect.ValueOf(merged).Elem(), reflect.ValueOf(override).Elem()
+	for i := range ov.NumField() {
+		if f := ov.Field(i); !f.IsNil() {
+			mv.Field(i).Set(f)
+		}
+	}
+
+	return merged
+}
+
+// envVarPattern matches references to environment variables (e.g. "${SESMON_TOKEN}"),
+// including those escaped with an additional "$" (e.g. "$${SESMON_TOKEN}") to keep them.
+var envVarPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvYAML expands references to environment variables (e.g. "${SESMON_TOKEN}")
+// within the values of a YAML document, returning an error for any unset variable.
+// Comments are not expanded and the YAML document is only re-encoded if needed.
+func expandEnvYAML(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failure parsing YAML: %w", err)
+	}
+
+	var expanded bool
+	var expandErr error
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode && strings.Contains(n.Value, "${") {
+			value := envVarPattern.ReplaceAllStringFunc(n.Value, func(ref string) string {
+				if strings.HasPrefix(ref, "$$") {
+					return ref[1:]
+				}
+				name := ref[2 : len(ref)-1]
+				v, ok := lookup(name)
+				if !ok && expandErr == nil {
+					expandErr = fmt.Errorf("%w: [%s] (line %d)", errEnvNotSet, name, n.Line)
+				}
+
+				return v
+			})
+			if value != n.Value {
+				n.Value = value
+				if n.Style == 0 {
+					n.Tag = "" // re-resolve plain values (e.g. to numbers)
+				}
+				expanded = true
+			}
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(&root)
+
+	if expandErr != nil {
+		return nil, expandErr
+	}
+	if !expanded {
+		return data, nil
+	}
+
+	out, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failure encoding YAML: %w", err)
+	}
+
+	return out, nil
+}
+
+// unsafeDirNameChars matches all characters that are not safe to use within a folder name.
+var unsafeDirNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeDirName returns a filesystem-safe folder name for a device path or address,
+// with the "/dev/" prefix removed (e.g. "/dev/sg0" becomes "sg0") and all other unsafe
+// characters replaced (e.g. "/dev/disk/by-id/x" becomes "disk_by-id_x").
+func sanitizeDirName(name string) string {
+	name = strings.TrimPrefix(name, "/dev/")
+	name = strings.Trim(unsafeDirNameChars.ReplaceAllString(name, "_"), "_")
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+
+	return name
+}
@@ -1,15 +1,17 @@
-package main
+package sesmon
 
 import (
 	"bytes"
 	"context"
 	"errors"
-	"log"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 // Expectation: fne should return first non-empty string.
@@ -493,14 +495,68 @@ func Test_mergeDeviceMonitorConfig_Defaults_Success(t *testing.T) {
 
 			require.NotNil(t, result)
 			require.Equal(t, defaultCfg.PollInterval, result.PollInterval)
+			require.Equal(t, defaultCfg.PollJitter, result.PollJitter)
 			require.Equal(t, defaultCfg.PollAttempts, result.PollAttempts)
 			require.Equal(t, defaultCfg.PollAttemptTimeout, result.PollAttemptTimeout)
 			require.Equal(t, defaultCfg.PollAttemptInterval, result.PollAttemptInterval)
+			require.Equal(t, defaultCfg.PollAttemptBackoff, result.PollAttemptBackoff)
+			require.Equal(t, defaultCfg.PollAttemptBackoffMax, result.PollAttemptBackoffMax)
+			require.Equal(t, defaultCfg.PollAttemptJitter, result.PollAttemptJitter)
+			require.Equal(t, defaultCfg.PollWaitDelay, result.PollWaitDelay)
+			require.Equal(t, defaultCfg.PollBudgetStrict, result.PollBudgetStrict)
 			require.Equal(t, defaultCfg.PollBackoffAfter, result.PollBackoffAfter)
 			require.Equal(t, defaultCfg.PollBackoffTime, result.PollBackoffTime)
 			require.Equal(t, defaultCfg.PollBackoffNotify, result.PollBackoffNotify)
 			require.Equal(t, defaultCfg.PollBackoffStopMonitor, result.PollBackoffStopMonitor)
+			require.Equal(t, defaultCfg.BackoffResetBaseline, result.BackoffResetBaseline)
+			require.Equal(t, defaultCfg.PollFailureRateAlert, result.PollFailureRateAlert)
+			require.Equal(t, defaultCfg.PollFailureRateWindow, result.PollFailureRateWindow)
+			require.Equal(t, defaultCfg.OnStopCommand, result.OnStopCommand)
+			require.Equal(t, defaultCfg.OnStopTimeout, result.OnStopTimeout)
+			require.Equal(t, defaultCfg.PrePollCommand, result.PrePollCommand)
+			require.Equal(t, defaultCfg.PrePollTimeout, result.PrePollTimeout)
+			require.Equal(t, defaultCfg.PrePollAbort, result.PrePollAbort)
+			require.Equal(t, defaultCfg.SgSesPath, result.SgSesPath)
+			require.Equal(t, defaultCfg.SgSesArgs, result.SgSesArgs)
+			require.Equal(t, defaultCfg.FetchCommand, result.FetchCommand)
+			require.Equal(t, defaultCfg.FetchArgs, result.FetchArgs)
+			require.Equal(t, defaultCfg.ToleratePreamble, result.ToleratePreamble)
+			require.Equal(t, defaultCfg.NotifyOnRecovery, result.NotifyOnRecovery)
+			require.Equal(t, defaultCfg.NotifyMinSeverity, result.NotifyMinSeverity)
+			require.Equal(t, defaultCfg.AlertOnStartUnhealthy, result.AlertOnStartUnhealthy)
+			require.Equal(t, defaultCfg.NotifyMinInterval, result.NotifyMinInterval)
+			require.Equal(t, defaultCfg.AlertDedupTTL, result.AlertDedupTTL)
+			require.Equal(t, defaultCfg.CompactMessages, result.CompactMessages)
+			require.Equal(t, defaultCfg.HeartbeatInterval, result.HeartbeatInterval)
+			require.Equal(t, defaultCfg.TempWarn, result.TempWarn)
+			require.Equal(t, defaultCfg.TempCrit, result.TempCrit)
+			require.Equal(t, defaultCfg.TempHysteresis, result.TempHysteresis)
+			require.Equal(t, defaultCfg.AlertFanSpeed, result.AlertFanSpeed)
+			require.Equal(t, defaultCfg.PrdFailCritical, result.PrdFailCritical)
+			require.Equal(t, defaultCfg.MonitorTypes, result.MonitorTypes)
+			require.Equal(t, defaultCfg.SuppressTypes, result.SuppressTypes)
+			require.Equal(t, defaultCfg.ChangeDebounce, result.ChangeDebounce)
+			require.Equal(t, defaultCfg.TransientStatuses, result.TransientStatuses)
+			require.Equal(t, defaultCfg.TransientDebounce, result.TransientDebounce)
+			require.Equal(t, defaultCfg.ElementDropThreshold, result.ElementDropThreshold)
+			require.Equal(t, defaultCfg.CanonicalStatusMeanings, result.CanonicalStatusMeanings)
+			require.Equal(t, defaultCfg.StatusMeanings, result.StatusMeanings)
 			require.Equal(t, defaultCfg.OutputDir, result.OutputDir)
+			require.Equal(t, defaultCfg.OutputPrefix, result.OutputPrefix)
+			require.Equal(t, defaultCfg.WriteSnapshots, result.WriteSnapshots)
+			require.Equal(t, defaultCfg.WriteChangeReports, result.WriteChangeReports)
+			require.Equal(t, defaultCfg.ReportResults, result.ReportResults)
+			require.Equal(t, defaultCfg.OutputMaxReports, result.OutputMaxReports)
+			require.Equal(t, defaultCfg.OutputMaxAge, result.OutputMaxAge)
+			require.Equal(t, defaultCfg.OutputChangelog, result.OutputChangelog)
+			require.Equal(t, defaultCfg.OutputCompress, result.OutputCompress)
+			require.Equal(t, defaultCfg.OutputFileMode, result.OutputFileMode)
+			require.Equal(t, defaultCfg.OutputDirMode, result.OutputDirMode)
+			require.Equal(t, defaultCfg.PersistAcks, result.PersistAcks)
+			require.Equal(t, defaultCfg.SnapshotHistory, result.SnapshotHistory)
+			require.Equal(t, defaultCfg.SnapshotEveryPoll, result.SnapshotEveryPoll)
+			require.Equal(t, defaultCfg.ElementHistory, result.ElementHistory)
+			require.Equal(t, defaultCfg.LogChangeEvents, result.LogChangeEvents)
 			require.Equal(t, defaultCfg.Verbose, result.Verbose)
 		})
 	}
@@ -518,28 +574,136 @@ func Test_mergeDeviceMonitorConfig_UserConfig_Success(t *testing.T) {
 		{
 			name: "all fields provided by user",
 			userCfg: &DeviceMonitorConfig{
-				PollInterval:           ptr(10 * time.Second),
-				PollAttempts:           ptr(5),
-				PollAttemptTimeout:     ptr(30 * time.Second),
-				PollAttemptInterval:    ptr(2 * time.Second),
-				PollBackoffAfter:       ptr(3),
-				PollBackoffTime:        ptr(15 * time.Second),
-				PollBackoffNotify:      ptr(false),
-				PollBackoffStopMonitor: ptr(true),
-				OutputDir:              ptr("/custom/path"),
-				Verbose:                ptr(true),
+				PollInterval:            ptr(10 * time.Second),
+				PollJitter:              ptr(10 * time.Second),
+				PollAttempts:            ptr(5),
+				PollAttemptTimeout:      ptr(30 * time.Second),
+				PollAttemptInterval:     ptr(2 * time.Second),
+				PollAttemptBackoff:      ptr(2.0),
+				PollAttemptBackoffMax:   ptr(time.Minute),
+				PollAttemptJitter:       ptr(0.2),
+				PollWaitDelay:           ptr(10 * time.Second),
+				PollBudgetStrict:        ptr(true),
+				PollBackoffAfter:        ptr(3),
+				PollBackoffTime:         ptr(15 * time.Second),
+				PollBackoffNotify:       ptr(false),
+				PollBackoffStopMonitor:  ptr(true),
+				BackoffResetBaseline:    ptr(true),
+				PollFailureRateAlert:    ptr(0.5),
+				PollFailureRateWindow:   ptr(6),
+				OnStopCommand:           ptr("/usr/local/bin/on-stop"),
+				OnStopTimeout:           ptr(5 * time.Second),
+				PrePollCommand:          ptr("/usr/local/bin/pre-poll"),
+				PrePollTimeout:          ptr(5 * time.Second),
+				PrePollAbort:            ptr(true),
+				SgSesPath:               ptr("/usr/local/sbin/sg_ses"),
+				SgSesArgs:               []string{"--all", "--json", "--maxlen=1024"},
+				FetchCommand:            ptr("/usr/local/bin/vendor-ses"),
+				FetchArgs:               []string{"--json", "{{.Path}}"},
+				ToleratePreamble:        ptr(true),
+				NotifyOnRecovery:        ptr(false),
+				NotifyMinSeverity:       ptr(SeverityCritical),
+				AlertOnStartUnhealthy:   ptr(true),
+				NotifyMinInterval:       ptr(10 * time.Minute),
+				AlertDedupTTL:           ptr(time.Hour),
+				CompactMessages:         ptr(true),
+				HeartbeatInterval:       ptr(24 * time.Hour),
+				TempWarn:                ptr(45),
+				TempCrit:                ptr(55),
+				TempHysteresis:          ptr(3),
+				AlertFanSpeed:           ptr(true),
+				PrdFailCritical:         ptr(true),
+				MonitorTypes:            []int{2, 23},
+				SuppressTypes:           []int{16, 23},
+				ChangeDebounce:          ptr(2),
+				TransientStatuses:       []int{5},
+				TransientDebounce:       ptr(5),
+				ElementDropThreshold:    ptr(0.5),
+				CanonicalStatusMeanings: ptr(true),
+				StatusMeanings:          map[int]string{3: "Non-critical"},
+				OutputDir:               ptr("/custom/path"),
+				OutputPrefix:            ptr("sesmon-"),
+				WriteSnapshots:          ptr(false),
+				WriteChangeReports:      ptr(false),
+				ReportResults:           ptr(true),
+				OutputMaxReports:        ptr(100),
+				OutputMaxAge:            ptr(720 * time.Hour),
+				OutputChangelog:         ptr(true),
+				OutputCompress:          ptr(true),
+				OutputFileMode:          ptr("0640"),
+				OutputDirMode:           ptr("0750"),
+				PersistAcks:             ptr(true),
+				SnapshotHistory:         ptr(24),
+				SnapshotEveryPoll:       ptr(true),
+				ElementHistory:          ptr(20),
+				LogChangeEvents:         ptr(true),
+				Verbose:                 ptr(true),
 			},
 			expected: &DeviceMonitorConfig{
-				PollInterval:           ptr(10 * time.Second),
-				PollAttempts:           ptr(5),
-				PollAttemptTimeout:     ptr(30 * time.Second),
-				PollAttemptInterval:    ptr(2 * time.Second),
-				PollBackoffAfter:       ptr(3),
-				PollBackoffTime:        ptr(15 * time.Second),
-				PollBackoffNotify:      ptr(false),
-				PollBackoffStopMonitor: ptr(true),
-				OutputDir:              ptr("/custom/path"),
-				Verbose:                ptr(true),
+				PollInterval:            ptr(10 * time.Second),
+				PollJitter:              ptr(10 * time.Second),
+				PollAttempts:            ptr(5),
+				PollAttemptTimeout:      ptr(30 * time.Second),
+				PollAttemptInterval:     ptr(2 * time.Second),
+				PollAttemptBackoff:      ptr(2.0),
+				PollAttemptBackoffMax:   ptr(time.Minute),
+				PollAttemptJitter:       ptr(0.2),
+				PollWaitDelay:           ptr(10 * time.Second),
+				PollBudgetStrict:        ptr(true),
+				PollBackoffAfter:        ptr(3),
+				PollBackoffTime:         ptr(15 * time.Second),
+				PollBackoffNotify:       ptr(false),
+				PollBackoffStopMonitor:  ptr(true),
+				BackoffResetBaseline:    ptr(true),
+				PollFailureRateAlert:    ptr(0.5),
+				PollFailureRateWindow:   ptr(6),
+				OnStopCommand:           ptr("/usr/local/bin/on-stop"),
+				OnStopTimeout:           ptr(5 * time.Second),
+				PrePollCommand:          ptr("/usr/local/bin/pre-poll"),
+				PrePollTimeout:          ptr(5 * time.Second),
+				PrePollAbort:            ptr(true),
+				SgSesPath:               ptr("/usr/local/sbin/sg_ses"),
+				SgSesArgs:               []string{"--all", "--json", "--maxlen=1024"},
+				FetchCommand:            ptr("/usr/local/bin/vendor-ses"),
+				FetchArgs:               []string{"--json", "{{.Path}}"},
+				ToleratePreamble:        ptr(true),
+				NotifyOnRecovery:        ptr(false),
+				NotifyMinSeverity:       ptr(SeverityCritical),
+				AlertOnStartUnhealthy:   ptr(true),
+				NotifyMinInterval:       ptr(10 * time.Minute),
+				AlertDedupTTL:           ptr(time.Hour),
+				CompactMessages:         ptr(true),
+				HeartbeatInterval:       ptr(24 * time.Hour),
+				TempWarn:                ptr(45),
+				TempCrit:                ptr(55),
+				TempHysteresis:          ptr(3),
+				AlertFanSpeed:           ptr(true),
+				PrdFailCritical:         ptr(true),
+				MonitorTypes:            []int{2, 23},
+				SuppressTypes:           []int{16, 23},
+				ChangeDebounce:          ptr(2),
+				TransientStatuses:       []int{5},
+				TransientDebounce:       ptr(5),
+				ElementDropThreshold:    ptr(0.5),
+				CanonicalStatusMeanings: ptr(true),
+				StatusMeanings:          map[int]string{3: "Non-critical"},
+				OutputDir:               ptr("/custom/path"),
+				OutputPrefix:            ptr("sesmon-"),
+				WriteSnapshots:          ptr(false),
+				WriteChangeReports:      ptr(false),
+				ReportResults:           ptr(true),
+				OutputMaxReports:        ptr(100),
+				OutputMaxAge:            ptr(720 * time.Hour),
+				OutputChangelog:         ptr(true),
+				OutputCompress:          ptr(true),
+				OutputFileMode:          ptr("0640"),
+				OutputDirMode:           ptr("0750"),
+				PersistAcks:             ptr(true),
+				SnapshotHistory:         ptr(24),
+				SnapshotEveryPoll:       ptr(true),
+				ElementHistory:          ptr(20),
+				LogChangeEvents:         ptr(true),
+				Verbose:                 ptr(true),
 			},
 		},
 		{
@@ -606,6 +770,171 @@ func Test_mergeDeviceMonitorConfig_UserConfig_Success(t *testing.T) {
 	}
 }
 
+// Expectation: The function should return errors for invalid values.
+func Test_mergeDeviceMonitorConfig_InvalidValues_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		userCfg *DeviceMonitorConfig
+	}{
+		{
+			name:    "zero PollAttempts",
+			userCfg: &DeviceMonitorConfig{PollAttempts: ptr(0)},
+		},
+		{
+			name:    "empty SgSesPath",
+			userCfg: &DeviceMonitorConfig{SgSesPath: ptr(" ")},
+		},
+		{
+			name:    "TempWarn not below TempCrit",
+			userCfg: &DeviceMonitorConfig{TempWarn: ptr(50), TempCrit: ptr(50)},
+		},
+		{
+			name:    "negative TempHysteresis",
+			userCfg: &DeviceMonitorConfig{TempHysteresis: ptr(-1)},
+		},
+		{
+			name:    "negative PollJitter",
+			userCfg: &DeviceMonitorConfig{PollJitter: ptr(-time.Second)},
+		},
+		{
+			name:    "negative OutputMaxReports",
+			userCfg: &DeviceMonitorConfig{OutputMaxReports: ptr(-1)},
+		},
+		{
+			name:    "negative OutputMaxAge",
+			userCfg: &DeviceMonitorConfig{OutputMaxAge: ptr(-time.Hour)},
+		},
+		{
+			name:    "sub-100ms PollInterval",
+			userCfg: &DeviceMonitorConfig{PollInterval: ptr(60 * time.Nanosecond)},
+		},
+		{
+			name:    "negative HeartbeatInterval",
+			userCfg: &DeviceMonitorConfig{HeartbeatInterval: ptr(-time.Hour)},
+		},
+		{
+			name:    "zero ChangeDebounce",
+			userCfg: &DeviceMonitorConfig{ChangeDebounce: ptr(0)},
+		},
+		{
+			name:    "negative MonitorTypes",
+			userCfg: &DeviceMonitorConfig{MonitorTypes: []int{-1}},
+		},
+		{
+			name:    "out-of-range SuppressTypes",
+			userCfg: &DeviceMonitorConfig{SuppressTypes: []int{16, 256}},
+		},
+		{
+			name:    "negative PollAttemptTimeout",
+			userCfg: &DeviceMonitorConfig{PollAttemptTimeout: ptr(-time.Second)},
+		},
+		{
+			name:    "negative PollAttemptInterval",
+			userCfg: &DeviceMonitorConfig{PollAttemptInterval: ptr(-time.Second)},
+		},
+		{
+			name:    "PollAttemptBackoff below one",
+			userCfg: &DeviceMonitorConfig{PollAttemptBackoff: ptr(0.5)},
+		},
+		{
+			name:    "negative PollAttemptBackoffMax",
+			userCfg: &DeviceMonitorConfig{PollAttemptBackoffMax: ptr(-time.Second)},
+		},
+		{
+			name:    "PollAttemptJitter above one",
+			userCfg: &DeviceMonitorConfig{PollAttemptJitter: ptr(1.5)},
+		},
+		{
+			name:    "TransientStatuses out of range",
+			userCfg: &DeviceMonitorConfig{TransientStatuses: []int{16}},
+		},
+		{
+			name:    "zero TransientDebounce",
+			userCfg: &DeviceMonitorConfig{TransientDebounce: ptr(0)},
+		},
+		{
+			name:    "PollFailureRateAlert above one",
+			userCfg: &DeviceMonitorConfig{PollFailureRateAlert: ptr(1.5)},
+		},
+		{
+			name:    "zero PollFailureRateWindow",
+			userCfg: &DeviceMonitorConfig{PollFailureRateWindow: ptr(0)},
+		},
+		{
+			name:    "OutputPrefix with path separator",
+			userCfg: &DeviceMonitorConfig{OutputPrefix: ptr("../sesmon-")},
+		},
+		{
+			name:    "negative ElementDropThreshold",
+			userCfg: &DeviceMonitorConfig{ElementDropThreshold: ptr(-0.5)},
+		},
+		{
+			name:    "unknown NotifyMinSeverity",
+			userCfg: &DeviceMonitorConfig{NotifyMinSeverity: ptr("severe")},
+		},
+		{
+			name:    "StatusMeanings out of range",
+			userCfg: &DeviceMonitorConfig{StatusMeanings: map[int]string{16: "Vendor"}},
+		},
+		{
+			name:    "StatusMeanings empty description",
+			userCfg: &DeviceMonitorConfig{StatusMeanings: map[int]string{3: " "}},
+		},
+		{
+			name:    "zero PollWaitDelay",
+			userCfg: &DeviceMonitorConfig{PollWaitDelay: ptr(time.Duration(0))},
+		},
+		{
+			name:    "negative PollBackoffTime",
+			userCfg: &DeviceMonitorConfig{PollBackoffTime: ptr(-time.Second)},
+		},
+		{
+			name:    "invalid OutputFileMode",
+			userCfg: &DeviceMonitorConfig{OutputFileMode: ptr("0999")},
+		},
+		{
+			name:    "out of range OutputDirMode",
+			userCfg: &DeviceMonitorConfig{OutputDirMode: ptr("01777")},
+		},
+		{
+			name:    "empty OnStopCommand",
+			userCfg: &DeviceMonitorConfig{OnStopCommand: ptr(" ")},
+		},
+		{
+			name:    "zero OnStopTimeout",
+			userCfg: &DeviceMonitorConfig{OnStopTimeout: ptr(time.Duration(0))},
+		},
+		{
+			name:    "empty FetchCommand",
+			userCfg: &DeviceMonitorConfig{FetchCommand: ptr(" ")},
+		},
+		{
+			name:    "invalid FetchArgs template",
+			userCfg: &DeviceMonitorConfig{FetchCommand: ptr("vendor-ses"), FetchArgs: []string{"{{.Path"}},
+		},
+		{
+			name:    "unknown FetchArgs template field",
+			userCfg: &DeviceMonitorConfig{FetchCommand: ptr("vendor-ses"), FetchArgs: []string{"{{.Serial}}"}},
+		},
+		{
+			name:    "FetchArgs without FetchCommand",
+			userCfg: &DeviceMonitorConfig{FetchArgs: []string{"{{.Path}}"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := mergeDeviceMonitorConfig(tt.userCfg)
+			require.ErrorIs(t, err, errInvalidArgument)
+			require.Nil(t, result)
+		})
+	}
+}
+
 // Expectation: The function should meet the table's expectations.
 func Test_mergeScriptNotifierConfig_Defaults_Success(t *testing.T) {
 	t.Parallel()
@@ -637,6 +966,8 @@ func Test_mergeScriptNotifierConfig_Defaults_Success(t *testing.T) {
 			require.Equal(t, defaultCfg.NotifyAttempts, result.NotifyAttempts)
 			require.Equal(t, defaultCfg.NotifyAttemptTimeout, result.NotifyAttemptTimeout)
 			require.Equal(t, defaultCfg.NotifyAttemptInterval, result.NotifyAttemptInterval)
+			require.Equal(t, defaultCfg.StdinPayload, result.StdinPayload)
+			require.Equal(t, defaultCfg.ExportEnv, result.ExportEnv)
 		})
 	}
 }
@@ -656,11 +987,15 @@ func Test_mergeScriptNotifierConfig_UserConfig_Success(t *testing.T) {
 				NotifyAttempts:        ptr(5),
 				NotifyAttemptTimeout:  ptr(60 * time.Second),
 				NotifyAttemptInterval: ptr(5 * time.Second),
+				StdinPayload:          ptr(true),
+				ExportEnv:             ptr(true),
 			},
 			expected: &ScriptNotifierConfig{
 				NotifyAttempts:        ptr(5),
 				NotifyAttemptTimeout:  ptr(60 * time.Second),
 				NotifyAttemptInterval: ptr(5 * time.Second),
+				StdinPayload:          ptr(true),
+				ExportEnv:             ptr(true),
 			},
 		},
 		{
@@ -714,6 +1049,8 @@ func Test_mergeScriptNotifierConfig_UserConfig_Success(t *testing.T) {
 			require.Equal(t, tt.expected.NotifyAttempts, result.NotifyAttempts)
 			require.Equal(t, tt.expected.NotifyAttemptTimeout, result.NotifyAttemptTimeout)
 			require.Equal(t, tt.expected.NotifyAttemptInterval, result.NotifyAttemptInterval)
+			require.Equal(t, tt.expected.StdinPayload, result.StdinPayload)
+			require.Equal(t, tt.expected.ExportEnv, result.ExportEnv)
 		})
 	}
 }
@@ -931,6 +1268,70 @@ func Test_withRetries_RespectsRetryInterval_Success(t *testing.T) {
 	require.GreaterOrEqual(t, elapsed, 2*interval)
 }
 
+// Expectation: withBackoffRetries should grow the waits between the attempts.
+func Test_withBackoffRetries_GrowsInterval_Success(t *testing.T) {
+	t.Parallel()
+
+	var calls []time.Time
+	fn := func() error {
+		calls = append(calls, time.Now())
+		if len(calls) < 3 {
+			return errors.New("error")
+		}
+
+		return nil
+	}
+
+	interval := 40 * time.Millisecond
+	attempt, err := withBackoffRetries(t.Context(), fn, nil, 3, interval, RetryBackoff{Multiplier: 3})
+
+	require.NoError(t, err)
+	require.Equal(t, 3, attempt)
+	require.GreaterOrEqual(t, calls[1].Sub(calls[0]), interval)
+	require.GreaterOrEqual(t, calls[2].Sub(calls[1]), 3*interval)
+}
+
+// Expectation: RetryBackoff should return the grown, bounded and jittered waits.
+func Test_RetryBackoff_wait_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		backoff  RetryBackoff
+		attempt  int
+		expected time.Duration
+	}{
+		{"zero value keeps interval", RetryBackoff{}, 5, time.Second},
+		{"multiplier of one keeps interval", RetryBackoff{Multiplier: 1}, 5, time.Second},
+		{"first attempt keeps interval", RetryBackoff{Multiplier: 2}, 1, time.Second},
+		{"third attempt is grown", RetryBackoff{Multiplier: 2}, 3, 4 * time.Second},
+		{"grown wait is bounded", RetryBackoff{Multiplier: 2, Max: 3 * time.Second}, 3, 3 * time.Second},
+		{"bound below interval keeps interval", RetryBackoff{Multiplier: 2, Max: time.Millisecond}, 3, time.Second},
+		{"jitter at lowest random", RetryBackoff{Jitter: 0.5, rand: func() float64 { return 0 }}, 1, 500 * time.Millisecond},
+		{"jitter at middle random", RetryBackoff{Jitter: 0.5, rand: func() float64 { return 0.5 }}, 1, time.Second},
+		{"jitter applies after bound", RetryBackoff{Multiplier: 2, Max: 2 * time.Second, Jitter: 0.5, rand: func() float64 { return 0.75 }}, 4, 2500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, tt.backoff.wait(time.Second, tt.attempt))
+		})
+	}
+}
+
+// Expectation: RetryBackoff should return the longest possible wait (including jitter).
+func Test_RetryBackoff_maxWait_Success(t *testing.T) {
+	t.Parallel()
+
+	b := RetryBackoff{Multiplier: 2, Max: 4 * time.Second, Jitter: 0.5}
+
+	require.Equal(t, 1500*time.Millisecond, b.maxWait(time.Second, 1))
+	require.Equal(t, 3*time.Second, b.maxWait(time.Second, 2))
+	require.Equal(t, 6*time.Second, b.maxWait(time.Second, 5))
+}
+
 // Expectation: withRetries should handle nil fn without panicking.
 func Test_withRetries_NilFn_Success(t *testing.T) {
 	t.Parallel()
@@ -1074,16 +1475,16 @@ func Test_withRetries_OnRetryErrCalledOnceOnSecondSuccess_Success(t *testing.T)
 	require.Equal(t, 1, retryErrCount)
 }
 
-// Expectation: recoverGoPanic should log panic message with description and stack trace when panic occurs.
-func Test_recoverGoPanic_WithLogger_PanicRecovered(t *testing.T) {
+// Expectation: RecoverGoPanic should log panic message with description and stack trace when panic occurs.
+func Test_RecoverGoPanic_WithLogger_PanicRecovered(t *testing.T) {
 	t.Parallel()
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 	desc := "test operation"
 
 	func() {
-		defer recoverGoPanic(desc, logger)
+		defer RecoverGoPanic(desc, logger)
 		panic("intentional panic")
 	}()
 
@@ -1091,19 +1492,19 @@ func Test_recoverGoPanic_WithLogger_PanicRecovered(t *testing.T) {
 	require.Contains(t, output, desc)
 	require.Contains(t, output, "panic recovered")
 	require.Contains(t, output, "intentional panic")
-	require.Contains(t, output, "Test_recoverGoPanic")
+	require.Contains(t, output, "Test_RecoverGoPanic")
 }
 
-// Expectation: recoverGoPanic should not log anything when no panic occurs.
-func Test_recoverGoPanic_NoPanic_NoOutput(t *testing.T) {
+// Expectation: RecoverGoPanic should not log anything when no panic occurs.
+func Test_RecoverGoPanic_NoPanic_NoOutput(t *testing.T) {
 	t.Parallel()
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 	desc := "test operation"
 
 	func() {
-		defer recoverGoPanic(desc, logger)
+		defer RecoverGoPanic(desc, logger)
 		// Normal execution, no panic
 	}()
 
@@ -1111,10 +1512,10 @@ func Test_recoverGoPanic_NoPanic_NoOutput(t *testing.T) {
 	require.Empty(t, output)
 }
 
-// Expectation: recoverGoPanic should write to stderr when logger is nil.
+// Expectation: RecoverGoPanic should write to stderr when logger is nil.
 //
 //nolint:paralleltest
-func Test_recoverGoPanic_NilLogger_WritesToStderr(t *testing.T) {
+func Test_RecoverGoPanic_NilLogger_WritesToStderr(t *testing.T) {
 	// Note: Cannot use t.Parallel() due to stderr manipulation
 
 	oldStderr := os.Stderr
@@ -1124,7 +1525,7 @@ func Test_recoverGoPanic_NilLogger_WritesToStderr(t *testing.T) {
 	desc := "test operation"
 
 	func() {
-		defer recoverGoPanic(desc, nil)
+		defer RecoverGoPanic(desc, nil)
 		panic("panic with nil logger")
 	}()
 
@@ -1141,15 +1542,15 @@ func Test_recoverGoPanic_NilLogger_WritesToStderr(t *testing.T) {
 	require.Contains(t, output, "panic with nil logger")
 }
 
-// Expectation: recoverGoPanic should handle string panic values correctly.
-func Test_recoverGoPanic_StringPanic_Recovered(t *testing.T) {
+// Expectation: RecoverGoPanic should handle string panic values correctly.
+func Test_RecoverGoPanic_StringPanic_Recovered(t *testing.T) {
 	t.Parallel()
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
 	func() {
-		defer recoverGoPanic("test", logger)
+		defer RecoverGoPanic("test", logger)
 		panic("string error")
 	}()
 
@@ -1158,15 +1559,15 @@ func Test_recoverGoPanic_StringPanic_Recovered(t *testing.T) {
 	require.Contains(t, output, "string error")
 }
 
-// Expectation: recoverGoPanic should handle integer panic values correctly.
-func Test_recoverGoPanic_IntPanic_Recovered(t *testing.T) {
+// Expectation: RecoverGoPanic should handle integer panic values correctly.
+func Test_RecoverGoPanic_IntPanic_Recovered(t *testing.T) {
 	t.Parallel()
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
 	func() {
-		defer recoverGoPanic("test", logger)
+		defer RecoverGoPanic("test", logger)
 		panic(42)
 	}()
 
@@ -1175,15 +1576,15 @@ func Test_recoverGoPanic_IntPanic_Recovered(t *testing.T) {
 	require.Contains(t, output, "42")
 }
 
-// Expectation: recoverGoPanic should handle struct panic values correctly.
-func Test_recoverGoPanic_StructPanic_Recovered(t *testing.T) {
+// Expectation: RecoverGoPanic should handle struct panic values correctly.
+func Test_RecoverGoPanic_StructPanic_Recovered(t *testing.T) {
 	t.Parallel()
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
 	func() {
-		defer recoverGoPanic("test", logger)
+		defer RecoverGoPanic("test", logger)
 		panic(struct{ msg string }{"error"})
 	}()
 
@@ -1191,15 +1592,15 @@ func Test_recoverGoPanic_StructPanic_Recovered(t *testing.T) {
 	require.Contains(t, output, "panic recovered")
 }
 
-// Expectation: recoverGoPanic should handle nil panic values correctly.
-func Test_recoverGoPanic_NilPanic_Recovered(t *testing.T) {
+// Expectation: RecoverGoPanic should handle nil panic values correctly.
+func Test_RecoverGoPanic_NilPanic_Recovered(t *testing.T) {
 	t.Parallel()
 
 	var buf bytes.Buffer
-	logger := log.New(&buf, "", 0)
+	logger := NewLogger(&buf, false, true)
 
 	func() {
-		defer recoverGoPanic("test", logger)
+		defer RecoverGoPanic("test", logger)
 		// Pointless (pun intended) but I needed to trick the
 		// code linters not to go crazy about panic(nil). ;-)
 		var v *uintptr
@@ -1210,3 +1611,250 @@ func Test_recoverGoPanic_NilPanic_Recovered(t *testing.T) {
 	output := buf.String()
 	require.Contains(t, output, "panic recovered")
 }
+
+// Expectation: DeviceMonitorConfig should accept bare integers as seconds and duration strings.
+func Test_DeviceMonitorConfig_UnmarshalYAML_Success(t *testing.T) {
+	t.Parallel()
+
+	var cfg DeviceMonitorConfig
+	err := yaml.Unmarshal([]byte("poll_interval: 60\npoll_attempt_timeout: \"2m\"\npoll_backoff_time: 0x10\npoll_attempts: 3\n"), &cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, 60*time.Second, *cfg.PollInterval)
+	require.Equal(t, 2*time.Minute, *cfg.PollAttemptTimeout)
+	require.Equal(t, 16*time.Second, *cfg.PollBackoffTime)
+	require.Equal(t, 3, *cfg.PollAttempts)
+	require.Nil(t, cfg.PollAttemptInterval)
+}
+
+// Expectation: DeviceMonitorConfig should still reject unknown fields when decoded strictly.
+func Test_DeviceMonitorConfig_UnmarshalYAML_UnknownField_Error(t *testing.T) {
+	t.Parallel()
+
+	var cfg DeviceMonitorConfig
+	decoder := yaml.NewDecoder(strings.NewReader("poll_interval: 60\nunknown_field: 1\n"))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(&cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "field unknown_field not found in type sesmon.DeviceMonitorConfig")
+}
+
+// Expectation: DeviceMonitorConfig should reject bare integers that overflow as seconds.
+func Test_DeviceMonitorConfig_UnmarshalYAML_Overflow_Error(t *testing.T) {
+	t.Parallel()
+
+	var cfg DeviceMonitorConfig
+	err := yaml.Unmarshal([]byte("poll_interval: 9223372036854775807\n"), &cfg)
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: replaceYAMLScalars should replace the rewritten values in place (also several on one line),
+// and fail if a value is not found at its position (e.g. with an explicit tag).
+func Test_replaceYAMLScalars_Success(t *testing.T) {
+	t.Parallel()
+
+	typ := reflect.TypeFor[DeviceMonitorConfig]()
+
+	replace := func(data string) (string, bool) {
+		var doc yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte(data), &doc))
+
+		root, changed, err := yamlSecondsToDurations(doc.Content[0], typ)
+		require.NoError(t, err)
+		require.True(t, changed)
+
+		out, ok := replaceYAMLScalars([]byte(data), doc.Content[0], root)
+
+		return string(out), ok
+	}
+
+	out, ok := replace("# Zeit für Kommentare\npoll_interval: 60\nverbose: true\n")
+	require.True(t, ok)
+	require.Equal(t, "# Zeit für Kommentare\npoll_interval: \"1m0s\"\nverbose: true\n", out)
+
+	out, ok = replace(`{"poll_interval": 60, "poll_attempt_timeout": 5, "verbose": true}`)
+	require.True(t, ok)
+	require.JSONEq(t, `{"poll_interval": "1m0s", "poll_attempt_timeout": "5s", "verbose": true}`, out)
+
+	_, ok = replace("poll_interval: !!int 60\n")
+	require.False(t, ok)
+}
+
+// Expectation: ScriptNotifierConfig and WebhookNotifierConfig should accept bare integers as seconds.
+func Test_NotifierConfig_UnmarshalYAML_Success(t *testing.T) {
+	t.Parallel()
+
+	var scfg ScriptNotifierConfig
+	require.NoError(t, yaml.Unmarshal([]byte("notify_attempt_timeout: 15\nnotify_attempt_interval: 5s\n"), &scfg))
+	require.Equal(t, 15*time.Second, *scfg.NotifyAttemptTimeout)
+	require.Equal(t, 5*time.Second, *scfg.NotifyAttemptInterval)
+
+	var wcfg WebhookNotifierConfig
+	require.NoError(t, yaml.Unmarshal([]byte("notify_attempt_timeout: 15\nnotify_attempt_interval: 5s\n"), &wcfg))
+	require.Equal(t, 15*time.Second, *wcfg.NotifyAttemptTimeout)
+	require.Equal(t, 5*time.Second, *wcfg.NotifyAttemptInterval)
+}
+
+// Expectation: mergeScriptNotifierConfig and mergeWebhookNotifierConfig should reject negative durations.
+func Test_mergeNotifierConfig_NegativeDurations_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := mergeScriptNotifierConfig(&ScriptNotifierConfig{NotifyAttemptTimeout: ptr(-time.Second)})
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = mergeScriptNotifierConfig(&ScriptNotifierConfig{NotifyAttemptInterval: ptr(-time.Second)})
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = mergeWebhookNotifierConfig(&WebhookNotifierConfig{NotifyAttemptTimeout: ptr(-time.Second)})
+	require.ErrorIs(t, err, errInvalidArgument)
+
+	_, err = mergeWebhookNotifierConfig(&WebhookNotifierConfig{NotifyAttemptInterval: ptr(-time.Second)})
+	require.ErrorIs(t, err, errInvalidArgument)
+}
+
+// Expectation: overlayConfig should apply all set settings of the override on top of the base.
+func Test_overlayConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	base := &DeviceMonitorConfig{
+		PollInterval: ptr(5 * time.Minute),
+		PollAttempts: ptr(5),
+		SgSesArgs:    []string{"--all", "--json"},
+	}
+	override := &DeviceMonitorConfig{
+		PollInterval: ptr(time.Minute),
+		Verbose:      ptr(true),
+	}
+
+	merged := overlayConfig(base, override)
+	require.Equal(t, time.Minute, *merged.PollInterval)
+	require.Equal(t, 5, *merged.PollAttempts)
+	require.Equal(t, []string{"--all", "--json"}, merged.SgSesArgs)
+	require.True(t, *merged.Verbose)
+	require.Nil(t, merged.OutputDir)
+
+	require.Equal(t, 5*time.Minute, *base.PollInterval)
+	require.Nil(t, base.Verbose)
+
+	require.Equal(t, base, overlayConfig(base, nil))
+	require.Equal(t, override, overlayConfig(nil, override))
+}
+
+// Expectation: expandEnvYAML should expand environment variables within values (not comments).
+func Test_expandEnvYAML_Success(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"SESMON_URL":      "https://example.com/hook",
+		"SESMON_ATTEMPTS": "5",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+
+		return v, ok
+	}
+
+	data := []byte(`
+# uses ${SESMON_UNSET} from the environment
+url: "${SESMON_URL}/sesmon"
+attempts: ${SESMON_ATTEMPTS}
+literal: "$${SESMON_URL}"
+`)
+
+	out, err := expandEnvYAML(data, lookup)
+	require.NoError(t, err)
+
+	var result struct {
+		URL      string `yaml:"url"`
+		Attempts int    `yaml:"attempts"`
+		Literal  string `yaml:"literal"`
+	}
+	require.NoError(t, yaml.Unmarshal(out, &result))
+	require.Equal(t, "https://example.com/hook/sesmon", result.URL)
+	require.Equal(t, 5, result.Attempts)
+	require.Equal(t, "${SESMON_URL}", result.Literal)
+}
+
+// Expectation: expandEnvYAML should return the data unmodified if there is nothing to expand.
+func Test_expandEnvYAML_NothingToExpand_Success(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("# comment\nurl: \"https://example.com/$path\"\n")
+
+	out, err := expandEnvYAML(data, func(string) (string, bool) { return "", false })
+	require.NoError(t, err)
+	require.Equal(t, data, out)
+}
+
+// Expectation: expandEnvYAML should return an error for unset environment variables.
+func Test_expandEnvYAML_Unset_Error(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("url: \"${SESMON_UNSET}\"\n")
+
+	out, err := expandEnvYAML(data, func(string) (string, bool) { return "", false })
+	require.ErrorIs(t, err, errEnvNotSet)
+	require.ErrorContains(t, err, "SESMON_UNSET")
+	require.Nil(t, out)
+}
+
+// Expectation: sanitizeDirName should return filesystem-safe folder names.
+func Test_sanitizeDirName_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"/dev/sg0":           "sg0",
+		"/dev/disk/by-id/x":  "disk_by-id_x",
+		"0x5000c500a1b2c3d4": "0x5000c500a1b2c3d4",
+		"/tmp/dump.json":     "tmp_dump.json",
+		"..":                 "_",
+		"/":                  "_",
+		"":                   "_",
+	}
+
+	for in, want := range tests {
+		require.Equal(t, want, sanitizeDirName(in), in)
+	}
+}
+
+// Expectation: isGlobPattern should detect the special characters of glob patterns.
+func Test_isGlobPattern_Success(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isGlobPattern("/tmp/*.json"))
+	require.True(t, isGlobPattern("/tmp/enc-?.json"))
+	require.True(t, isGlobPattern("/tmp/enc-[ab].json"))
+	require.False(t, isGlobPattern("/tmp/enc.json"))
+}
+
+// Expectation: parseFileMode should parse permissions in octal notation.
+func Test_parseFileMode_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]os.FileMode{
+		"0640":  0o640,
+		"640":   0o640,
+		"0o750": 0o750,
+		" 0600": 0o600,
+		"0":     0,
+		"0777":  0o777,
+	}
+
+	for in, want := range tests {
+		mode, err := parseFileMode("output_file_mode", in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, mode, in)
+	}
+}
+
+// Expectation: parseFileMode should return an error for invalid permissions.
+func Test_parseFileMode_Error(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"", "rw-r-----", "0999", "1777", "-640"} {
+		_, err := parseFileMode("output_file_mode", in)
+		require.ErrorIs(t, err, errInvalidArgument, in)
+		require.ErrorContains(t, err, "output_file_mode", in)
+	}
+}
@@ -0,0 +1,97 @@
+package sesmon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// ValidateConfig checks all devices of a [ConfigYAML] (regardless if enabled)
+// for problems that would otherwise only surface once a device gets enabled, such as
+// invalid monitor settings, notification agents that cannot be established (e.g. a
+// non-executable script) or output directories that are not writable. Unlike with
+// [NewProgram], all problems are reported (joined) rather than only the first one.
+func ValidateConfig(config ConfigYAML, fsys afero.Fs) error {
+	logger := slog.New(slog.DiscardHandler)
+	runner := &RetryCommandRunner{logger: logger}
+
+	var errs []error
+	for i, deviceCfg := range config.Devices {
+		if deviceCfg.Device == "" && deviceCfg.Address == "" {
+			errs = append(errs, fmt.Errorf("[config:%d] %w: missing device and address "+
+				"(needs to have at least one to be monitorable)", i, errInvalidArgument))
+
+			continue
+		}
+
+		deviceCfg = applyDeviceDefaults(deviceCfg, config.Defaults)
+		deviceCfg = applyOutputBase(deviceCfg, config.OutputBase)
+
+		for _, err := range validateDevice(deviceCfg, fsys, runner, logger) {
+			errs = append(errs, fmt.Errorf("[config:%d:%s:%s] %w", i, deviceCfg.Device, deviceCfg.Address, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateDevice returns all problems found with the settings of a single [DeviceYAML].
+func validateDevice(deviceCfg DeviceYAML, fsys afero.Fs, runner CommandRunner, logger *slog.Logger) []error {
+	var errs []error
+
+	mcfg, err := mergeDeviceMonitorConfig(deviceCfg.MonitorConfig)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failure creating monitoring agent: %w", err))
+	}
+
+	if _, err := setupNotifier(deviceCfg, fsys, runner, logger); err != nil {
+		errs = append(errs, fmt.Errorf("failure creating notification agent: %w", err))
+	}
+
+	if mcfg != nil && mcfg.OutputDir != nil {
+		if err := checkWritableDir(fsys, *mcfg.OutputDir); err != nil {
+			errs = append(errs, fmt.Errorf("output_dir: %w", err))
+		}
+	}
+
+	return errs
+}
+
+// checkWritableDir checks if files can be created in a directory by creating (and removing)
+// a temporary file. If the directory does not exist yet, its nearest existing parent is
+// checked instead, as the monitor creates the missing directories when writing to it.
+func checkWritableDir(fsys afero.Fs, dir string) error {
+	existing := filepath.Clean(dir)
+	for {
+		fi, err := fsys.Stat(existing)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("%q: %w: %q is not a directory", dir, errNotWritable, existing)
+			}
+
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%q: %w: %w", dir, errNotWritable, err)
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("%q: %w: %w", dir, errNotWritable, err)
+		}
+		existing = parent
+	}
+
+	f, err := afero.TempFile(fsys, existing, ".sesmon-write-test-*")
+	if err != nil {
+		return fmt.Errorf("%q: %w: %w", dir, errNotWritable, err)
+	}
+	_ = f.Close()
+	_ = fsys.Remove(f.Name())
+
+	return nil
+}
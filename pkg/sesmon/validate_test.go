@@ -0,0 +1,112 @@
+package sesmon
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+// parseTestConfig parses a YAML configuration into a [ConfigYAML] for testing.
+func parseTestConfig(t *testing.T, yamlConfig string) ConfigYAML {
+	t.Helper()
+
+	config, err := ParseConfigYAML([]byte(yamlConfig))
+	require.NoError(t, err)
+
+	return config
+}
+
+// Expectation: ValidateConfig should accept valid devices, regardless if enabled.
+func Test_ValidateConfig_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/scripts/notify.sh", []byte("#!/bin/bash"), 0o755))
+
+	config := parseTestConfig(t, `
+output_base: /var/lib/sesmon
+devices:
+  - device: /dev/sg0
+    enabled: true
+    script_notifier:
+      script: /scripts/notify.sh
+  - address: "0x500a098012345678"
+    enabled: false
+    script_notifier:
+      script: /scripts/notify.sh
+`)
+
+	require.NoError(t, ValidateConfig(config, fsys))
+
+	files, err := afero.ReadDir(fsys, "/")
+	require.NoError(t, err)
+	require.Len(t, files, 1, "no write test files should be left behind")
+}
+
+// Expectation: ValidateConfig should report all problems, including those of disabled devices.
+func Test_ValidateConfig_DisabledDevices_Error(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fsys, "/scripts/notify.sh", []byte("#!/bin/bash"), 0o644))
+
+	config := parseTestConfig(t, `
+devices:
+  - device: /dev/sg0
+    enabled: false
+    script_notifier:
+      script: /scripts/notify.sh
+  - device: /dev/sg1
+    enabled: false
+    script_notifier:
+      script: /scripts/missing.sh
+  - device: /dev/sg2
+    enabled: false
+    config:
+      poll_interval: -1s
+  - description: "Nothing to monitor"
+`)
+
+	err := ValidateConfig(config, fsys)
+	require.ErrorIs(t, err, errNotExecutable)
+	require.ErrorIs(t, err, errInvalidArgument)
+	require.Contains(t, err.Error(), "[config:0:/dev/sg0:]")
+	require.Contains(t, err.Error(), "[config:1:/dev/sg1:]")
+	require.Contains(t, err.Error(), "[config:2:/dev/sg2:]")
+	require.Contains(t, err.Error(), "[config:3]")
+}
+
+// Expectation: ValidateConfig should report output directories that are not writable.
+func Test_ValidateConfig_OutputDirNotWritable_Error(t *testing.T) {
+	t.Parallel()
+
+	base := afero.NewMemMapFs()
+	require.NoError(t, base.MkdirAll("/var/lib/sesmon", 0o755))
+	require.NoError(t, afero.WriteFile(base, "/var/lib/file", []byte{}, 0o644))
+
+	config := parseTestConfig(t, `
+devices:
+  - device: /dev/sg0
+    enabled: false
+    config:
+      output_dir: /var/lib/sesmon/sg0
+  - device: /dev/sg1
+    enabled: false
+    config:
+      output_dir: /var/lib/file/sg1
+`)
+
+	err := ValidateConfig(config, afero.NewReadOnlyFs(base))
+	require.ErrorIs(t, err, errNotWritable)
+	require.Contains(t, err.Error(), "[config:0:/dev/sg0:] output_dir")
+	require.Contains(t, err.Error(), "[config:1:/dev/sg1:] output_dir")
+	require.Contains(t, err.Error(), "is not a directory")
+
+	require.NoError(t, ValidateConfig(parseTestConfig(t, `
+devices:
+  - device: /dev/sg0
+    config:
+      output_dir: /var/lib/sesmon/sg0
+`), base))
+}
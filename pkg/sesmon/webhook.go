@@ -0,0 +1,365 @@
+package sesmon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// errUnexpectedStatus occurs when a remote endpoint responds with a non-2xx status.
+var errUnexpectedStatus = errors.New("unexpected response status")
+
+// WebhookNotifierConfig is the configuration for a [WebhookNotifier] implementation.
+type WebhookNotifierConfig struct {
+	// How often to attempt a notification (must be > 0).
+	NotifyAttempts *int `yaml:"notify_attempts"`
+
+	// How long a notification attempt can take (multiplies with attempts).
+	NotifyAttemptTimeout *time.Duration `yaml:"notify_attempt_timeout"`
+
+	// How long to wait between notification attempts (in case of failure).
+	NotifyAttemptInterval *time.Duration `yaml:"notify_attempt_interval"`
+
+	// Skip verification of the remote endpoint's TLS certificate.
+	TLSSkipVerify *bool `yaml:"tls_skip_verify"`
+
+	// Path to a PEM client certificate for mutual TLS (requires a key).
+	TLSClientCert *string `yaml:"tls_client_cert"`
+
+	// Path to the PEM private key of the client certificate (requires a certificate).
+	TLSClientKey *string `yaml:"tls_client_key"`
+
+	// Path to a PEM CA bundle to verify the remote endpoint's TLS certificate
+	// with (instead of the system's CA certificates, empty = system's).
+	TLSCACert *string `yaml:"tls_ca_cert"`
+
+	// Template (Go text/template) for the notification message, evaluated with
+	// a [MessageTemplateData] (empty = default message). Validated on load.
+	MessageTemplate *string `yaml:"message_template"`
+}
+
+// MarshalJSON is a custom JSON marshaller for user readable [time.Duration] strings.
+func (c WebhookNotifierConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct { //nolint:wrapcheck
+		NotifyAttempts        *int    `json:"notify_attempts"`
+		NotifyAttemptTimeout  *string `json:"notify_attempt_timeout"`
+		NotifyAttemptInterval *string `json:"notify_attempt_interval"`
+		TLSSkipVerify         *bool   `json:"tls_skip_verify"`
+		TLSClientCert         *string `json:"tls_client_cert"`
+		TLSClientKey          *string `json:"tls_client_key"`
+		TLSCACert             *string `json:"tls_ca_cert"`
+		MessageTemplate       *string `json:"message_template"`
+	}{
+		NotifyAttempts:        c.NotifyAttempts,
+		NotifyAttemptTimeout:  durPtrToStrPtr(c.NotifyAttemptTimeout),
+		NotifyAttemptInterval: durPtrToStrPtr(c.NotifyAttemptInterval),
+		TLSSkipVerify:         c.TLSSkipVerify,
+		TLSClientCert:         c.TLSClientCert,
+		TLSClientKey:          c.TLSClientKey,
+		TLSCACert:             c.TLSCACert,
+		MessageTemplate:       c.MessageTemplate,
+	})
+}
+
+// UnmarshalYAML is a custom YAML unmarshaller accepting bare integers (as seconds) for durations.
+func (c *WebhookNotifierConfig) UnmarshalYAML(value *yaml.Node) error {
+	type plain WebhookNotifierConfig
+
+	return decodeYAMLDurations(value, (*plain)(c), reflect.TypeFor[WebhookNotifierConfig]())
+}
+
+// DefaultWebhookNotifierConfig returns a pointer to a default [WebhookNotifierConfig].
+//
+//nolint:mnd
+func DefaultWebhookNotifierConfig() *WebhookNotifierConfig {
+	return &WebhookNotifierConfig{
+		NotifyAttempts:        ptr(3),
+		NotifyAttemptTimeout:  ptr(15 * time.Second),
+		NotifyAttemptInterval: ptr(15 * time.Second),
+		TLSSkipVerify:         ptr(false),
+	}
+}
+
+// WebhookPayload is the JSON body that is sent by a [WebhookNotifier].
+type WebhookPayload struct {
+	Device  Device `json:"device"`
+	Message string `json:"message"`
+	Report  any    `json:"report,omitempty"`
+}
+
+var _ Notifier = (*WebhookNotifier)(nil)
+
+// WebhookNotifier is a [Notifier] sending a JSON-encoded [WebhookPayload]
+// to a user-defined URL using an HTTP POST request. The payload contains the
+// device, the notification message and the change report (where applicable).
+type WebhookNotifier struct {
+	// URL to send the HTTP POST request to.
+	url string
+
+	// URL as used in all logs and errors (the URL itself, unless it contains a secret).
+	display string
+
+	// Custom headers to set on the HTTP POST request.
+	headers map[string]string
+
+	// Bearer token to set as the "Authorization" header (if not empty).
+	token string
+
+	client *http.Client
+	logger *slog.Logger
+
+	cfg  *WebhookNotifierConfig
+	tmpl *template.Template
+}
+
+// NewWebhookNotifier returns a pointer to a new [WebhookNotifier]. Any configured
+// TLS client certificate and CA bundle are read (and validated) from the filesystem
+// once here, with the resulting [http.Client] then being reused for all notifications.
+func NewWebhookNotifier(
+	target string, headers map[string]string, token string,
+	cfg *WebhookNotifierConfig, fsys afero.Fs, logger *slog.Logger,
+) (*WebhookNotifier, error) {
+	return newWebhookNotifier(target, target, headers, token, cfg, fsys, logger)
+}
+
+// newWebhookNotifier returns a pointer to a new [WebhookNotifier], which uses the given
+// display URL in place of the URL in all logs and errors (see [redactedURL]).
+func newWebhookNotifier(
+	target string, display string, headers map[string]string, token string,
+	cfg *WebhookNotifierConfig, fsys afero.Fs, logger *slog.Logger,
+) (*WebhookNotifier, error) {
+	if fsys == nil || logger == nil {
+		return nil, fmt.Errorf("%w: required dependency is nil", errInvalidArgument)
+	}
+
+	if target == "" {
+		return nil, fmt.Errorf("%w: no url provided", errInvalidArgument)
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w: failure parsing url: %w", display, errInvalidArgument, redactURLError(err, display))
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q: %w: url needs to be http(s)://host[:port][/path]", display, errInvalidArgument)
+	}
+
+	wcfg, err := mergeWebhookNotifierConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	tmpl, err := parseMessageTemplate(fmtPtrStr(wcfg.MessageTemplate, ""))
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	tlsCfg, err := webhookTLSConfig(fsys, wcfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuration failure: %w", err)
+	}
+
+	client, err := newHTTPClient(tlsCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookNotifier{
+		url:     target,
+		display: display,
+		headers: headers,
+		token:   token,
+		client:  client,
+		logger:  logger,
+		cfg:     wcfg,
+		tmpl:    tmpl,
+	}, nil
+}
+
+// newHTTPClient returns a new [http.Client] for notification agents (based on the default transport).
+func newHTTPClient(tlsCfg *tls.Config) (*http.Client, error) {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("%w: unexpected default transport", errInvalidArgument)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsCfg
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// webhookTLSConfig returns the [tls.Config] for a [WebhookNotifierConfig], reading the TLS
+// client certificate (for mutual TLS) and the CA bundle from the filesystem (if configured).
+// The errors only ever contain the paths of the files, never any of their contents.
+func webhookTLSConfig(fsys afero.Fs, cfg *WebhookNotifierConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		InsecureSkipVerify: *cfg.TLSSkipVerify, //nolint:gosec
+	}
+
+	if certFile, keyFile := fmtPtrStr(cfg.TLSClientCert, ""), fmtPtrStr(cfg.TLSClientKey, ""); certFile != "" {
+		certPEM, err := afero.ReadFile(fsys, certFile)
+		if err != nil {
+			return nil, fmt.Errorf("%q: failure reading tls_client_cert: %w", certFile, err)
+		}
+		keyPEM, err := afero.ReadFile(fsys, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%q: failure reading tls_client_key: %w", keyFile, err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("%q/%q: %w: failure loading client certificate: %w",
+				certFile, keyFile, errInvalidArgument, err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile := fmtPtrStr(cfg.TLSCACert, ""); caFile != "" {
+		caPEM, err := afero.ReadFile(fsys, caFile)
+		if err != nil {
+			return nil, fmt.Errorf("%q: failure reading tls_ca_cert: %w", caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("%q: %w: no PEM certificates found in tls_ca_cert", caFile, errInvalidArgument)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	return tlsCfg, nil
+}
+
+// Notify sends the [WebhookPayload] to the user-defined URL with HTTP POST.
+// Any response status code other than 2xx is considered as a failed attempt.
+// It both observes and respects context cancellations for earlier notification terminations.
+func (n *WebhookNotifier) Notify(ctx context.Context, device Device, message string, extra any) error {
+	message, err := renderMessage(n.tmpl, device, message, extra)
+	if err != nil {
+		n.logger.Warn(fmt.Sprintf("%q: %v (using default message)", n.display, err), LogEvent("template_failure"))
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		Device:  device,
+		Message: message,
+		Report:  extra,
+	})
+	if err != nil {
+		return fmt.Errorf("%q: failure marshalling payload to JSON: %w", n.display, err)
+	}
+
+	return n.deliver(ctx, body)
+}
+
+// deliver sends a JSON-encoded body to the user-defined URL with HTTP POST,
+// retrying as configured in the [WebhookNotifierConfig] (in case of failure).
+func (n *WebhookNotifier) deliver(ctx context.Context, body []byte) error {
+	attempt, err := withRetries(
+		ctx,
+		func() error {
+			return n.post(ctx, body)
+		},
+		func(attempt int, err error) {
+			n.logger.Warn(fmt.Sprintf("%q: [%d/%d] notification failure: %v",
+				n.display, attempt, *n.cfg.NotifyAttempts, err), LogEvent("notify_retry"))
+		},
+		*n.cfg.NotifyAttempts,
+		*n.cfg.NotifyAttemptInterval,
+	)
+	if err != nil {
+		return fmt.Errorf("%q: [%d/%d] notification failure: %w",
+			n.display, attempt, *n.cfg.NotifyAttempts, err)
+	}
+
+	return nil
+}
+
+// post is a single notification attempt (HTTP POST) to the user-defined URL.
+func (n *WebhookNotifier) post(ctx context.Context, body []byte) error {
+	reqCtx, reqCancel := context.WithTimeout(ctx, *n.cfg.NotifyAttemptTimeout)
+	defer reqCancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failure creating request: %w", redactURLError(err, n.display))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failure sending request: %w", redactURLError(err, n.display))
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", errUnexpectedStatus, resp.Status)
+	}
+
+	return nil
+}
+
+// Name returns the name of the notification agent as a string.
+func (n *WebhookNotifier) Name() string {
+	return "webhook_notifier"
+}
+
+// Config returns the configuration of the notification agent as a string.
+// The bearer token and any header values are redacted from the output.
+func (n *WebhookNotifier) Config() string {
+	cfgJSON, err := json.Marshal(n.cfg)
+	if err != nil {
+		cfgJSON = []byte("n/a")
+	}
+
+	token := "-"
+	if n.token != "" {
+		token = "[redacted]"
+	}
+
+	return fmt.Sprintf("%q:%s:headers=%v:token=%s",
+		n.display, cfgJSON, slices.Sorted(maps.Keys(n.headers)), token)
+}
+
+// redactedURL returns the URL with everything but its scheme and host redacted, such as
+// for the URL of a Slack incoming webhook (where the path is the secret), to display.
+func redactedURL(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "[redacted]"
+	}
+
+	return u.Scheme + "://" + u.Host + "/[redacted]"
+}
+
+// redactURLError replaces the URL within any [url.Error] of an error (as returned by both
+// [url.Parse] and [http.Client]) with the display URL, so that it never contains a secret.
+func redactURLError(err error, display string) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = display
+	}
+
+	return err
+}
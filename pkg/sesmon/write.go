@@ -0,0 +1,342 @@
+package sesmon
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	baseFilePerms   = 0o666
+	baseFolderPerms = 0o777
+
+	changeReportPrefix = "change-"
+	changeReportSuffix = ".json"
+
+	snapshotPrefix = "snapshot-"
+	snapshotSuffix = ".json"
+
+	changelogFilename = "changelog.jsonl"
+
+	// compressedSuffix is appended to the filenames of gzip-compressed files.
+	compressedSuffix = ".gz"
+
+	// outputTimestampFormat is the timestamp format of timestamped files (sorting chronologically).
+	outputTimestampFormat = "20060102-150405"
+)
+
+// writeFileAtomic writes data to a temporary file in the same directory and then
+// renames it into place, so that readers never see a partially written file.
+// The permissions are applied on creation of the temporary file (as the final file).
+func writeFileAtomic(fsys afero.Fs, path string, data []byte, perm os.FileMode) error {
+	tmpPath := filepath.Join(filepath.Dir(path),
+		fmt.Sprintf(".%s.tmp-%d", filepath.Base(path), time.Now().UnixNano()))
+
+	f, err := fsys.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("failure creating temporary file: %w", err)
+	}
+
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = fsys.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = fsys.Remove(tmpPath)
+
+		return fmt.Errorf("failure writing temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// compressOutput gzip-compresses data to be written to path if
+// [DeviceMonitorConfig.OutputCompress] is enabled, returning the
+// (possibly suffixed) path and data to be written instead.
+func (d *DeviceMonitor) compressOutput(path string, data []byte) (string, []byte, error) {
+	if d.cfg.OutputCompress == nil || !*d.cfg.OutputCompress {
+		return path, data, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Name = filepath.Base(path)
+
+	if _, err := zw.Write(data); err != nil {
+		return "", nil, fmt.Errorf("failure compressing: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", nil, fmt.Errorf("failure compressing: %w", err)
+	}
+
+	return path + compressedSuffix, buf.Bytes(), nil
+}
+
+// outputFileMode returns the permissions of the files written to [DeviceMonitorConfig.OutputDir].
+func (d *DeviceMonitor) outputFileMode() os.FileMode {
+	if d.cfg.OutputFileMode == nil {
+		return baseFilePerms
+	}
+	mode, err := parseFileMode("output_file_mode", *d.cfg.OutputFileMode)
+	if err != nil {
+		return baseFilePerms
+	}
+
+	return mode
+}
+
+// outputDirMode returns the permissions of the folders created for [DeviceMonitorConfig.OutputDir].
+func (d *DeviceMonitor) outputDirMode() os.FileMode {
+	if d.cfg.OutputDirMode == nil {
+		return baseFolderPerms
+	}
+	mode, err := parseFileMode("output_dir_mode", *d.cfg.OutputDirMode)
+	if err != nil {
+		return baseFolderPerms
+	}
+
+	return mode
+}
+
+// ensureDeviceFolder ensures that [DeviceMonitorConfig.OutputDir] exists.
+func (d *DeviceMonitor) ensureDeviceFolder() (string, error) {
+	if err := d.fsys.MkdirAll(*d.cfg.OutputDir, d.outputDirMode()); err != nil {
+		return "", fmt.Errorf("failure creating directory: %w", err)
+	}
+
+	return *d.cfg.OutputDir, nil
+}
+
+// outputName returns the name of a file in [DeviceMonitorConfig.OutputDir],
+// prefixed with [DeviceMonitorConfig.OutputPrefix] (if any).
+func (d *DeviceMonitor) outputName(name string) string {
+	if d.cfg.OutputPrefix == nil {
+		return name
+	}
+
+	return *d.cfg.OutputPrefix + name
+}
+
+// writeDeviceSnapshot writes a [DeviceSnapshot] to a JSON file (named with [DeviceMonitor.outputName])
+// (gzip-compressed if [DeviceMonitorConfig.OutputCompress] is enabled).
+func (d *DeviceMonitor) writeDeviceSnapshot(snapshot DeviceSnapshot, filename string) error {
+	deviceDir, err := d.ensureDeviceFolder()
+	if err != nil {
+		return fmt.Errorf("failure ensuring folder: %w", err)
+	}
+
+	currentPath := filepath.Join(deviceDir, d.outputName(filename))
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failure marshalling to JSON: %w", err)
+	}
+
+	currentPath, data, err = d.compressOutput(currentPath, data)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(d.fsys, currentPath, data, d.outputFileMode()); err != nil {
+		return fmt.Errorf("failure writing to file: %w", err)
+	}
+
+	return nil
+}
+
+// readOutputFile reads a file of the output folder (named with [DeviceMonitor.outputName]), which can
+// be gzip-compressed (with [compressedSuffix]) regardless of [DeviceMonitorConfig.OutputCompress], so
+// that the files written before toggling it are still recognized. If both exist, the newer one is read
+// (with the one matching [DeviceMonitorConfig.OutputCompress] preferred if both are equally new).
+func (d *DeviceMonitor) readOutputFile(filename string) ([]byte, error) {
+	plainPath := filepath.Join(*d.cfg.OutputDir, d.outputName(filename))
+
+	paths := []string{plainPath, plainPath + compressedSuffix}
+	if d.cfg.OutputCompress != nil && *d.cfg.OutputCompress {
+		paths[0], paths[1] = paths[1], paths[0]
+	}
+
+	path := paths[0]
+	var modTime time.Time
+	var found bool
+	for _, p := range paths {
+		if fi, err := d.fsys.Stat(p); err == nil && (!found || fi.ModTime().After(modTime)) {
+			path, modTime, found = p, fi.ModTime(), true
+		}
+	}
+
+	data, err := afero.ReadFile(d.fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failure reading from file: %w", err)
+	}
+
+	if strings.HasSuffix(path, compressedSuffix) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failure decompressing file: %w", err)
+		}
+		defer zr.Close()
+
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failure decompressing file: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// readDeviceSnapshot reads a [DeviceSnapshot] from a JSON file of the output folder
+// (gzip-compressed or not, see [DeviceMonitor.readOutputFile]).
+func (d *DeviceMonitor) readDeviceSnapshot(filename string) (DeviceSnapshot, error) {
+	var snapshot DeviceSnapshot
+
+	data, err := d.readOutputFile(filename)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failure parsing file: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// writeChangeReport writes a [ChangeReport] to a time-stamped JSON file
+// (gzip-compressed if [DeviceMonitorConfig.OutputCompress] is enabled).
+func (d *DeviceMonitor) writeChangeReport(report ChangeReport) error {
+	deviceDir, err := d.ensureDeviceFolder()
+	if err != nil {
+		return fmt.Errorf("failure ensuring folder: %w", err)
+	}
+
+	timestamp := time.Now().Format(outputTimestampFormat)
+	filename := d.outputName(changeReportPrefix + timestamp + changeReportSuffix)
+	reportPath := filepath.Join(deviceDir, filename)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failure marshalling to JSON: %w", err)
+	}
+
+	reportPath, data, err = d.compressOutput(reportPath, data)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(d.fsys, reportPath, data, d.outputFileMode()); err != nil {
+		return fmt.Errorf("failure writing to file: %w", err)
+	}
+
+	return nil
+}
+
+// appendChangelog appends a [ChangeReport] as a single JSON line to the changelog.
+// The line is written with a single write to a file opened with [os.O_APPEND],
+// with appends serialized per monitor, so lines are ordered and never interleave.
+func (d *DeviceMonitor) appendChangelog(report ChangeReport) error {
+	deviceDir, err := d.ensureDeviceFolder()
+	if err != nil {
+		return fmt.Errorf("failure ensuring folder: %w", err)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failure marshalling to JSON: %w", err)
+	}
+	data = append(data, '\n')
+
+	d.state.changelogMu.Lock()
+	defer d.state.changelogMu.Unlock()
+
+	f, err := d.fsys.OpenFile(filepath.Join(deviceDir, d.outputName(changelogFilename)),
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, d.outputFileMode())
+	if err != nil {
+		return fmt.Errorf("failure opening file: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("failure writing to file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failure closing file: %w", err)
+	}
+
+	return nil
+}
+
+// pruneChangeReports removes the change reports of [DeviceMonitorConfig.OutputDir]
+// that exceed either [DeviceMonitorConfig.OutputMaxReports] (oldest first) or
+// [DeviceMonitorConfig.OutputMaxAge]. Only change report files are ever removed.
+func (d *DeviceMonitor) pruneChangeReports() error {
+	return d.pruneOutputFiles(d.outputName(changeReportPrefix), changeReportSuffix,
+		*d.cfg.OutputMaxReports, *d.cfg.OutputMaxAge)
+}
+
+// pruneSnapshots removes the timestamped snapshots of [DeviceMonitorConfig.OutputDir]
+// that exceed [DeviceMonitorConfig.SnapshotHistory] (oldest first).
+// Only timestamped snapshot files are ever removed.
+func (d *DeviceMonitor) pruneSnapshots() error {
+	return d.pruneOutputFiles(d.outputName(snapshotPrefix), snapshotSuffix, *d.cfg.SnapshotHistory, 0)
+}
+
+// pruneOutputFiles removes the timestamped files (of the given prefix and suffix) of
+// [DeviceMonitorConfig.OutputDir] that exceed either maxFiles (oldest first) or maxAge
+// (with zero meaning unlimited for both). Only files of the prefix and suffix are removed,
+// with gzip-compressed files (of the suffix and [compressedSuffix]) recognized as well.
+func (d *DeviceMonitor) pruneOutputFiles(prefix, suffix string, maxFiles int, maxAge time.Duration) error {
+	if maxFiles == 0 && maxAge == 0 {
+		return nil
+	}
+
+	entries, err := afero.ReadDir(d.fsys, *d.cfg.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failure reading directory: %w", err)
+	}
+
+	var files []string
+	var expired []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) ||
+			(!strings.HasSuffix(e.Name(), suffix) && !strings.HasSuffix(e.Name(), suffix+compressedSuffix)) {
+			continue
+		}
+		if maxAge > 0 && time.Since(e.ModTime()) > maxAge {
+			expired = append(expired, e.Name())
+
+			continue
+		}
+		files = append(files, e.Name())
+	}
+
+	// Timestamped filenames are sorting chronologically (oldest first).
+	sort.Strings(files)
+	if maxFiles > 0 && len(files) > maxFiles {
+		expired = append(expired, files[:len(files)-maxFiles]...)
+	}
+
+	var errs []error
+	for _, name := range expired {
+		if err := d.fsys.Remove(filepath.Join(*d.cfg.OutputDir, name)); err != nil {
+			errs = append(errs, fmt.Errorf("failure removing file: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
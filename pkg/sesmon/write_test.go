@@ -0,0 +1,974 @@
+package sesmon
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+type mockFs struct {
+	afero.Fs
+
+	mkdirAllErr  error
+	writeFileErr error
+	renameErr    error
+}
+
+func (m *mockFs) Rename(oldname, newname string) error {
+	if m.renameErr != nil {
+		return m.renameErr
+	}
+	if m.Fs != nil {
+		return m.Fs.Rename(oldname, newname) //nolint:wrapcheck
+	}
+
+	return errors.New("no filesystem")
+}
+
+func (m *mockFs) MkdirAll(path string, perm os.FileMode) error {
+	if m.mkdirAllErr != nil {
+		return m.mkdirAllErr
+	}
+	if m.Fs != nil {
+		return m.Fs.MkdirAll(path, perm) //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+func (m *mockFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if m.writeFileErr != nil {
+		return nil, m.writeFileErr
+	}
+	if m.Fs != nil {
+		return m.Fs.OpenFile(name, flag, perm) //nolint:wrapcheck
+	}
+
+	return nil, errors.New("no filesystem")
+}
+
+// Expectation: ensureDeviceFolder should create the device directory.
+func Test_DeviceMonitor_ensureDeviceFolder_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: Device{Type: 0, Path: "/dev/sg25"},
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys: fsys,
+	}
+
+	deviceDir, err := m.ensureDeviceFolder()
+	require.NoError(t, err)
+	require.Equal(t, "/output", deviceDir)
+
+	exists, err := afero.DirExists(fsys, "/output")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+// Expectation: Output files and folders should be created with the configured permissions.
+func Test_DeviceMonitor_OutputModes_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir:      ptr("/output/sg25"),
+			OutputFileMode: ptr("0640"),
+			OutputDirMode:  ptr("0750"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+		state:  &deviceMonitorState{},
+	}
+
+	require.NoError(t, m.writeDeviceSnapshot(DeviceSnapshot{Device: dev}, "current.json"))
+
+	fi, err := fsys.Stat("/output/sg25")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o750), fi.Mode().Perm())
+
+	fi, err = fsys.Stat("/output/sg25/current.json")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), fi.Mode().Perm())
+}
+
+// Expectation: ensureDeviceFolder should not fail if directory already exists.
+func Test_DeviceMonitor_ensureDeviceFolder_AlreadyExists_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, fsys.MkdirAll("/output", 0o755))
+
+	m := &DeviceMonitor{
+		device: Device{Type: 0, Path: "/dev/sg25"},
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys: fsys,
+	}
+
+	deviceDir, err := m.ensureDeviceFolder()
+	require.NoError(t, err)
+	require.Equal(t, "/output", deviceDir)
+}
+
+// Expectation: writeDeviceSnapshot should write snapshot to file.
+func Test_DeviceMonitor_writeDeviceSnapshot_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	snapshot := DeviceSnapshot{
+		Device:     dev,
+		CapturedAt: "2025-01-01T12:00:00Z",
+		Raw:        json.RawMessage(`{"key":"value"}`),
+	}
+
+	err := m.writeDeviceSnapshot(snapshot, "snapshot.json")
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fsys, "/output/snapshot.json")
+	require.NoError(t, err)
+
+	var loaded DeviceSnapshot
+	err = json.Unmarshal(data, &loaded)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg25", loaded.Device.Path)
+	require.Equal(t, "test-device", loaded.Device.Description)
+	require.Equal(t, "2025-01-01T12:00:00Z", loaded.CapturedAt)
+	require.JSONEq(t, `{"key":"value"}`, string(loaded.Raw))
+}
+
+// Expectation: writeDeviceSnapshot should overwrite existing snapshot.
+func Test_DeviceMonitor_writeDeviceSnapshot_Overwrite_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	snapshot1 := DeviceSnapshot{
+		Device:     dev,
+		CapturedAt: "2025-01-01T12:00:00Z",
+		Raw:        json.RawMessage(`{"version":1}`),
+	}
+	err := m.writeDeviceSnapshot(snapshot1, "snapshot.json")
+	require.NoError(t, err)
+
+	snapshot2 := DeviceSnapshot{
+		Device:     dev,
+		CapturedAt: "2025-01-01T13:00:00Z",
+		Raw:        json.RawMessage(`{"version":2}`),
+	}
+	err = m.writeDeviceSnapshot(snapshot2, "snapshot.json")
+	require.NoError(t, err)
+
+	data, err := afero.ReadFile(fsys, "/output/snapshot.json")
+	require.NoError(t, err)
+
+	var loaded DeviceSnapshot
+	err = json.Unmarshal(data, &loaded)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"version":2}`, string(loaded.Raw))
+}
+
+// Expectation: writeChangeReport should write change report with timestamp.
+func Test_DeviceMonitor_writeChangeReport_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	report := ChangeReport{
+		Device:     dev,
+		DetectedAt: "2025-01-01T12:00:00Z",
+		Changes: []Change{
+			{
+				ID:       "15#0",
+				Type:     15,
+				TypeDesc: ptr("Enclosure"),
+				TypeNum:  0,
+				Before:   &Result{Status: ptr(1)},
+				After:    &Result{Status: ptr(2)},
+			},
+		},
+	}
+
+	err := m.writeChangeReport(report)
+	require.NoError(t, err)
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Contains(t, files[0].Name(), "change-")
+	require.Contains(t, files[0].Name(), ".json")
+
+	data, err := afero.ReadFile(fsys, "/output/"+files[0].Name())
+	require.NoError(t, err)
+
+	var loaded ChangeReport
+	err = json.Unmarshal(data, &loaded)
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg25", loaded.Device.Path)
+	require.Len(t, loaded.Changes, 1)
+}
+
+// Expectation: writeChangeReport should create multiple reports without overwriting.
+func Test_DeviceMonitor_writeChangeReport_MultipleReports_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	report1 := ChangeReport{
+		Device:     dev,
+		DetectedAt: "2025-01-01T12:00:00Z",
+		Changes:    []Change{{ID: "15#0", Type: 15, TypeNum: 0}},
+	}
+	err := m.writeChangeReport(report1)
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Second) // Ensure different timestamp
+
+	report2 := ChangeReport{
+		Device:     dev,
+		DetectedAt: "2025-01-01T13:00:00Z",
+		Changes:    []Change{{ID: "23#1", Type: 23, TypeNum: 1}},
+	}
+	err = m.writeChangeReport(report2)
+	require.NoError(t, err)
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+// Expectation: ensureDeviceFolder should return error when MkdirAll fails.
+func Test_DeviceMonitor_ensureDeviceFolder_MkdirAllError(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("mkdir error")
+	fsys := &mockFs{
+		mkdirAllErr: expectedErr,
+	}
+	outputDir := "/test/output"
+	dm := &DeviceMonitor{
+		fsys: fsys,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: &outputDir,
+		},
+	}
+
+	path, err := dm.ensureDeviceFolder()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure creating directory")
+	require.Empty(t, path)
+}
+
+// Expectation: writeDeviceSnapshot should return error when ensureDeviceFolder fails.
+func Test_DeviceMonitor_writeDeviceSnapshot_EnsureFolderError(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("mkdir error")
+	fsys := &mockFs{
+		mkdirAllErr: expectedErr,
+	}
+	outputDir := "/test/output"
+	dm := &DeviceMonitor{
+		fsys: fsys,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: &outputDir,
+		},
+	}
+
+	snapshot := DeviceSnapshot{
+		Device:     Device{Path: "/dev/sg0"},
+		CapturedAt: time.Now().Format(time.RFC3339),
+		Raw:        json.RawMessage(`{}`),
+	}
+
+	err := dm.writeDeviceSnapshot(snapshot, "test.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure ensuring folder")
+}
+
+// Expectation: writeDeviceSnapshot should return error when WriteFile fails.
+func Test_DeviceMonitor_writeDeviceSnapshot_WriteFileError(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("write error")
+	fsys := &mockFs{
+		Fs:           afero.NewMemMapFs(),
+		writeFileErr: expectedErr,
+	}
+	outputDir := "/test/output"
+	dm := &DeviceMonitor{
+		fsys: fsys,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: &outputDir,
+		},
+	}
+
+	snapshot := DeviceSnapshot{
+		Device:     Device{Path: "/dev/sg0"},
+		CapturedAt: time.Now().Format(time.RFC3339),
+		Raw:        json.RawMessage(`{}`),
+	}
+
+	err := dm.writeDeviceSnapshot(snapshot, "test.json")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure writing to file")
+}
+
+// Expectation: writeChangeReport should return error when ensureDeviceFolder fails.
+func Test_DeviceMonitor_writeChangeReport_EnsureFolderError(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("mkdir error")
+	fsys := &mockFs{
+		mkdirAllErr: expectedErr,
+	}
+	outputDir := "/test/output"
+	dm := &DeviceMonitor{
+		fsys: fsys,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: &outputDir,
+		},
+	}
+
+	report := ChangeReport{
+		Device:     Device{Path: "/dev/sg0"},
+		DetectedAt: time.Now().Format(time.RFC3339),
+		Changes:    []Change{},
+	}
+
+	err := dm.writeChangeReport(report)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure ensuring folder")
+}
+
+// Expectation: writeChangeReport should return error when WriteFile fails.
+func Test_DeviceMonitor_writeChangeReport_WriteFileError(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("write error")
+	fsys := &mockFs{
+		Fs:           afero.NewMemMapFs(),
+		writeFileErr: expectedErr,
+	}
+	outputDir := "/test/output"
+	dm := &DeviceMonitor{
+		fsys: fsys,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: &outputDir,
+		},
+	}
+
+	report := ChangeReport{
+		Device:     Device{Path: "/dev/sg0"},
+		DetectedAt: time.Now().Format(time.RFC3339),
+		Changes:    []Change{},
+	}
+
+	err := dm.writeChangeReport(report)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure writing to file")
+}
+
+// Expectation: pruneChangeReports should keep only the newest change reports beyond the limit.
+func Test_DeviceMonitor_pruneChangeReports_MaxReports_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:        ptr("/output"),
+			OutputMaxReports: ptr(2),
+			OutputMaxAge:     ptr(time.Duration(0)),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	for _, name := range []string{
+		"change-20250101-120000.json",
+		"change-20250102-120000.json",
+		"change-20250103-120000.json",
+		"change-20250104-120000.json",
+		"current.json",
+		"current_parsed.json",
+	} {
+		require.NoError(t, afero.WriteFile(fsys, "/output/"+name, []byte("{}"), 0o644))
+	}
+
+	require.NoError(t, m.pruneChangeReports())
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	require.ElementsMatch(t, []string{
+		"change-20250103-120000.json",
+		"change-20250104-120000.json",
+		"current.json",
+		"current_parsed.json",
+	}, names)
+}
+
+// Expectation: pruneChangeReports should remove only change reports older than the maximum age.
+func Test_DeviceMonitor_pruneChangeReports_MaxAge_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:        ptr("/output"),
+			OutputMaxReports: ptr(0),
+			OutputMaxAge:     ptr(24 * time.Hour),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"change-20250101-120000.json", "change-20250102-120000.json", "current.json"} {
+		require.NoError(t, afero.WriteFile(fsys, "/output/"+name, []byte("{}"), 0o644))
+	}
+	require.NoError(t, fsys.Chtimes("/output/change-20250101-120000.json", old, old))
+	require.NoError(t, fsys.Chtimes("/output/current.json", old, old))
+
+	require.NoError(t, m.pruneChangeReports())
+
+	exists, err := afero.Exists(fsys, "/output/change-20250101-120000.json")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	exists, err = afero.Exists(fsys, "/output/change-20250102-120000.json")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = afero.Exists(fsys, "/output/current.json")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+// Expectation: pruneChangeReports should not remove anything when no limits are configured.
+func Test_DeviceMonitor_pruneChangeReports_Unlimited_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:        ptr("/output"),
+			OutputMaxReports: ptr(0),
+			OutputMaxAge:     ptr(time.Duration(0)),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	for _, name := range []string{"change-20250101-120000.json", "change-20250102-120000.json"} {
+		require.NoError(t, afero.WriteFile(fsys, "/output/"+name, []byte("{}"), 0o644))
+	}
+
+	require.NoError(t, m.pruneChangeReports())
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+// Expectation: pruneChangeReports should return an error when the directory cannot be read.
+func Test_DeviceMonitor_pruneChangeReports_ReadDirError(t *testing.T) {
+	t.Parallel()
+
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:        ptr("/nonexistent"),
+			OutputMaxReports: ptr(1),
+			OutputMaxAge:     ptr(time.Duration(0)),
+		},
+		fsys:   afero.NewMemMapFs(),
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	err := m.pruneChangeReports()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure reading directory")
+}
+
+// Expectation: appendChangelog should append each change report as a single JSON line.
+func Test_DeviceMonitor_appendChangelog_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+		state:  newDeviceMonitorState(),
+	}
+
+	require.NoError(t, m.appendChangelog(ChangeReport{Device: dev, DetectedAt: "first"}))
+	require.NoError(t, m.appendChangelog(ChangeReport{Device: dev, DetectedAt: "second"}))
+
+	data, err := afero.ReadFile(fsys, "/output/changelog.jsonl")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var first, second ChangeReport
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	require.Equal(t, "first", first.DetectedAt)
+	require.Equal(t, "second", second.DetectedAt)
+}
+
+// Expectation: appendChangelog should never interleave lines with concurrent appends.
+func Test_DeviceMonitor_appendChangelog_Concurrent_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+		state:  newDeviceMonitorState(),
+	}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			require.NoError(t, m.appendChangelog(ChangeReport{DetectedAt: strconv.Itoa(i)}))
+		})
+	}
+	wg.Wait()
+
+	data, err := afero.ReadFile(fsys, "/output/changelog.jsonl")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 50)
+
+	for _, line := range lines {
+		var report ChangeReport
+		require.NoError(t, json.Unmarshal([]byte(line), &report))
+	}
+}
+
+// Expectation: appendChangelog should return an error when the file cannot be opened.
+func Test_DeviceMonitor_appendChangelog_OpenFileError(t *testing.T) {
+	t.Parallel()
+
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir: ptr("/output"),
+		},
+		fsys: &mockFs{
+			Fs:           afero.NewMemMapFs(),
+			writeFileErr: errors.New("open failed"),
+		},
+		logger: slog.New(slog.DiscardHandler),
+		state:  newDeviceMonitorState(),
+	}
+
+	err := m.appendChangelog(ChangeReport{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failure opening file")
+}
+
+// Expectation: writeFileAtomic should write the file with permissions and leave no temporary files.
+func Test_writeFileAtomic_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	require.NoError(t, fsys.MkdirAll("/output", 0o755))
+	require.NoError(t, afero.WriteFile(fsys, "/output/current.json", []byte("old"), 0o644))
+
+	require.NoError(t, writeFileAtomic(fsys, "/output/current.json", []byte("new"), 0o640))
+
+	data, err := afero.ReadFile(fsys, "/output/current.json")
+	require.NoError(t, err)
+	require.Equal(t, "new", string(data))
+
+	st, err := fsys.Stat("/output/current.json")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), st.Mode().Perm())
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+// Expectation: writeFileAtomic should keep the original file and remove the temporary file on failure.
+func Test_writeFileAtomic_RenameError(t *testing.T) {
+	t.Parallel()
+
+	base := afero.NewMemMapFs()
+	require.NoError(t, base.MkdirAll("/output", 0o755))
+	require.NoError(t, afero.WriteFile(base, "/output/current.json", []byte("old"), 0o644))
+
+	fsys := &mockFs{Fs: base, renameErr: errors.New("rename failed")}
+
+	err := writeFileAtomic(fsys, "/output/current.json", []byte("new"), 0o644)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "rename failed")
+
+	data, err := afero.ReadFile(base, "/output/current.json")
+	require.NoError(t, err)
+	require.Equal(t, "old", string(data))
+
+	files, err := afero.ReadDir(base, "/output")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+// Expectation: pruneSnapshots should keep only the newest snapshots and never touch other files.
+func Test_DeviceMonitor_pruneSnapshots_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:       ptr("/output"),
+			SnapshotHistory: ptr(2),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	for _, name := range []string{
+		"snapshot-20250101-120000.json",
+		"snapshot-20250102-120000.json",
+		"snapshot-20250103-120000.json",
+		"change-20250101-120000.json",
+		"current_parsed.json",
+	} {
+		require.NoError(t, afero.WriteFile(fsys, "/output/"+name, []byte("{}"), 0o644))
+	}
+
+	require.NoError(t, m.pruneSnapshots())
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	require.ElementsMatch(t, []string{
+		"snapshot-20250102-120000.json",
+		"snapshot-20250103-120000.json",
+		"change-20250101-120000.json",
+		"current_parsed.json",
+	}, names)
+}
+
+// Expectation: writeChangeReport should write a gzip-compressed change report when enabled.
+func Test_DeviceMonitor_writeChangeReport_Compressed_Success(t *testing.T) {
+	t.Parallel()
+
+	dev := Device{Type: 0, Path: "/dev/sg25", Description: "test-device"}
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		device: dev,
+		cfg: &DeviceMonitorConfig{
+			OutputDir:      ptr("/output"),
+			OutputCompress: ptr(true),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	require.NoError(t, m.writeChangeReport(ChangeReport{Device: dev, DetectedAt: "2025-01-01T12:00:00Z"}))
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasPrefix(files[0].Name(), changeReportPrefix))
+	require.True(t, strings.HasSuffix(files[0].Name(), changeReportSuffix+compressedSuffix))
+
+	f, err := fsys.Open("/output/" + files[0].Name())
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var loaded ChangeReport
+	require.NoError(t, json.NewDecoder(zr).Decode(&loaded))
+	require.Equal(t, "/dev/sg25", loaded.Device.Path)
+}
+
+// Expectation: writeDeviceSnapshot should write a gzip-compressed snapshot when enabled.
+func Test_DeviceMonitor_writeDeviceSnapshot_Compressed_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:      ptr("/output"),
+			OutputCompress: ptr(true),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	snapshot := DeviceSnapshot{
+		Device:     Device{Path: "/dev/sg0"},
+		CapturedAt: "2025-01-01T12:00:00Z",
+		Raw:        json.RawMessage(`{"test":"data"}`),
+	}
+	require.NoError(t, m.writeDeviceSnapshot(snapshot, "current.json"))
+
+	exists, err := afero.Exists(fsys, "/output/current.json")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	f, err := fsys.Open("/output/current.json.gz")
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	require.Equal(t, "current.json", zr.Name)
+
+	var loaded DeviceSnapshot
+	require.NoError(t, json.NewDecoder(zr).Decode(&loaded))
+	require.Equal(t, "/dev/sg0", loaded.Device.Path)
+}
+
+// Expectation: readDeviceSnapshot should read a snapshot written before toggling output_compress
+// (either way), and the newer one if both a plain and a gzip-compressed snapshot exist.
+func Test_DeviceMonitor_readDeviceSnapshot_CompressToggled_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:      ptr("/output"),
+			OutputCompress: ptr(true),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	require.NoError(t, m.writeDeviceSnapshot(DeviceSnapshot{Device: Device{Path: "/dev/sg0"}}, "current_parsed.json"))
+
+	m.cfg.OutputCompress = ptr(false)
+	snapshot, err := m.readDeviceSnapshot("current_parsed.json")
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", snapshot.Device.Path)
+
+	require.NoError(t, m.writeDeviceSnapshot(DeviceSnapshot{Device: Device{Path: "/dev/sg1"}}, "current_parsed.json"))
+	require.NoError(t, fsys.Chtimes("/output/current_parsed.json.gz", time.Now(), time.Now().Add(-time.Hour)))
+
+	m.cfg.OutputCompress = ptr(true)
+	snapshot, err = m.readDeviceSnapshot("current_parsed.json")
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg1", snapshot.Device.Path)
+
+	require.NoError(t, fsys.Remove("/output/current_parsed.json"))
+	snapshot, err = m.readDeviceSnapshot("current_parsed.json")
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg0", snapshot.Device.Path)
+
+	require.NoError(t, fsys.Remove("/output/current_parsed.json.gz"))
+	_, err = m.readDeviceSnapshot("current_parsed.json")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// Expectation: pruneChangeReports should recognize both plain and gzip-compressed change reports.
+func Test_DeviceMonitor_pruneChangeReports_Compressed_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:        ptr("/output"),
+			OutputMaxReports: ptr(2),
+			OutputMaxAge:     ptr(time.Duration(0)),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	for _, name := range []string{
+		"change-20250101-120000.json",
+		"change-20250102-120000.json.gz",
+		"change-20250103-120000.json",
+		"change-20250104-120000.json.gz",
+		"current.json.gz",
+	} {
+		require.NoError(t, afero.WriteFile(fsys, "/output/"+name, []byte("{}"), 0o644))
+	}
+
+	require.NoError(t, m.pruneChangeReports())
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	require.ElementsMatch(t, []string{
+		"change-20250103-120000.json",
+		"change-20250104-120000.json.gz",
+		"current.json.gz",
+	}, names)
+}
+
+// Expectation: poll should write all files of the output folder with the output prefix (and read them back).
+func Test_DeviceMonitor_poll_OutputPrefix_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	runner := &mockCommandRunner{}
+
+	m := newTestDeviceMonitor(t,
+		Device{Type: 0, Path: "/dev/sg25"},
+		&DeviceMonitorConfig{
+			OutputDir:          ptr("/output"),
+			OutputPrefix:       ptr("sesmon-"),
+			WriteChangeReports: ptr(true),
+			OutputChangelog:    ptr(true),
+		},
+		fsys,
+		runner,
+		slog.New(slog.DiscardHandler),
+		newMockNotifier(),
+	)
+
+	for _, out := range []string{jsonAckElement(1, 0), jsonAckElement(2, 0)} {
+		runner.setResponse(out, "", nil)
+		require.NoError(t, m.poll(t.Context()))
+	}
+	m.state.notifications.Wait()
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+	for _, f := range files {
+		require.True(t, strings.HasPrefix(f.Name(), "sesmon-"), f.Name())
+	}
+
+	for _, name := range []string{"sesmon-current.json", "sesmon-current_parsed.json", "sesmon-changelog.jsonl"} {
+		exists, err := afero.Exists(fsys, "/output/"+name)
+		require.NoError(t, err)
+		require.True(t, exists, name)
+	}
+
+	reports, err := afero.Glob(fsys, "/output/sesmon-change-*.json")
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	snapshot, err := m.readDeviceSnapshot("current_parsed.json")
+	require.NoError(t, err)
+	require.Equal(t, "/dev/sg25", snapshot.Device.Path)
+}
+
+// Expectation: pruneChangeReports should only remove the change reports of the output prefix.
+func Test_DeviceMonitor_pruneChangeReports_OutputPrefix_Success(t *testing.T) {
+	t.Parallel()
+
+	fsys := afero.NewMemMapFs()
+	m := &DeviceMonitor{
+		cfg: &DeviceMonitorConfig{
+			OutputDir:        ptr("/output"),
+			OutputPrefix:     ptr("sesmon-"),
+			OutputMaxReports: ptr(1),
+			OutputMaxAge:     ptr(time.Duration(0)),
+		},
+		fsys:   fsys,
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	for _, name := range []string{
+		"sesmon-change-20250101-120000.json",
+		"sesmon-change-20250102-120000.json",
+		"change-20250101-120000.json",
+		"change-20250102-120000.json",
+	} {
+		require.NoError(t, afero.WriteFile(fsys, "/output/"+name, []byte("{}"), 0o644))
+	}
+
+	require.NoError(t, m.pruneChangeReports())
+
+	files, err := afero.ReadDir(fsys, "/output")
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	require.ElementsMatch(t, []string{
+		"sesmon-change-20250102-120000.json",
+		"change-20250101-120000.json",
+		"change-20250102-120000.json",
+	}, names)
+}